@@ -0,0 +1,455 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"ryan-mall/internal/config"
+	"ryan-mall/internal/repository"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/idgen"
+	"ryan-mall/pkg/redis"
+	"ryan-mall/pkg/scheduler"
+	"strconv"
+	"time"
+)
+
+// 定时任务名称
+const (
+	orderExpireJob    = "order-expire"
+	orderTimeoutJob   = "order-timeout"
+	autoConfirmJob    = "order-auto-confirm"
+	idempotencyJob    = "idempotency-cleanup"
+	inventoryJob      = "inventory-reconcile"
+	rankingJob        = "ranking-snapshot"
+	messageJob        = "message-dispatch"
+	refundJob         = "payment-refund"
+	refundCompleteJob = "refund-complete"
+)
+
+// rankingSnapshotSize 每个排行榜写回数据库的名次数
+const rankingSnapshotSize = 100
+
+// notificationChannel 站内通知实时推送的Redis频道
+const notificationChannel = "notify:push"
+
+// initRedis 初始化Redis连接
+// Redis不可用时返回nil，依赖Redis的功能降级运行
+func initRedis(cfg *config.Config) *redis.RedisManager {
+	var rm *redis.RedisManager
+	if cfg.Redis.ClusterEnabled && len(cfg.Redis.ClusterNodes) > 0 {
+		rm = redis.NewRedisClusterManager(cfg.Redis.ClusterNodes, cfg.Redis.Password)
+	} else {
+		rm = redis.NewRedisManager(cfg.Redis.Host+":"+cfg.Redis.Port, cfg.Redis.Password, cfg.Redis.DB)
+	}
+
+	if err := rm.Ping(); err != nil {
+		log.Printf("⚠️  Redis不可用，相关功能降级运行: %v", err)
+		return nil
+	}
+
+	log.Println("✅ Redis连接初始化完成")
+	return rm
+}
+
+// newOrderTimeoutQueue 创建订单支付超时延时队列，Redis不可用时返回nil
+func newOrderTimeoutQueue(rm *redis.RedisManager) *redis.DelayQueue {
+	if rm == nil {
+		return nil
+	}
+	// 领取后1分钟内未确认的消息会重新投递
+	return rm.NewDelayQueue(orderTimeoutJob, time.Minute)
+}
+
+// newHotSearchStore 创建热门搜索词存储，Redis不可用时返回nil
+// 热搜榜合并最近7天的搜索次数，每早一天权重减半
+func newHotSearchStore(rm *redis.RedisManager) service.HotSearchStore {
+	if rm == nil {
+		return nil
+	}
+	return redis.NewHotSearchManager(rm, 7, 0.5)
+}
+
+// newProductRankingStore 创建商品热度排行存储，Redis不可用时返回nil
+func newProductRankingStore(rm *redis.RedisManager) service.ProductRankingStore {
+	if rm == nil {
+		return nil
+	}
+	return redis.NewProductRankingManager(rm)
+}
+
+// newNotificationBus 创建站内通知推送总线，Redis不可用时返回nil，此时只能推送给本实例上的连接
+func newNotificationBus(rm *redis.RedisManager) *redis.NotificationBus {
+	if rm == nil {
+		return nil
+	}
+	return redis.NewNotificationBus(rm, notificationChannel)
+}
+
+// openMessageOutbox 打开本地发件箱文件，路径为空时使用标准输出
+func openMessageOutbox(path string) (*os.File, error) {
+	if path == "" {
+		return os.Stdout, nil
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+}
+
+// newIDGenerator 创建单号生成器
+// 未配置固定WorkerID时从Redis租用，Redis不可用时使用WorkerID 0，此时只能单实例部署
+func newIDGenerator(cfg *config.Config, rm *redis.RedisManager) (*idgen.Generator, error) {
+	if cfg.IDGen.WorkerID >= 0 {
+		return idgen.New(int64(cfg.IDGen.WorkerID))
+	}
+	if rm == nil {
+		log.Println("⚠️  Redis不可用，单号生成器使用WorkerID 0，多实例部署时请设置IDGEN_WORKER_ID")
+		return idgen.New(0)
+	}
+
+	g, err := idgen.NewLeased(rm, instanceID(), time.Duration(cfg.IDGen.LeaseSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ 单号生成器初始化完成 (WorkerID %d)", g.WorkerID())
+	return g, nil
+}
+
+// instanceID 当前实例标识，用于分布式锁和租约的持有者
+func instanceID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// newScheduler 创建内置任务调度器
+// Redis可用时通过分布式锁选主，只有主实例执行任务，断点保存在Redis中；
+// Redis不可用时以单实例模式运行，任务本身是幂等的，多实例同时执行也不会重复处理
+func newScheduler(cfg *config.Config, rm *redis.RedisManager, timeoutQueue *redis.DelayQueue, orderService service.OrderService, shipmentService service.ShipmentService, inventoryService service.InventoryService, productService *service.CachedProductService, rankingService service.RankingService, messageService service.MessageService, paymentService service.PaymentService, refundService service.RefundService, idempotencyRepo repository.IdempotencyRepository) *scheduler.Scheduler {
+	leaseTTL := time.Duration(cfg.Scheduler.LeaseSeconds) * time.Second
+
+	var locker scheduler.Locker
+	checkpoint := scheduler.NewMemoryCheckpoint()
+	if rm != nil {
+		locker = rm.NewDistributedLock("scheduler:leader", instanceID(), leaseTTL)
+		checkpoint = scheduler.NewRedisCheckpoint(rm, "scheduler:checkpoint:")
+	}
+
+	s := scheduler.New(locker, leaseTTL)
+	if timeoutQueue != nil {
+		s.Add(scheduler.Job{
+			Name:     orderTimeoutJob,
+			Interval: time.Duration(cfg.Scheduler.OrderTimeoutInterval) * time.Second,
+			Run:      cancelTimeoutOrders(orderService, productService, timeoutQueue, cfg.Order.ExpireBatchSize),
+		})
+	}
+	// 兜底扫描：处理延时队列登记失败或Redis不可用期间创建的订单
+	s.Add(scheduler.Job{
+		Name:     orderExpireJob,
+		Interval: time.Duration(cfg.Scheduler.OrderExpireInterval) * time.Second,
+		Run:      expireOrders(orderService, productService, checkpoint, cfg.Order.ExpireBatchSize),
+	})
+	s.Add(scheduler.Job{
+		Name:     autoConfirmJob,
+		Interval: time.Duration(cfg.Scheduler.AutoConfirmInterval) * time.Second,
+		Run:      autoConfirmOrders(shipmentService, cfg.Order.ExpireBatchSize),
+	})
+	s.Add(scheduler.Job{
+		Name:     idempotencyJob,
+		Interval: time.Duration(cfg.Scheduler.IdempotencyInterval) * time.Second,
+		Run:      cleanupIdempotencyRecords(idempotencyRepo, cfg.Order.ExpireBatchSize),
+	})
+	s.Add(scheduler.Job{
+		Name:     inventoryJob,
+		Interval: time.Duration(cfg.Scheduler.InventoryInterval) * time.Second,
+		Run:      reconcileInventory(inventoryService, productService, cfg.Order.ExpireBatchSize),
+	})
+	s.Add(scheduler.Job{
+		Name:     messageJob,
+		Interval: time.Duration(cfg.Scheduler.MessageInterval) * time.Second,
+		Run:      dispatchMessages(messageService, cfg.Order.ExpireBatchSize),
+	})
+	s.Add(scheduler.Job{
+		Name:     refundJob,
+		Interval: time.Duration(cfg.Scheduler.PaymentRefundInterval) * time.Second,
+		Run:      retryPendingRefunds(paymentService, cfg.Order.ExpireBatchSize),
+	})
+	s.Add(scheduler.Job{
+		Name:     refundCompleteJob,
+		Interval: time.Duration(cfg.Scheduler.RefundCompleteInterval) * time.Second,
+		Run:      completeRefunds(refundService, cfg.Order.ExpireBatchSize),
+	})
+	if rm != nil {
+		s.Add(scheduler.Job{
+			Name:     rankingJob,
+			Interval: time.Duration(cfg.Scheduler.RankingInterval) * time.Second,
+			Run:      snapshotRankings(rankingService, rankingSnapshotSize),
+		})
+	}
+
+	return s
+}
+
+// expireOrders 过期订单任务
+// 按订单ID分批取消超时未支付的订单，每批完成后保存断点并清除释放了库存的商品缓存
+func expireOrders(orderService service.OrderService, productService *service.CachedProductService, checkpoint scheduler.Checkpoint, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		// 1. 从断点继续上次中断的处理
+		var afterID uint
+		value, err := checkpoint.Load(orderExpireJob)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid checkpoint %q: %w", value, err)
+			}
+			afterID = uint(id)
+		}
+
+		// 2. 分批处理
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			batch, err := orderService.CancelExpiredOrders(afterID, batchSize)
+			if err != nil {
+				return err
+			}
+
+			productService.InvalidateProducts(batch.ProductIDs...)
+			if batch.Cancelled > 0 {
+				log.Printf("order expire: cancelled %d orders, released stock for %d products", batch.Cancelled, len(batch.ProductIDs))
+			}
+
+			// 3. 全部处理完成，清除断点
+			if batch.Scanned < batchSize {
+				return checkpoint.Clear(orderExpireJob)
+			}
+
+			afterID = batch.LastID
+			if err := checkpoint.Save(orderExpireJob, strconv.FormatUint(uint64(afterID), 10)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// cancelTimeoutOrders 订单支付超时任务
+// 从延时队列领取到期的订单并取消，处理成功后确认消息；失败的消息超时后会重新投递
+func cancelTimeoutOrders(orderService service.OrderService, productService *service.CachedProductService, queue *redis.DelayQueue, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			// 1. 领取到期消息
+			members, err := queue.Claim(batchSize)
+			if err != nil {
+				return err
+			}
+			if len(members) == 0 {
+				return nil
+			}
+
+			orderIDs := make([]uint, 0, len(members))
+			for _, member := range members {
+				id, err := strconv.ParseUint(member, 10, 64)
+				if err != nil {
+					log.Printf("order timeout: invalid order id %q, dropped", member)
+					continue
+				}
+				orderIDs = append(orderIDs, uint(id))
+			}
+
+			// 2. 取消仍未支付的订单
+			batch, err := orderService.CancelTimeoutOrders(orderIDs)
+			if err != nil {
+				return err
+			}
+
+			productService.InvalidateProducts(batch.ProductIDs...)
+			if batch.Cancelled > 0 {
+				log.Printf("order timeout: cancelled %d orders, released stock for %d products", batch.Cancelled, len(batch.ProductIDs))
+			}
+
+			// 3. 确认消息
+			if err := queue.Ack(members...); err != nil {
+				return err
+			}
+
+			if len(members) < batchSize {
+				return nil
+			}
+		}
+	}
+}
+
+// autoConfirmOrders 自动确认收货任务
+// 已发货订单在最后一条物流轨迹之后超过设定天数仍未确认收货的，由系统确认
+func autoConfirmOrders(shipmentService service.ShipmentService, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			confirmed, err := shipmentService.AutoConfirmOrders(batchSize)
+			if err != nil {
+				return err
+			}
+			if confirmed > 0 {
+				log.Printf("order auto confirm: confirmed %d orders", confirmed)
+			}
+
+			if confirmed < batchSize {
+				return nil
+			}
+		}
+	}
+}
+
+// cleanupIdempotencyRecords 过期幂等记录清理任务
+// 幂等键过期后不再生效，分批删除避免长时间锁表
+func cleanupIdempotencyRecords(idempotencyRepo repository.IdempotencyRepository, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now()
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			deleted, err := idempotencyRepo.DeleteExpired(now, batchSize)
+			if err != nil {
+				return err
+			}
+			if deleted > 0 {
+				log.Printf("idempotency cleanup: deleted %d expired records", deleted)
+			}
+
+			if deleted < int64(batchSize) {
+				return nil
+			}
+		}
+	}
+}
+
+// reconcileInventory 库存预占对账任务
+// 先处理订单已支付或已取消但仍在预占的记录，再按预占记录逐批修正商品的预占库存
+func reconcileInventory(inventoryService service.InventoryService, productService *service.CachedProductService, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		// 1. 处理遗留的预占记录
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			settled, productIDs, err := inventoryService.SettleStaleReservations(batchSize)
+			if err != nil {
+				return err
+			}
+
+			productService.InvalidateProducts(productIDs...)
+			if settled > 0 {
+				log.Printf("inventory reconcile: settled stale reservations of %d orders", settled)
+			}
+
+			if settled < batchSize {
+				break
+			}
+		}
+
+		// 2. 修正商品的预占库存
+		var afterID uint
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			batch, err := inventoryService.ReconcileReservedStock(afterID, batchSize)
+			if err != nil {
+				return err
+			}
+
+			productService.InvalidateProducts(batch.ProductIDs...)
+			if len(batch.ProductIDs) > 0 {
+				log.Printf("inventory reconcile: fixed reserved stock of products %v", batch.ProductIDs)
+			}
+
+			if batch.Scanned < batchSize {
+				return nil
+			}
+			afterID = batch.LastID
+		}
+	}
+}
+
+// snapshotRankings 商品热度排行快照任务
+// 将Redis中全站和各分类、各时间窗口的排行写回数据库，用于分析热度趋势
+func snapshotRankings(rankingService service.RankingService, size int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		saved, err := rankingService.SnapshotRankings(size)
+		if err != nil {
+			return err
+		}
+		if saved > 0 {
+			log.Printf("ranking snapshot: saved %d entries", saved)
+		}
+		return nil
+	}
+}
+
+// dispatchMessages 邮件、短信发送任务
+// 分批发送订单事件生成的待发消息，发送失败的消息按退避策略重试，重试用尽后标记为失败
+func dispatchMessages(messageService service.MessageService, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			sent, failed, err := messageService.DispatchPending(ctx, batchSize)
+			if err != nil {
+				return err
+			}
+			if sent > 0 || failed > 0 {
+				log.Printf("message dispatch: sent %d messages, %d failed", sent, failed)
+			}
+
+			if sent+failed < batchSize {
+				return nil
+			}
+		}
+	}
+}
+
+// retryPendingRefunds 自动退款重试任务
+// 订单已不再等待时到账的支付需要原路退回，渠道退款失败的每次重试一批，失败的留待下次
+func retryPendingRefunds(paymentService service.PaymentService, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		refunded, failed, err := paymentService.RetryPendingRefunds(batchSize)
+		if err != nil {
+			return err
+		}
+		if refunded > 0 || failed > 0 {
+			log.Printf("payment refund: refunded %d payments, %d failed", refunded, failed)
+		}
+		return nil
+	}
+}
+
+// completeRefunds 售后退款补完任务
+// 渠道已退款但订单商品、库存和订单状态没有更新成功的退款申请，每次重试一批，失败的留待下次
+func completeRefunds(refundService service.RefundService, batchSize int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		completed, failed, err := refundService.CompleteRefunds(batchSize)
+		if err != nil {
+			return err
+		}
+		if completed > 0 || failed > 0 {
+			log.Printf("refund complete: completed %d refunds, %d failed", completed, failed)
+		}
+		return nil
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"ryan-mall/internal/config"
 	"ryan-mall/internal/handler"
 	"ryan-mall/internal/middleware"
@@ -12,7 +15,14 @@ import (
 	"ryan-mall/pkg/cache"
 	"ryan-mall/pkg/database"
 	"ryan-mall/pkg/jwt"
+	"ryan-mall/pkg/logistics"
+	"ryan-mall/pkg/messaging"
+	"ryan-mall/pkg/payment"
+	"ryan-mall/pkg/push"
 	"ryan-mall/pkg/response"
+	"ryan-mall/pkg/retry"
+	"ryan-mall/pkg/search"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,32 +44,59 @@ func main() {
 	// GORM会根据模型结构自动创建和更新表
 	if err := database.AutoMigrate(
 		&model.User{},
+		&model.Address{},
 		&model.Category{},
 		&model.Product{},
 		&model.CartItem{},
 		&model.Order{},
 		&model.OrderItem{},
+		&model.Payment{},
+		&model.Refund{},
+		&model.RefundItem{},
+		&model.OrderStatusLog{},
+		&model.CouponTemplate{},
+		&model.UserCoupon{},
+		&model.Shipment{},
+		&model.ShipmentItem{},
+		&model.ShipmentEvent{},
+		&model.IdempotencyRecord{},
+		&model.InventoryReservation{},
+		&model.Warehouse{},
+		&model.WarehouseStock{},
+		&model.ProductSku{},
+		&model.ProductRankingSnapshot{},
+		&model.ProductReview{},
+		&model.ReviewHelpfulVote{},
+		&model.Favorite{},
+		&model.Notification{},
+		&model.OutboxMessage{},
+		&model.MessagePreference{},
+		&model.Permission{},
+		&model.Role{},
+		&model.UserRole{},
+		&model.AdminAuditLog{},
 	); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	// 库存预占记录的唯一索引先后加入了仓库和SKU，删除旧索引
+	if err := database.DropIndexes(&model.InventoryReservation{}, "idx_order_product", "idx_order_product_warehouse"); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
 
 	// 4. 初始化缓存系统 - 必须在创建服务之前
 	// 优化为16分片，减少哈希计算开销
 	cache.SetGlobalCache(cache.NewShardedCache(16))
 	log.Println("✅ 分片缓存系统初始化完成 (16分片，性能优化)")
 
-	// 5. 初始化Redis集群（如果启用）
-	// var redisCluster interface{}
-	// if cfg.Redis.ClusterEnabled && len(cfg.Redis.ClusterNodes) > 0 {
-	// 	log.Println("🔗 初始化Redis集群...")
-	// 	// 这里暂时注释掉，因为需要先启动集群
-	// 	// redisCluster = redisManager.NewClusterManager(cfg.Redis.ClusterNodes, cfg.Redis.Password)
-	// 	// log.Printf("✅ Redis集群初始化完成，节点数: %d", len(cfg.Redis.ClusterNodes))
-	// 	log.Println("⚠️  Redis集群配置已检测到，但暂未启用（需要先启动集群）")
-	// } else {
-	// 	log.Println("📝 使用内存缓存模式（Redis集群未启用）")
-	// }
-	// _ = redisCluster // 避免未使用变量警告
+	// 5. 初始化Redis（不可用时降级运行）
+	redisManager := initRedis(cfg)
+
+	// 单号生成器：订单号、支付单号、退款单号
+	idGenerator, err := newIDGenerator(cfg, redisManager)
+	if err != nil {
+		log.Fatal("Failed to initialize id generator:", err)
+	}
+	defer idGenerator.Close()
 
 	// 5. 初始化依赖组件
 	// 创建JWT管理器
@@ -67,33 +104,203 @@ func main() {
 
 	// 创建数据访问层
 	userRepo := repository.NewUserRepository(database.GetDB())
+	addressRepo := repository.NewAddressRepository(database.GetDB())
 	productRepo := repository.NewProductRepository(database.GetDB())
 	categoryRepo := repository.NewCategoryRepository(database.GetDB())
 	cartRepo := repository.NewCartRepository(database.GetDB())
 	orderRepo := repository.NewOrderRepository(database.GetDB())
+	paymentRepo := repository.NewPaymentRepository(database.GetDB())
+	refundRepo := repository.NewRefundRepository(database.GetDB())
+	couponRepo := repository.NewCouponRepository(database.GetDB())
+	shipmentRepo := repository.NewShipmentRepository(database.GetDB())
+	inventoryRepo := repository.NewInventoryRepository(database.GetDB())
+	warehouseRepo := repository.NewWarehouseRepository(database.GetDB())
+	skuRepo := repository.NewSkuRepository(database.GetDB())
+	idempotencyRepo := repository.NewIdempotencyRepository(database.GetDB())
+	rankingRepo := repository.NewRankingRepository(database.GetDB())
+	reviewRepo := repository.NewReviewRepository(database.GetDB())
+	favoriteRepo := repository.NewFavoriteRepository(database.GetDB())
+	notificationRepo := repository.NewNotificationRepository(database.GetDB())
+	messageRepo := repository.NewMessageRepository(database.GetDB())
+	roleRepo := repository.NewRoleRepository(database.GetDB())
+	adminRepo := repository.NewAdminRepository(database.GetDB())
+	auditLogRepo := repository.NewAuditLogRepository(database.GetDB())
+
+	// 创建支付渠道注册表
+	// 目前所有支付方式都接入本地沙箱渠道，接入真实渠道时在这里替换即可
+	paymentProviders := payment.NewRegistry()
+	for _, method := range []string{"alipay", "wechat", "balance"} {
+		paymentProviders.Register(method, payment.NewSandboxProvider(method, cfg.Payment.SandboxSecret))
+	}
+
+	// 创建物流公司注册表
+	// 目前只接入本地模拟物流公司，接入真实物流公司时在这里注册即可
+	carriers := logistics.NewRegistry()
+	carriers.Register(logistics.NewFakeCarrier("fake", cfg.Logistics.FakeSecret, cfg.Logistics.WebhookBaseURL+"/api/v1/logistics/webhook/fake"))
+
+	// 创建邮件、短信渠道注册表
+	// 目前尚未接入真实的邮件、短信网关，消息都写入本地发件箱，接入时在这里替换即可
+	outboxFile, err := openMessageOutbox(cfg.Messaging.OutboxPath)
+	if err != nil {
+		log.Fatal("Failed to open message outbox:", err)
+	}
+	defer outboxFile.Close()
+	outbox := messaging.NewOutbox(outboxFile)
+	messageSenders := messaging.NewRegistry()
+	messageSenders.Register(outbox.Sender(messaging.ChannelEmail))
+	messageSenders.Register(outbox.Sender(messaging.ChannelSMS))
+
+	// 创建订单支付超时延时队列，Redis不可用时由兜底扫描任务处理过期订单
+	var orderTimeouts service.OrderTimeoutQueue
+	orderTimeoutQueue := newOrderTimeoutQueue(redisManager)
+	if orderTimeoutQueue != nil {
+		orderTimeouts = orderTimeoutQueue
+	}
+
+	// 创建订单状态机，所有订单状态变更都经过它并记录状态历史
+	orderStates := service.NewOrderStateMachine()
+
+	// 站内通知实时推送：WebSocket连接登记在各自的实例上，Redis可用时通过发布订阅转发给所有实例
+	pushHub := push.NewHub()
+	var notificationPusher service.NotificationPusher = pushHub
+	notificationBus := newNotificationBus(redisManager)
+	if notificationBus != nil {
+		notificationPusher = notificationBus
+	}
 
 	// 创建业务逻辑层
-	userService := service.NewUserService(userRepo, jwtManager)
+	userService := service.NewUserService(userRepo, roleRepo, jwtManager)
+	// 角色权限：写入内置权限和admin角色，给配置的用户分配admin角色
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	if err := roleService.Bootstrap(cfg.RBAC.AdminUsernames); err != nil {
+		log.Fatal("Failed to initialize roles:", err)
+	}
+	addressService := service.NewAddressService(addressRepo)
 	// 使用带缓存的商品服务
 	productService := service.NewCachedProductService(productRepo, categoryRepo)
 	categoryService := service.NewCategoryService(categoryRepo)
-	cartService := service.NewCartService(cartRepo, productRepo)
-	orderService := service.NewOrderService(orderRepo, cartRepo, productRepo, database.GetDB())
+	// 商品热度排行：浏览、加入购物车、支付订单计入Redis中按时间衰减的热度，Redis不可用时按销量排行
+	rankingService := service.NewRankingService(newProductRankingStore(redisManager), rankingRepo, productRepo, categoryRepo, orderStates)
+	productService.SetRankingService(rankingService)
+	// 商品评价：确认收货后才能评价，审核通过后计入商品详情的评分汇总
+	reviewService := service.NewReviewService(reviewRepo, userRepo, productService)
+	productService.SetRatingSource(reviewService)
+	cartService := service.NewCartService(cartRepo, productRepo, rankingService)
+	// 站内通知：订单状态变更、支付结果、物流更新时通知用户
+	notificationService := service.NewNotificationService(notificationRepo, notificationPusher, orderStates)
+	// 邮件、短信消息：订单创建、支付成功、发货时按用户的渠道偏好生成待发消息，由定时任务发送
+	messageService := service.NewMessageService(messageRepo, messageSenders, retry.Policy{
+		Attempts: cfg.Messaging.RetryAttempts,
+		Initial:  time.Duration(cfg.Messaging.RetryInitialMS) * time.Millisecond,
+		Max:      time.Duration(cfg.Messaging.RetryMaxMS) * time.Millisecond,
+	}, orderStates)
+	paymentService := service.NewPaymentService(paymentRepo, orderRepo, paymentProviders, cfg.Payment.NotifyBaseURL, orderStates, notificationService, idGenerator, database.GetDB())
+	couponService := service.NewCouponService(couponRepo, orderStates, database.GetDB())
+	inventoryService := service.NewInventoryService(inventoryRepo, service.AllocationStrategy(cfg.Inventory.AllocationStrategy), orderStates, database.GetDB())
+	warehouseService := service.NewWarehouseService(warehouseRepo, productRepo, productService)
+	skuService := service.NewSkuService(skuRepo, productRepo, productService)
+	// 商品搜索：启用Elasticsearch时商品变更增量同步到索引，不可用时降级为数据库模糊查询
+	var searchClient *search.Client
+	if cfg.Search.Enabled {
+		searchClient = search.NewClient(cfg.Search.Addresses, cfg.Search.Username, cfg.Search.Password, time.Duration(cfg.Search.TimeoutMS)*time.Millisecond)
+	}
+	searchService := service.NewProductSearchService(searchClient, productRepo, productService, service.ProductSearchConfig{
+		Index:          cfg.Search.Index,
+		Analyzer:       cfg.Search.Analyzer,
+		SearchAnalyzer: cfg.Search.SearchAnalyzer,
+	})
+	productService.AddObserver(searchService)
+	// 搜索建议和热门搜索词，Redis不可用时不记录热门搜索词
+	suggestService := service.NewSearchSuggestService(productRepo, categoryRepo, newHotSearchStore(redisManager))
+	productService.AddObserver(suggestService)
+	// 商品收藏：收藏的商品降价或重新到货时通知用户
+	favoriteService := service.NewFavoriteService(favoriteRepo, productRepo, notificationService)
+	productService.AddObserver(favoriteService)
+	// 待支付订单的支付时限，可按支付方式单独配置
+	orderExpiry := service.OrderExpiryPolicy{
+		Default:  time.Duration(cfg.Order.ExpireMinutes) * time.Minute,
+		ByMethod: make(map[string]time.Duration),
+	}
+	for method, minutes := range cfg.Order.MethodExpireMinutes {
+		orderExpiry.ByMethod[method] = time.Duration(minutes) * time.Minute
+	}
+	// 结算规则：运费和税率
+	checkoutPolicy := service.CheckoutPolicy{
+		ShippingFee:           cfg.Checkout.ShippingFee,
+		FreeShippingThreshold: cfg.Checkout.FreeShippingThreshold,
+		RemoteShippingFee:     cfg.Checkout.RemoteShippingFee,
+		RemoteProvinces:       cfg.Checkout.RemoteProvinces,
+		TaxRate:               cfg.Checkout.TaxRate,
+	}
+	orderService := service.NewOrderService(orderRepo, cartRepo, productRepo, addressRepo, paymentService, couponService, inventoryService, orderStates, orderExpiry, checkoutPolicy, orderTimeouts, idGenerator, database.GetDB())
+	refundService := service.NewRefundService(refundRepo, orderRepo, paymentService, orderStates, idGenerator, database.GetDB())
+	shipmentService := service.NewShipmentService(shipmentRepo, orderRepo, carriers, orderStates, notificationService, time.Duration(cfg.Order.AutoConfirmDays)*24*time.Hour, database.GetDB())
 	
+	// 管理后台：跨用户查询订单和用户、强制变更订单状态、批量上下架商品、销售看板
+	adminService := service.NewAdminService(adminRepo, auditLogRepo, orderRepo, userRepo, userService, productService, orderStates, database.GetDB())
 	aiService := service.NewAIService()
 
+	// 创建幂等中间件，防止下单、支付等请求重复提交
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyRepo,
+		time.Duration(cfg.Order.IdempotencyTTLHours)*time.Hour,
+		time.Duration(cfg.Order.IdempotencyLeaseSec)*time.Second)
+	// 创建审计中间件，记录管理后台的每一次操作
+	auditMiddleware := middleware.NewAuditMiddleware(auditLogRepo)
+
 	// 创建HTTP处理器
 	userHandler := handler.NewUserHandler(userService)
-	productHandler := handler.NewProductHandler(productService, categoryService)
-	categoryHandler := handler.NewCategoryHandler(categoryService)
+	addressHandler := handler.NewAddressHandler(addressService)
+	productHandler := handler.NewProductHandler(productService, categoryService, auditMiddleware)
+	categoryHandler := handler.NewCategoryHandler(categoryService, auditMiddleware)
 	cartHandler := handler.NewCartHandler(cartService)
-	orderHandler := handler.NewOrderHandler(orderService)
+	orderHandler := handler.NewOrderHandler(orderService, idempotencyMiddleware)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	refundHandler := handler.NewRefundHandler(refundService, auditMiddleware)
+	couponHandler := handler.NewCouponHandler(couponService, auditMiddleware)
+	shipmentHandler := handler.NewShipmentHandler(shipmentService, auditMiddleware)
+	warehouseHandler := handler.NewWarehouseHandler(warehouseService, auditMiddleware)
+	skuHandler := handler.NewSkuHandler(skuService, auditMiddleware)
+	searchHandler := handler.NewSearchHandler(searchService, suggestService)
+	rankingHandler := handler.NewRankingHandler(rankingService)
+	reviewHandler := handler.NewReviewHandler(reviewService, auditMiddleware)
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+	notificationHandler := handler.NewNotificationHandler(notificationService, pushHub)
+	messageHandler := handler.NewMessageHandler(messageService)
+	roleHandler := handler.NewRoleHandler(roleService, auditMiddleware)
+	adminHandler := handler.NewAdminHandler(adminService, auditMiddleware)
 	aiHandler := handler.NewAIHandler(aiService)
 
 
 
 	// 创建中间件
-	authMiddleware := middleware.NewAuthMiddleware(userService)
+	authMiddleware := middleware.NewAuthMiddleware(userService, roleService)
+
+	// 启动内置定时任务（过期订单自动取消等）
+	// 多实例部署时通过Redis分布式锁选主，只有一个实例执行
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// 索引不存在时创建并导入商品，失败时搜索降级为数据库查询
+	if searchClient != nil {
+		go func() {
+			if err := searchService.EnsureIndex(ctx); err != nil {
+				log.Printf("⚠️  商品搜索索引初始化失败，搜索降级为数据库查询: %v", err)
+			}
+		}()
+	}
+	// 订阅其他实例发布的站内通知，推送给本实例上的连接
+	if notificationBus != nil {
+		go func() {
+			if err := notificationBus.Subscribe(ctx, pushHub.Deliver); err != nil {
+				log.Printf("⚠️  站内通知推送订阅失败，实时推送不可用: %v", err)
+			}
+		}()
+	}
+	if cfg.Scheduler.Enabled {
+		jobScheduler := newScheduler(cfg, redisManager, orderTimeoutQueue, orderService, shipmentService, inventoryService, productService, rankingService, messageService, paymentService, refundService, idempotencyRepo)
+		jobScheduler.Start(ctx)
+		defer jobScheduler.Stop()
+		log.Println("✅ 定时任务调度器已启动")
+	}
 
 	// 5. 设置Gin运行模式
 	// debug: 开发模式，会输出详细日志
@@ -138,9 +345,30 @@ func main() {
 		// 注册用户相关路由
 		userHandler.RegisterRoutes(v1, authMiddleware)
 
+		// 注册收货地址相关路由
+		addressHandler.RegisterRoutes(v1, authMiddleware)
+
 		// 注册商品相关路由
 		productHandler.RegisterRoutes(v1, authMiddleware)
 
+		// 注册商品搜索相关路由
+		searchHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册商品热度排行相关路由
+		rankingHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册商品评价相关路由
+		reviewHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册商品收藏相关路由
+		favoriteHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册站内通知相关路由
+		notificationHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册邮件、短信消息相关路由
+		messageHandler.RegisterRoutes(v1, authMiddleware)
+
 		// 注册分类相关路由
 		categoryHandler.RegisterRoutes(v1, authMiddleware)
 
@@ -150,6 +378,30 @@ func main() {
 		// 注册订单相关路由
 		orderHandler.RegisterRoutes(v1, authMiddleware)
 
+		// 注册支付相关路由
+		paymentHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册优惠券相关路由
+		couponHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册售后退款相关路由
+		refundHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册发货与物流相关路由
+		shipmentHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册仓库相关路由
+		warehouseHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册商品规格相关路由
+		skuHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册角色权限相关路由
+		roleHandler.RegisterRoutes(v1, authMiddleware)
+
+		// 注册管理后台相关路由
+		adminHandler.RegisterRoutes(v1, authMiddleware)
+
 		// 注册AI相关路由
 		aiHandler.RegisterRoutes(v1, authMiddleware)
 
@@ -177,7 +429,15 @@ func main() {
 		MaxHeaderBytes: 1 << 16,           // 减少最大请求头大小 64KB
 	}
 
-	if err := server.ListenAndServe(); err != nil {
+	// 收到退出信号后优雅关闭，释放定时任务的主实例锁
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatal("Failed to start server:", err)
 	}
 }
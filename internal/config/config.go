@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config 应用程序配置结构体
@@ -17,6 +18,26 @@ type Config struct {
 	Redis RedisConfig
 	// JWT配置
 	JWT JWTConfig
+	// 支付配置
+	Payment PaymentConfig
+	// 物流配置
+	Logistics LogisticsConfig
+	// 结算配置
+	Checkout CheckoutConfig
+	// 订单配置
+	Order OrderConfig
+	// 定时任务配置
+	Scheduler SchedulerConfig
+	// 单号生成配置
+	IDGen IDGenConfig
+	// 库存配置
+	Inventory InventoryConfig
+	// 商品搜索配置
+	Search SearchConfig
+	// 邮件、短信消息配置
+	Messaging MessagingConfig
+	// 角色权限配置
+	RBAC RBACConfig
 }
 
 // ServerConfig 服务器相关配置
@@ -53,6 +74,88 @@ type JWTConfig struct {
 	ExpireHours int  // Token过期时间（小时）
 }
 
+// PaymentConfig 支付相关配置
+type PaymentConfig struct {
+	NotifyBaseURL string // 支付异步通知回调的外部访问地址
+	SandboxSecret string // 沙箱支付渠道的通知签名密钥
+}
+
+// LogisticsConfig 物流相关配置
+type LogisticsConfig struct {
+	WebhookBaseURL string // 物流轨迹推送回调的外部访问地址
+	FakeSecret     string // 模拟物流公司的推送签名密钥
+}
+
+// CheckoutConfig 结算相关配置（运费、税率）
+type CheckoutConfig struct {
+	ShippingFee           float64  // 基础运费
+	FreeShippingThreshold float64  // 包邮门槛（优惠后商品金额），0表示不包邮
+	RemoteShippingFee     float64  // 偏远地区运费
+	RemoteProvinces       []string // 偏远地区省份，不参与包邮
+	TaxRate               float64  // 商品价格中包含的税率，如13表示13%
+}
+
+// OrderConfig 订单相关配置
+type OrderConfig struct {
+	ExpireMinutes       int            // 待支付订单默认支付时限（分钟）
+	MethodExpireMinutes map[string]int // 按支付方式配置的支付时限（分钟），未配置的使用默认值
+	ExpireBatchSize     int            // 过期订单每批处理数量
+	AutoConfirmDays     int            // 最后一条物流轨迹后自动确认收货的天数
+	IdempotencyTTLHours int            // 下单、支付、取消请求幂等键的有效期（小时）
+	IdempotencyLeaseSec int            // 幂等键处理中状态的租约（秒），服务崩溃后租约到期即可重新提交
+}
+
+// SchedulerConfig 定时任务相关配置
+type SchedulerConfig struct {
+	Enabled                bool // 是否启用内置定时任务
+	LeaseSeconds           int  // 主实例锁有效期（秒）
+	OrderExpireInterval    int  // 过期订单兜底扫描间隔（秒）
+	OrderTimeoutInterval   int  // 订单超时延时队列消费间隔（秒），需要Redis
+	AutoConfirmInterval    int  // 自动确认收货扫描间隔（秒）
+	IdempotencyInterval    int  // 过期幂等记录清理间隔（秒）
+	InventoryInterval      int  // 库存预占对账间隔（秒）
+	RankingInterval        int  // 商品热度排行快照间隔（秒），需要Redis
+	MessageInterval        int  // 邮件、短信待发消息发送间隔（秒）
+	PaymentRefundInterval  int  // 自动退款重试间隔（秒）
+	RefundCompleteInterval int  // 售后退款本地更新重试间隔（秒）
+}
+
+// IDGenConfig 单号生成相关配置
+type IDGenConfig struct {
+	WorkerID     int // 固定WorkerID（0-1023），小于0时从Redis租用
+	LeaseSeconds int // 从Redis租用的WorkerID租约有效期（秒）
+}
+
+// InventoryConfig 库存相关配置
+type InventoryConfig struct {
+	AllocationStrategy string // 多仓库分配策略：nearest 就近发货，most_stock 库存最多的仓库优先
+}
+
+// SearchConfig 商品搜索（Elasticsearch）相关配置
+type SearchConfig struct {
+	Enabled        bool     // 是否启用Elasticsearch，未启用或不可用时搜索降级为数据库模糊查询
+	Addresses      []string // Elasticsearch节点地址列表
+	Username       string   // 用户名，为空时不认证
+	Password       string   // 密码
+	Index          string   // 商品索引别名
+	Analyzer       string   // 建索引使用的分词器，安装IK插件后可设置为ik_max_word
+	SearchAnalyzer string   // 搜索时使用的分词器，安装IK插件后可设置为ik_smart
+	TimeoutMS      int      // 请求超时时间（毫秒）
+}
+
+// MessagingConfig 邮件、短信消息相关配置
+type MessagingConfig struct {
+	OutboxPath     string // 本地发件箱文件路径，为空时写到标准输出；接入真实网关前邮件和短信都写入发件箱
+	RetryAttempts  int    // 每条消息最多发送次数（含第一次）
+	RetryInitialMS int    // 第一次重试前的等待时间（毫秒），之后每次翻倍
+	RetryMaxMS     int    // 重试最长等待时间（毫秒）
+}
+
+// RBACConfig 角色权限相关配置
+type RBACConfig struct {
+	AdminUsernames []string // 启动时分配管理员角色的用户名，用于初始化第一个管理员
+}
+
 // LoadConfig 加载配置
 // 这个函数从环境变量中读取配置，如果没有设置则使用默认值
 // 在生产环境中，建议通过环境变量来配置这些敏感信息
@@ -81,6 +184,68 @@ func LoadConfig() *Config {
 			SecretKey:   getEnv("JWT_SECRET", "ryan-mall-secret-key"),
 			ExpireHours: getEnvAsInt("JWT_EXPIRE_HOURS", 24),
 		},
+		Payment: PaymentConfig{
+			NotifyBaseURL: getEnv("PAYMENT_NOTIFY_BASE_URL", "http://localhost:8080"),
+			SandboxSecret: getEnv("PAYMENT_SANDBOX_SECRET", "ryan-mall-sandbox-secret"),
+		},
+		Logistics: LogisticsConfig{
+			WebhookBaseURL: getEnv("LOGISTICS_WEBHOOK_BASE_URL", "http://localhost:8080"),
+			FakeSecret:     getEnv("LOGISTICS_FAKE_SECRET", "ryan-mall-fake-carrier-secret"),
+		},
+		Checkout: CheckoutConfig{
+			ShippingFee:           getEnvAsFloat("CHECKOUT_SHIPPING_FEE", 10),
+			FreeShippingThreshold: getEnvAsFloat("CHECKOUT_FREE_SHIPPING_THRESHOLD", 99),
+			RemoteShippingFee:     getEnvAsFloat("CHECKOUT_REMOTE_SHIPPING_FEE", 20),
+			RemoteProvinces:       getEnvAsStringSlice("CHECKOUT_REMOTE_PROVINCES", []string{"新疆维吾尔自治区", "西藏自治区"}),
+			TaxRate:               getEnvAsFloat("CHECKOUT_TAX_RATE", 13),
+		},
+		Order: OrderConfig{
+			ExpireMinutes:       getEnvAsInt("ORDER_EXPIRE_MINUTES", 30),
+			MethodExpireMinutes: getEnvAsIntMap("ORDER_EXPIRE_MINUTES_BY_METHOD", map[string]int{}),
+			ExpireBatchSize:     getEnvAsInt("ORDER_EXPIRE_BATCH_SIZE", 100),
+			AutoConfirmDays:     getEnvAsInt("ORDER_AUTO_CONFIRM_DAYS", 7),
+			IdempotencyTTLHours: getEnvAsInt("ORDER_IDEMPOTENCY_TTL_HOURS", 24),
+			IdempotencyLeaseSec: getEnvAsInt("ORDER_IDEMPOTENCY_LEASE_SECONDS", 120),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:                getEnvAsBool("SCHEDULER_ENABLED", true),
+			LeaseSeconds:           getEnvAsInt("SCHEDULER_LEASE_SECONDS", 30),
+			OrderExpireInterval:    getEnvAsInt("SCHEDULER_ORDER_EXPIRE_INTERVAL", 60),
+			OrderTimeoutInterval:   getEnvAsInt("SCHEDULER_ORDER_TIMEOUT_INTERVAL", 1),
+			AutoConfirmInterval:    getEnvAsInt("SCHEDULER_AUTO_CONFIRM_INTERVAL", 3600),
+			IdempotencyInterval:    getEnvAsInt("SCHEDULER_IDEMPOTENCY_INTERVAL", 3600),
+			InventoryInterval:      getEnvAsInt("SCHEDULER_INVENTORY_INTERVAL", 600),
+			RankingInterval:        getEnvAsInt("SCHEDULER_RANKING_INTERVAL", 3600),
+			MessageInterval:        getEnvAsInt("SCHEDULER_MESSAGE_INTERVAL", 10),
+			PaymentRefundInterval:  getEnvAsInt("SCHEDULER_PAYMENT_REFUND_INTERVAL", 60),
+			RefundCompleteInterval: getEnvAsInt("SCHEDULER_REFUND_COMPLETE_INTERVAL", 60),
+		},
+		IDGen: IDGenConfig{
+			WorkerID:     getEnvAsInt("IDGEN_WORKER_ID", -1),
+			LeaseSeconds: getEnvAsInt("IDGEN_LEASE_SECONDS", 30),
+		},
+		Inventory: InventoryConfig{
+			AllocationStrategy: getEnv("INVENTORY_ALLOCATION_STRATEGY", "nearest"),
+		},
+		Search: SearchConfig{
+			Enabled:        getEnvAsBool("ES_ENABLED", false),
+			Addresses:      getEnvAsStringSlice("ES_ADDRESSES", []string{"http://localhost:9200"}),
+			Username:       getEnv("ES_USERNAME", ""),
+			Password:       getEnv("ES_PASSWORD", ""),
+			Index:          getEnv("ES_INDEX", "ryan_mall_products"),
+			Analyzer:       getEnv("ES_ANALYZER", "cjk"),
+			SearchAnalyzer: getEnv("ES_SEARCH_ANALYZER", "cjk"),
+			TimeoutMS:      getEnvAsInt("ES_TIMEOUT_MS", 2000),
+		},
+		Messaging: MessagingConfig{
+			OutboxPath:     getEnv("MESSAGE_OUTBOX_PATH", ""),
+			RetryAttempts:  getEnvAsInt("MESSAGE_RETRY_ATTEMPTS", 3),
+			RetryInitialMS: getEnvAsInt("MESSAGE_RETRY_INITIAL_MS", 500),
+			RetryMaxMS:     getEnvAsInt("MESSAGE_RETRY_MAX_MS", 5000),
+		},
+		RBAC: RBACConfig{
+			AdminUsernames: getEnvAsStringSlice("ADMIN_USERNAMES", []string{}),
+		},
 	}
 }
 
@@ -103,6 +268,17 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat 获取环境变量并转换为浮点数，如果不存在或转换失败则返回默认值
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		log.Printf("Warning: Invalid float value for %s: %s, using default: %g", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
 // getEnvAsBool 获取环境变量并转换为布尔值，如果不存在或转换失败则返回默认值
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -139,3 +315,31 @@ func getEnvAsStringSlice(key string, defaultValue []string) []string {
 	}
 	return defaultValue
 }
+
+// getEnvAsIntMap 获取环境变量并转换为字符串到整数的映射，格式：key1:1,key2:2
+// 如果不存在或全部解析失败则返回默认值
+func getEnvAsIntMap(key string, defaultValue map[string]int) map[string]int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			log.Printf("Warning: Invalid map entry for %s: %s, skipped", key, pair)
+			continue
+		}
+		intValue, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			log.Printf("Warning: Invalid integer value for %s: %s, skipped", key, pair)
+			continue
+		}
+		result[strings.TrimSpace(k)] = intValue
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHandler 收货地址HTTP处理器
+type AddressHandler struct {
+	addressService service.AddressService
+}
+
+// NewAddressHandler 创建收货地址处理器实例
+func NewAddressHandler(addressService service.AddressService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+	}
+}
+
+// ListAddresses 获取收货地址列表
+// GET /api/v1/users/addresses
+// 需要认证
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	addresses, err := h.addressService.ListAddresses(userID)
+	if err != nil {
+		response.InternalServerError(c, "获取收货地址失败")
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, addresses)
+}
+
+// GetAddress 获取收货地址详情
+// GET /api/v1/users/addresses/:id
+// 需要认证
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "地址ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	address, err := h.addressService.GetAddress(userID, uint(addressID))
+	if err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, address)
+}
+
+// CreateAddress 新增收货地址
+// POST /api/v1/users/addresses
+// 需要认证
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	address, err := h.addressService.CreateAddress(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "收货地址添加成功", address)
+}
+
+// UpdateAddress 修改收货地址
+// PUT /api/v1/users/addresses/:id
+// 需要认证
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "地址ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	address, err := h.addressService.UpdateAddress(userID, uint(addressID), &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "收货地址修改成功", address)
+}
+
+// DeleteAddress 删除收货地址
+// DELETE /api/v1/users/addresses/:id
+// 需要认证
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "地址ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.addressService.DeleteAddress(userID, uint(addressID)); err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "收货地址删除成功", nil)
+}
+
+// SetDefaultAddress 设置默认地址
+// PUT /api/v1/users/addresses/:id/default
+// 需要认证
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "地址ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.addressService.SetDefaultAddress(userID, uint(addressID)); err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "默认地址设置成功", nil)
+}
+
+// RegisterRoutes 注册收货地址相关路由
+func (h *AddressHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 收货地址路由组（需要认证）
+	addresses := r.Group("/users/addresses")
+	addresses.Use(authMiddleware.RequireAuth())
+	{
+		addresses.GET("", h.ListAddresses)                 // 获取收货地址列表
+		addresses.POST("", h.CreateAddress)                // 新增收货地址
+		addresses.GET("/:id", h.GetAddress)                // 获取收货地址详情
+		addresses.PUT("/:id", h.UpdateAddress)             // 修改收货地址
+		addresses.DELETE("/:id", h.DeleteAddress)          // 删除收货地址
+		addresses.PUT("/:id/default", h.SetDefaultAddress) // 设置默认地址
+	}
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler 管理后台HTTP处理器
+// 所有接口都需要对应的管理权限，每一次操作都记录审计日志
+type AdminHandler struct {
+	adminService service.AdminService
+	audit        *middleware.AuditMiddleware
+}
+
+// NewAdminHandler 创建管理后台处理器实例
+func NewAdminHandler(adminService service.AdminService, audit *middleware.AuditMiddleware) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		audit:        audit,
+	}
+}
+
+// SearchOrders 搜索全部用户的订单
+// GET /api/v1/admin/orders
+// 需要订单管理权限
+func (h *AdminHandler) SearchOrders(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.AdminOrderSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.adminService.SearchOrders(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// ForceOrderStatus 强制变更订单状态
+// PUT /api/v1/admin/orders/:id/status
+// 需要订单管理权限
+func (h *AdminHandler) ForceOrderStatus(c *gin.Context) {
+	// 1. 获取管理员ID
+	adminID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 解析订单ID
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.ForceOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	if err := h.adminService.ForceOrderStatus(adminID, uint(orderID), &req); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "订单状态已变更", nil)
+}
+
+// ListUsers 分页查询用户
+// GET /api/v1/admin/users
+// 需要用户管理权限
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.AdminUserListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.adminService.ListUsers(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// UpdateUserStatus 禁用、启用用户
+// PUT /api/v1/admin/users/:id/status
+// 需要用户管理权限
+func (h *AdminHandler) UpdateUserStatus(c *gin.Context) {
+	// 1. 获取管理员ID
+	adminID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 解析用户ID
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "用户ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	user, err := h.adminService.UpdateUserStatus(adminID, uint(userID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "用户状态已更新", user)
+}
+
+// BulkUpdateProductStatus 批量上下架商品
+// PUT /api/v1/admin/products/status
+// 需要商品管理权限
+func (h *AdminHandler) BulkUpdateProductStatus(c *gin.Context) {
+	// 1. 绑定请求参数
+	var req model.BulkProductStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.adminService.BulkUpdateProductStatus(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.SuccessWithMessage(c, "商品状态已更新", result)
+}
+
+// SalesDashboard 销售看板
+// GET /api/v1/admin/dashboard/sales
+// 需要查看销售看板权限
+func (h *AdminHandler) SalesDashboard(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.SalesDashboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	dashboard, err := h.adminService.SalesDashboard(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, dashboard)
+}
+
+// ListAuditLogs 查询管理后台审计日志
+// GET /api/v1/admin/audit-logs
+// 需要查看审计日志权限
+func (h *AdminHandler) ListAuditLogs(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.AdminAuditLogListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.adminService.ListAuditLogs(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// RegisterRoutes 注册管理后台相关路由
+func (h *AdminHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	admin := r.Group("/admin")
+
+	// 订单管理
+	orders := admin.Group("/orders")
+	orders.Use(authMiddleware.RequirePermission(model.PermissionOrderManage))
+	{
+		orders.GET("", h.audit.Record(model.AdminActionOrderSearch), h.SearchOrders)                     // 搜索订单
+		orders.PUT("/:id/status", h.audit.Record(model.AdminActionOrderForceStatus), h.ForceOrderStatus) // 强制变更订单状态
+	}
+
+	// 用户管理
+	users := admin.Group("/users")
+	users.Use(authMiddleware.RequirePermission(model.PermissionUserManage))
+	{
+		users.GET("", h.audit.Record(model.AdminActionUserList), h.ListUsers)                     // 查询用户
+		users.PUT("/:id/status", h.audit.Record(model.AdminActionUserStatus), h.UpdateUserStatus) // 禁用、启用用户
+	}
+
+	// 商品管理
+	products := admin.Group("/products")
+	products.Use(authMiddleware.RequirePermission(model.PermissionProductManage))
+	{
+		products.PUT("/status", h.audit.Record(model.AdminActionProductBulkStatus), h.BulkUpdateProductStatus) // 批量上下架商品
+	}
+
+	// 销售看板和审计日志
+	admin.GET("/dashboard/sales", authMiddleware.RequirePermission(model.PermissionDashboardView),
+		h.audit.Record(model.AdminActionDashboardView), h.SalesDashboard) // 销售看板
+	admin.GET("/audit-logs", authMiddleware.RequirePermission(model.PermissionAuditView),
+		h.audit.Record(model.AdminActionAuditLogList), h.ListAuditLogs) // 查询审计日志
+}
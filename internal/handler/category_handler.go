@@ -13,12 +13,14 @@ import (
 // CategoryHandler 分类HTTP处理器
 type CategoryHandler struct {
 	categoryService service.CategoryService
+	audit           *middleware.AuditMiddleware
 }
 
 // NewCategoryHandler 创建分类处理器实例
-func NewCategoryHandler(categoryService service.CategoryService) *CategoryHandler {
+func NewCategoryHandler(categoryService service.CategoryService, audit *middleware.AuditMiddleware) *CategoryHandler {
 	return &CategoryHandler{
 		categoryService: categoryService,
+		audit:           audit,
 	}
 }
 
@@ -197,12 +199,12 @@ func (h *CategoryHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *mid
 	r.GET("/categories/:id", h.GetCategory)            // 获取分类详情
 	r.GET("/categories/:id/children", h.GetSubCategories) // 获取子分类
 	
-	// 需要认证的路由（管理员功能）
+	// 需要分类管理权限的路由（管理员功能）
 	admin := r.Group("")
-	admin.Use(authMiddleware.RequireAuth())
+	admin.Use(authMiddleware.RequirePermission(model.PermissionCategoryManage))
 	{
-		admin.POST("/categories", h.CreateCategory)        // 创建分类
-		admin.PUT("/categories/:id", h.UpdateCategory)     // 更新分类
-		admin.DELETE("/categories/:id", h.DeleteCategory)  // 删除分类
+		admin.POST("/categories", h.audit.Record(model.AdminActionCategoryCreate), h.CreateCategory)       // 创建分类
+		admin.PUT("/categories/:id", h.audit.Record(model.AdminActionCategoryUpdate), h.UpdateCategory)    // 更新分类
+		admin.DELETE("/categories/:id", h.audit.Record(model.AdminActionCategoryDelete), h.DeleteCategory) // 删除分类
 	}
 }
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CouponHandler 优惠券HTTP处理器
+type CouponHandler struct {
+	couponService service.CouponService
+	audit         *middleware.AuditMiddleware
+}
+
+// NewCouponHandler 创建优惠券处理器实例
+func NewCouponHandler(couponService service.CouponService, audit *middleware.AuditMiddleware) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+		audit:         audit,
+	}
+}
+
+// ListClaimable 获取可领取的优惠券
+// GET /api/v1/coupons
+func (h *CouponHandler) ListClaimable(c *gin.Context) {
+	templates, err := h.couponService.ListClaimable()
+	if err != nil {
+		response.InternalServerError(c, "获取优惠券失败")
+		return
+	}
+
+	response.Success(c, templates)
+}
+
+// ClaimCoupon 领取优惠券
+// POST /api/v1/coupons/:id/claim
+// 需要认证
+func (h *CouponHandler) ClaimCoupon(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "优惠券ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	coupon, err := h.couponService.ClaimCoupon(userID, uint(templateID))
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "领取成功", coupon)
+}
+
+// ListUserCoupons 获取我的优惠券
+// GET /api/v1/users/coupons?state=usable
+// 需要认证
+func (h *CouponHandler) ListUserCoupons(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定查询参数
+	var req model.UserCouponListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	coupons, err := h.couponService.ListUserCoupons(userID, &req)
+	if err != nil {
+		response.InternalServerError(c, "获取优惠券失败")
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, coupons)
+}
+
+// CreateTemplate 创建优惠券模板
+// POST /api/v1/admin/coupons
+// 需要优惠券管理权限
+func (h *CouponHandler) CreateTemplate(c *gin.Context) {
+	// 1. 绑定请求参数
+	var req model.CreateCouponTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	template, err := h.couponService.CreateTemplate(&req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.SuccessWithMessage(c, "优惠券创建成功", template)
+}
+
+// ListTemplates 获取优惠券模板列表
+// GET /api/v1/admin/coupons
+// 需要优惠券管理权限
+func (h *CouponHandler) ListTemplates(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.CouponTemplateListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.couponService.ListTemplates(&req)
+	if err != nil {
+		response.InternalServerError(c, "获取优惠券列表失败")
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// UpdateTemplateStatus 启用/停用优惠券模板
+// PUT /api/v1/admin/coupons/:id/status
+// 需要优惠券管理权限
+func (h *CouponHandler) UpdateTemplateStatus(c *gin.Context) {
+	// 1. 获取路径参数
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "优惠券ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.UpdateCouponTemplateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.couponService.UpdateTemplateStatus(uint(templateID), req.Status); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "优惠券状态已更新", nil)
+}
+
+// IssueCoupons 发放优惠券
+// POST /api/v1/admin/coupons/:id/issue
+// 需要优惠券管理权限
+func (h *CouponHandler) IssueCoupons(c *gin.Context) {
+	// 1. 获取路径参数
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "优惠券ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.IssueCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	issued, err := h.couponService.IssueCoupons(uint(templateID), req.UserIDs)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "优惠券发放成功", gin.H{"issued": issued})
+}
+
+// RegisterRoutes 注册优惠券相关路由
+func (h *CouponHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 优惠券领取
+	coupons := r.Group("/coupons")
+	{
+		coupons.GET("", h.ListClaimable)                                        // 获取可领取的优惠券（公开）
+		coupons.POST("/:id/claim", authMiddleware.RequireAuth(), h.ClaimCoupon) // 领取优惠券
+	}
+
+	// 我的优惠券（需要认证）
+	r.GET("/users/coupons", authMiddleware.RequireAuth(), h.ListUserCoupons)
+
+	// 优惠券管理（需要优惠券管理权限）
+	admin := r.Group("/admin/coupons")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionCouponManage))
+	{
+		admin.POST("", h.audit.Record(model.AdminActionCouponCreate), h.CreateTemplate)                 // 创建优惠券模板
+		admin.GET("", h.ListTemplates)                                                                  // 获取优惠券模板列表
+		admin.PUT("/:id/status", h.audit.Record(model.AdminActionCouponStatus), h.UpdateTemplateStatus) // 启用/停用优惠券模板
+		admin.POST("/:id/issue", h.audit.Record(model.AdminActionCouponIssue), h.IssueCoupons)          // 发放优惠券
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FavoriteHandler 商品收藏HTTP处理器
+type FavoriteHandler struct {
+	favoriteService service.FavoriteService
+}
+
+// NewFavoriteHandler 创建商品收藏处理器实例
+func NewFavoriteHandler(favoriteService service.FavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: favoriteService,
+	}
+}
+
+// AddFavorite 收藏商品
+// POST /api/v1/users/favorites
+// 需要认证
+func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.AddFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.favoriteService.AddFavorite(userID, &req); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "收藏成功", nil)
+}
+
+// RemoveFavorite 取消收藏
+// DELETE /api/v1/users/favorites/:productId
+// 需要认证
+func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "商品ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.favoriteService.RemoveFavorite(userID, uint(productID)); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "已取消收藏", nil)
+}
+
+// GetFavorites 获取收藏列表
+// GET /api/v1/users/favorites?page=1&page_size=10
+// 需要认证
+func (h *FavoriteHandler) GetFavorites(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定查询参数
+	var req model.FavoriteListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	result, err := h.favoriteService.GetFavorites(userID, &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, result)
+}
+
+// RegisterRoutes 注册商品收藏相关路由
+func (h *FavoriteHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 商品收藏（需要认证）
+	favorites := r.Group("/users/favorites")
+	favorites.Use(authMiddleware.RequireAuth())
+	{
+		favorites.POST("", h.AddFavorite)                 // 收藏商品
+		favorites.GET("", h.GetFavorites)                 // 获取收藏列表
+		favorites.DELETE("/:productId", h.RemoveFavorite) // 取消收藏
+	}
+}
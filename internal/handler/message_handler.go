@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MessageHandler 邮件、短信消息HTTP处理器
+type MessageHandler struct {
+	messageService service.MessageService
+}
+
+// NewMessageHandler 创建邮件、短信消息处理器实例
+func NewMessageHandler(messageService service.MessageService) *MessageHandler {
+	return &MessageHandler{
+		messageService: messageService,
+	}
+}
+
+// GetPreference 获取消息渠道偏好
+// GET /api/v1/users/message-preferences
+// 需要认证
+func (h *MessageHandler) GetPreference(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	preference, err := h.messageService.GetPreference(userID)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, preference)
+}
+
+// UpdatePreference 更新消息渠道偏好
+// PUT /api/v1/users/message-preferences
+// 需要认证
+func (h *MessageHandler) UpdatePreference(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.UpdateMessagePreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	preference, err := h.messageService.UpdatePreference(userID, &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "消息偏好已更新", preference)
+}
+
+// RegisterRoutes 注册邮件、短信消息相关路由
+func (h *MessageHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 消息渠道偏好（需要认证）
+	preferences := r.Group("/users/message-preferences")
+	preferences.Use(authMiddleware.RequireAuth())
+	{
+		preferences.GET("", h.GetPreference)    // 获取消息渠道偏好
+		preferences.PUT("", h.UpdatePreference) // 更新消息渠道偏好
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"log"
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/push"
+	"ryan-mall/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 站内通知HTTP处理器
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	hub                 *push.Hub
+}
+
+// NewNotificationHandler 创建站内通知处理器实例
+// hub管理本实例的WebSocket连接
+func NewNotificationHandler(notificationService service.NotificationService, hub *push.Hub) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		hub:                 hub,
+	}
+}
+
+// GetNotifications 获取通知列表
+// GET /api/v1/users/notifications?page=1&page_size=10&unread_only=true
+// 需要认证
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定查询参数
+	var req model.NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	result, err := h.notificationService.GetNotifications(userID, &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, result)
+}
+
+// GetUnreadCount 获取未读通知数
+// GET /api/v1/users/notifications/unread-count
+// 需要认证
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	count, err := h.notificationService.UnreadCount(userID)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, gin.H{"unread_count": count})
+}
+
+// MarkRead 标记指定通知已读
+// PUT /api/v1/users/notifications/read
+// 需要认证
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.MarkNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.notificationService.MarkRead(userID, req.IDs); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "已标记为已读", nil)
+}
+
+// MarkAllRead 标记全部通知已读
+// PUT /api/v1/users/notifications/read-all
+// 需要认证
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.SuccessWithMessage(c, "已全部标记为已读", nil)
+}
+
+// Connect 建立通知推送的WebSocket连接
+// GET /api/v1/ws/notifications?token=xxx
+// 需要认证，浏览器无法设置请求头时通过token查询参数传递令牌
+// 连接建立后服务端推送新通知的JSON，客户端无需发送消息；断线重连后通过通知列表补齐
+func (h *NotificationHandler) Connect(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 升级为WebSocket连接，阻塞到连接断开
+	// 升级失败时已经返回了错误响应
+	if err := h.hub.Serve(c.Writer, c.Request, userID); err != nil {
+		log.Printf("用户%d建立通知推送连接失败: %v", userID, err)
+	}
+}
+
+// RegisterRoutes 注册站内通知相关路由
+func (h *NotificationHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 站内通知收件箱（需要认证）
+	notifications := r.Group("/users/notifications")
+	notifications.Use(authMiddleware.RequireAuth())
+	{
+		notifications.GET("", h.GetNotifications)            // 获取通知列表
+		notifications.GET("/unread-count", h.GetUnreadCount) // 获取未读通知数
+		notifications.PUT("/read", h.MarkRead)               // 标记指定通知已读
+		notifications.PUT("/read-all", h.MarkAllRead)        // 标记全部通知已读
+	}
+
+	// 实时推送（需要认证）
+	r.GET("/ws/notifications", authMiddleware.RequireQueryTokenAuth(), h.Connect)
+}
@@ -13,12 +13,14 @@ import (
 // OrderHandler 订单HTTP处理器
 type OrderHandler struct {
 	orderService service.OrderService
+	idempotency  *middleware.IdempotencyMiddleware
 }
 
 // NewOrderHandler 创建订单处理器实例
-func NewOrderHandler(orderService service.OrderService) *OrderHandler {
+func NewOrderHandler(orderService service.OrderService, idempotency *middleware.IdempotencyMiddleware) *OrderHandler {
 	return &OrderHandler{
 		orderService: orderService,
+		idempotency:  idempotency,
 	}
 }
 
@@ -51,6 +53,35 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	response.SuccessWithMessage(c, "订单创建成功", order)
 }
 
+// PreviewOrder 订单预览
+// POST /api/v1/orders/preview
+// 需要认证，返回与下单一致的计价明细
+func (h *OrderHandler) PreviewOrder(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+	
+	// 2. 绑定请求参数
+	var req model.OrderPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+	
+	// 3. 调用业务逻辑
+	preview, err := h.orderService.PreviewOrder(userID, &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+	
+	// 4. 返回成功响应
+	response.Success(c, preview)
+}
+
 // GetOrder 获取订单详情
 // GET /api/v1/orders/:id
 // 需要认证
@@ -180,7 +211,7 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	response.SuccessWithMessage(c, "订单取消成功", nil)
 }
 
-// PayOrder 支付订单
+// PayOrder 发起支付
 // POST /api/v1/orders/:id/pay
 // 需要认证
 func (h *OrderHandler) PayOrder(c *gin.Context) {
@@ -207,14 +238,14 @@ func (h *OrderHandler) PayOrder(c *gin.Context) {
 	}
 	
 	// 4. 调用业务逻辑
-	err = h.orderService.PayOrder(userID, uint(orderID), &req)
+	result, err := h.orderService.PayOrder(userID, uint(orderID), &req)
 	if err != nil {
 		response.Error(c, response.ERROR, err.Error())
 		return
 	}
 	
-	// 5. 返回成功响应
-	response.SuccessWithMessage(c, "支付成功", nil)
+	// 5. 返回成功响应（订单状态以支付回调为准）
+	response.SuccessWithMessage(c, "支付单已创建，请完成支付", result)
 }
 
 // ConfirmOrder 确认收货
@@ -269,19 +300,55 @@ func (h *OrderHandler) GetOrderStatistics(c *gin.Context) {
 	response.Success(c, stats)
 }
 
-// ProcessExpiredOrders 处理过期订单（管理员功能）
-// POST /api/v1/orders/process-expired
-// 需要认证（管理员权限）
-func (h *OrderHandler) ProcessExpiredOrders(c *gin.Context) {
-	// 调用业务逻辑
-	err := h.orderService.ProcessExpiredOrders()
+// GetOrderTimeline 获取订单时间线
+// GET /api/v1/orders/:id/timeline
+// 需要认证
+func (h *OrderHandler) GetOrderTimeline(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+	
+	// 2. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+	
+	// 3. 调用业务逻辑
+	timeline, err := h.orderService.GetOrderTimeline(userID, uint(orderID))
+	if err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+	
+	// 4. 返回成功响应
+	response.Success(c, timeline)
+}
+
+// GetOrderTimelineForStaff 获取任意订单的时间线（客服/管理员功能）
+// GET /api/v1/admin/orders/:id/timeline
+// 需要查看订单权限
+func (h *OrderHandler) GetOrderTimelineForStaff(c *gin.Context) {
+	// 1. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		response.Error(c, response.ERROR, err.Error())
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+	
+	// 2. 调用业务逻辑
+	timeline, err := h.orderService.GetOrderTimelineForStaff(uint(orderID))
+	if err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
 		return
 	}
 	
-	// 返回成功响应
-	response.SuccessWithMessage(c, "过期订单处理完成", nil)
+	// 3. 返回成功响应
+	response.Success(c, timeline)
 }
 
 // RegisterRoutes 注册订单相关路由
@@ -289,17 +356,25 @@ func (h *OrderHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middle
 	// 所有订单路由都需要认证
 	orders := r.Group("/orders")
 	orders.Use(authMiddleware.RequireAuth())
+	// 创建订单、取消订单、发起支付支持Idempotency-Key，防止重复提交
+	idempotent := h.idempotency.Handle()
+	{
+		orders.POST("", idempotent, h.CreateOrder)           // 创建订单
+		orders.POST("/preview", h.PreviewOrder)              // 订单预览
+		orders.GET("", h.GetOrderList)                       // 获取订单列表
+		orders.GET("/statistics", h.GetOrderStatistics)      // 获取订单统计
+		orders.GET("/:id", h.GetOrder)                       // 获取订单详情
+		orders.GET("/no/:orderNo", h.GetOrderByNo)           // 根据订单号获取订单
+		orders.PUT("/:id/cancel", idempotent, h.CancelOrder) // 取消订单
+		orders.POST("/:id/pay", idempotent, h.PayOrder)      // 发起支付
+		orders.PUT("/:id/confirm", h.ConfirmOrder)           // 确认收货
+		orders.GET("/:id/timeline", h.GetOrderTimeline)      // 获取订单时间线
+	}
+	
+	// 客服/管理员功能（需要查看订单权限）
+	admin := r.Group("/admin/orders")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionOrderView))
 	{
-		orders.POST("", h.CreateOrder)                    // 创建订单
-		orders.GET("", h.GetOrderList)                    // 获取订单列表
-		orders.GET("/statistics", h.GetOrderStatistics)  // 获取订单统计
-		orders.GET("/:id", h.GetOrder)                    // 获取订单详情
-		orders.GET("/no/:orderNo", h.GetOrderByNo)        // 根据订单号获取订单
-		orders.PUT("/:id/cancel", h.CancelOrder)          // 取消订单
-		orders.POST("/:id/pay", h.PayOrder)               // 支付订单
-		orders.PUT("/:id/confirm", h.ConfirmOrder)        // 确认收货
-		
-		// 管理员功能
-		orders.POST("/process-expired", h.ProcessExpiredOrders) // 处理过期订单
+		admin.GET("/:id/timeline", h.GetOrderTimelineForStaff) // 获取订单时间线
 	}
 }
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentHandler 支付HTTP处理器
+type PaymentHandler struct {
+	paymentService service.PaymentService
+}
+
+// NewPaymentHandler 创建支付处理器实例
+func NewPaymentHandler(paymentService service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+// Notify 支付渠道异步通知
+// POST /api/v1/payments/notify/:method
+// 公开接口，由支付渠道调用，依靠签名校验保证安全
+func (h *PaymentHandler) Notify(c *gin.Context) {
+	// 1. 读取原始报文（验签需要原始字节）
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.String(http.StatusBadRequest, "fail")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	method := c.Param("method")
+	if err := h.paymentService.HandleNotify(method, c.Request.Header, body); err != nil {
+		log.Printf("payment notify (%s) rejected: %v", method, err)
+		c.String(http.StatusBadRequest, "fail")
+		return
+	}
+
+	// 3. 按渠道约定返回确认，渠道收到后停止重试
+	c.String(http.StatusOK, "success")
+}
+
+// SimulatePay 沙箱模拟付款
+// POST /api/v1/payments/sandbox/:method/:paymentNo
+// 需要认证，仅沙箱渠道可用
+func (h *PaymentHandler) SimulatePay(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定请求参数，默认模拟支付成功
+	req := struct {
+		Success *bool `json:"success"`
+	}{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "请求参数错误: "+err.Error())
+			return
+		}
+	}
+	success := req.Success == nil || *req.Success
+
+	// 3. 调用业务逻辑
+	err := h.paymentService.SimulatePay(userID, c.Param("method"), c.Param("paymentNo"), success)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "模拟支付已提交，等待支付回调", nil)
+}
+
+// GetOrderPayments 获取订单的支付记录
+// GET /api/v1/orders/:id/payments
+// 需要认证
+func (h *PaymentHandler) GetOrderPayments(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	payments, err := h.paymentService.GetOrderPayments(userID, uint(orderID))
+	if err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, payments)
+}
+
+// RegisterRoutes 注册支付相关路由
+func (h *PaymentHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	payments := r.Group("/payments")
+	{
+		// 支付渠道回调（公开）
+		payments.POST("/notify/:method", h.Notify)
+
+		// 沙箱模拟付款（需要认证）
+		payments.POST("/sandbox/:method/:paymentNo", authMiddleware.RequireAuth(), h.SimulatePay)
+	}
+
+	// 订单支付记录（需要认证）
+	r.GET("/orders/:id/payments", authMiddleware.RequireAuth(), h.GetOrderPayments)
+}
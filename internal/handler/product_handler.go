@@ -14,13 +14,15 @@ import (
 type ProductHandler struct {
 	productService  service.ProductService
 	categoryService service.CategoryService
+	audit           *middleware.AuditMiddleware
 }
 
 // NewProductHandler 创建商品处理器实例
-func NewProductHandler(productService service.ProductService, categoryService service.CategoryService) *ProductHandler {
+func NewProductHandler(productService service.ProductService, categoryService service.CategoryService, audit *middleware.AuditMiddleware) *ProductHandler {
 	return &ProductHandler{
 		productService:  productService,
 		categoryService: categoryService,
+		audit:           audit,
 	}
 }
 
@@ -123,8 +125,8 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 }
 
 // GetProductList 获取商品列表
-// GET /api/v1/products
-// 公开接口，支持搜索和筛选
+// GET /api/v1/products?brand=华为&brand=小米&attr=颜色:黑色&in_stock=true
+// 公开接口，支持搜索和筛选，同时返回价格区间、分类、品牌、规格属性的分面统计
 func (h *ProductHandler) GetProductList(c *gin.Context) {
 	// 1. 绑定查询参数
 	var req model.ProductListRequest
@@ -206,13 +208,16 @@ func (h *ProductHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *midd
 	r.GET("/products/:id", h.GetProduct)           // 获取商品详情
 	r.GET("/categories/:id/products", h.GetProductsByCategory) // 根据分类获取商品
 	
-	// 需要认证的路由（管理员功能）
+	// 需要商品管理权限的路由（管理员功能）
 	admin := r.Group("")
-	admin.Use(authMiddleware.RequireAuth())
+	admin.Use(authMiddleware.RequirePermission(model.PermissionProductManage))
 	{
-		admin.POST("/products", h.CreateProduct)           // 创建商品
-		admin.PUT("/products/:id", h.UpdateProduct)        // 更新商品
-		admin.DELETE("/products/:id", h.DeleteProduct)     // 删除商品
-		admin.PUT("/products/:id/stock", h.UpdateStock)    // 更新库存
+		admin.POST("/products", h.audit.Record(model.AdminActionProductCreate), h.CreateProduct)       // 创建商品
+		admin.PUT("/products/:id", h.audit.Record(model.AdminActionProductUpdate), h.UpdateProduct)    // 更新商品
+		admin.DELETE("/products/:id", h.audit.Record(model.AdminActionProductDelete), h.DeleteProduct) // 删除商品
 	}
+	
+	// 需要库存管理权限的路由
+	r.PUT("/products/:id/stock", authMiddleware.RequirePermission(model.PermissionStockManage),
+		h.audit.Record(model.AdminActionProductStock), h.UpdateStock) // 更新库存
 }
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RankingHandler 商品热度排行HTTP处理器
+type RankingHandler struct {
+	rankingService service.RankingService
+}
+
+// NewRankingHandler 创建商品热度排行处理器实例
+func NewRankingHandler(rankingService service.RankingService) *RankingHandler {
+	return &RankingHandler{
+		rankingService: rankingService,
+	}
+}
+
+// HotProducts 热门商品排行
+// GET /api/v1/products/hot?window=daily&category_id=1&limit=10
+// window: daily 最近24小时，weekly 最近7天；不传category_id时为全站排行
+func (h *RankingHandler) HotProducts(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.HotProductsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.rankingService.HotProducts(&req)
+	if err != nil {
+		response.InternalServerError(c, "获取热门商品失败")
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// RegisterRoutes 注册商品热度排行相关路由
+func (h *RankingHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	r.GET("/products/hot", h.HotProducts) // 热门商品排行（公开）
+}
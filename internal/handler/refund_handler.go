@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefundHandler 售后退款HTTP处理器
+type RefundHandler struct {
+	refundService service.RefundService
+	audit         *middleware.AuditMiddleware
+}
+
+// NewRefundHandler 创建售后退款处理器实例
+func NewRefundHandler(refundService service.RefundService, audit *middleware.AuditMiddleware) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+		audit:         audit,
+	}
+}
+
+// CreateRefund 申请退款
+// POST /api/v1/orders/:id/refunds
+// 需要认证
+func (h *RefundHandler) CreateRefund(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	refund, err := h.refundService.CreateRefund(userID, uint(orderID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "退款申请已提交", refund)
+}
+
+// GetOrderRefunds 获取订单的退款申请
+// GET /api/v1/orders/:id/refunds
+// 需要认证
+func (h *RefundHandler) GetOrderRefunds(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	refunds, err := h.refundService.GetOrderRefunds(userID, uint(orderID))
+	if err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, refunds)
+}
+
+// ListRefunds 获取退款申请列表
+// GET /api/v1/admin/refunds
+// 需要售后管理权限
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.RefundListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.refundService.ListRefunds(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// ApproveRefund 审核通过退款
+// PUT /api/v1/admin/refunds/:id/approve
+// 需要售后管理权限
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	// 1. 获取审核人ID
+	reviewerID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	refundID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "退款申请ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.ReviewRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	refund, err := h.refundService.ApproveRefund(reviewerID, uint(refundID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "退款成功", refund)
+}
+
+// RejectRefund 拒绝退款
+// PUT /api/v1/admin/refunds/:id/reject
+// 需要售后管理权限
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	// 1. 获取审核人ID
+	reviewerID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	refundID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "退款申请ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.ReviewRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	if err := h.refundService.RejectRefund(reviewerID, uint(refundID), &req); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "已拒绝退款申请", nil)
+}
+
+// RegisterRoutes 注册售后退款相关路由
+func (h *RefundHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 用户售后（需要认证）
+	orders := r.Group("/orders")
+	orders.Use(authMiddleware.RequireAuth())
+	{
+		orders.POST("/:id/refunds", h.CreateRefund)   // 申请退款
+		orders.GET("/:id/refunds", h.GetOrderRefunds) // 获取订单的退款申请
+	}
+
+	// 售后审核（需要售后管理权限）
+	admin := r.Group("/admin/refunds")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionRefundManage))
+	{
+		admin.GET("", h.ListRefunds)                                                               // 获取退款申请列表
+		admin.PUT("/:id/approve", h.audit.Record(model.AdminActionRefundApprove), h.ApproveRefund) // 审核通过
+		admin.PUT("/:id/reject", h.audit.Record(model.AdminActionRefundReject), h.RejectRefund)    // 拒绝退款
+	}
+}
@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler 商品评价HTTP处理器
+type ReviewHandler struct {
+	reviewService service.ReviewService
+	audit         *middleware.AuditMiddleware
+}
+
+// NewReviewHandler 创建商品评价处理器实例
+func NewReviewHandler(reviewService service.ReviewService, audit *middleware.AuditMiddleware) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+		audit:         audit,
+	}
+}
+
+// CreateReview 发表评价
+// POST /api/v1/reviews
+// 需要认证，订单确认收货后才能评价
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	review, err := h.reviewService.CreateReview(userID, &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "评价已提交，审核通过后展示", review)
+}
+
+// GetProductReviews 获取商品的评价列表
+// GET /api/v1/products/:id/reviews?rating=5&with_images=true&sort=helpful&page=1&page_size=10
+func (h *ReviewHandler) GetProductReviews(c *gin.Context) {
+	// 1. 获取路径参数
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "商品ID格式错误")
+		return
+	}
+
+	// 2. 绑定查询参数
+	var req model.ProductReviewListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	result, err := h.reviewService.GetProductReviews(uint(productID), &req)
+	if err != nil {
+		response.InternalServerError(c, "获取商品评价失败")
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, result)
+}
+
+// GetUserReviews 获取我的评价
+// GET /api/v1/users/reviews?status=1&page=1&page_size=10
+// 需要认证
+func (h *ReviewHandler) GetUserReviews(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 绑定查询参数
+	var req model.ReviewListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	result, err := h.reviewService.GetUserReviews(userID, &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, result)
+}
+
+// VoteHelpful 投票评价有用
+// POST /api/v1/reviews/:id/helpful
+// 需要认证
+func (h *ReviewHandler) VoteHelpful(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "评价ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.reviewService.VoteHelpful(userID, uint(reviewID)); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "已标记为有用", nil)
+}
+
+// UnvoteHelpful 取消有用投票
+// DELETE /api/v1/reviews/:id/helpful
+// 需要认证
+func (h *ReviewHandler) UnvoteHelpful(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "评价ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.reviewService.UnvoteHelpful(userID, uint(reviewID)); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "已取消", nil)
+}
+
+// ListReviews 获取评价列表
+// GET /api/v1/admin/reviews?status=1&product_id=1&page=1&page_size=10
+// 需要评价管理权限
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.ReviewListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "查询参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.reviewService.ListReviews(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, result)
+}
+
+// ApproveReview 审核通过评价
+// PUT /api/v1/admin/reviews/:id/approve
+// 需要评价管理权限
+func (h *ReviewHandler) ApproveReview(c *gin.Context) {
+	// 1. 获取审核人ID
+	moderatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "评价ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.reviewService.ApproveReview(moderatorID, uint(reviewID)); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "审核通过", nil)
+}
+
+// RejectReview 驳回评价
+// PUT /api/v1/admin/reviews/:id/reject
+// 需要评价管理权限
+func (h *ReviewHandler) RejectReview(c *gin.Context) {
+	// 1. 获取审核人ID
+	moderatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "评价ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	if err := h.reviewService.RejectReview(moderatorID, uint(reviewID), &req); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "已驳回评价", nil)
+}
+
+// ReplyReview 商家回复评价
+// PUT /api/v1/admin/reviews/:id/reply
+// 需要评价管理权限
+func (h *ReviewHandler) ReplyReview(c *gin.Context) {
+	// 1. 获取路径参数
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "评价ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.ReplyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.reviewService.ReplyReview(uint(reviewID), &req); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "回复成功", nil)
+}
+
+// RegisterRoutes 注册商品评价相关路由
+func (h *ReviewHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 公开路由（不需要认证）
+	r.GET("/products/:id/reviews", h.GetProductReviews) // 获取商品的评价列表
+
+	// 用户评价（需要认证）
+	user := r.Group("")
+	user.Use(authMiddleware.RequireAuth())
+	{
+		user.POST("/reviews", h.CreateReview)                // 发表评价
+		user.GET("/users/reviews", h.GetUserReviews)         // 获取我的评价
+		user.POST("/reviews/:id/helpful", h.VoteHelpful)     // 投票评价有用
+		user.DELETE("/reviews/:id/helpful", h.UnvoteHelpful) // 取消有用投票
+	}
+
+	// 评价审核与回复（需要评价管理权限）
+	admin := r.Group("/admin/reviews")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionReviewManage))
+	{
+		admin.GET("", h.ListReviews)                                                               // 获取评价列表
+		admin.PUT("/:id/approve", h.audit.Record(model.AdminActionReviewApprove), h.ApproveReview) // 审核通过
+		admin.PUT("/:id/reject", h.audit.Record(model.AdminActionReviewReject), h.RejectReview)    // 驳回评价
+		admin.PUT("/:id/reply", h.audit.Record(model.AdminActionReviewReply), h.ReplyReview)       // 商家回复
+	}
+}
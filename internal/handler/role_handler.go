@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色权限HTTP处理器
+type RoleHandler struct {
+	roleService service.RoleService
+	audit       *middleware.AuditMiddleware
+}
+
+// NewRoleHandler 创建角色权限处理器实例
+func NewRoleHandler(roleService service.RoleService, audit *middleware.AuditMiddleware) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		audit:       audit,
+	}
+}
+
+// ListRoles 获取全部角色及其权限
+// GET /api/v1/admin/roles
+// 需要角色管理权限
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	response.Success(c, roles)
+}
+
+// ListPermissions 获取全部权限
+// GET /api/v1/admin/permissions
+// 需要角色管理权限
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleService.ListPermissions()
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	response.Success(c, permissions)
+}
+
+// CreateRole 创建角色
+// POST /api/v1/admin/roles
+// 需要角色管理权限
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	// 1. 绑定请求参数
+	var req model.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	role, err := h.roleService.CreateRole(&req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.SuccessWithMessage(c, "角色创建成功", role)
+}
+
+// UpdateRole 更新角色
+// PUT /api/v1/admin/roles/:id
+// 需要角色管理权限
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	// 1. 解析角色ID
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "角色ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	role, err := h.roleService.UpdateRole(uint(id), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "角色更新成功", role)
+}
+
+// DeleteRole 删除角色
+// DELETE /api/v1/admin/roles/:id
+// 需要角色管理权限
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	// 1. 解析角色ID
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "角色ID格式错误")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	if err := h.roleService.DeleteRole(uint(id)); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.SuccessWithMessage(c, "角色删除成功", nil)
+}
+
+// GetUserRoles 获取用户的角色
+// GET /api/v1/admin/users/:id/roles
+// 需要角色管理权限
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	// 1. 解析用户ID
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "用户ID格式错误")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	roles, err := h.roleService.GetUserRoles(uint(userID))
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, gin.H{"roles": roles})
+}
+
+// SetUserRoles 设置用户的角色，无需重新登录即可生效
+// PUT /api/v1/admin/users/:id/roles
+// 需要角色管理权限
+func (h *RoleHandler) SetUserRoles(c *gin.Context) {
+	// 1. 解析用户ID
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "用户ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	roles, err := h.roleService.SetUserRoles(uint(userID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "用户角色已更新", gin.H{"roles": roles})
+}
+
+// RegisterRoutes 注册角色权限相关路由
+func (h *RoleHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 角色权限管理（需要角色管理权限）
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionRoleManage))
+	{
+		admin.GET("/roles", h.ListRoles)                                                          // 获取全部角色
+		admin.POST("/roles", h.audit.Record(model.AdminActionRoleCreate), h.CreateRole)           // 创建角色
+		admin.PUT("/roles/:id", h.audit.Record(model.AdminActionRoleUpdate), h.UpdateRole)        // 更新角色
+		admin.DELETE("/roles/:id", h.audit.Record(model.AdminActionRoleDelete), h.DeleteRole)     // 删除角色
+		admin.GET("/permissions", h.ListPermissions)                                              // 获取全部权限
+		admin.GET("/users/:id/roles", h.GetUserRoles)                                             // 获取用户的角色
+		admin.PUT("/users/:id/roles", h.audit.Record(model.AdminActionUserRoles), h.SetUserRoles) // 设置用户的角色
+	}
+}
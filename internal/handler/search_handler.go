@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler 商品搜索HTTP处理器
+type SearchHandler struct {
+	searchService  service.ProductSearchService
+	suggestService service.SearchSuggestService
+}
+
+// NewSearchHandler 创建商品搜索处理器实例
+func NewSearchHandler(searchService service.ProductSearchService, suggestService service.SearchSuggestService) *SearchHandler {
+	return &SearchHandler{
+		searchService:  searchService,
+		suggestService: suggestService,
+	}
+}
+
+// SearchProducts 搜索商品
+// GET /api/v1/products/search?keyword=手机&category_id=1&min_price=100&max_price=5000&sort=relevance&page=1&page_size=10
+// sort: relevance 相关度，sales 销量，price_asc/price_desc 价格，newest 最新上架
+func (h *SearchHandler) SearchProducts(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.ProductSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	result, err := h.searchService.Search(c.Request.Context(), &req)
+	if err != nil {
+		response.InternalServerError(c, "搜索商品失败")
+		return
+	}
+
+	// 3. 记录搜索词，翻页不重复记录
+	if req.Keyword != "" && req.Page == 1 {
+		h.suggestService.RecordSearch(req.Keyword)
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, result)
+}
+
+// Suggest 搜索建议
+// GET /api/v1/products/suggest?q=hw&limit=10
+// 按已输入的内容补全商品名称、分类和品牌，支持拼音首字母
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.SuggestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	suggestions, err := h.suggestService.Suggest(&req)
+	if err != nil {
+		response.InternalServerError(c, "获取搜索建议失败")
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, suggestions)
+}
+
+// HotSearches 热门搜索词
+// GET /api/v1/products/hot-searches?limit=10
+func (h *SearchHandler) HotSearches(c *gin.Context) {
+	// 1. 绑定查询参数
+	var req model.HotSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	keywords, err := h.suggestService.HotSearches(req.Limit)
+	if err != nil {
+		response.InternalServerError(c, "获取热门搜索词失败")
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, keywords)
+}
+
+// RegisterRoutes 注册商品搜索相关路由
+func (h *SearchHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	r.GET("/products/search", h.SearchProducts)    // 搜索商品（公开）
+	r.GET("/products/suggest", h.Suggest)          // 搜索建议（公开）
+	r.GET("/products/hot-searches", h.HotSearches) // 热门搜索词（公开）
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShipmentHandler 发货与物流HTTP处理器
+type ShipmentHandler struct {
+	shipmentService service.ShipmentService
+	audit           *middleware.AuditMiddleware
+}
+
+// NewShipmentHandler 创建发货与物流处理器实例
+func NewShipmentHandler(shipmentService service.ShipmentService, audit *middleware.AuditMiddleware) *ShipmentHandler {
+	return &ShipmentHandler{
+		shipmentService: shipmentService,
+		audit:           audit,
+	}
+}
+
+// ShipOrder 发货
+// POST /api/v1/admin/orders/:id/shipments
+// 需要发货管理权限
+func (h *ShipmentHandler) ShipOrder(c *gin.Context) {
+	// 1. 获取操作人ID
+	operatorID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	// 3. 绑定请求参数
+	var req model.ShipOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 4. 调用业务逻辑
+	shipment, err := h.shipmentService.ShipOrder(operatorID, uint(orderID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 5. 返回成功响应
+	response.SuccessWithMessage(c, "发货成功", shipment)
+}
+
+// GetOrderShipments 获取订单的包裹和物流轨迹
+// GET /api/v1/orders/:id/shipments
+// 需要认证
+func (h *ShipmentHandler) GetOrderShipments(c *gin.Context) {
+	// 1. 获取用户ID
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "用户未认证")
+		return
+	}
+
+	// 2. 获取路径参数
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "订单ID格式错误")
+		return
+	}
+
+	// 3. 调用业务逻辑
+	shipments, err := h.shipmentService.GetOrderShipments(userID, uint(orderID))
+	if err != nil {
+		response.Error(c, response.NOT_FOUND, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.Success(c, shipments)
+}
+
+// Webhook 物流公司轨迹推送
+// POST /api/v1/logistics/webhook/:carrier
+// 公开接口，由物流公司调用，依靠签名校验保证安全
+func (h *ShipmentHandler) Webhook(c *gin.Context) {
+	// 1. 读取原始报文（验签需要原始字节）
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.String(http.StatusBadRequest, "fail")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	carrier := c.Param("carrier")
+	if err := h.shipmentService.HandleWebhook(carrier, c.Request.Header, body); err != nil {
+		log.Printf("logistics webhook (%s) rejected: %v", carrier, err)
+		c.String(http.StatusBadRequest, "fail")
+		return
+	}
+
+	// 3. 返回确认，物流公司收到后停止重试
+	c.String(http.StatusOK, "success")
+}
+
+// SimulateTracking 模拟物流轨迹
+// POST /api/v1/admin/shipments/:id/simulate
+// 需要发货管理权限，仅模拟物流公司可用
+func (h *ShipmentHandler) SimulateTracking(c *gin.Context) {
+	// 1. 获取路径参数
+	shipmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "包裹ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.SimulateTrackingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.shipmentService.SimulateTracking(uint(shipmentID), &req); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "物流轨迹已推送", nil)
+}
+
+// RegisterRoutes 注册发货与物流相关路由
+func (h *ShipmentHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 物流公司轨迹推送（公开接口，依靠签名校验）
+	r.POST("/logistics/webhook/:carrier", h.Webhook)
+
+	// 用户查看物流（需要认证）
+	orders := r.Group("/orders")
+	orders.Use(authMiddleware.RequireAuth())
+	{
+		orders.GET("/:id/shipments", h.GetOrderShipments) // 获取订单的包裹和物流轨迹
+	}
+
+	// 发货管理（需要发货管理权限）
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionShipmentManage))
+	{
+		admin.POST("/orders/:id/shipments", h.audit.Record(model.AdminActionShipmentCreate), h.ShipOrder)         // 发货
+		admin.POST("/shipments/:id/simulate", h.audit.Record(model.AdminActionShipmentSimulate), h.SimulateTracking) // 模拟物流轨迹
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SkuHandler 商品规格HTTP处理器
+type SkuHandler struct {
+	skuService service.SkuService
+	audit      *middleware.AuditMiddleware
+}
+
+// NewSkuHandler 创建商品规格处理器实例
+func NewSkuHandler(skuService service.SkuService, audit *middleware.AuditMiddleware) *SkuHandler {
+	return &SkuHandler{
+		skuService: skuService,
+		audit:      audit,
+	}
+}
+
+// SetProductSkus 设置商品的规格矩阵
+// PUT /api/v1/admin/products/:id/skus
+// 需要管理员权限
+func (h *SkuHandler) SetProductSkus(c *gin.Context) {
+	// 1. 获取路径参数
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "商品ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.SetProductSkusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	skus, err := h.skuService.SetProductSkus(uint(productID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "商品规格更新成功", skus)
+}
+
+// ListProductSkus 获取商品的全部SKU，包含已下架的
+// GET /api/v1/admin/products/:id/skus
+// 需要管理员权限
+func (h *SkuHandler) ListProductSkus(c *gin.Context) {
+	// 1. 获取路径参数
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "商品ID格式错误")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	skus, err := h.skuService.ListProductSkus(uint(productID))
+	if err != nil {
+		response.InternalServerError(c, "获取商品规格失败")
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, skus)
+}
+
+// RegisterRoutes 注册商品规格相关路由
+// 用户端的规格矩阵随商品详情返回，这里只有管理接口
+func (h *SkuHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	admin := r.Group("/admin/products")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionProductManage))
+	{
+		admin.PUT("/:id/skus", h.audit.Record(model.AdminActionProductSkus), h.SetProductSkus) // 设置商品的规格矩阵
+		admin.GET("/:id/skus", h.ListProductSkus)                                              // 获取商品的全部SKU
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"ryan-mall/internal/middleware"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/service"
+	"ryan-mall/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WarehouseHandler 仓库HTTP处理器
+type WarehouseHandler struct {
+	warehouseService service.WarehouseService
+	audit            *middleware.AuditMiddleware
+}
+
+// NewWarehouseHandler 创建仓库处理器实例
+func NewWarehouseHandler(warehouseService service.WarehouseService, audit *middleware.AuditMiddleware) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseService: warehouseService,
+		audit:            audit,
+	}
+}
+
+// CreateWarehouse 创建仓库
+// POST /api/v1/admin/warehouses
+// 需要管理员权限
+func (h *WarehouseHandler) CreateWarehouse(c *gin.Context) {
+	// 1. 绑定请求参数
+	var req model.CreateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 2. 调用业务逻辑
+	warehouse, err := h.warehouseService.CreateWarehouse(&req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.SuccessWithMessage(c, "仓库创建成功", warehouse)
+}
+
+// ListWarehouses 获取仓库列表
+// GET /api/v1/admin/warehouses
+// 需要管理员权限
+func (h *WarehouseHandler) ListWarehouses(c *gin.Context) {
+	warehouses, err := h.warehouseService.ListWarehouses()
+	if err != nil {
+		response.InternalServerError(c, "获取仓库列表失败")
+		return
+	}
+
+	response.Success(c, warehouses)
+}
+
+// UpdateWarehouse 更新仓库
+// PUT /api/v1/admin/warehouses/:id
+// 需要管理员权限
+func (h *WarehouseHandler) UpdateWarehouse(c *gin.Context) {
+	// 1. 获取路径参数
+	warehouseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "仓库ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.UpdateWarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	warehouse, err := h.warehouseService.UpdateWarehouse(uint(warehouseID), &req)
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "仓库更新成功", warehouse)
+}
+
+// SetStock 设置商品在仓库的库存
+// PUT /api/v1/admin/warehouses/:id/stocks/:productId
+// 需要管理员权限
+func (h *WarehouseHandler) SetStock(c *gin.Context) {
+	// 1. 获取路径参数
+	warehouseID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "仓库ID格式错误")
+		return
+	}
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "商品ID格式错误")
+		return
+	}
+
+	// 2. 绑定请求参数
+	var req model.SetWarehouseStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 3. 调用业务逻辑
+	if err := h.warehouseService.SetStock(uint(warehouseID), uint(productID), *req.Stock); err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 4. 返回成功响应
+	response.SuccessWithMessage(c, "仓库库存更新成功", nil)
+}
+
+// GetProductStocks 获取商品的分仓库存
+// GET /api/v1/admin/products/:id/stocks
+// 需要管理员权限
+func (h *WarehouseHandler) GetProductStocks(c *gin.Context) {
+	// 1. 获取路径参数
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "商品ID格式错误")
+		return
+	}
+
+	// 2. 调用业务逻辑
+	stocks, err := h.warehouseService.GetProductStocks(uint(productID))
+	if err != nil {
+		response.Error(c, response.ERROR, err.Error())
+		return
+	}
+
+	// 3. 返回成功响应
+	response.Success(c, stocks)
+}
+
+// RegisterRoutes 注册仓库相关路由
+func (h *WarehouseHandler) RegisterRoutes(r *gin.RouterGroup, authMiddleware *middleware.AuthMiddleware) {
+	// 仓库管理（管理员功能）
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware.RequirePermission(model.PermissionStockManage))
+	{
+		admin.POST("/warehouses", h.audit.Record(model.AdminActionWarehouseCreate), h.CreateWarehouse)              // 创建仓库
+		admin.GET("/warehouses", h.ListWarehouses)                                                                  // 获取仓库列表
+		admin.PUT("/warehouses/:id", h.audit.Record(model.AdminActionWarehouseUpdate), h.UpdateWarehouse)           // 更新仓库
+		admin.PUT("/warehouses/:id/stocks/:productId", h.audit.Record(model.AdminActionWarehouseStock), h.SetStock) // 设置商品在仓库的库存
+		admin.GET("/products/:id/stocks", h.GetProductStocks)                                                       // 获取商品的分仓库存
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware 管理后台审计中间件
+// 记录每一次管理操作的操作人、请求内容和结果
+type AuditMiddleware struct {
+	repo repository.AuditLogRepository // 审计日志存储
+}
+
+// NewAuditMiddleware 创建管理后台审计中间件实例
+func NewAuditMiddleware(repo repository.AuditLogRepository) *AuditMiddleware {
+	return &AuditMiddleware{
+		repo: repo,
+	}
+}
+
+// Record 记录管理操作的中间件，需要放在RequireRole或RequirePermission之后
+// 无论操作成功与否都会记录；写入审计日志失败只记录日志，不影响已经完成的操作
+func (m *AuditMiddleware) Record(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. 读取请求体，读取后放回去给处理器绑定
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.BadRequest(c, "读取请求失败")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// 2. 执行处理器并记录响应
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// 3. 写入审计日志
+		adminID, _ := GetCurrentUserID(c)
+		adminName, _ := GetCurrentUsername(c)
+		entry := &model.AdminAuditLog{
+			AdminID:   adminID,
+			AdminName: adminName,
+			Action:    action,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.RequestURI(),
+			TargetID:  c.Param("id"),
+			Success:   succeeded(recorder.Status(), recorder.body.Bytes()),
+			Message:   responseMessage(recorder.body.Bytes()),
+			IP:        c.ClientIP(),
+		}
+		entry.SetRequest(body)
+		if err := m.repo.Create(entry); err != nil {
+			log.Printf("audit: record %s by admin %d failed: %v", action, adminID, err)
+		}
+	}
+}
+
+// responseMessage 取出响应体中的消息，不是统一响应格式时返回空字符串
+func responseMessage(body []byte) string {
+	var resp response.Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	message := []rune(resp.Message)
+	if len(message) > 255 {
+		message = message[:255]
+	}
+	return string(message)
+}
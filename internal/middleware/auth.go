@@ -13,12 +13,14 @@ import (
 // 用于验证JWT令牌并提取用户信息
 type AuthMiddleware struct {
 	userService service.UserService // 用户服务，用于验证令牌
+	roleService service.RoleService // 角色服务，用于检查权限
 }
 
 // NewAuthMiddleware 创建认证中间件实例
-func NewAuthMiddleware(userService service.UserService) *AuthMiddleware {
+func NewAuthMiddleware(userService service.UserService, roleService service.RoleService) *AuthMiddleware {
 	return &AuthMiddleware{
 		userService: userService,
+		roleService: roleService,
 	}
 }
 
@@ -26,52 +28,74 @@ func NewAuthMiddleware(userService service.UserService) *AuthMiddleware {
 // 验证请求头中的JWT令牌，如果有效则继续处理，否则返回401错误
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 1. 从请求头获取Authorization字段
-		// 标准格式：Authorization: Bearer <token>
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			response.Unauthorized(c, "缺少认证令牌")
-			c.Abort() // 终止请求处理
-			return
-		}
-		
-		// 2. 解析Bearer令牌
-		// 检查是否以"Bearer "开头
-		const bearerPrefix = "Bearer "
-		if !strings.HasPrefix(authHeader, bearerPrefix) {
-			response.Unauthorized(c, "认证令牌格式错误")
-			c.Abort()
-			return
-		}
-		
-		// 提取令牌字符串（去掉"Bearer "前缀）
-		tokenString := authHeader[len(bearerPrefix):]
-		if tokenString == "" {
-			response.Unauthorized(c, "认证令牌为空")
-			c.Abort()
-			return
-		}
-		
-		// 3. 验证令牌
-		claims, err := m.userService.ValidateToken(tokenString)
-		if err != nil {
-			response.Unauthorized(c, "认证令牌无效: "+err.Error())
-			c.Abort()
+		if !m.authenticate(c) {
 			return
 		}
 		
-		// 4. 将用户信息存储到上下文中
-		// 后续的处理器可以通过c.Get()获取用户信息
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("email", claims.Email)
-		c.Set("claims", claims)
-		
-		// 5. 继续处理请求
+		// 继续处理请求
 		c.Next()
 	}
 }
 
+// authenticate 验证请求头中的JWT令牌并把用户信息存入上下文
+// 令牌无效时返回401错误并终止请求，返回false
+func (m *AuthMiddleware) authenticate(c *gin.Context) bool {
+	// 1. 从请求头获取Authorization字段
+	// 标准格式：Authorization: Bearer <token>
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		response.Unauthorized(c, "缺少认证令牌")
+		c.Abort() // 终止请求处理
+		return false
+	}
+	
+	// 2. 解析Bearer令牌
+	// 检查是否以"Bearer "开头
+	const bearerPrefix = "Bearer "
+	if !strings.HasPrefix(authHeader, bearerPrefix) {
+		response.Unauthorized(c, "认证令牌格式错误")
+		c.Abort()
+		return false
+	}
+	
+	// 提取令牌字符串（去掉"Bearer "前缀）
+	tokenString := authHeader[len(bearerPrefix):]
+	if tokenString == "" {
+		response.Unauthorized(c, "认证令牌为空")
+		c.Abort()
+		return false
+	}
+	
+	// 3. 验证令牌
+	claims, err := m.userService.ValidateToken(tokenString)
+	if err != nil {
+		response.Unauthorized(c, "认证令牌无效: "+err.Error())
+		c.Abort()
+		return false
+	}
+	
+	// 4. 检查用户状态，已禁用用户的令牌在过期前同样不能使用
+	active, err := m.userService.IsActive(claims.UserID)
+	if err != nil {
+		response.InternalServerError(c, "检查用户状态失败")
+		c.Abort()
+		return false
+	}
+	if !active {
+		response.Unauthorized(c, "账户已被禁用")
+		c.Abort()
+		return false
+	}
+	
+	// 5. 将用户信息存储到上下文中
+	// 后续的处理器可以通过c.Get()获取用户信息
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("email", claims.Email)
+	c.Set("claims", claims)
+	return true
+}
+
 // OptionalAuth 可选认证的中间件
 // 如果有令牌则验证并设置用户信息，没有令牌也允许继续处理
 // 适用于某些接口既支持游客访问也支持用户访问的场景
@@ -107,6 +131,11 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		if active, err := m.userService.IsActive(claims.UserID); err != nil || !active {
+			// 用户已禁用，继续处理（作为游客）
+			c.Next()
+			return
+		}
 		
 		// 4. 令牌有效，设置用户信息
 		c.Set("user_id", claims.UserID)
@@ -119,6 +148,20 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	}
 }
 
+// RequireQueryTokenAuth 需要认证的中间件，令牌也可以放在token查询参数中
+// 浏览器建立WebSocket连接时无法设置请求头，只能通过查询参数传递令牌
+// 与RequireAuth一样检查用户状态，已禁用的用户不能建立连接
+func (m *AuthMiddleware) RequireQueryTokenAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		m.RequireAuth()(c)
+	}
+}
+
 // GetCurrentUserID 从上下文中获取当前用户ID
 // 这是一个辅助函数，用于在处理器中获取用户ID
 func GetCurrentUserID(c *gin.Context) (uint, bool) {
@@ -153,43 +196,56 @@ func GetCurrentUserEmail(c *gin.Context) (string, bool) {
 	return emailStr, ok
 }
 
-// RequireRole 需要特定角色的中间件（扩展功能）
-// 这是一个示例，展示如何实现基于角色的访问控制
-// 在实际项目中，可能需要在用户模型中添加角色字段
+// RequireRole 需要特定角色的中间件
+// 用户拥有任一指定角色即可访问，管理员角色可以访问全部接口
+// 每次请求从角色服务获取用户当前的角色，修改用户角色后无需重新登录
 func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 首先需要认证
-		m.RequireAuth()(c)
-		
-		// 如果认证失败，上面的中间件已经处理了
-		if c.IsAborted() {
+		// 1. 首先需要认证
+		if !m.authenticate(c) {
 			return
 		}
 		
-		// TODO: 实现角色验证逻辑
-		// 1. 从数据库获取用户角色
-		// 2. 检查用户是否具有所需角色
-		// 3. 如果没有权限，返回403错误
-		
-		// 示例代码（需要根据实际角色系统实现）:
-		/*
+		// 2. 检查角色
 		userID, _ := GetCurrentUserID(c)
-		userRoles := getUserRoles(userID) // 需要实现这个函数
+		allowed, err := m.roleService.UserHasRole(userID, roles...)
+		if err != nil {
+			response.InternalServerError(c, "检查权限失败")
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.Forbidden(c, "权限不足")
+			c.Abort()
+			return
+		}
 		
-		hasPermission := false
-		for _, role := range roles {
-			if contains(userRoles, role) {
-				hasPermission = true
-				break
-			}
+		c.Next()
+	}
+}
+
+// RequirePermission 需要特定权限的中间件
+// 用户的任一角色拥有该权限即可访问，角色的权限可以在管理后台调整
+func (m *AuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. 首先需要认证
+		if !m.authenticate(c) {
+			return
 		}
 		
-		if !hasPermission {
+		// 2. 检查权限
+		userID, _ := GetCurrentUserID(c)
+		allowed, err := m.roleService.UserHasPermission(userID, permission)
+		if err != nil {
+			response.InternalServerError(c, "检查权限失败")
+			c.Abort()
+			return
+		}
+		if !allowed {
 			response.Forbidden(c, "权限不足")
 			c.Abort()
 			return
 		}
-		*/
 		
 		c.Next()
 	}
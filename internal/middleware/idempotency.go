@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/response"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader 客户端传入幂等键的请求头
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyMiddleware 幂等中间件
+// 客户端为一次操作生成唯一的Idempotency-Key，重复提交（双击、超时重试）时
+// 不会重复执行业务逻辑，而是返回第一次请求的响应
+type IdempotencyMiddleware struct {
+	repo  repository.IdempotencyRepository // 幂等记录存储
+	ttl   time.Duration                    // 幂等键有效期
+	lease time.Duration                    // 处理中记录的租约，到期后幂等键可以被重新占用
+}
+
+// NewIdempotencyMiddleware 创建幂等中间件实例
+// 租约需要比请求的最长处理时间长、比有效期短，未设置或超过有效期时使用有效期
+func NewIdempotencyMiddleware(repo repository.IdempotencyRepository, ttl, lease time.Duration) *IdempotencyMiddleware {
+	if lease <= 0 || lease > ttl {
+		lease = ttl
+	}
+	return &IdempotencyMiddleware{
+		repo:  repo,
+		ttl:   ttl,
+		lease: lease,
+	}
+}
+
+// Handle 幂等处理中间件，需要放在RequireAuth之后
+// 没有Idempotency-Key请求头的请求直接放行；只有业务成功的响应会被保存，
+// 失败的请求会释放幂等键，客户端可以用同一个键重试
+func (m *IdempotencyMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. 读取幂等键
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > model.MaxIdempotencyKeyLength {
+			response.BadRequest(c, "幂等键过长")
+			c.Abort()
+			return
+		}
+
+		userID, exists := GetCurrentUserID(c)
+		if !exists {
+			response.Unauthorized(c, "用户未认证")
+			c.Abort()
+			return
+		}
+
+		// 2. 计算请求指纹，读取后的请求体放回去给处理器绑定
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.BadRequest(c, "读取请求失败")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := model.IdempotencyFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		// 3. 占用幂等键，已被占用时按已有记录处理
+		now := time.Now()
+		leaseUntil := now.Add(m.lease)
+		record := &model.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      model.IdempotencyStatusProcessing,
+			ExpireAt:    now.Add(m.ttl),
+			LeaseUntil:  &leaseUntil,
+		}
+		existing, err := m.repo.Acquire(record)
+		if err != nil {
+			log.Printf("idempotency: acquire key %q for user %d failed: %v", key, userID, err)
+			response.InternalServerError(c, "请求处理失败，请稍后重试")
+			c.Abort()
+			return
+		}
+		if existing != nil {
+			m.replay(c, existing, fingerprint)
+			c.Abort()
+			return
+		}
+
+		// 4. 执行处理器并记录响应，处理器panic时释放幂等键
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				m.release(record)
+				panic(recovered)
+			}
+		}()
+		c.Next()
+
+		// 5. 保存成功的响应，失败的释放幂等键
+		if !succeeded(recorder.Status(), recorder.body.Bytes()) {
+			m.release(record)
+			return
+		}
+		err = m.repo.Complete(record.ID, recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err != nil {
+			log.Printf("idempotency: save response for key %q failed: %v", key, err)
+		}
+	}
+}
+
+// replay 处理幂等键已被占用的请求
+func (m *IdempotencyMiddleware) replay(c *gin.Context, existing *model.IdempotencyRecord, fingerprint string) {
+	if existing.Fingerprint != fingerprint {
+		response.Conflict(c, "幂等键已用于其他请求")
+		return
+	}
+	if existing.Status != model.IdempotencyStatusCompleted {
+		response.Conflict(c, "请求正在处理中，请勿重复提交")
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(existing.ResponseStatus, existing.ContentType, []byte(existing.ResponseBody))
+}
+
+// release 释放幂等键
+func (m *IdempotencyMiddleware) release(record *model.IdempotencyRecord) {
+	if err := m.repo.Release(record.ID); err != nil {
+		log.Printf("idempotency: release key %q failed: %v", record.Key, err)
+	}
+}
+
+// succeeded 判断响应是否业务成功
+// 业务错误也以HTTP 200返回，需要根据响应体中的业务状态码判断
+func succeeded(status int, body []byte) bool {
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return false
+	}
+	var resp response.Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return false
+	}
+	return resp.Code == response.SUCCESS
+}
+
+// responseRecorder 记录响应内容的ResponseWriter
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write 写入响应的同时记录内容
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应的同时记录内容
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxAddressesPerUser 每个用户最多保存的收货地址数量
+const MaxAddressesPerUser = 20
+
+var (
+	phonePattern   = regexp.MustCompile(`^1[3-9]\d{9}$`) // 大陆手机号
+	zipcodePattern = regexp.MustCompile(`^\d{6}$`)       // 大陆邮政编码
+)
+
+// Address 用户收货地址模型
+type Address struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`                     // 地址ID
+	UserID    uint           `json:"user_id" gorm:"not null;index"`            // 用户ID
+	Name      string         `json:"name" gorm:"size:50;not null"`             // 收货人姓名
+	Phone     string         `json:"phone" gorm:"size:20;not null"`            // 收货人电话
+	Province  string         `json:"province" gorm:"size:50;not null"`         // 省份
+	City      string         `json:"city" gorm:"size:50;not null"`             // 城市
+	District  string         `json:"district" gorm:"size:50"`                  // 区县
+	Address   string         `json:"address" gorm:"size:255;not null"`         // 详细地址
+	Zipcode   string         `json:"zipcode" gorm:"size:10"`                   // 邮政编码
+	IsDefault bool           `json:"is_default" gorm:"not null;default:false"` // 是否默认地址
+	CreatedAt time.Time      `json:"created_at"`                               // 创建时间
+	UpdatedAt time.Time      `json:"updated_at"`                               // 更新时间
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`                           // 软删除时间
+}
+
+// Snapshot 生成地址快照，下单时保存到订单中
+// 之后修改或删除地址不会影响已下单的收货信息
+func (a *Address) Snapshot() JSONAddress {
+	return JSONAddress{
+		Name:     a.Name,
+		Phone:    a.Phone,
+		Province: a.Province,
+		City:     a.City,
+		District: a.District,
+		Address:  a.Address,
+		Zipcode:  a.Zipcode,
+	}
+}
+
+// AddressRequest 新增/修改收货地址请求
+type AddressRequest struct {
+	Name      string `json:"name" binding:"required,max=50"`     // 收货人姓名
+	Phone     string `json:"phone" binding:"required"`           // 收货人电话
+	Province  string `json:"province" binding:"required,max=50"` // 省份
+	City      string `json:"city" binding:"required,max=50"`     // 城市
+	District  string `json:"district" binding:"max=50"`          // 区县
+	Address   string `json:"address" binding:"required,max=255"` // 详细地址
+	Zipcode   string `json:"zipcode"`                            // 邮政编码
+	IsDefault bool   `json:"is_default"`                         // 是否设为默认地址
+}
+
+// ToJSONAddress 转换为地址信息，用于统一校验
+func (r *AddressRequest) ToJSONAddress() JSONAddress {
+	return JSONAddress{
+		Name:     strings.TrimSpace(r.Name),
+		Phone:    strings.TrimSpace(r.Phone),
+		Province: strings.TrimSpace(r.Province),
+		City:     strings.TrimSpace(r.City),
+		District: strings.TrimSpace(r.District),
+		Address:  strings.TrimSpace(r.Address),
+		Zipcode:  strings.TrimSpace(r.Zipcode),
+	}
+}
+
+// Validate 校验收货地址
+// 地址簿和下单时直接填写的地址都使用这里的规则
+func (ja JSONAddress) Validate() error {
+	if strings.TrimSpace(ja.Name) == "" {
+		return errors.New("收货人姓名不能为空")
+	}
+	if err := ValidatePhone(ja.Phone); err != nil {
+		return err
+	}
+	if strings.TrimSpace(ja.Province) == "" || strings.TrimSpace(ja.City) == "" {
+		return errors.New("省份和城市不能为空")
+	}
+	if strings.TrimSpace(ja.Address) == "" {
+		return errors.New("详细地址不能为空")
+	}
+	if ja.Zipcode != "" && !zipcodePattern.MatchString(ja.Zipcode) {
+		return errors.New("邮政编码格式错误，应为6位数字")
+	}
+	return nil
+}
+
+// ValidatePhone 校验手机号
+func ValidatePhone(phone string) error {
+	if !phonePattern.MatchString(phone) {
+		return errors.New("手机号格式错误")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+func TestJSONAddressValidate(t *testing.T) {
+	valid := JSONAddress{
+		Name:     "张三",
+		Phone:    "13800138000",
+		Province: "广东省",
+		City:     "深圳市",
+		District: "南山区",
+		Address:  "科技园路1号",
+		Zipcode:  "518000",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(a *JSONAddress)
+		wantErr bool
+	}{
+		{"valid", func(a *JSONAddress) {}, false},
+		{"empty zipcode", func(a *JSONAddress) { a.Zipcode = "" }, false},
+		{"short phone", func(a *JSONAddress) { a.Phone = "1380013800" }, true},
+		{"landline phone", func(a *JSONAddress) { a.Phone = "075512345678" }, true},
+		{"bad zipcode", func(a *JSONAddress) { a.Zipcode = "51800" }, true},
+		{"missing name", func(a *JSONAddress) { a.Name = " " }, true},
+		{"missing city", func(a *JSONAddress) { a.City = "" }, true},
+	}
+
+	for _, tt := range tests {
+		addr := valid
+		tt.modify(&addr)
+		if err := addr.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
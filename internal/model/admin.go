@@ -0,0 +1,202 @@
+package model
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// 管理后台操作，记录在审计日志中
+const (
+	AdminActionOrderSearch       = "order.search"        // 搜索订单
+	AdminActionOrderForceStatus  = "order.force_status"  // 强制变更订单状态
+	AdminActionUserList          = "user.list"           // 查询用户
+	AdminActionUserStatus        = "user.status"         // 禁用、启用用户
+	AdminActionProductBulkStatus = "product.bulk_status" // 批量上下架商品
+	AdminActionDashboardView     = "dashboard.view"      // 查看销售看板
+	AdminActionAuditLogList      = "audit_log.list"      // 查看审计日志
+
+	AdminActionProductCreate    = "product.create"    // 创建商品
+	AdminActionProductUpdate    = "product.update"    // 更新商品
+	AdminActionProductDelete    = "product.delete"    // 删除商品
+	AdminActionProductStock     = "product.stock"     // 更新商品库存
+	AdminActionProductSkus      = "product.skus"      // 设置商品的规格矩阵
+	AdminActionCategoryCreate   = "category.create"   // 创建分类
+	AdminActionCategoryUpdate   = "category.update"   // 更新分类
+	AdminActionCategoryDelete   = "category.delete"   // 删除分类
+	AdminActionWarehouseCreate  = "warehouse.create"  // 创建仓库
+	AdminActionWarehouseUpdate  = "warehouse.update"  // 更新仓库
+	AdminActionWarehouseStock   = "warehouse.stock"   // 设置商品在仓库的库存
+	AdminActionCouponCreate     = "coupon.create"     // 创建优惠券模板
+	AdminActionCouponStatus     = "coupon.status"     // 启用、停用优惠券模板
+	AdminActionCouponIssue      = "coupon.issue"      // 发放优惠券
+	AdminActionRefundApprove    = "refund.approve"    // 审核通过退款
+	AdminActionRefundReject     = "refund.reject"     // 拒绝退款
+	AdminActionShipmentCreate   = "shipment.create"   // 发货
+	AdminActionShipmentSimulate = "shipment.simulate" // 模拟物流轨迹
+	AdminActionReviewApprove    = "review.approve"    // 审核通过评价
+	AdminActionReviewReject     = "review.reject"     // 驳回评价
+	AdminActionReviewReply      = "review.reply"      // 商家回复评价
+	AdminActionRoleCreate       = "role.create"       // 创建角色
+	AdminActionRoleUpdate       = "role.update"       // 更新角色
+	AdminActionRoleDelete       = "role.delete"       // 删除角色
+	AdminActionUserRoles        = "user.roles"        // 设置用户的角色
+)
+
+// maxAuditRequestLength 审计日志中保存的请求体最大字符数
+const maxAuditRequestLength = 2000
+
+// AdminAuditLog 管理后台操作审计日志
+// 管理后台的每一次操作（含查询）都记录操作人、请求内容和结果，只增不改
+type AdminAuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`                 // 日志ID
+	AdminID   uint      `json:"admin_id" gorm:"not null;index"`       // 操作人用户ID
+	AdminName string    `json:"admin_name" gorm:"size:50"`            // 操作人用户名
+	Action    string    `json:"action" gorm:"size:64;not null;index"` // 操作，如 order.force_status
+	Method    string    `json:"method" gorm:"size:10;not null"`       // 请求方法
+	Path      string    `json:"path" gorm:"size:500;not null"`        // 请求路径，含查询参数
+	TargetID  string    `json:"target_id" gorm:"size:64;index"`       // 操作对象ID，来自路径参数，批量操作时为空
+	Request   string    `json:"request" gorm:"type:text"`             // 请求体，过长时截断
+	Success   bool      `json:"success"`                              // 操作是否成功
+	Message   string    `json:"message" gorm:"size:255"`              // 响应消息
+	IP        string    `json:"ip" gorm:"size:64"`                    // 客户端IP
+	CreatedAt time.Time `json:"created_at" gorm:"index"`              // 操作时间
+}
+
+// TableName 指定表名
+func (AdminAuditLog) TableName() string {
+	return "admin_audit_logs"
+}
+
+// SetRequest 保存请求体，超过最大长度时截断
+func (l *AdminAuditLog) SetRequest(body []byte) {
+	request := string(body)
+	if utf8.RuneCountInString(request) > maxAuditRequestLength {
+		request = string([]rune(request)[:maxAuditRequestLength])
+	}
+	l.Request = request
+}
+
+// AdminAuditLogListRequest 审计日志查询请求
+type AdminAuditLogListRequest struct {
+	Page      int        `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize  int        `form:"page_size,default=20" binding:"min=1,max=100"` // 每页数量
+	AdminID   *uint      `form:"admin_id"`                                     // 操作人用户ID
+	Action    string     `form:"action"`                                       // 操作
+	TargetID  string     `form:"target_id"`                                    // 操作对象ID
+	StartDate *time.Time `form:"start_date"`                                   // 开始时间
+	EndDate   *time.Time `form:"end_date"`                                     // 结束时间
+}
+
+// AdminAuditLogListResponse 审计日志列表响应
+type AdminAuditLogListResponse struct {
+	Logs       []*AdminAuditLog `json:"logs"`        // 日志列表
+	Total      int64            `json:"total"`       // 总数量
+	Page       int              `json:"page"`        // 当前页码
+	PageSize   int              `json:"page_size"`   // 每页数量
+	TotalPages int              `json:"total_pages"` // 总页数
+}
+
+// AdminOrderSearchRequest 管理后台订单搜索请求，所有条件可以组合
+type AdminOrderSearchRequest struct {
+	Page          int          `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize      int          `form:"page_size,default=20" binding:"min=1,max=100"` // 每页数量
+	OrderNo       string       `form:"order_no"`                                     // 订单号，精确匹配
+	UserID        *uint        `form:"user_id"`                                      // 用户ID
+	Username      string       `form:"username"`                                     // 用户名，精确匹配
+	Phone         string       `form:"phone"`                                        // 联系电话，精确匹配
+	Status        *OrderStatus `form:"status"`                                       // 订单状态
+	PaymentMethod string       `form:"payment_method"`                               // 支付方式
+	MinAmount     *float64     `form:"min_amount" binding:"omitempty,min=0"`         // 最低订单金额
+	MaxAmount     *float64     `form:"max_amount" binding:"omitempty,min=0"`         // 最高订单金额
+	StartDate     *time.Time   `form:"start_date"`                                   // 下单开始时间
+	EndDate       *time.Time   `form:"end_date"`                                     // 下单结束时间
+}
+
+// AdminOrderSearchResponse 管理后台订单搜索响应
+type AdminOrderSearchResponse struct {
+	Orders     []*Order `json:"orders"`      // 订单列表，包含下单用户
+	Total      int64    `json:"total"`       // 总数量
+	Page       int      `json:"page"`        // 当前页码
+	PageSize   int      `json:"page_size"`   // 每页数量
+	TotalPages int      `json:"total_pages"` // 总页数
+}
+
+// ForceOrderStatusRequest 强制变更订单状态请求
+type ForceOrderStatusRequest struct {
+	Status OrderStatus `json:"status" binding:"required"`         // 目标状态
+	Reason string      `json:"reason" binding:"required,max=200"` // 变更原因，记录在订单时间线中
+}
+
+// AdminUserListRequest 管理后台用户列表请求
+type AdminUserListRequest struct {
+	Page     int    `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize int    `form:"page_size,default=20" binding:"min=1,max=100"` // 每页数量
+	Keyword  string `form:"keyword"`                                      // 用户名、邮箱或手机号，前缀匹配
+	Status   *int   `form:"status" binding:"omitempty,oneof=0 1"`         // 用户状态
+}
+
+// AdminUserListResponse 管理后台用户列表响应
+type AdminUserListResponse struct {
+	Users      []*UserProfileResponse `json:"users"`       // 用户列表
+	Total      int64                  `json:"total"`       // 总数量
+	Page       int                    `json:"page"`        // 当前页码
+	PageSize   int                    `json:"page_size"`   // 每页数量
+	TotalPages int                    `json:"total_pages"` // 总页数
+}
+
+// UpdateUserStatusRequest 禁用、启用用户请求
+type UpdateUserStatusRequest struct {
+	Status *int `json:"status" binding:"required,oneof=0 1"` // 用户状态：0禁用，1正常
+}
+
+// BulkProductStatusRequest 批量上下架商品请求
+type BulkProductStatusRequest struct {
+	IDs    []uint `json:"ids" binding:"required,min=1,max=200"` // 商品ID列表
+	Status *int   `json:"status" binding:"required,oneof=0 1"`  // 商品状态：0下架，1上架
+}
+
+// BulkProductStatusResponse 批量上下架商品响应
+type BulkProductStatusResponse struct {
+	Updated int64 `json:"updated"` // 实际变更的商品数量，状态未变化或不存在的商品不计入
+}
+
+// GMVOrderStatuses 计入成交额的订单状态：已支付的订单，包括之后发货、退款的
+var GMVOrderStatuses = []OrderStatus{
+	OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered,
+	OrderStatusRefundRequested, OrderStatusRefunded, OrderStatusPartiallyRefunded,
+}
+
+// SalesDashboardRequest 销售看板请求
+type SalesDashboardRequest struct {
+	Days int `form:"days,default=30" binding:"min=1,max=366"` // 统计最近多少天，含今天
+	Top  int `form:"top,default=10" binding:"min=1,max=50"`   // 热销商品数量
+}
+
+// DailySales 一天的销售数据
+type DailySales struct {
+	Date       string  `json:"date"`        // 日期，格式 2006-01-02
+	Orders     int64   `json:"orders"`      // 下单数
+	PaidOrders int64   `json:"paid_orders"` // 成交订单数
+	GMV        float64 `json:"gmv"`         // 成交额
+}
+
+// TopProduct 热销商品
+type TopProduct struct {
+	ProductID   uint    `json:"product_id"`   // 商品ID
+	ProductName string  `json:"product_name"` // 商品名称
+	Quantity    int64   `json:"quantity"`     // 成交件数
+	Amount      float64 `json:"amount"`       // 成交金额（扣除分摊的优惠）
+}
+
+// SalesDashboard 销售看板
+// 按下单时间统计，成交指订单状态在GMVOrderStatuses中，成交额为订单应付金额
+type SalesDashboard struct {
+	StartDate     string        `json:"start_date"`      // 统计开始日期
+	EndDate       string        `json:"end_date"`        // 统计结束日期
+	Orders        int64         `json:"orders"`          // 下单数
+	PaidOrders    int64         `json:"paid_orders"`     // 成交订单数
+	GMV           float64       `json:"gmv"`             // 成交额
+	AvgOrderValue float64       `json:"avg_order_value"` // 客单价
+	Daily         []*DailySales `json:"daily"`           // 每天的销售数据，没有订单的日期补0
+	TopProducts   []*TopProduct `json:"top_products"`    // 热销商品，按成交件数排序
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAdminAuditLog_SetRequestTruncates(t *testing.T) {
+	l := &AdminAuditLog{}
+	l.SetRequest([]byte(`{"reason":"补发"}`))
+	if l.Request != `{"reason":"补发"}` {
+		t.Fatalf("request = %q", l.Request)
+	}
+
+	l.SetRequest([]byte(strings.Repeat("审", maxAuditRequestLength+10)))
+	if n := utf8.RuneCountInString(l.Request); n != maxAuditRequestLength {
+		t.Fatalf("truncated length = %d, want %d", n, maxAuditRequestLength)
+	}
+	if !utf8.ValidString(l.Request) {
+		t.Fatal("truncated request is not valid utf-8")
+	}
+}
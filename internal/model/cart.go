@@ -12,6 +12,7 @@ type CartItem struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`                                       // 购物车项ID
 	UserID    uint           `json:"user_id" gorm:"not null;index"`                             // 用户ID，添加索引
 	ProductID uint           `json:"product_id" gorm:"not null;index"`                          // 商品ID，添加索引
+	SkuID     uint           `json:"sku_id" gorm:"not null;default:0;index"`                   // SKU ID，商品没有规格时为0
 	Quantity  int            `json:"quantity" gorm:"not null;default:1"`                        // 商品数量
 	CreatedAt time.Time      `json:"created_at"`                                                // 添加时间
 	UpdatedAt time.Time      `json:"updated_at"`                                                // 更新时间
@@ -20,6 +21,55 @@ type CartItem struct {
 	// 关联关系
 	User      User           `json:"user,omitempty" gorm:"foreignKey:UserID"`                   // 所属用户
 	Product   Product        `json:"product,omitempty" gorm:"foreignKey:ProductID"`             // 关联商品
+	Sku       *ProductSku    `json:"sku,omitempty" gorm:"foreignKey:SkuID"`                     // 关联SKU
+}
+
+// UnitPrice 购物车项单价，有规格时使用SKU价格
+func (c *CartItem) UnitPrice() float64 {
+	if c.Sku != nil {
+		return c.Sku.Price
+	}
+	return c.Product.Price
+}
+
+// AvailableStock 购物车项可售库存，有规格时使用SKU库存
+func (c *CartItem) AvailableStock() int {
+	if c.Sku != nil {
+		return c.Sku.AvailableStock()
+	}
+	return c.Product.AvailableStock()
+}
+
+// Image 购物车项图片，SKU没有图片时使用商品主图
+func (c *CartItem) Image() *string {
+	if c.Sku != nil && c.Sku.Image != nil {
+		return c.Sku.Image
+	}
+	return c.Product.MainImage
+}
+
+// SpecKey 购物车项的规格属性组合，没有规格时为空
+func (c *CartItem) SpecKey() string {
+	if c.Sku != nil {
+		return c.Sku.SpecKey
+	}
+	return ""
+}
+
+// Unavailable 购物车项不能下单的原因，为空表示可以下单
+// 商品下架、启用规格后未选择SKU、SKU下架或库存不足
+func (c *CartItem) Unavailable() string {
+	switch {
+	case c.Product.ID == 0 || c.Product.Status != ProductStatusOnline:
+		return "商品 " + c.Product.Name + " 已下架"
+	case c.Product.HasSkus() && c.SkuID == 0:
+		return "商品 " + c.Product.Name + " 请选择规格"
+	case c.SkuID != 0 && (c.Sku == nil || c.Sku.ProductID != c.ProductID || c.Sku.Status != SkuStatusOnline):
+		return "商品 " + c.Product.Name + " 的规格已下架"
+	case c.Quantity > c.AvailableStock():
+		return "商品 " + c.Product.Name + " 库存不足"
+	}
+	return ""
 }
 
 // TableName 指定表名
@@ -32,6 +82,7 @@ func (CartItem) TableName() string {
 // AddToCartRequest 添加到购物车请求
 type AddToCartRequest struct {
 	ProductID uint `json:"product_id" binding:"required"`                    // 商品ID，必填
+	SkuID     uint `json:"sku_id"`                                           // SKU ID，商品有规格时必填
 	Quantity  int  `json:"quantity" binding:"required,min=1,max=999"`        // 数量，必填，1-999
 }
 
@@ -45,6 +96,8 @@ type UpdateCartRequest struct {
 type CartResponse struct {
 	ID          uint    `json:"id"`                                           // 购物车项ID
 	ProductID   uint    `json:"product_id"`                                   // 商品ID
+	SkuID       uint    `json:"sku_id"`                                       // SKU ID，没有规格时为0
+	SpecKey     string  `json:"spec_key,omitempty"`                           // 规格属性组合
 	ProductName string  `json:"product_name"`                                 // 商品名称
 	ProductImage *string `json:"product_image"`                               // 商品图片
 	Price       float64 `json:"price"`                                        // 商品价格
@@ -0,0 +1,315 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CouponType 优惠券类型
+type CouponType int
+
+const (
+	CouponTypeFixed     CouponType = 1 // 立减券：直接减免固定金额
+	CouponTypePercent   CouponType = 2 // 折扣券：按比例减免，可设置最高减免金额
+	CouponTypeThreshold CouponType = 3 // 满减券：满X元减Y元
+)
+
+// CouponScope 优惠券适用范围
+type CouponScope int
+
+const (
+	CouponScopeAll      CouponScope = 1 // 全场通用
+	CouponScopeCategory CouponScope = 2 // 指定分类
+	CouponScopeProduct  CouponScope = 3 // 指定商品
+)
+
+// CouponTemplateStatus 优惠券模板状态
+type CouponTemplateStatus int
+
+const (
+	CouponTemplateActive   CouponTemplateStatus = 1 // 可领取
+	CouponTemplateInactive CouponTemplateStatus = 2 // 已停用
+)
+
+// UserCouponStatus 用户优惠券状态
+// 过期不单独存储，由ExpireAt判断
+type UserCouponStatus int
+
+const (
+	UserCouponStatusUnused UserCouponStatus = 1 // 未使用
+	UserCouponStatusUsed   UserCouponStatus = 2 // 已使用
+)
+
+// CouponTemplate 优惠券模板
+// 定义优惠规则、适用范围和发放限制，用户领取后生成UserCoupon
+type CouponTemplate struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`                                // 模板ID
+	Name         string               `json:"name" gorm:"size:100;not null"`                       // 优惠券名称
+	Type         CouponType           `json:"type" gorm:"not null"`                                // 优惠券类型
+	Amount       float64              `json:"amount" gorm:"type:decimal(10,2);not null;default:0"` // 减免金额（立减券、满减券）
+	Percent      float64              `json:"percent" gorm:"type:decimal(5,2);not null;default:0"` // 减免比例，如15表示减免15%（折扣券）
+	MaxDiscount  float64              `json:"max_discount" gorm:"type:decimal(10,2);default:0"`    // 最高减免金额，0表示不限（折扣券）
+	MinAmount    float64              `json:"min_amount" gorm:"type:decimal(10,2);default:0"`      // 使用门槛，适用商品金额需达到该值
+	Scope        CouponScope          `json:"scope" gorm:"not null;default:1"`                     // 适用范围
+	ScopeIDs     JSONIDs              `json:"scope_ids" gorm:"type:json"`                          // 适用的分类ID或商品ID
+	TotalCount   int                  `json:"total_count" gorm:"not null;default:0"`               // 发放总量，0表示不限
+	IssuedCount  int                  `json:"issued_count" gorm:"not null;default:0"`              // 已发放数量
+	PerUserLimit int                  `json:"per_user_limit" gorm:"not null;default:1"`            // 每人限领数量，0表示不限
+	ValidDays    int                  `json:"valid_days" gorm:"not null;default:0"`                // 领取后有效天数，0表示以EndAt为准
+	StartAt      time.Time            `json:"start_at"`                                            // 领取开始时间
+	EndAt        time.Time            `json:"end_at" gorm:"index"`                                 // 领取和使用截止时间
+	Status       CouponTemplateStatus `json:"status" gorm:"default:1;index"`                       // 模板状态
+	CreatedAt    time.Time            `json:"created_at"`                                          // 创建时间
+	UpdatedAt    time.Time            `json:"updated_at"`                                          // 更新时间
+}
+
+// UserCoupon 用户优惠券
+// 下单时与订单在同一事务中核销，订单取消后退回
+type UserCoupon struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`              // 用户优惠券ID
+	UserID     uint             `json:"user_id" gorm:"not null;index"`     // 用户ID
+	TemplateID uint             `json:"template_id" gorm:"not null;index"` // 模板ID
+	Status     UserCouponStatus `json:"status" gorm:"default:1;index"`     // 使用状态
+	ExpireAt   time.Time        `json:"expire_at" gorm:"index"`            // 过期时间
+	OrderID    *uint            `json:"order_id" gorm:"index"`             // 使用的订单ID
+	UsedAt     *time.Time       `json:"used_at"`                           // 使用时间
+	CreatedAt  time.Time        `json:"created_at"`                        // 领取时间
+	UpdatedAt  time.Time        `json:"updated_at"`                        // 更新时间
+
+	// 关联关系
+	Template CouponTemplate `json:"template,omitempty" gorm:"foreignKey:TemplateID"` // 优惠券模板
+}
+
+// Usable 优惠券当前是否可用
+func (uc *UserCoupon) Usable(now time.Time) bool {
+	return uc.Status == UserCouponStatusUnused && now.Before(uc.ExpireAt)
+}
+
+// Claimable 模板当前是否可领取
+func (t *CouponTemplate) Claimable(now time.Time) error {
+	if t.Status != CouponTemplateActive {
+		return errors.New("优惠券已停用")
+	}
+	if now.Before(t.StartAt) {
+		return errors.New("优惠券尚未开始领取")
+	}
+	if !now.Before(t.EndAt) {
+		return errors.New("优惠券已过期")
+	}
+	if t.TotalCount > 0 && t.IssuedCount >= t.TotalCount {
+		return errors.New("优惠券已领完")
+	}
+	return nil
+}
+
+// ExpireAtFor 计算在指定时间领取的优惠券的过期时间
+func (t *CouponTemplate) ExpireAtFor(issuedAt time.Time) time.Time {
+	if t.ValidDays > 0 {
+		if expireAt := issuedAt.AddDate(0, 0, t.ValidDays); expireAt.Before(t.EndAt) {
+			return expireAt
+		}
+	}
+	return t.EndAt
+}
+
+// Applies 优惠券是否适用于指定商品
+func (t *CouponTemplate) Applies(productID, categoryID uint) bool {
+	switch t.Scope {
+	case CouponScopeCategory:
+		return t.ScopeIDs.Contains(categoryID)
+	case CouponScopeProduct:
+		return t.ScopeIDs.Contains(productID)
+	default:
+		return true
+	}
+}
+
+// Discount 计算适用商品金额可减免的金额
+// 未达到使用门槛时返回错误；减免金额不会超过适用商品金额
+func (t *CouponTemplate) Discount(eligibleAmount float64) (float64, error) {
+	if eligibleAmount <= 0 {
+		return 0, errors.New("订单中没有适用该优惠券的商品")
+	}
+	if eligibleAmount+0.001 < t.MinAmount {
+		return 0, fmt.Errorf("适用商品金额未满%.2f元", t.MinAmount)
+	}
+
+	var discount float64
+	switch t.Type {
+	case CouponTypeFixed, CouponTypeThreshold:
+		discount = t.Amount
+	case CouponTypePercent:
+		discount = math.Round(eligibleAmount*t.Percent) / 100
+		if t.MaxDiscount > 0 && discount > t.MaxDiscount {
+			discount = t.MaxDiscount
+		}
+	default:
+		return 0, errors.New("不支持的优惠券类型")
+	}
+
+	if discount > eligibleAmount {
+		discount = eligibleAmount
+	}
+	return discount, nil
+}
+
+// Validate 校验模板规则
+func (t *CouponTemplate) Validate() error {
+	switch t.Type {
+	case CouponTypeFixed:
+		if t.Amount <= 0 {
+			return errors.New("立减金额必须大于0")
+		}
+	case CouponTypeThreshold:
+		if t.Amount <= 0 || t.MinAmount <= t.Amount {
+			return errors.New("满减券的门槛金额必须大于减免金额")
+		}
+	case CouponTypePercent:
+		if t.Percent <= 0 || t.Percent >= 100 {
+			return errors.New("折扣比例必须在0到100之间")
+		}
+	default:
+		return errors.New("不支持的优惠券类型")
+	}
+
+	if t.Scope != CouponScopeAll && len(t.ScopeIDs) == 0 {
+		return errors.New("指定分类或商品的优惠券必须设置适用范围")
+	}
+	if !t.StartAt.Before(t.EndAt) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+	return nil
+}
+
+// GetCouponTypeText 获取优惠券类型文本
+func GetCouponTypeText(couponType CouponType) string {
+	switch couponType {
+	case CouponTypeFixed:
+		return "立减券"
+	case CouponTypePercent:
+		return "折扣券"
+	case CouponTypeThreshold:
+		return "满减券"
+	default:
+		return "未知类型"
+	}
+}
+
+// JSONIDs ID列表的JSON类型
+type JSONIDs []uint
+
+// Contains 是否包含指定ID
+func (ids JSONIDs) Contains(id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan 实现sql.Scanner接口，用于从数据库读取JSON数据
+func (ids *JSONIDs) Scan(value interface{}) error {
+	if value == nil {
+		*ids = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into JSONIDs", value)
+	}
+
+	return json.Unmarshal(bytes, ids)
+}
+
+// Value 实现driver.Valuer接口，用于将Go数据写入数据库
+func (ids JSONIDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	return json.Marshal(ids)
+}
+
+// CreateCouponTemplateRequest 创建优惠券模板请求
+type CreateCouponTemplateRequest struct {
+	Name         string      `json:"name" binding:"required,max=100"`      // 优惠券名称
+	Type         CouponType  `json:"type" binding:"required,oneof=1 2 3"`  // 优惠券类型
+	Amount       float64     `json:"amount" binding:"min=0"`               // 减免金额
+	Percent      float64     `json:"percent" binding:"min=0,max=100"`      // 减免比例
+	MaxDiscount  float64     `json:"max_discount" binding:"min=0"`         // 最高减免金额
+	MinAmount    float64     `json:"min_amount" binding:"min=0"`           // 使用门槛
+	Scope        CouponScope `json:"scope" binding:"required,oneof=1 2 3"` // 适用范围
+	ScopeIDs     []uint      `json:"scope_ids"`                            // 适用的分类ID或商品ID
+	TotalCount   int         `json:"total_count" binding:"min=0"`          // 发放总量
+	PerUserLimit int         `json:"per_user_limit" binding:"min=0"`       // 每人限领数量
+	ValidDays    int         `json:"valid_days" binding:"min=0"`           // 领取后有效天数
+	StartAt      time.Time   `json:"start_at" binding:"required"`          // 领取开始时间
+	EndAt        time.Time   `json:"end_at" binding:"required"`            // 截止时间
+}
+
+// CouponTemplateListRequest 优惠券模板列表查询请求
+type CouponTemplateListRequest struct {
+	Page     int                   `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize int                   `form:"page_size,default=10" binding:"min=1,max=100"` // 每页数量
+	Status   *CouponTemplateStatus `form:"status"`                                       // 状态筛选
+}
+
+// CouponTemplateListResponse 优惠券模板列表响应
+type CouponTemplateListResponse struct {
+	Templates  []*CouponTemplate `json:"templates"`   // 优惠券模板列表
+	Total      int64             `json:"total"`       // 总数量
+	Page       int               `json:"page"`        // 当前页码
+	PageSize   int               `json:"page_size"`   // 每页数量
+	TotalPages int               `json:"total_pages"` // 总页数
+}
+
+// UpdateCouponTemplateStatusRequest 启用/停用优惠券模板请求
+type UpdateCouponTemplateStatusRequest struct {
+	Status CouponTemplateStatus `json:"status" binding:"required,oneof=1 2"` // 模板状态
+}
+
+// IssueCouponRequest 管理员发放优惠券请求
+type IssueCouponRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,max=1000"` // 发放的用户ID
+}
+
+// UserCouponListRequest 用户优惠券列表查询请求
+type UserCouponListRequest struct {
+	State string `form:"state" binding:"omitempty,oneof=usable used expired"` // 状态筛选：可用、已使用、已过期
+}
+
+// PriceLine 订单商品计价行
+type PriceLine struct {
+	ProductID  uint    `json:"product_id"`  // 商品ID
+	CategoryID uint    `json:"category_id"` // 分类ID
+	Price      float64 `json:"price"`       // 单价
+	Quantity   int     `json:"quantity"`    // 数量
+	Subtotal   float64 `json:"subtotal"`    // 小计金额
+	Discount   float64 `json:"discount"`    // 分摊的优惠金额
+	PayAmount  float64 `json:"pay_amount"`  // 应付金额
+}
+
+// DiscountAllocation 一项优惠分摊到某个计价行的金额
+type DiscountAllocation struct {
+	Line         int     `json:"line"`           // 计价行序号
+	ProductID    uint    `json:"product_id"`     // 商品ID
+	UserCouponID uint    `json:"user_coupon_id"` // 用户优惠券ID
+	Amount       float64 `json:"amount"`         // 分摊金额
+}
+
+// PriceQuote 计价结果
+type PriceQuote struct {
+	Lines          []*PriceLine         `json:"lines"`           // 计价行
+	Allocations    []DiscountAllocation `json:"allocations"`     // 优惠分摊明细
+	TotalAmount    float64              `json:"total_amount"`    // 商品总金额
+	DiscountAmount float64              `json:"discount_amount"` // 优惠总金额
+	ShippingFee    float64              `json:"shipping_fee"`    // 运费
+	TaxAmount      float64              `json:"tax_amount"`      // 税额（商品价格已含税，仅展示）
+	PayAmount      float64              `json:"pay_amount"`      // 应付金额 = 商品总金额 - 优惠 + 运费
+	UserCouponID   uint                 `json:"user_coupon_id"`  // 使用的用户优惠券ID
+}
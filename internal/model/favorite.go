@@ -0,0 +1,53 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// Favorite 商品收藏模型
+// 记录收藏时（以及上次提醒时）的价格和有货状态，商品降价或到货时据此提醒用户
+type Favorite struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`                                                     // 收藏ID
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_product,priority:1"`          // 用户ID
+	ProductID   uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_user_product,priority:2;index"` // 商品ID
+	LastPrice   float64   `json:"last_price" gorm:"type:decimal(10,2);not null"`                            // 上次记录的价格
+	LastInStock bool      `json:"last_in_stock" gorm:"not null"`                                            // 上次记录时是否有货
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`                                                  // 收藏时间
+
+	// 关联关系
+	Product Product `json:"product,omitempty" gorm:"foreignKey:ProductID"` // 收藏的商品
+}
+
+// TableName 指定表名
+func (Favorite) TableName() string {
+	return "favorites"
+}
+
+// Changes 与上次记录相比商品是否降价、是否重新到货
+// 价格按分比较，避免浮点误差
+func (f *Favorite) Changes(price float64, inStock bool) (priceDropped, restocked bool) {
+	priceDropped = math.Round(price*100) < math.Round(f.LastPrice*100)
+	restocked = inStock && !f.LastInStock
+	return priceDropped, restocked
+}
+
+// AddFavoriteRequest 收藏商品请求
+type AddFavoriteRequest struct {
+	ProductID uint `json:"product_id" binding:"required"` // 商品ID
+}
+
+// FavoriteListRequest 收藏列表查询请求
+type FavoriteListRequest struct {
+	Page     int `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize int `form:"page_size,default=10" binding:"min=1,max=100"` // 每页数量
+}
+
+// FavoriteListResponse 收藏列表响应
+type FavoriteListResponse struct {
+	Favorites  []*Favorite `json:"favorites"`   // 收藏列表，按收藏时间倒序
+	Total      int         `json:"total"`       // 总数量
+	Page       int         `json:"page"`        // 当前页码
+	PageSize   int         `json:"page_size"`   // 每页数量
+	TotalPages int         `json:"total_pages"` // 总页数
+}
@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestFavorite_Changes(t *testing.T) {
+	cases := []struct {
+		lastPrice   float64
+		lastInStock bool
+		price       float64
+		inStock     bool
+		dropped     bool
+		restocked   bool
+	}{
+		{99.9, true, 89.9, true, true, false},
+		{99.9, true, 99.9, true, false, false},
+		{19.9, true, 19.90000001, true, false, false}, // 按分比较，忽略浮点误差
+		{89.9, true, 99.9, true, false, false},        // 涨价不提醒
+		{99.9, false, 99.9, true, false, true},
+		{99.9, false, 79.9, true, true, true},
+		{99.9, true, 99.9, false, false, false}, // 售罄不提醒
+	}
+	for _, c := range cases {
+		f := &Favorite{LastPrice: c.lastPrice, LastInStock: c.lastInStock}
+		dropped, restocked := f.Changes(c.price, c.inStock)
+		if dropped != c.dropped || restocked != c.restocked {
+			t.Errorf("%+v: got dropped=%v restocked=%v", c, dropped, restocked)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// IdempotencyStatus 幂等记录状态
+type IdempotencyStatus int
+
+const (
+	IdempotencyStatusProcessing IdempotencyStatus = 1 // 处理中
+	IdempotencyStatusCompleted  IdempotencyStatus = 2 // 已完成，保存了响应
+)
+
+// IdempotencyRecord 幂等记录模型
+// 以用户ID和客户端传入的Idempotency-Key唯一标识一次请求，保存请求指纹和响应内容，
+// 相同的请求重复提交时直接返回保存的响应。处理中的记录带有租约，
+// 服务在处理过程中崩溃时记录会停在处理中，租约到期后幂等键可以被重新占用
+type IdempotencyRecord struct {
+	ID             uint              `json:"id" gorm:"primaryKey"`                                             // 记录ID
+	UserID         uint              `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_user_key"`     // 用户ID
+	Key            string            `json:"key" gorm:"size:64;not null;uniqueIndex:idx_idempotency_user_key"` // 幂等键
+	Fingerprint    string            `json:"fingerprint" gorm:"size:64;not null"`                              // 请求指纹
+	Status         IdempotencyStatus `json:"status" gorm:"not null;default:1"`                                 // 状态
+	ResponseStatus int               `json:"response_status"`                                                  // 响应HTTP状态码
+	ContentType    string            `json:"content_type" gorm:"size:128"`                                     // 响应Content-Type
+	ResponseBody   string            `json:"response_body" gorm:"type:mediumtext"`                             // 响应内容
+	ExpireAt       time.Time         `json:"expire_at" gorm:"not null;index"`                                  // 过期时间，过期后幂等键可以重新使用
+	LeaseUntil     *time.Time        `json:"lease_until"`                                                      // 处理中状态的租约到期时间，到期后视为处理失败
+	CreatedAt      time.Time         `json:"created_at"`                                                       // 创建时间
+	UpdatedAt      time.Time         `json:"updated_at"`                                                       // 更新时间
+}
+
+// TableName 指定表名
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_records"
+}
+
+// Reusable 判断幂等键是否可以被重新占用
+// 记录已过期，或停在处理中且租约已到期时可以重新占用
+func (r *IdempotencyRecord) Reusable(now time.Time) bool {
+	if !r.ExpireAt.After(now) {
+		return true
+	}
+	return r.Status == IdempotencyStatusProcessing && r.LeaseUntil != nil && !r.LeaseUntil.After(now)
+}
+
+// MaxIdempotencyKeyLength 幂等键最大长度
+const MaxIdempotencyKeyLength = 64
+
+// IdempotencyFingerprint 计算请求指纹
+// 同一个幂等键只能用于方法、路径和请求体都相同的请求
+func IdempotencyFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotencyRecord_Reusable(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Second)
+	future := now.Add(time.Minute)
+
+	cases := []struct {
+		name   string
+		record IdempotencyRecord
+		want   bool
+	}{
+		{"processing within lease", IdempotencyRecord{Status: IdempotencyStatusProcessing, ExpireAt: now.Add(time.Hour), LeaseUntil: &future}, false},
+		{"processing lease expired", IdempotencyRecord{Status: IdempotencyStatusProcessing, ExpireAt: now.Add(time.Hour), LeaseUntil: &past}, true},
+		{"processing without lease", IdempotencyRecord{Status: IdempotencyStatusProcessing, ExpireAt: now.Add(time.Hour)}, false},
+		{"completed after lease", IdempotencyRecord{Status: IdempotencyStatusCompleted, ExpireAt: now.Add(time.Hour), LeaseUntil: &past}, false},
+		{"expired", IdempotencyRecord{Status: IdempotencyStatusCompleted, ExpireAt: past}, true},
+	}
+	for _, c := range cases {
+		if got := c.record.Reusable(now); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
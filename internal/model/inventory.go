@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// ReservationStatus 库存预占状态
+type ReservationStatus int
+
+const (
+	ReservationStatusReserved  ReservationStatus = 1 // 已预占，订单待支付
+	ReservationStatusCommitted ReservationStatus = 2 // 已扣减，订单已支付
+	ReservationStatusReleased  ReservationStatus = 3 // 已释放，订单已取消
+)
+
+// InventoryReservation 库存预占记录
+// 下单时按订单商品预占库存（products.reserved_stock增加），支付后从库存中扣减，
+// 取消或超时后释放。商品的reserved_stock应等于该商品所有已预占记录的数量之和。
+// 一个订单商品的库存可以分配到多个仓库，每个仓库一条记录；有规格的商品同时预占SKU库存
+type InventoryReservation struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`                                                       // 预占记录ID
+	OrderID     uint              `json:"order_id" gorm:"not null;uniqueIndex:idx_order_sku_warehouse"`               // 订单ID
+	ProductID   uint              `json:"product_id" gorm:"not null;uniqueIndex:idx_order_sku_warehouse;index"`       // 商品ID
+	SkuID       uint              `json:"sku_id" gorm:"not null;default:0;uniqueIndex:idx_order_sku_warehouse;index"` // SKU ID，商品没有规格时为0
+	WarehouseID uint              `json:"warehouse_id" gorm:"not null;default:0;uniqueIndex:idx_order_sku_warehouse"` // 发货仓库ID，0表示未分配库存
+	Quantity    int               `json:"quantity" gorm:"not null"`                                                   // 预占数量
+	Status      ReservationStatus `json:"status" gorm:"not null;default:1;index"`                                     // 预占状态
+	Restocked   int               `json:"restocked" gorm:"not null;default:0"`                                        // 退款时已退回仓库的数量
+	CreatedAt   time.Time         `json:"created_at"`                                                                 // 创建时间
+	UpdatedAt   time.Time         `json:"updated_at"`                                                                 // 更新时间
+}
+
+// AllocateRestock 把退款退回的数量分配到订单扣减时的各仓库预占记录
+// 按记录顺序分配，每条记录最多退回扣减数量减去之前已退回的数量，
+// 多次部分退款不会重复退回同一个仓库；返回每条记录本次退回的数量，超出部分不分配
+func AllocateRestock(reservations []InventoryReservation, quantity int) []int {
+	allocations := make([]int, len(reservations))
+	remaining := quantity
+	for i, reservation := range reservations {
+		if remaining <= 0 {
+			break
+		}
+		restock := reservation.Quantity - reservation.Restocked
+		if restock <= 0 {
+			continue
+		}
+		if restock > remaining {
+			restock = remaining
+		}
+		allocations[i] = restock
+		remaining -= restock
+	}
+	return allocations
+}
+
+// StaleReservation 订单已不是待支付状态但仍处于预占状态的记录
+type StaleReservation struct {
+	OrderID     uint        // 订单ID
+	OrderStatus OrderStatus // 订单当前状态
+}
+
+// ReconcileBatch 库存预占对账的一批处理结果
+type ReconcileBatch struct {
+	LastID     uint   // 本批最后一个商品ID
+	Scanned    int    // 本批扫描的商品数
+	ProductIDs []uint // 修正了预占库存的商品ID
+}
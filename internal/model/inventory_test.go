@@ -0,0 +1,45 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocateRestock_TwoPartialRefunds(t *testing.T) {
+	// 订单商品共5件，从两个仓库扣减：仓库1扣2件，仓库2扣3件
+	reservations := []InventoryReservation{
+		{ID: 1, WarehouseID: 1, Quantity: 2},
+		{ID: 2, WarehouseID: 2, Quantity: 3},
+	}
+
+	// 第一次退款3件：仓库1退满2件，仓库2退1件
+	first := AllocateRestock(reservations, 3)
+	if !reflect.DeepEqual(first, []int{2, 1}) {
+		t.Fatalf("first refund: got %v", first)
+	}
+	for i, restock := range first {
+		reservations[i].Restocked += restock
+	}
+
+	// 第二次退款2件：仓库1已退满，只能退到仓库2剩余的2件
+	second := AllocateRestock(reservations, 2)
+	if !reflect.DeepEqual(second, []int{0, 2}) {
+		t.Fatalf("second refund: got %v", second)
+	}
+	for i, restock := range second {
+		reservations[i].Restocked += restock
+	}
+
+	// 全部退回后再退款不再分配到任何仓库
+	if third := AllocateRestock(reservations, 1); !reflect.DeepEqual(third, []int{0, 0}) {
+		t.Errorf("third refund: got %v", third)
+	}
+}
+
+func TestAllocateRestock_ExceedsReserved(t *testing.T) {
+	// 超出扣减数量的部分不分配到仓库，由调用方退回未分配库存
+	reservations := []InventoryReservation{{ID: 1, WarehouseID: 1, Quantity: 2}}
+	if got := AllocateRestock(reservations, 5); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("got %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import "time"
+
+// 消息渠道
+const (
+	MessageChannelEmail = "email" // 邮件
+	MessageChannelSMS   = "sms"   // 短信
+)
+
+// 触发消息的业务事件
+const (
+	MessageEventOrderCreated = "order_created" // 订单已创建
+	MessageEventOrderPaid    = "order_paid"    // 订单已支付
+	MessageEventOrderShipped = "order_shipped" // 订单已发货
+)
+
+// OutboxMessageStatus 待发消息状态
+type OutboxMessageStatus int
+
+const (
+	OutboxMessagePending OutboxMessageStatus = 1 // 待发送
+	OutboxMessageSent    OutboxMessageStatus = 2 // 已发送
+	OutboxMessageFailed  OutboxMessageStatus = 3 // 发送失败
+)
+
+// maxMessageErrorLength 失败原因的最大长度（字符）
+const maxMessageErrorLength = 500
+
+// OutboxMessage 待发消息模型
+// 与触发它的业务变更在同一事务中写入，由后台任务发送，业务回滚时不会发出消息
+type OutboxMessage struct {
+	ID        uint                `json:"id" gorm:"primaryKey"`               // 消息ID
+	UserID    uint                `json:"user_id" gorm:"not null;index"`      // 接收用户ID
+	Event     string              `json:"event" gorm:"size:32;not null"`      // 触发事件
+	Channel   string              `json:"channel" gorm:"size:16;not null"`    // 消息渠道
+	Recipient string              `json:"recipient" gorm:"size:100;not null"` // 收件人，邮箱地址或手机号
+	Subject   string              `json:"subject" gorm:"size:200"`            // 主题
+	Body      string              `json:"body" gorm:"size:1000;not null"`     // 正文
+	RefID     uint                `json:"ref_id" gorm:"not null;default:0"`   // 关联对象ID，如订单ID
+	Status    OutboxMessageStatus `json:"status" gorm:"default:1;index"`      // 状态
+	Attempts  int                 `json:"attempts" gorm:"not null;default:0"` // 已尝试发送次数
+	LastError string              `json:"last_error" gorm:"size:500"`         // 最近一次失败原因
+	SentAt    *time.Time          `json:"sent_at"`                            // 发送时间
+	CreatedAt time.Time           `json:"created_at"`                         // 创建时间
+	UpdatedAt time.Time           `json:"updated_at"`                         // 更新时间
+}
+
+// TableName 指定表名
+func (OutboxMessage) TableName() string {
+	return "message_outbox"
+}
+
+// MarkSent 标记已发送
+func (m *OutboxMessage) MarkSent(sentAt time.Time) {
+	m.Status = OutboxMessageSent
+	m.SentAt = &sentAt
+	m.LastError = ""
+}
+
+// MarkFailed 标记发送失败，失败原因过长时截断
+func (m *OutboxMessage) MarkFailed(reason string) {
+	m.Status = OutboxMessageFailed
+	if runes := []rune(reason); len(runes) > maxMessageErrorLength {
+		reason = string(runes[:maxMessageErrorLength])
+	}
+	m.LastError = reason
+}
+
+// MessagePreference 用户的消息渠道偏好
+// 没有记录的用户使用默认偏好：接收邮件，不接收短信
+type MessagePreference struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"` // 用户ID
+	Email     bool      `json:"email" gorm:"not null"`                         // 接收邮件
+	SMS       bool      `json:"sms" gorm:"not null"`                           // 接收短信
+	UpdatedAt time.Time `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+func (MessagePreference) TableName() string {
+	return "message_preferences"
+}
+
+// DefaultMessagePreference 默认的消息渠道偏好
+func DefaultMessagePreference(userID uint) *MessagePreference {
+	return &MessagePreference{
+		UserID: userID,
+		Email:  true,
+	}
+}
+
+// Channels 用户接收消息的渠道
+func (p *MessagePreference) Channels() []string {
+	var channels []string
+	if p.Email {
+		channels = append(channels, MessageChannelEmail)
+	}
+	if p.SMS {
+		channels = append(channels, MessageChannelSMS)
+	}
+	return channels
+}
+
+// UpdateMessagePreferenceRequest 更新消息渠道偏好请求，未填写的渠道保持不变
+type UpdateMessagePreferenceRequest struct {
+	Email *bool `json:"email"` // 接收邮件
+	SMS   *bool `json:"sms"`   // 接收短信
+}
+
+// Apply 将请求中填写的渠道应用到偏好上
+func (r *UpdateMessagePreferenceRequest) Apply(p *MessagePreference) {
+	if r.Email != nil {
+		p.Email = *r.Email
+	}
+	if r.SMS != nil {
+		p.SMS = *r.SMS
+	}
+}
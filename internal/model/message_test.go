@@ -0,0 +1,39 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessagePreference_DefaultAndApply(t *testing.T) {
+	p := DefaultMessagePreference(1)
+	if got := p.Channels(); !reflect.DeepEqual(got, []string{MessageChannelEmail}) {
+		t.Fatalf("default channels = %v, want email only", got)
+	}
+
+	enabled, disabled := true, false
+	(&UpdateMessagePreferenceRequest{SMS: &enabled}).Apply(p)
+	if got := p.Channels(); !reflect.DeepEqual(got, []string{MessageChannelEmail, MessageChannelSMS}) {
+		t.Fatalf("channels after enabling sms = %v", got)
+	}
+
+	(&UpdateMessagePreferenceRequest{Email: &disabled, SMS: &disabled}).Apply(p)
+	if got := p.Channels(); len(got) != 0 {
+		t.Fatalf("channels after disabling all = %v, want none", got)
+	}
+}
+
+func TestOutboxMessage_MarkFailedTruncatesReason(t *testing.T) {
+	m := &OutboxMessage{Status: OutboxMessagePending}
+	m.MarkFailed(strings.Repeat("错", maxMessageErrorLength+10))
+	if m.Status != OutboxMessageFailed || len([]rune(m.LastError)) != maxMessageErrorLength {
+		t.Fatalf("status = %d, reason length = %d", m.Status, len([]rune(m.LastError)))
+	}
+
+	m.MarkSent(time.Now())
+	if m.Status != OutboxMessageSent || m.SentAt == nil || m.LastError != "" {
+		t.Fatalf("unexpected message after MarkSent: %+v", m)
+	}
+}
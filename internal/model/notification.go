@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// 站内通知类型常量
+const (
+	NotificationTypePriceDrop   = "price_drop"    // 收藏的商品降价
+	NotificationTypeBackInStock = "back_in_stock" // 收藏的商品到货
+	NotificationTypeOrder       = "order"         // 订单状态变更
+	NotificationTypePayment     = "payment"       // 支付结果
+	NotificationTypeShipment    = "shipment"      // 物流更新
+)
+
+// Notification 站内通知模型
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`                                           // 通知ID
+	UserID    uint       `json:"user_id" gorm:"not null;index:idx_notification_user,priority:1"` // 接收用户ID
+	Type      string     `json:"type" gorm:"size:32;not null"`                                   // 通知类型
+	Title     string     `json:"title" gorm:"size:100;not null"`                                 // 标题
+	Content   string     `json:"content" gorm:"size:500"`                                        // 内容
+	RefID     uint       `json:"ref_id" gorm:"not null;default:0"`                               // 关联对象ID，如商品ID、订单ID
+	ReadAt    *time.Time `json:"read_at"`                                                        // 阅读时间，为空表示未读
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_notification_user,priority:2"`       // 创建时间
+}
+
+// TableName 指定表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationListRequest 站内通知列表查询请求
+type NotificationListRequest struct {
+	Page       int  `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize   int  `form:"page_size,default=10" binding:"min=1,max=100"` // 每页数量
+	UnreadOnly bool `form:"unread_only"`                                  // 只看未读
+}
+
+// NotificationListResponse 站内通知列表响应
+type NotificationListResponse struct {
+	Notifications []*Notification `json:"notifications"` // 通知列表，按时间倒序
+	Total         int             `json:"total"`         // 总数量
+	UnreadCount   int             `json:"unread_count"`  // 未读数量
+	Page          int             `json:"page"`          // 当前页码
+	PageSize      int             `json:"page_size"`     // 每页数量
+	TotalPages    int             `json:"total_pages"`   // 总页数
+}
+
+// MarkNotificationsReadRequest 标记通知已读请求
+type MarkNotificationsReadRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=100"` // 通知ID列表
+}
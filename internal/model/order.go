@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,6 +19,10 @@ type Order struct {
 	OrderNo         string         `json:"order_no" gorm:"uniqueIndex;size:32;not null"`          // 订单号，唯一
 	UserID          uint           `json:"user_id" gorm:"not null;index"`                         // 用户ID
 	TotalAmount     float64        `json:"total_amount" gorm:"type:decimal(10,2);not null"`       // 订单总金额
+	DiscountAmount  float64        `json:"discount_amount" gorm:"type:decimal(10,2);default:0"`   // 优惠金额
+	ShippingFee     float64        `json:"shipping_fee" gorm:"type:decimal(10,2);default:0"`      // 运费
+	TaxAmount       float64        `json:"tax_amount" gorm:"type:decimal(10,2);default:0"`        // 税额（价内税，已包含在商品价格中）
+	UserCouponID    *uint          `json:"user_coupon_id" gorm:"index"`                           // 使用的用户优惠券ID
 	Status          OrderStatus    `json:"status" gorm:"default:1;index"`                         // 订单状态
 	PaymentMethod   string         `json:"payment_method" gorm:"size:20;index"`                   // 支付方式
 	ContactPhone    string         `json:"contact_phone" gorm:"size:20"`                          // 联系电话
@@ -31,6 +36,7 @@ type Order struct {
 	// 关联关系
 	User            User           `json:"user,omitempty" gorm:"foreignKey:UserID"`               // 所属用户
 	OrderItems      []OrderItem    `json:"order_items,omitempty" gorm:"foreignKey:OrderID"`       // 订单商品
+	Allocations     []InventoryReservation `json:"allocations,omitempty" gorm:"foreignKey:OrderID"` // 订单商品的分仓库存分配
 }
 
 // OrderItem 订单商品模型
@@ -38,11 +44,16 @@ type OrderItem struct {
 	ID           uint      `json:"id" gorm:"primaryKey"`                                         // 订单商品ID
 	OrderID      uint      `json:"order_id" gorm:"not null;index"`                              // 订单ID
 	ProductID    uint      `json:"product_id" gorm:"not null;index"`                            // 商品ID
+	SkuID        uint      `json:"sku_id" gorm:"not null;default:0;index"`                      // SKU ID，商品没有规格时为0
 	ProductName  string    `json:"product_name" gorm:"size:200;not null"`                       // 商品名称（冗余存储）
+	SpecKey      string    `json:"spec_key" gorm:"size:255"`                                    // 规格属性组合（冗余存储）
 	ProductImage *string   `json:"product_image" gorm:"size:255"`                               // 商品图片（冗余存储）
 	Price        float64   `json:"price" gorm:"type:decimal(10,2);not null"`                    // 商品单价
 	Quantity     int       `json:"quantity" gorm:"not null"`                                    // 购买数量
 	TotalPrice   float64   `json:"total_price" gorm:"type:decimal(10,2);not null"`              // 小计金额
+	DiscountAmount float64 `json:"discount_amount" gorm:"type:decimal(10,2);default:0"`         // 分摊的优惠金额
+	RefundedQuantity int   `json:"refunded_quantity" gorm:"not null;default:0"`                 // 已退款数量
+	ShippedQuantity  int   `json:"shipped_quantity" gorm:"not null;default:0"`                  // 已发货数量
 	CreatedAt    time.Time `json:"created_at"`                                                  // 创建时间
 	
 	// 关联关系
@@ -50,6 +61,68 @@ type OrderItem struct {
 	Product      Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`               // 关联商品
 }
 
+// PayableAmount 订单应付金额
+func (o *Order) PayableAmount() float64 {
+	return math.Round((o.TotalAmount-o.DiscountAmount+o.ShippingFee)*100) / 100
+}
+
+// PaidAmount 商品实付金额（小计减去分摊的优惠）
+func (oi *OrderItem) PaidAmount() float64 {
+	return oi.TotalPrice - oi.DiscountAmount
+}
+
+// RefundAmount 退还指定数量商品的金额
+// 按实付金额计算，优惠按数量等比例扣除；多次部分退款的金额之和正好等于实付金额
+func (oi *OrderItem) RefundAmount(quantity int) float64 {
+	if oi.Quantity <= 0 {
+		return 0
+	}
+	paid := math.Round(oi.PaidAmount() * 100)
+	refunded := math.Round(paid * float64(oi.RefundedQuantity) / float64(oi.Quantity))
+	total := math.Round(paid * float64(oi.RefundedQuantity+quantity) / float64(oi.Quantity))
+	return (total - refunded) / 100
+}
+
+// RefundShippingFee 本次退款应退还的运费
+// 退款后订单全部商品都已退款时，运费随这次退款一并退还；之前的部分退款不含运费，运费只会退还一次
+func (o *Order) RefundShippingFee(items []RefundItem) float64 {
+	quantities := make(map[uint]int, len(items))
+	for _, item := range items {
+		quantities[item.OrderItemID] += item.Quantity
+	}
+	for _, item := range o.OrderItems {
+		if item.RemainingQuantity() > quantities[item.ID] {
+			return 0
+		}
+	}
+	return o.ShippingFee
+}
+
+// RemainingQuantity 剩余可退款数量
+func (oi *OrderItem) RemainingQuantity() int {
+	return oi.Quantity - oi.RefundedQuantity
+}
+
+// UnshippedQuantity 待发货数量（已退款的商品不再发货）
+func (oi *OrderItem) UnshippedQuantity() int {
+	if n := oi.Quantity - oi.RefundedQuantity - oi.ShippedQuantity; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// OrderStatusLog 订单状态变更记录
+// 记录订单每一次状态变化，便于追溯订单历史
+type OrderStatusLog struct {
+	ID         uint        `json:"id" gorm:"primaryKey"`                  // 记录ID
+	OrderID    uint        `json:"order_id" gorm:"not null;index"`        // 订单ID
+	FromStatus OrderStatus `json:"from_status" gorm:"not null"`           // 变更前状态
+	ToStatus   OrderStatus `json:"to_status" gorm:"not null"`             // 变更后状态
+	Actor      string      `json:"actor" gorm:"size:50;not null"`         // 操作人，如 user:1、admin:2、system
+	Reason     string      `json:"reason" gorm:"size:255"`                // 变更原因
+	CreatedAt  time.Time   `json:"created_at" gorm:"index"`               // 变更时间
+}
+
 // JSONAddress 地址信息的JSON类型
 // 用于存储收货地址的详细信息
 type JSONAddress struct {
@@ -82,13 +155,53 @@ func (ja JSONAddress) Value() (driver.Value, error) {
 	return json.Marshal(ja)
 }
 
+// OrderPreviewRequest 订单预览请求
+// 收货地址二选一：AddressID使用地址簿中的地址，ShippingAddress直接填写地址，都不填时使用默认地址
+type OrderPreviewRequest struct {
+	CartItemIDs     []uint       `json:"cart_item_ids" binding:"required,min=1"`                 // 购物车商品ID列表
+	AddressID       uint         `json:"address_id"`                                             // 地址簿中的收货地址ID
+	ShippingAddress *JSONAddress `json:"shipping_address"`                                       // 收货地址
+	CouponID        uint         `json:"coupon_id"`                                              // 使用的用户优惠券ID
+}
+
 // CreateOrderRequest 创建订单请求
+// 结算相关参数与订单预览相同，保证预览和下单的计价一致
 type CreateOrderRequest struct {
-	CartItemIDs     []uint      `json:"cart_item_ids" binding:"required,min=1"`                 // 购物车商品ID列表
-	ShippingAddress JSONAddress `json:"shipping_address" binding:"required"`                    // 收货地址
-	PaymentMethod   string      `json:"payment_method" binding:"required"`                      // 支付方式
-	ContactPhone    string      `json:"contact_phone" binding:"required"`                       // 联系电话
-	Remark          string      `json:"remark"`                                                 // 订单备注
+	OrderPreviewRequest
+	PaymentMethod   string       `json:"payment_method" binding:"required"`                      // 支付方式
+	ContactPhone    string       `json:"contact_phone"`                                          // 联系电话，为空时使用收货人电话
+	Remark          string       `json:"remark"`                                                 // 订单备注
+}
+
+// OrderPreviewItem 订单预览商品
+type OrderPreviewItem struct {
+	CartItemID   uint    `json:"cart_item_id"`      // 购物车项ID
+	ProductID    uint    `json:"product_id"`        // 商品ID
+	SkuID        uint    `json:"sku_id"`            // SKU ID
+	SpecKey      string  `json:"spec_key"`          // 规格属性组合
+	ProductName  string  `json:"product_name"`      // 商品名称
+	ProductImage *string `json:"product_image"`     // 商品图片
+	Price        float64 `json:"price"`             // 单价
+	Quantity     int     `json:"quantity"`          // 购买数量
+	Stock        int     `json:"stock"`             // 当前库存
+	Subtotal     float64 `json:"subtotal"`          // 小计金额
+	Discount     float64 `json:"discount"`          // 分摊的优惠金额
+	PayAmount    float64 `json:"pay_amount"`        // 应付金额
+	Warning      string  `json:"warning,omitempty"` // 不能下单的原因，如已下架、库存不足
+}
+
+// OrderPreviewResponse 订单预览响应
+type OrderPreviewResponse struct {
+	Items           []*OrderPreviewItem  `json:"items"`            // 商品明细
+	ShippingAddress JSONAddress          `json:"shipping_address"` // 收货地址
+	Allocations     []DiscountAllocation `json:"allocations"`      // 优惠分摊明细
+	TotalAmount     float64              `json:"total_amount"`     // 商品总金额
+	DiscountAmount  float64              `json:"discount_amount"`  // 优惠金额
+	ShippingFee     float64              `json:"shipping_fee"`     // 运费
+	TaxAmount       float64              `json:"tax_amount"`       // 税额（价内税）
+	PayAmount       float64              `json:"pay_amount"`       // 应付金额
+	UserCouponID    uint                 `json:"user_coupon_id"`   // 使用的用户优惠券ID
+	Purchasable     bool                 `json:"purchasable"`      // 是否可以下单
 }
 
 // OrderListRequest 订单列表查询请求
@@ -129,6 +242,18 @@ type PayOrderRequest struct {
 	PaymentMethod string `json:"payment_method" binding:"required"`                        // 支付方式
 }
 
+// PayOrderResponse 发起支付响应
+// 订单不会立即变为已支付，需等待支付渠道的签名回调
+type PayOrderResponse struct {
+	OrderID       uint    `json:"order_id"`       // 订单ID
+	OrderNo       string  `json:"order_no"`       // 订单号
+	PaymentMethod string  `json:"payment_method"` // 支付方式
+	OutTradeNo    string  `json:"out_trade_no"`   // 商户侧交易号
+	TradeNo       string  `json:"trade_no"`       // 渠道交易号
+	Amount        float64 `json:"amount"`         // 支付金额
+	PayURL        string  `json:"pay_url"`        // 收银台地址
+}
+
 // OrderStatistics 订单统计
 type OrderStatistics struct {
 	UserID          uint    `json:"user_id"`          // 用户ID
@@ -141,6 +266,14 @@ type OrderStatistics struct {
 	CancelledCount  int     `json:"cancelled_count"`  // 已取消订单数
 }
 
+// ExpiredOrderBatch 一批过期订单的处理结果
+type ExpiredOrderBatch struct {
+	LastID     uint   // 本批最后一个订单ID，作为下一批的起点
+	Scanned    int    // 本批扫描到的过期订单数
+	Cancelled  int    // 实际取消的订单数
+	ProductIDs []uint // 可售库存发生变化的商品ID
+}
+
 // 订单状态常量
 const (
 	OrderStatusPending   OrderStatus = 1 // 待支付
@@ -148,6 +281,9 @@ const (
 	OrderStatusShipped   OrderStatus = 3 // 已发货
 	OrderStatusDelivered OrderStatus = 4 // 已送达
 	OrderStatusCancelled OrderStatus = 5 // 已取消
+	OrderStatusRefundRequested   OrderStatus = 6 // 退款申请中
+	OrderStatusRefunded          OrderStatus = 7 // 已退款
+	OrderStatusPartiallyRefunded OrderStatus = 8 // 部分退款
 )
 
 // GetStatusText 获取订单状态文本
@@ -163,6 +299,12 @@ func GetOrderStatusText(status OrderStatus) string {
 		return "已送达"
 	case OrderStatusCancelled:
 		return "已取消"
+	case OrderStatusRefundRequested:
+		return "退款申请中"
+	case OrderStatusRefunded:
+		return "已退款"
+	case OrderStatusPartiallyRefunded:
+		return "部分退款"
 	default:
 		return "未知状态"
 	}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// orderTransitions 订单状态流转表：当前状态 -> 允许变更到的状态
+// 新增状态或流转时只需要修改这张表
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusRefundRequested},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusRefundRequested},
+	OrderStatusDelivered: {OrderStatusRefundRequested},
+	// 退款完成或部分完成；拒绝退款见orderRestoreTransitions
+	OrderStatusRefundRequested: {OrderStatusRefunded, OrderStatusPartiallyRefunded},
+	// 部分退款后剩余商品继续履约，也可以再次申请退款
+	OrderStatusPartiallyRefunded: {OrderStatusShipped, OrderStatusDelivered, OrderStatusRefundRequested},
+}
+
+// orderRestoreTransitions 拒绝退款时恢复到申请前的状态
+// 只有拒绝退款可以使用，不放在orderTransitions中，避免退款处理中的订单被支付、发货或确认收货
+var orderRestoreTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusRefundRequested: {
+		OrderStatusPaid, OrderStatusShipped, OrderStatusDelivered, OrderStatusPartiallyRefunded,
+	},
+}
+
+// CanTransitOrder 订单状态是否允许从from变更为to
+func CanTransitOrder(from, to OrderStatus) bool {
+	return hasOrderTransition(orderTransitions, from, to)
+}
+
+// CanRestoreOrder 拒绝退款时订单状态是否允许从from恢复为to
+func CanRestoreOrder(from, to OrderStatus) bool {
+	return hasOrderTransition(orderRestoreTransitions, from, to)
+}
+
+// forceOrderTransitions 管理员允许强制变更的状态：当前状态 -> 目标状态
+// 只包含不涉及资金和库存变动的变更，如线下发货、代确认收货、取消未支付订单；
+// 已支付订单的取消、退款相关状态和回到待支付都必须走正常流程
+var forceOrderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:           {OrderStatusCancelled},
+	OrderStatusPaid:              {OrderStatusShipped},
+	OrderStatusShipped:           {OrderStatusDelivered},
+	OrderStatusPartiallyRefunded: {OrderStatusShipped, OrderStatusDelivered},
+}
+
+// CanForceOrder 管理员是否允许把订单状态从from强制变更为to
+func CanForceOrder(from, to OrderStatus) bool {
+	return hasOrderTransition(forceOrderTransitions, from, to)
+}
+
+// hasOrderTransition 流转表中是否有从from到to的变更
+func hasOrderTransition(table map[OrderStatus][]OrderStatus, from, to OrderStatus) bool {
+	for _, next := range table[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidOrderStatus 是否为已定义的订单状态
+func IsValidOrderStatus(status OrderStatus) bool {
+	return status >= OrderStatusPending && status <= OrderStatusPartiallyRefunded
+}
+
+// OrderActorSystem 系统自动操作
+const OrderActorSystem = "system"
+
+// OrderActorUser 用户操作
+func OrderActorUser(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// OrderActorAdmin 管理员操作
+func OrderActorAdmin(adminID uint) string {
+	return fmt.Sprintf("admin:%d", adminID)
+}
+
+// OrderTimelineEvent 订单时间线中的一次状态变更
+type OrderTimelineEvent struct {
+	FromStatus     OrderStatus `json:"from_status"`      // 变更前状态，下单时为0
+	FromStatusText string      `json:"from_status_text"` // 变更前状态文本
+	ToStatus       OrderStatus `json:"to_status"`        // 变更后状态
+	ToStatusText   string      `json:"to_status_text"`   // 变更后状态文本
+	Actor          string      `json:"actor"`            // 操作人
+	Reason         string      `json:"reason"`           // 变更原因
+	CreatedAt      time.Time   `json:"created_at"`       // 变更时间
+}
+
+// OrderTimeline 订单时间线
+type OrderTimeline struct {
+	OrderID    uint                  `json:"order_id"`    // 订单ID
+	OrderNo    string                `json:"order_no"`    // 订单号
+	Status     OrderStatus           `json:"status"`      // 当前状态
+	StatusText string                `json:"status_text"` // 当前状态文本
+	Events     []*OrderTimelineEvent `json:"events"`      // 状态变更记录，按时间升序
+}
+
+// NewOrderTimeline 根据订单和状态变更记录生成时间线
+func NewOrderTimeline(order *Order, logs []*OrderStatusLog) *OrderTimeline {
+	timeline := &OrderTimeline{
+		OrderID:    order.ID,
+		OrderNo:    order.OrderNo,
+		Status:     order.Status,
+		StatusText: GetOrderStatusText(order.Status),
+		Events:     make([]*OrderTimelineEvent, 0, len(logs)),
+	}
+
+	for _, log := range logs {
+		event := &OrderTimelineEvent{
+			FromStatus:   log.FromStatus,
+			ToStatus:     log.ToStatus,
+			ToStatusText: GetOrderStatusText(log.ToStatus),
+			Actor:        log.Actor,
+			Reason:       log.Reason,
+			CreatedAt:    log.CreatedAt,
+		}
+		if log.FromStatus != 0 {
+			event.FromStatusText = GetOrderStatusText(log.FromStatus)
+		}
+		timeline.Events = append(timeline.Events, event)
+	}
+
+	return timeline
+}
@@ -0,0 +1,76 @@
+package model
+
+import "testing"
+
+func TestCanTransitOrder(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderStatusPending, OrderStatusPaid, true},
+		{OrderStatusPending, OrderStatusCancelled, true},
+		{OrderStatusPending, OrderStatusShipped, false},
+		{OrderStatusPaid, OrderStatusCancelled, false},
+		{OrderStatusShipped, OrderStatusDelivered, true},
+		{OrderStatusDelivered, OrderStatusShipped, false},
+		{OrderStatusRefundRequested, OrderStatusPartiallyRefunded, true},
+		{OrderStatusRefundRequested, OrderStatusPaid, false},
+		{OrderStatusRefundRequested, OrderStatusShipped, false},
+		{OrderStatusRefundRequested, OrderStatusDelivered, false},
+		{OrderStatusCancelled, OrderStatusPaid, false},
+		{OrderStatusRefunded, OrderStatusRefundRequested, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitOrder(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitOrder(%s, %s) = %v, want %v",
+				GetOrderStatusText(tt.from), GetOrderStatusText(tt.to), got, tt.want)
+		}
+	}
+}
+
+func TestCanRestoreOrder(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderStatusRefundRequested, OrderStatusPaid, true},
+		{OrderStatusRefundRequested, OrderStatusShipped, true},
+		{OrderStatusRefundRequested, OrderStatusDelivered, true},
+		{OrderStatusRefundRequested, OrderStatusPartiallyRefunded, true},
+		{OrderStatusRefundRequested, OrderStatusRefunded, false},
+		{OrderStatusShipped, OrderStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanRestoreOrder(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanRestoreOrder(%s, %s) = %v, want %v",
+				GetOrderStatusText(tt.from), GetOrderStatusText(tt.to), got, tt.want)
+		}
+	}
+}
+
+func TestCanForceOrder(t *testing.T) {
+	tests := []struct {
+		from, to OrderStatus
+		want     bool
+	}{
+		{OrderStatusPending, OrderStatusCancelled, true},
+		{OrderStatusPaid, OrderStatusShipped, true},
+		{OrderStatusShipped, OrderStatusDelivered, true},
+		{OrderStatusPartiallyRefunded, OrderStatusDelivered, true},
+		{OrderStatusPending, OrderStatusPaid, false},
+		{OrderStatusPaid, OrderStatusCancelled, false},
+		{OrderStatusCancelled, OrderStatusPending, false},
+		{OrderStatusDelivered, OrderStatusRefunded, false},
+		{OrderStatusPaid, OrderStatusRefundRequested, false},
+		{OrderStatusRefundRequested, OrderStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanForceOrder(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanForceOrder(%s, %s) = %v, want %v",
+				GetOrderStatusText(tt.from), GetOrderStatusText(tt.to), got, tt.want)
+		}
+	}
+}
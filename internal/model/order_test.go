@@ -0,0 +1,60 @@
+package model
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrderItemRefundAmount(t *testing.T) {
+	// 3件共100元，分摊优惠10元，实付90元
+	item := OrderItem{Price: 100.0 / 3, Quantity: 3, TotalPrice: 100, DiscountAmount: 10}
+
+	var refunded float64
+	for _, want := range []float64{30, 30, 30} {
+		got := item.RefundAmount(1)
+		if math.Abs(got-want) > 0.001 {
+			t.Errorf("RefundAmount(1) after %d refunded = %.2f, want %.2f", item.RefundedQuantity, got, want)
+		}
+		refunded += got
+		item.RefundedQuantity++
+	}
+	if math.Abs(refunded-item.PaidAmount()) > 0.001 {
+		t.Errorf("refunded %.2f in total, want %.2f", refunded, item.PaidAmount())
+	}
+
+	// 无法整除时，多次部分退款之和等于实付金额
+	item = OrderItem{Quantity: 3, TotalPrice: 100}
+	total := item.RefundAmount(1)
+	item.RefundedQuantity = 1
+	total += item.RefundAmount(2)
+	if math.Abs(total-100) > 0.001 {
+		t.Errorf("partial refunds sum to %.2f, want 100", total)
+	}
+}
+
+func TestOrderRefundShippingFee(t *testing.T) {
+	order := Order{
+		ShippingFee: 10,
+		OrderItems: []OrderItem{
+			{ID: 1, Quantity: 2, ShippedQuantity: 2},
+			{ID: 2, Quantity: 1},
+		},
+	}
+
+	// 还有商品未退款，不退运费
+	if got := order.RefundShippingFee([]RefundItem{{OrderItemID: 1, Quantity: 2}}); got != 0 {
+		t.Errorf("partial refund shipping fee = %.2f, want 0", got)
+	}
+
+	// 退还全部商品时退运费
+	all := []RefundItem{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 2, Quantity: 1}}
+	if got := order.RefundShippingFee(all); got != 10 {
+		t.Errorf("full refund shipping fee = %.2f, want 10", got)
+	}
+
+	// 之前已部分退款，这次退还剩余商品时退运费
+	order.OrderItems[0].RefundedQuantity = 2
+	if got := order.RefundShippingFee([]RefundItem{{OrderItemID: 2, Quantity: 1}}); got != 10 {
+		t.Errorf("last refund shipping fee = %.2f, want 10", got)
+	}
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// PaymentStatus 支付状态
+// 与微服务侧 internal/payment/domain/entity.Payment 聚合保持一致
+type PaymentStatus string
+
+const (
+	PaymentStatusPending    PaymentStatus = "PENDING"    // 待支付
+	PaymentStatusProcessing PaymentStatus = "PROCESSING" // 处理中（已在渠道下单）
+	PaymentStatusCompleted  PaymentStatus = "COMPLETED"  // 已完成
+	PaymentStatusFailed     PaymentStatus = "FAILED"     // 失败
+	PaymentStatusCancelled  PaymentStatus = "CANCELLED"  // 已取消
+	PaymentStatusRefunded   PaymentStatus = "REFUNDED"   // 已退款
+	// 部分退款：售后按商品退款时，累计退款金额小于支付金额
+	PaymentStatusPartiallyRefunded PaymentStatus = "PARTIALLY_REFUNDED"
+)
+
+// Payment 支付记录模型
+// 每次发起支付都会生成一条记录，用于审计支付、失败和退款
+type Payment struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`                              // 支付记录ID
+	PaymentNo     string        `json:"payment_no" gorm:"uniqueIndex;size:32;not null"`    // 支付单号（即渠道的商户侧交易号）
+	OrderID       uint          `json:"order_id" gorm:"not null;index"`                    // 订单ID
+	OrderNo       string        `json:"order_no" gorm:"size:32;not null;index"`            // 订单号（冗余存储）
+	UserID        uint          `json:"user_id" gorm:"not null;index"`                     // 用户ID
+	Amount        float64       `json:"amount" gorm:"type:decimal(10,2);not null"`         // 支付金额
+	Method        string        `json:"method" gorm:"size:20;not null"`                    // 支付方式
+	Status        PaymentStatus `json:"status" gorm:"size:20;not null;index"`              // 支付状态
+	TransactionID string        `json:"transaction_id" gorm:"size:64;index"`               // 渠道交易号
+	RefundID      string        `json:"refund_id" gorm:"size:64"`                          // 最近一次渠道退款单号
+	RefundAmount  float64       `json:"refund_amount" gorm:"type:decimal(10,2);default:0"` // 累计退款金额
+	RefundReason  string        `json:"refund_reason" gorm:"size:255"`                     // 退款原因
+	FailureReason string        `json:"failure_reason" gorm:"size:255"`                    // 失败原因
+	RefundPending bool          `json:"refund_pending" gorm:"default:false;index"`         // 待自动退款：到账时订单已不再等待这笔支付，需原路全额退回
+	PaidAt        *time.Time    `json:"paid_at"`                                           // 支付完成时间
+	CreatedAt     time.Time     `json:"created_at"`                                        // 创建时间
+	UpdatedAt     time.Time     `json:"updated_at"`                                        // 更新时间
+}
+
+// 支付状态流转错误
+var (
+	ErrPaymentNotPending    = errors.New("只有待支付的支付单可以处理")
+	ErrPaymentNotProcessing = errors.New("只有处理中的支付单可以完成或失败")
+	ErrPaymentNotClosed     = errors.New("只有处理中、已取消或失败的支付单可以登记为待退款")
+	ErrPaymentNotCancelable = errors.New("已完成、失败或已退款的支付单不能取消")
+	ErrPaymentNotRefundable = errors.New("只有已完成或部分退款的支付单可以退款")
+)
+
+// Process 处理支付（已在渠道下单）
+func (p *Payment) Process(transactionID string) error {
+	if p.Status != PaymentStatusPending {
+		return ErrPaymentNotPending
+	}
+	if transactionID == "" {
+		return errors.New("渠道交易号不能为空")
+	}
+
+	p.Status = PaymentStatusProcessing
+	p.TransactionID = transactionID
+	return nil
+}
+
+// Complete 完成支付
+func (p *Payment) Complete(paidAt time.Time) error {
+	if p.Status != PaymentStatusProcessing {
+		return ErrPaymentNotProcessing
+	}
+
+	p.Status = PaymentStatusCompleted
+	p.PaidAt = &paidAt
+	return nil
+}
+
+// CompleteForRefund 登记到账后需要原路退回的支付
+// 已被新的支付尝试取代（已取消、失败）的支付单在渠道侧仍可能支付成功，
+// 或到账时订单已取消、已由其他支付单完成；资金已经到账，记为完成并标记待自动退款
+func (p *Payment) CompleteForRefund(paidAt time.Time) error {
+	if p.Status != PaymentStatusProcessing && p.Status != PaymentStatusCancelled && p.Status != PaymentStatusFailed {
+		return ErrPaymentNotClosed
+	}
+
+	p.Status = PaymentStatusCompleted
+	p.PaidAt = &paidAt
+	p.RefundPending = true
+	return nil
+}
+
+// Fail 支付失败
+func (p *Payment) Fail(reason string) error {
+	if p.Status != PaymentStatusProcessing {
+		return ErrPaymentNotProcessing
+	}
+	if reason == "" {
+		return errors.New("失败原因不能为空")
+	}
+
+	p.Status = PaymentStatusFailed
+	p.FailureReason = reason
+	return nil
+}
+
+// Cancel 取消支付
+func (p *Payment) Cancel() error {
+	if !p.CanBeCancelled() {
+		return ErrPaymentNotCancelable
+	}
+
+	p.Status = PaymentStatusCancelled
+	return nil
+}
+
+// Refund 退款
+// 支持多次部分退款，累计退款金额达到支付金额时变为已退款
+func (p *Payment) Refund(refundID string, refundAmount float64, reason string) error {
+	if !p.CanBeRefunded() {
+		return ErrPaymentNotRefundable
+	}
+	if refundID == "" {
+		return errors.New("退款单号不能为空")
+	}
+	if reason == "" {
+		return errors.New("退款原因不能为空")
+	}
+	if refundAmount <= 0 {
+		return errors.New("退款金额必须大于0")
+	}
+	if p.RefundAmount+refundAmount > p.Amount+0.001 {
+		return errors.New("退款金额不能超过支付金额")
+	}
+
+	p.RefundAmount += refundAmount
+	if p.RefundAmount >= p.Amount-0.001 {
+		p.Status = PaymentStatusRefunded
+		p.RefundPending = false
+	} else {
+		p.Status = PaymentStatusPartiallyRefunded
+	}
+	p.RefundID = refundID
+	p.RefundReason = reason
+	return nil
+}
+
+// RefundableAmount 剩余可退款金额
+func (p *Payment) RefundableAmount() float64 {
+	return p.Amount - p.RefundAmount
+}
+
+// IsCompleted 是否已完成
+func (p *Payment) IsCompleted() bool {
+	return p.Status == PaymentStatusCompleted
+}
+
+// CanBeCancelled 是否可以取消
+func (p *Payment) CanBeCancelled() bool {
+	return p.Status == PaymentStatusPending || p.Status == PaymentStatusProcessing
+}
+
+// CanBeRefunded 是否可以退款
+func (p *Payment) CanBeRefunded() bool {
+	return p.Status == PaymentStatusCompleted || p.Status == PaymentStatusPartiallyRefunded
+}
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPayment_Lifecycle(t *testing.T) {
+	payment := &Payment{Amount: 100, Status: PaymentStatusPending}
+
+	if err := payment.Complete(time.Now()); !errors.Is(err, ErrPaymentNotProcessing) {
+		t.Fatalf("expected pending payment not to complete, got %v", err)
+	}
+
+	if err := payment.Process(""); err == nil {
+		t.Fatal("expected empty transaction ID to be rejected")
+	}
+	if err := payment.Process("TX1"); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if payment.Status != PaymentStatusProcessing || payment.TransactionID != "TX1" {
+		t.Fatalf("unexpected payment after process: %+v", payment)
+	}
+
+	paidAt := time.Now()
+	if err := payment.Complete(paidAt); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if !payment.IsCompleted() || payment.PaidAt == nil || !payment.PaidAt.Equal(paidAt) {
+		t.Fatalf("unexpected payment after complete: %+v", payment)
+	}
+
+	if err := payment.Cancel(); !errors.Is(err, ErrPaymentNotCancelable) {
+		t.Fatalf("expected completed payment not to cancel, got %v", err)
+	}
+}
+
+func TestPayment_Fail(t *testing.T) {
+	payment := &Payment{Amount: 100, Status: PaymentStatusPending}
+	if err := payment.Fail("declined"); !errors.Is(err, ErrPaymentNotProcessing) {
+		t.Fatalf("expected pending payment not to fail, got %v", err)
+	}
+
+	_ = payment.Process("TX1")
+	if err := payment.Fail(""); err == nil {
+		t.Fatal("expected empty failure reason to be rejected")
+	}
+	if err := payment.Fail("declined"); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if payment.Status != PaymentStatusFailed || payment.FailureReason != "declined" {
+		t.Fatalf("unexpected payment after fail: %+v", payment)
+	}
+	if err := payment.Refund("R1", 10, "reason"); !errors.Is(err, ErrPaymentNotRefundable) {
+		t.Fatalf("expected failed payment not to refund, got %v", err)
+	}
+}
+
+func TestPayment_Refund(t *testing.T) {
+	tests := []struct {
+		name      string
+		refundID  string
+		amount    float64
+		reason    string
+		expectErr bool
+	}{
+		{name: "valid refund", refundID: "R1", amount: 100, reason: "退货"},
+		{name: "empty refund ID", refundID: "", amount: 10, reason: "退货", expectErr: true},
+		{name: "empty reason", refundID: "R1", amount: 10, reason: "", expectErr: true},
+		{name: "zero amount", refundID: "R1", amount: 0, reason: "退货", expectErr: true},
+		{name: "exceeds payment", refundID: "R1", amount: 100.01, reason: "退货", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &Payment{Amount: 100, Status: PaymentStatusCompleted}
+			err := payment.Refund(tt.refundID, tt.amount, tt.reason)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if payment.Status != PaymentStatusCompleted {
+					t.Fatalf("status should be unchanged, got %s", payment.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("refund: %v", err)
+			}
+			if payment.Status != PaymentStatusRefunded || payment.RefundAmount != tt.amount {
+				t.Fatalf("unexpected payment after refund: %+v", payment)
+			}
+		})
+	}
+}
+
+func TestPayment_PartialRefund(t *testing.T) {
+	payment := &Payment{Amount: 100, Status: PaymentStatusCompleted}
+
+	if err := payment.Refund("R1", 30, "退一件"); err != nil {
+		t.Fatalf("first refund: %v", err)
+	}
+	if payment.Status != PaymentStatusPartiallyRefunded || payment.RefundableAmount() != 70 {
+		t.Fatalf("unexpected payment after partial refund: %+v", payment)
+	}
+	if err := payment.Cancel(); !errors.Is(err, ErrPaymentNotCancelable) {
+		t.Fatalf("expected partially refunded payment not to cancel, got %v", err)
+	}
+
+	if err := payment.Refund("R2", 80, "超额"); err == nil {
+		t.Fatal("expected refund exceeding remaining amount to fail")
+	}
+
+	if err := payment.Refund("R3", 70, "退剩余"); err != nil {
+		t.Fatalf("second refund: %v", err)
+	}
+	if payment.Status != PaymentStatusRefunded || payment.RefundID != "R3" {
+		t.Fatalf("unexpected payment after full refund: %+v", payment)
+	}
+	if err := payment.Refund("R4", 1, "再退"); !errors.Is(err, ErrPaymentNotRefundable) {
+		t.Fatalf("expected refunded payment not to refund again, got %v", err)
+	}
+}
+
+func TestPayment_CompleteForRefund(t *testing.T) {
+	// 被取代的支付单在渠道侧仍然支付成功
+	payment := &Payment{Amount: 100, Status: PaymentStatusCancelled}
+	paidAt := time.Now()
+	if err := payment.CompleteForRefund(paidAt); err != nil {
+		t.Fatalf("complete cancelled payment for refund: %v", err)
+	}
+	if !payment.IsCompleted() || !payment.RefundPending || payment.PaidAt == nil {
+		t.Fatalf("unexpected payment: %+v", payment)
+	}
+
+	// 全额退回后不再待退款
+	if err := payment.Refund("R1", 100, "订单已取消"); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if payment.RefundPending || payment.Status != PaymentStatusRefunded {
+		t.Fatalf("unexpected payment after refund: %+v", payment)
+	}
+
+	// 已完成的支付不能再登记
+	completed := &Payment{Amount: 100, Status: PaymentStatusCompleted}
+	if err := completed.CompleteForRefund(paidAt); !errors.Is(err, ErrPaymentNotClosed) {
+		t.Fatalf("expected ErrPaymentNotClosed, got %v", err)
+	}
+}
@@ -14,14 +14,18 @@ import (
 type Product struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`                                    // 商品ID，主键
 	Name          string         `json:"name" gorm:"size:200;not null;index"`                    // 商品名称，添加索引便于搜索
+	Brand         string         `json:"brand" gorm:"size:100;index"`                            // 品牌，用于筛选和分面统计
 	Description   *string        `json:"description" gorm:"type:text"`                           // 商品描述，使用TEXT类型
 	CategoryID    uint           `json:"category_id" gorm:"not null;index"`                      // 分类ID，外键，添加索引
 	Price         float64        `json:"price" gorm:"type:decimal(10,2);not null;index"`         // 商品价格，使用DECIMAL类型确保精度
 	OriginalPrice *float64       `json:"original_price" gorm:"type:decimal(10,2)"`               // 原价
-	Stock         int            `json:"stock" gorm:"not null;default:0"`                        // 库存数量
+	Stock         int            `json:"stock" gorm:"not null;default:0"`                        // 库存数量（包含已预占的库存）
+	ReservedStock int            `json:"reserved_stock" gorm:"not null;default:0"`               // 已预占库存，待支付订单占用
+	Available     int            `json:"available_stock" gorm:"-"`                               // 可售库存 = 库存 - 已预占库存
 	SalesCount    int            `json:"sales_count" gorm:"default:0;index"`                     // 销售数量，添加索引便于排序
 	MainImage     *string        `json:"main_image" gorm:"size:255"`                             // 主图片URL
 	Images        JSONArray `json:"images" gorm:"type:json"`                               // 商品图片列表，JSON格式
+	Specs         JSONSpecs      `json:"specs,omitempty" gorm:"type:json"`                       // 规格属性定义，为空表示没有规格
 	Status        int            `json:"status" gorm:"default:1;index"`                          // 商品状态，添加索引
 	CreatedAt     time.Time      `json:"created_at" gorm:"index"`                                // 创建时间，添加索引便于排序
 	UpdatedAt     time.Time      `json:"updated_at"`                                             // 更新时间
@@ -29,6 +33,31 @@ type Product struct {
 
 	// 关联关系
 	Category      Category       `json:"category,omitempty" gorm:"foreignKey:CategoryID"`        // 所属分类
+	Skus          []ProductSku   `json:"skus,omitempty" gorm:"foreignKey:ProductID"`             // 上架的SKU，与Specs组成规格矩阵
+
+	// 商品详情中的评价汇总，不存储
+	Rating        *ProductRatingSummary `json:"rating,omitempty" gorm:"-"`                       // 评分汇总
+}
+
+// HasSkus 商品是否启用了规格
+// 启用规格的商品按SKU下单，价格和库存以SKU为准
+func (p *Product) HasSkus() bool {
+	return len(p.Specs) > 0
+}
+
+// AfterFind 查询后计算可售库存
+func (p *Product) AfterFind(tx *gorm.DB) error {
+	p.Available = p.AvailableStock()
+	return nil
+}
+
+// AvailableStock 可售库存
+// 待支付订单预占的库存不能再卖出，支付后才从库存中扣减
+func (p *Product) AvailableStock() int {
+	if available := p.Stock - p.ReservedStock; available > 0 {
+		return available
+	}
+	return 0
 }
 
 // Category 商品分类模型
@@ -84,6 +113,9 @@ type ProductListRequest struct {
 	MaxPrice   *float64 `form:"max_price" binding:"omitempty,min=0"`    // 最高价格
 	SortBy     string `form:"sort_by,default=created_at"`               // 排序字段
 	SortOrder  string `form:"sort_order,default=desc"`                 // 排序方向：asc, desc
+	Brands     []string `form:"brand"`                                  // 品牌，可多选
+	Attrs      []string `form:"attr"`                                   // 规格属性，格式 名称:取值，如 颜色:红色，可多个
+	InStock    bool     `form:"in_stock"`                               // 只看有货
 }
 
 // ProductListResponse 商品列表响应
@@ -93,11 +125,13 @@ type ProductListResponse struct {
 	Page       int        `json:"page"`        // 当前页码
 	PageSize   int        `json:"page_size"`   // 每页数量
 	TotalPages int        `json:"total_pages"` // 总页数
+	Facets     *ProductFacets `json:"facets"`    // 分面统计
 }
 
 // ProductCreateRequest 创建商品请求
 type ProductCreateRequest struct {
 	Name          string   `json:"name" binding:"required,max=200"`
+	Brand         string   `json:"brand" binding:"max=100"`
 	Description   string   `json:"description"`
 	CategoryID    uint     `json:"category_id" binding:"required"`
 	Price         float64  `json:"price" binding:"required,min=0"`
@@ -110,6 +144,7 @@ type ProductCreateRequest struct {
 // ProductUpdateRequest 更新商品请求
 type ProductUpdateRequest struct {
 	Name          *string  `json:"name" binding:"omitempty,max=200"`
+	Brand         *string  `json:"brand" binding:"omitempty,max=100"`
 	Description   *string  `json:"description"`
 	CategoryID    *uint    `json:"category_id"`
 	Price         *float64 `json:"price" binding:"omitempty,min=0"`
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PriceRangeBounds 价格分面的区间边界，划分为 [0,100) [100,500) [500,1000) [1000,5000) [5000,+∞)
+var PriceRangeBounds = []float64{100, 500, 1000, 5000}
+
+// AttrFilter 规格属性筛选条件，同一属性的多个取值为或，不同属性之间为与
+type AttrFilter struct {
+	Name   string   // 属性名
+	Values []string // 属性取值
+}
+
+// ParseAttrFilters 解析规格属性筛选参数，格式 名称:取值，如 颜色:红色
+// 按属性名合并，保持参数中属性名首次出现的顺序
+func ParseAttrFilters(attrs []string) ([]AttrFilter, error) {
+	var filters []AttrFilter
+	index := make(map[string]int)
+	for _, attr := range attrs {
+		parts := strings.SplitN(attr, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("规格属性筛选格式错误：%s，应为 名称:取值", attr)
+		}
+		name, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if name == "" || value == "" || strings.ContainsAny(name+value, ":;") {
+			return nil, fmt.Errorf("规格属性筛选格式错误：%s，应为 名称:取值", attr)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(filters)
+			index[name] = i
+			filters = append(filters, AttrFilter{Name: name})
+		}
+		filters[i].Values = append(filters[i].Values, value)
+	}
+	return filters, nil
+}
+
+// ProductFacets 商品列表的分面统计
+// 每个分面的计数应用除自身以外的全部筛选条件，便于在已选条件下切换同一分面的其他取值
+type ProductFacets struct {
+	PriceRanges []PriceRangeFacet `json:"price_ranges"` // 价格区间
+	Categories  []CategoryFacet   `json:"categories"`   // 分类
+	Brands      []ValueFacet      `json:"brands"`       // 品牌
+	Attributes  []AttributeFacet  `json:"attributes"`   // 规格属性，只统计上架的SKU
+}
+
+// PriceRangeFacet 价格区间分面，Max为空表示不设上限
+type PriceRangeFacet struct {
+	Min   float64  `json:"min"`   // 最低价格（包含）
+	Max   *float64 `json:"max"`   // 最高价格（不包含）
+	Count int64    `json:"count"` // 商品数量
+}
+
+// CategoryFacet 分类分面
+type CategoryFacet struct {
+	ID    uint   `json:"id"`    // 分类ID
+	Name  string `json:"name"`  // 分类名称
+	Count int64  `json:"count"` // 商品数量
+}
+
+// ValueFacet 取值分面
+type ValueFacet struct {
+	Value string `json:"value"` // 取值
+	Count int64  `json:"count"` // 商品数量
+}
+
+// AttributeFacet 规格属性分面
+type AttributeFacet struct {
+	Name   string       `json:"name"`   // 属性名
+	Values []ValueFacet `json:"values"` // 各取值的商品数量
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// 热度排行时间窗口常量
+const (
+	RankingWindowDaily  = "daily"  // 最近24小时，按小时衰减
+	RankingWindowWeekly = "weekly" // 最近7天，按天衰减
+)
+
+// RankingWindows 支持的热度排行时间窗口
+var RankingWindows = []string{RankingWindowDaily, RankingWindowWeekly}
+
+// 热度信号权重：浏览、加入购物车、支付订单对商品热度的贡献
+const (
+	RankingWeightView     = 1.0 // 浏览一次
+	RankingWeightCart     = 3.0 // 加入购物车一次
+	RankingWeightPurchase = 5.0 // 每件支付的商品
+)
+
+// HotProductsRequest 热门商品请求
+type HotProductsRequest struct {
+	Window     string `form:"window" binding:"omitempty,oneof=daily weekly"` // 时间窗口，默认daily
+	CategoryID uint   `form:"category_id"`                                   // 分类ID，为空时为全站排行
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=100"`       // 返回数量，默认10
+}
+
+// RankedProduct 热门商品排行中的一项
+type RankedProduct struct {
+	*Product
+	Rank  int     `json:"rank"`  // 名次，从1开始
+	Score float64 `json:"score"` // 衰减后的热度，按销量补足的商品为0
+}
+
+// HotProductsResponse 热门商品响应
+type HotProductsResponse struct {
+	Window     string           `json:"window"`      // 时间窗口
+	CategoryID uint             `json:"category_id"` // 分类ID，0为全站排行
+	Products   []*RankedProduct `json:"products"`    // 热门商品
+}
+
+// OrderedProduct 订单中商品的购买件数，支付后计入热度排行
+type OrderedProduct struct {
+	ProductID  uint // 商品ID
+	CategoryID uint // 分类ID
+	Quantity   int  // 购买件数
+}
+
+// ProductRankingSnapshot 热度排行快照，定时从Redis写回，用于分析热度趋势
+type ProductRankingSnapshot struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`                                                       // 快照ID
+	SnapshotAt time.Time `json:"snapshot_at" gorm:"not null;uniqueIndex:idx_ranking_snapshot,priority:1"`    // 快照时间，按小时取整
+	Window     string    `json:"window" gorm:"size:20;not null;uniqueIndex:idx_ranking_snapshot,priority:2"` // 时间窗口
+	CategoryID uint      `json:"category_id" gorm:"not null;uniqueIndex:idx_ranking_snapshot,priority:3"`    // 分类ID，0为全站排行
+	Rank       int       `json:"rank" gorm:"not null;uniqueIndex:idx_ranking_snapshot,priority:4"`           // 名次
+	ProductID  uint      `json:"product_id" gorm:"not null;index"`                                           // 商品ID
+	Score      float64   `json:"score" gorm:"not null"`                                                      // 衰减后的热度
+	CreatedAt  time.Time `json:"created_at"`                                                                 // 创建时间
+}
+
+// TableName 指定表名
+func (ProductRankingSnapshot) TableName() string {
+	return "product_ranking_snapshots"
+}
@@ -0,0 +1,112 @@
+package model
+
+import "time"
+
+// 搜索排序方式常量
+const (
+	SearchSortRelevance = "relevance"  // 相关度（结合销量）
+	SearchSortSales     = "sales"      // 销量从高到低
+	SearchSortPriceAsc  = "price_asc"  // 价格从低到高
+	SearchSortPriceDesc = "price_desc" // 价格从高到低
+	SearchSortNewest    = "newest"     // 最新上架
+)
+
+// 搜索引擎常量，标识搜索结果的来源
+const (
+	SearchEngineElasticsearch = "elasticsearch" // Elasticsearch全文检索
+	SearchEngineDatabase      = "database"      // Elasticsearch不可用时降级为数据库模糊查询
+)
+
+// ProductDocument 商品搜索索引文档
+// 只保存检索、筛选、排序用到的字段，搜索结果按ID回表获取最新的价格和库存
+type ProductDocument struct {
+	ID             uint      `json:"id"`              // 商品ID
+	Name           string    `json:"name"`            // 商品名称
+	Brand          string    `json:"brand"`           // 品牌
+	Description    string    `json:"description"`     // 商品描述
+	CategoryID     uint      `json:"category_id"`     // 分类ID
+	CategoryName   string    `json:"category_name"`   // 分类名称
+	SpecValues     []string  `json:"spec_values"`     // 规格值，如红色、XL
+	Price          float64   `json:"price"`           // 价格，启用规格的商品为最低SKU价格
+	SalesCount     int       `json:"sales_count"`     // 销售数量
+	AvailableStock int       `json:"available_stock"` // 可售库存
+	MainImage      string    `json:"main_image"`      // 主图片URL
+	CreatedAt      time.Time `json:"created_at"`      // 创建时间
+}
+
+// NewProductDocument 根据商品生成搜索索引文档
+func NewProductDocument(p *Product) *ProductDocument {
+	doc := &ProductDocument{
+		ID:             p.ID,
+		Name:           p.Name,
+		Brand:          p.Brand,
+		CategoryID:     p.CategoryID,
+		CategoryName:   p.Category.Name,
+		Price:          p.Price,
+		SalesCount:     p.SalesCount,
+		AvailableStock: p.AvailableStock(),
+		CreatedAt:      p.CreatedAt,
+	}
+	if p.Description != nil {
+		doc.Description = *p.Description
+	}
+	if p.MainImage != nil {
+		doc.MainImage = *p.MainImage
+	}
+	for _, spec := range p.Specs {
+		doc.SpecValues = append(doc.SpecValues, spec.Values...)
+	}
+	return doc
+}
+
+// ProductSearchRequest 商品搜索请求
+type ProductSearchRequest struct {
+	Keyword    string   `form:"keyword" binding:"max=100"`                                                          // 搜索关键词，为空时按筛选条件浏览
+	CategoryID *uint    `form:"category_id"`                                                                        // 分类ID
+	MinPrice   *float64 `form:"min_price" binding:"omitempty,min=0"`                                                // 最低价格
+	MaxPrice   *float64 `form:"max_price" binding:"omitempty,min=0"`                                                // 最高价格
+	Sort       string   `form:"sort,default=relevance" binding:"oneof=relevance sales price_asc price_desc newest"` // 排序方式
+	Page       int      `form:"page,default=1" binding:"min=1"`                                                     // 页码
+	PageSize   int      `form:"page_size,default=10" binding:"min=1,max=100"`                                       // 每页数量
+}
+
+// ProductSearchItem 搜索结果中的一个商品
+type ProductSearchItem struct {
+	*Product
+	Highlight map[string][]string `json:"highlight,omitempty"` // 高亮片段，关键词用<em>标签包裹，键为name、description
+}
+
+// ProductSearchResponse 商品搜索响应
+type ProductSearchResponse struct {
+	Products   []*ProductSearchItem `json:"products"`    // 商品列表
+	Total      int64                `json:"total"`       // 总数量
+	Page       int                  `json:"page"`        // 当前页码
+	PageSize   int                  `json:"page_size"`   // 每页数量
+	TotalPages int                  `json:"total_pages"` // 总页数
+	Engine     string               `json:"engine"`      // 搜索结果来源：elasticsearch、database
+}
+
+// 搜索建议类型常量
+const (
+	SuggestionTypeProduct  = "product"  // 商品名称
+	SuggestionTypeCategory = "category" // 分类名称
+	SuggestionTypeBrand    = "brand"    // 品牌
+)
+
+// SuggestRequest 搜索建议请求
+type SuggestRequest struct {
+	Q     string `form:"q" binding:"required,max=50"`             // 已输入的内容，支持汉字、拼音首字母
+	Limit int    `form:"limit,default=10" binding:"min=1,max=20"` // 返回数量
+}
+
+// Suggestion 一条搜索建议
+type Suggestion struct {
+	Text string `json:"text"`         // 建议的搜索词
+	Type string `json:"type"`         // 类型：product、category、brand
+	ID   uint   `json:"id,omitempty"` // 商品ID或分类ID
+}
+
+// HotSearchRequest 热门搜索词请求
+type HotSearchRequest struct {
+	Limit int `form:"limit,default=10" binding:"min=1,max=50"` // 返回数量
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProduct_AvailableStock(t *testing.T) {
+	cases := []struct {
+		stock, reserved, want int
+	}{
+		{stock: 10, reserved: 0, want: 10},
+		{stock: 10, reserved: 3, want: 7},
+		{stock: 3, reserved: 3, want: 0},
+		// 数据不一致导致预占库存超过库存时不返回负数
+		{stock: 2, reserved: 5, want: 0},
+	}
+	for _, c := range cases {
+		p := &Product{Stock: c.stock, ReservedStock: c.reserved}
+		if got := p.AvailableStock(); got != c.want {
+			t.Errorf("stock=%d reserved=%d: got %d, want %d", c.stock, c.reserved, got, c.want)
+		}
+	}
+}
+
+func TestParseAttrFilters(t *testing.T) {
+	filters, err := ParseAttrFilters([]string{"颜色:红色", "尺码: M ", "颜色:黑色"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []AttrFilter{
+		{Name: "颜色", Values: []string{"红色", "黑色"}},
+		{Name: "尺码", Values: []string{"M"}},
+	}
+	if !reflect.DeepEqual(filters, want) {
+		t.Fatalf("got %+v, want %+v", filters, want)
+	}
+
+	for _, attr := range []string{"颜色", "颜色:", ":红色", "颜色:红;尺码:M"} {
+		if _, err := ParseAttrFilters([]string{attr}); err == nil {
+			t.Errorf("%q: expected error", attr)
+		}
+	}
+}
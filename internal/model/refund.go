@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+// RefundStatus 售后退款申请状态
+type RefundStatus int
+
+const (
+	RefundStatusPending    RefundStatus = 1 // 待审核
+	RefundStatusProcessing RefundStatus = 2 // 审核通过，退款处理中
+	RefundStatusRefunded   RefundStatus = 3 // 已退款
+	RefundStatusRejected   RefundStatus = 4 // 已拒绝
+)
+
+// Refund 售后退款申请模型
+// 用户申请整单或按商品退款，管理员审核通过后原路退款
+type Refund struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`                             // 退款申请ID
+	RefundNo     string       `json:"refund_no" gorm:"uniqueIndex;size:32;not null"`    // 退款单号
+	OrderID      uint         `json:"order_id" gorm:"not null;index"`                   // 订单ID
+	UserID       uint         `json:"user_id" gorm:"not null;index"`                    // 申请用户ID
+	Amount       float64      `json:"amount" gorm:"type:decimal(10,2);not null"`        // 退款金额，包含退还的运费
+	ShippingFee  float64      `json:"shipping_fee" gorm:"type:decimal(10,2);default:0"` // 退还的运费，退还最后的商品时才有
+	Reason       string       `json:"reason" gorm:"size:500;not null"`                  // 退款原因
+	Status       RefundStatus `json:"status" gorm:"default:1;index"`                    // 申请状态
+	OrderStatus  OrderStatus  `json:"-" gorm:"not null"`                                // 申请前的订单状态，拒绝后恢复
+	Restock      bool         `json:"restock" gorm:"default:false"`                     // 是否退回库存，审核通过时选择
+	ReviewerID   *uint        `json:"reviewer_id"`                                      // 审核人ID
+	ReviewRemark string       `json:"review_remark" gorm:"size:500"`                    // 审核备注
+	PaymentRefID string       `json:"payment_ref_id" gorm:"size:64"`                    // 渠道退款单号
+	ReviewedAt   *time.Time   `json:"reviewed_at"`                                      // 审核时间
+	CreatedAt    time.Time    `json:"created_at" gorm:"index"`                          // 申请时间
+	UpdatedAt    time.Time    `json:"updated_at"`                                       // 更新时间
+
+	// 关联关系
+	Items []RefundItem `json:"items,omitempty" gorm:"foreignKey:RefundID"` // 退款商品
+}
+
+// RefundItem 退款商品模型
+type RefundItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`                      // 退款商品ID
+	RefundID    uint      `json:"refund_id" gorm:"not null;index"`           // 退款申请ID
+	OrderItemID uint      `json:"order_item_id" gorm:"not null;index"`       // 订单商品ID
+	ProductID   uint      `json:"product_id" gorm:"not null"`                // 商品ID
+	SkuID       uint      `json:"sku_id" gorm:"not null;default:0"`          // SKU ID
+	Quantity    int       `json:"quantity" gorm:"not null"`                  // 退款数量
+	Amount      float64   `json:"amount" gorm:"type:decimal(10,2);not null"` // 退款金额
+	CreatedAt   time.Time `json:"created_at"`                                // 创建时间
+}
+
+// CreateRefundRequest 申请退款请求
+// Items为空表示整单退款（退还全部剩余商品）
+type CreateRefundRequest struct {
+	Reason string              `json:"reason" binding:"required,max=500"` // 退款原因
+	Items  []RefundItemRequest `json:"items" binding:"omitempty,dive"`    // 按商品退款明细
+}
+
+// RefundItemRequest 按商品退款明细
+type RefundItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`  // 订单商品ID
+	Quantity    int  `json:"quantity" binding:"required,min=1"` // 退款数量
+}
+
+// ReviewRefundRequest 审核退款请求
+type ReviewRefundRequest struct {
+	Restock bool   `json:"restock"`                  // 是否退回库存（仅审核通过时有效）
+	Remark  string `json:"remark" binding:"max=500"` // 审核备注
+}
+
+// RefundListRequest 退款申请列表查询请求
+type RefundListRequest struct {
+	Page     int           `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize int           `form:"page_size,default=10" binding:"min=1,max=100"` // 每页数量
+	Status   *RefundStatus `form:"status"`                                       // 状态筛选
+}
+
+// RefundListResponse 退款申请列表响应
+type RefundListResponse struct {
+	Refunds    []*Refund `json:"refunds"`     // 退款申请列表
+	Total      int       `json:"total"`       // 总数量
+	Page       int       `json:"page"`        // 当前页码
+	PageSize   int       `json:"page_size"`   // 每页数量
+	TotalPages int       `json:"total_pages"` // 总页数
+}
+
+// GetRefundStatusText 获取退款申请状态文本
+func GetRefundStatusText(status RefundStatus) string {
+	switch status {
+	case RefundStatusPending:
+		return "待审核"
+	case RefundStatusProcessing:
+		return "退款中"
+	case RefundStatusRefunded:
+		return "已退款"
+	case RefundStatusRejected:
+		return "已拒绝"
+	default:
+		return "未知状态"
+	}
+}
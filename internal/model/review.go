@@ -0,0 +1,154 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// ReviewStatus 商品评价审核状态
+type ReviewStatus int
+
+const (
+	ReviewStatusPending  ReviewStatus = 1 // 待审核
+	ReviewStatusApproved ReviewStatus = 2 // 审核通过，公开展示
+	ReviewStatusRejected ReviewStatus = 3 // 已驳回
+)
+
+// 评价排序方式常量
+const (
+	ReviewSortNewest  = "newest"  // 最新发表
+	ReviewSortHelpful = "helpful" // 有用数最多
+)
+
+// ProductReview 商品评价模型
+// 只有确认收货的订单商品才能评价，每个订单商品只能评价一次，审核通过后公开展示
+type ProductReview struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`                                              // 评价ID
+	ProductID    uint         `json:"product_id" gorm:"not null;index:idx_review_product,priority:1"`    // 商品ID
+	SkuID        uint         `json:"sku_id" gorm:"not null;default:0"`                                  // SKU ID，商品没有规格时为0
+	SpecKey      string       `json:"spec_key" gorm:"size:255"`                                          // 购买的规格（冗余存储）
+	OrderID      uint         `json:"order_id" gorm:"not null;index"`                                    // 订单ID
+	OrderItemID  uint         `json:"order_item_id" gorm:"not null;uniqueIndex"`                         // 订单商品ID，每个订单商品只能评价一次
+	UserID       uint         `json:"user_id" gorm:"not null;index"`                                     // 评价用户ID
+	UserName     string       `json:"user_name" gorm:"size:50;not null"`                                 // 评价用户名（脱敏）
+	Rating       int          `json:"rating" gorm:"not null"`                                            // 评分，1-5星
+	Content      string       `json:"content" gorm:"size:1000"`                                          // 评价内容
+	Images       JSONArray    `json:"images" gorm:"type:json"`                                           // 评价图片
+	Status       ReviewStatus `json:"status" gorm:"default:1;index:idx_review_product,priority:2;index"` // 审核状态
+	RejectReason string       `json:"reject_reason,omitempty" gorm:"size:500"`                           // 驳回原因
+	ModeratorID  *uint        `json:"-"`                                                                 // 审核人ID
+	ModeratedAt  *time.Time   `json:"-"`                                                                 // 审核时间
+	Reply        string       `json:"reply,omitempty" gorm:"size:1000"`                                  // 商家回复
+	RepliedAt    *time.Time   `json:"replied_at,omitempty"`                                              // 商家回复时间
+	HelpfulCount int          `json:"helpful_count" gorm:"not null;default:0"`                           // 有用数
+	CreatedAt    time.Time    `json:"created_at"`                                                        // 发表时间
+	UpdatedAt    time.Time    `json:"updated_at"`                                                        // 更新时间
+}
+
+// TableName 指定表名
+func (ProductReview) TableName() string {
+	return "product_reviews"
+}
+
+// ReviewHelpfulVote 评价的有用投票，每个用户对每条评价只能投一次
+type ReviewHelpfulVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`                                             // 投票ID
+	ReviewID  uint      `json:"review_id" gorm:"not null;uniqueIndex:idx_review_user,priority:1"` // 评价ID
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_review_user,priority:2"`   // 投票用户ID
+	CreatedAt time.Time `json:"created_at"`                                                       // 投票时间
+}
+
+// TableName 指定表名
+func (ReviewHelpfulVote) TableName() string {
+	return "review_helpful_votes"
+}
+
+// ProductRatingSummary 商品评分汇总，只统计审核通过的评价
+type ProductRatingSummary struct {
+	Average      float64       `json:"average"`      // 平均评分，保留一位小数
+	Count        int64         `json:"count"`        // 评价数量
+	Distribution map[int]int64 `json:"distribution"` // 各星级的评价数量，1-5星都有
+}
+
+// NewProductRatingSummary 根据各星级的评价数量生成评分汇总
+func NewProductRatingSummary(counts map[int]int64) *ProductRatingSummary {
+	summary := &ProductRatingSummary{Distribution: make(map[int]int64, 5)}
+	var total int64
+	for rating := 1; rating <= 5; rating++ {
+		count := counts[rating]
+		summary.Distribution[rating] = count
+		summary.Count += count
+		total += int64(rating) * count
+	}
+	if summary.Count > 0 {
+		summary.Average = math.Round(float64(total)/float64(summary.Count)*10) / 10
+	}
+	return summary
+}
+
+// MaskUserName 评价中展示的用户名，只保留首尾字符，如 z***n
+func MaskUserName(name string) string {
+	runes := []rune(name)
+	switch {
+	case len(runes) == 0:
+		return "匿名用户"
+	case len(runes) <= 2:
+		return string(runes[:1]) + "***"
+	default:
+		return string(runes[:1]) + "***" + string(runes[len(runes)-1:])
+	}
+}
+
+// CreateReviewRequest 发表评价请求
+type CreateReviewRequest struct {
+	OrderItemID uint     `json:"order_item_id" binding:"required"`                  // 订单商品ID
+	Rating      int      `json:"rating" binding:"required,min=1,max=5"`             // 评分，1-5星
+	Content     string   `json:"content" binding:"max=1000"`                        // 评价内容
+	Images      []string `json:"images" binding:"omitempty,max=9,dive,url,max=255"` // 评价图片URL，最多9张
+}
+
+// ProductReviewListRequest 商品评价列表查询请求
+type ProductReviewListRequest struct {
+	Page       int    `form:"page,default=1" binding:"min=1"`                // 页码
+	PageSize   int    `form:"page_size,default=10" binding:"min=1,max=100"`  // 每页数量
+	Rating     int    `form:"rating" binding:"omitempty,min=1,max=5"`        // 按星级筛选
+	WithImages bool   `form:"with_images"`                                   // 只看有图评价
+	Sort       string `form:"sort" binding:"omitempty,oneof=newest helpful"` // 排序方式，默认最新发表
+}
+
+// ProductReviewListResponse 商品评价列表响应
+type ProductReviewListResponse struct {
+	Summary    *ProductRatingSummary `json:"summary"`     // 评分汇总
+	Reviews    []*ProductReview      `json:"reviews"`     // 评价列表
+	Total      int                   `json:"total"`       // 总数量
+	Page       int                   `json:"page"`        // 当前页码
+	PageSize   int                   `json:"page_size"`   // 每页数量
+	TotalPages int                   `json:"total_pages"` // 总页数
+}
+
+// ReviewListRequest 评价管理列表查询请求，用户查询自己的评价时忽略商品筛选
+type ReviewListRequest struct {
+	Page      int           `form:"page,default=1" binding:"min=1"`               // 页码
+	PageSize  int           `form:"page_size,default=10" binding:"min=1,max=100"` // 每页数量
+	Status    *ReviewStatus `form:"status"`                                       // 状态筛选
+	ProductID uint          `form:"product_id"`                                   // 商品筛选
+}
+
+// ReviewListResponse 评价管理列表响应
+type ReviewListResponse struct {
+	Reviews    []*ProductReview `json:"reviews"`     // 评价列表
+	Total      int              `json:"total"`       // 总数量
+	Page       int              `json:"page"`        // 当前页码
+	PageSize   int              `json:"page_size"`   // 每页数量
+	TotalPages int              `json:"total_pages"` // 总页数
+}
+
+// ModerateReviewRequest 审核评价请求
+type ModerateReviewRequest struct {
+	Reason string `json:"reason" binding:"max=500"` // 驳回原因
+}
+
+// ReplyReviewRequest 商家回复评价请求
+type ReplyReviewRequest struct {
+	Content string `json:"content" binding:"required,max=1000"` // 回复内容
+}
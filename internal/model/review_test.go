@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+func TestNewProductRatingSummary(t *testing.T) {
+	summary := NewProductRatingSummary(map[int]int64{5: 3, 4: 1, 1: 1})
+	if summary.Count != 5 {
+		t.Errorf("count: got %d", summary.Count)
+	}
+	// (5*3 + 4 + 1) / 5 = 4.0
+	if summary.Average != 4.0 {
+		t.Errorf("average: got %v", summary.Average)
+	}
+	// 没有评价的星级也要返回
+	if len(summary.Distribution) != 5 || summary.Distribution[2] != 0 || summary.Distribution[5] != 3 {
+		t.Errorf("distribution: got %v", summary.Distribution)
+	}
+
+	empty := NewProductRatingSummary(nil)
+	if empty.Count != 0 || empty.Average != 0 || len(empty.Distribution) != 5 {
+		t.Errorf("empty: got %+v", empty)
+	}
+}
+
+func TestMaskUserName(t *testing.T) {
+	cases := map[string]string{
+		"":     "匿名用户",
+		"ab":   "a***",
+		"ryan": "r***n",
+		"张三丰":  "张***丰",
+	}
+	for name, want := range cases {
+		if got := MaskUserName(name); got != want {
+			t.Errorf("%q: got %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import "time"
+
+// RoleAdmin 内置的超级管理员角色，拥有全部权限，不能删除或修改权限
+const RoleAdmin = "admin"
+
+// 权限编码
+const (
+	PermissionProductManage  = "product:manage"  // 管理商品和商品规格
+	PermissionCategoryManage = "category:manage" // 管理商品分类
+	PermissionStockManage    = "stock:manage"    // 管理库存和仓库
+	PermissionRoleManage     = "role:manage"     // 管理角色和用户角色
+	PermissionOrderManage    = "order:manage"    // 查询全部订单、强制变更订单状态
+	PermissionUserManage     = "user:manage"     // 查询用户、禁用用户
+	PermissionDashboardView  = "dashboard:view"  // 查看销售看板
+	PermissionAuditView      = "audit:view"      // 查看管理后台审计日志
+	PermissionOrderView      = "order:view"      // 查看任意订单的时间线
+	PermissionRefundManage   = "refund:manage"   // 审核售后退款
+	PermissionShipmentManage = "shipment:manage" // 订单发货、模拟物流轨迹
+	PermissionCouponManage   = "coupon:manage"   // 管理和发放优惠券
+	PermissionReviewManage   = "review:manage"   // 审核和回复商品评价
+)
+
+// BuiltinPermissions 系统内置的权限，启动时写入权限表
+var BuiltinPermissions = []Permission{
+	{Code: PermissionProductManage, Description: "管理商品和商品规格"},
+	{Code: PermissionCategoryManage, Description: "管理商品分类"},
+	{Code: PermissionStockManage, Description: "管理库存和仓库"},
+	{Code: PermissionRoleManage, Description: "管理角色和用户角色"},
+	{Code: PermissionOrderManage, Description: "查询全部订单、强制变更订单状态"},
+	{Code: PermissionUserManage, Description: "查询用户、禁用用户"},
+	{Code: PermissionDashboardView, Description: "查看销售看板"},
+	{Code: PermissionAuditView, Description: "查看管理后台审计日志"},
+	{Code: PermissionOrderView, Description: "查看任意订单的时间线"},
+	{Code: PermissionRefundManage, Description: "审核售后退款"},
+	{Code: PermissionShipmentManage, Description: "订单发货、模拟物流轨迹"},
+	{Code: PermissionCouponManage, Description: "管理和发放优惠券"},
+	{Code: PermissionReviewManage, Description: "审核和回复商品评价"},
+}
+
+// Permission 权限模型
+type Permission struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`                     // 权限ID
+	Code        string `json:"code" gorm:"uniqueIndex;size:64;not null"` // 权限编码，如 product:manage
+	Description string `json:"description" gorm:"size:200"`              // 说明
+}
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role 角色模型
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`                          // 角色ID
+	Name        string       `json:"name" gorm:"uniqueIndex;size:50;not null"`      // 角色名称，如 admin
+	Description string       `json:"description" gorm:"size:200"`                   // 说明
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions"` // 角色拥有的权限
+	CreatedAt   time.Time    `json:"created_at"`                                    // 创建时间
+	UpdatedAt   time.Time    `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// IsBuiltin 是否为内置角色
+func (r *Role) IsBuiltin() bool {
+	return r.Name == RoleAdmin
+}
+
+// UserRole 用户角色关联模型
+type UserRole struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`       // 用户ID
+	RoleID    uint      `json:"role_id" gorm:"primaryKey;autoIncrement:false;index"` // 角色ID
+	CreatedAt time.Time `json:"created_at"`                                          // 分配时间
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// HasRole 角色列表中是否包含任一指定角色
+func HasRole(roles []string, wanted ...string) bool {
+	for _, role := range roles {
+		for _, w := range wanted {
+			if role == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`  // 角色名称
+	Description string   `json:"description" binding:"max=200"`   // 说明
+	Permissions []string `json:"permissions" binding:"omitempty"` // 权限编码列表
+}
+
+// UpdateRoleRequest 更新角色请求，未填写的字段保持不变
+type UpdateRoleRequest struct {
+	Description *string  `json:"description" binding:"omitempty,max=200"` // 说明
+	Permissions []string `json:"permissions"`                             // 权限编码列表，为nil时不修改
+}
+
+// SetUserRolesRequest 设置用户角色请求，覆盖用户原有的角色
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles"` // 角色名称列表，为空表示移除全部角色
+}
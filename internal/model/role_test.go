@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		roles  []string
+		wanted []string
+		want   bool
+	}{
+		{nil, []string{RoleAdmin}, false},
+		{[]string{"operator"}, []string{RoleAdmin}, false},
+		{[]string{"operator", RoleAdmin}, []string{RoleAdmin}, true},
+		{[]string{"operator"}, []string{RoleAdmin, "operator"}, true},
+		{[]string{"operator"}, nil, false},
+	}
+	for _, tt := range tests {
+		if got := HasRole(tt.roles, tt.wanted...); got != tt.want {
+			t.Errorf("HasRole(%v, %v) = %v, want %v", tt.roles, tt.wanted, got, tt.want)
+		}
+	}
+}
+
+func TestRole_IsBuiltin(t *testing.T) {
+	if !(&Role{Name: RoleAdmin}).IsBuiltin() {
+		t.Error("admin role should be builtin")
+	}
+	if (&Role{Name: "operator"}).IsBuiltin() {
+		t.Error("custom role should not be builtin")
+	}
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+// ShipmentStatus 包裹状态
+type ShipmentStatus int
+
+const (
+	ShipmentStatusShipped   ShipmentStatus = 1 // 已发货
+	ShipmentStatusInTransit ShipmentStatus = 2 // 运输中
+	ShipmentStatusDelivered ShipmentStatus = 3 // 已签收
+	ShipmentStatusException ShipmentStatus = 4 // 异常
+)
+
+// Shipment 包裹模型
+// 一个订单可以拆成多个包裹发货，每个包裹对应一个运单号
+type Shipment struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`                                                 // 包裹ID
+	OrderID     uint           `json:"order_id" gorm:"not null;index"`                                       // 订单ID
+	Carrier     string         `json:"carrier" gorm:"size:32;not null;uniqueIndex:idx_carrier_tracking"`     // 物流公司编码
+	TrackingNo  string         `json:"tracking_no" gorm:"size:64;not null;uniqueIndex:idx_carrier_tracking"` // 运单号
+	Status      ShipmentStatus `json:"status" gorm:"default:1;index"`                                        // 包裹状态
+	OperatorID  uint           `json:"operator_id"`                                                          // 发货人ID
+	ShippedAt   time.Time      `json:"shipped_at"`                                                           // 发货时间
+	LastEventAt *time.Time     `json:"last_event_at" gorm:"index"`                                           // 最近一条轨迹时间
+	DeliveredAt *time.Time     `json:"delivered_at"`                                                         // 签收时间
+	CreatedAt   time.Time      `json:"created_at"`                                                           // 创建时间
+	UpdatedAt   time.Time      `json:"updated_at"`                                                           // 更新时间
+
+	// 关联关系
+	Items  []ShipmentItem  `json:"items,omitempty" gorm:"foreignKey:ShipmentID"`  // 包裹商品
+	Events []ShipmentEvent `json:"events,omitempty" gorm:"foreignKey:ShipmentID"` // 物流轨迹
+}
+
+// ShipmentItem 包裹商品模型
+type ShipmentItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`                // 包裹商品ID
+	ShipmentID  uint      `json:"shipment_id" gorm:"not null;index"`   // 包裹ID
+	OrderItemID uint      `json:"order_item_id" gorm:"not null;index"` // 订单商品ID
+	ProductID   uint      `json:"product_id" gorm:"not null"`          // 商品ID
+	Quantity    int       `json:"quantity" gorm:"not null"`            // 数量
+	CreatedAt   time.Time `json:"created_at"`                          // 创建时间
+}
+
+// ShipmentEvent 物流轨迹模型
+type ShipmentEvent struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`              // 轨迹ID
+	ShipmentID  uint           `json:"shipment_id" gorm:"not null;index"` // 包裹ID
+	Status      ShipmentStatus `json:"status"`                            // 轨迹对应的包裹状态
+	Location    string         `json:"location" gorm:"size:100"`          // 所在地点
+	Description string         `json:"description" gorm:"size:255"`       // 轨迹描述
+	OccurredAt  time.Time      `json:"occurred_at" gorm:"index"`          // 发生时间
+	CreatedAt   time.Time      `json:"created_at"`                        // 接收时间
+}
+
+// ShipOrderRequest 发货请求，每次请求发出一个包裹
+// Items为空表示将订单剩余待发货商品全部放入该包裹
+type ShipOrderRequest struct {
+	Carrier    string                `json:"carrier" binding:"required"`            // 物流公司编码
+	TrackingNo string                `json:"tracking_no" binding:"required,max=64"` // 运单号
+	Items      []ShipmentItemRequest `json:"items" binding:"omitempty,dive"`        // 包裹商品
+}
+
+// ShipmentItemRequest 包裹商品明细
+type ShipmentItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`  // 订单商品ID
+	Quantity    int  `json:"quantity" binding:"required,min=1"` // 数量
+}
+
+// SimulateTrackingRequest 模拟物流轨迹请求
+type SimulateTrackingRequest struct {
+	Status      string `json:"status" binding:"required"` // 轨迹状态，如 in_transit、delivered
+	Location    string `json:"location"`                  // 所在地点
+	Description string `json:"description"`               // 轨迹描述
+}
+
+// GetShipmentStatusText 获取包裹状态文本
+func GetShipmentStatusText(status ShipmentStatus) string {
+	switch status {
+	case ShipmentStatusShipped:
+		return "已发货"
+	case ShipmentStatusInTransit:
+		return "运输中"
+	case ShipmentStatusDelivered:
+		return "已签收"
+	case ShipmentStatusException:
+		return "异常"
+	default:
+		return "未知状态"
+	}
+}
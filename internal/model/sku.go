@@ -0,0 +1,183 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 商品规格（SKU）状态常量
+const (
+	SkuStatusOffline = 0 // 下架，规格矩阵中已移除的组合
+	SkuStatusOnline  = 1 // 上架
+)
+
+// ProductSpec 商品规格属性定义，如颜色、尺码
+type ProductSpec struct {
+	Name   string   `json:"name" binding:"required,max=20"`       // 属性名
+	Values []string `json:"values" binding:"required,min=1,dive"` // 可选值
+}
+
+// JSONSpecs 商品规格属性定义列表，存储为JSON
+type JSONSpecs []ProductSpec
+
+// Scan 实现sql.Scanner接口
+func (s *JSONSpecs) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into JSONSpecs", value)
+	}
+
+	return json.Unmarshal(bytes, s)
+}
+
+// Value 实现driver.Valuer接口
+func (s JSONSpecs) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// JSONAttributes SKU的属性取值，属性名到取值的映射，存储为JSON
+type JSONAttributes map[string]string
+
+// Scan 实现sql.Scanner接口
+func (a *JSONAttributes) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into JSONAttributes", value)
+	}
+
+	return json.Unmarshal(bytes, a)
+}
+
+// Value 实现driver.Valuer接口
+func (a JSONAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// ProductSku 商品规格（SKU）模型
+// 商品（SPU）定义规格属性，每个属性取值组合对应一个SKU，有自己的价格、库存和图片。
+// 启用规格的商品，商品库存等于各SKU库存之和，商品价格为上架SKU的最低价
+type ProductSku struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`                                           // SKU ID
+	ProductID     uint           `json:"product_id" gorm:"not null;uniqueIndex:idx_product_spec"`        // 商品ID
+	SpecKey       string         `json:"spec_key" gorm:"size:255;not null;uniqueIndex:idx_product_spec"` // 属性组合，按规格定义顺序拼接，如 颜色:红;尺码:M
+	SkuCode       string         `json:"sku_code" gorm:"size:64;index"`                                  // SKU编码
+	Attributes    JSONAttributes `json:"attributes" gorm:"type:json"`                                    // 属性取值
+	Price         float64        `json:"price" gorm:"type:decimal(10,2);not null"`                       // 价格
+	Stock         int            `json:"stock" gorm:"not null;default:0"`                                // 库存数量（包含已预占的库存）
+	ReservedStock int            `json:"reserved_stock" gorm:"not null;default:0"`                       // 已预占库存
+	Available     int            `json:"available_stock" gorm:"-"`                                       // 可售库存
+	Image         *string        `json:"image" gorm:"size:255"`                                          // SKU图片，为空时使用商品主图
+	Status        int            `json:"status" gorm:"default:1;index"`                                  // SKU状态
+	CreatedAt     time.Time      `json:"created_at"`                                                     // 创建时间
+	UpdatedAt     time.Time      `json:"updated_at"`                                                     // 更新时间
+}
+
+// AfterFind 查询后计算可售库存
+func (s *ProductSku) AfterFind(tx *gorm.DB) error {
+	s.Available = s.AvailableStock()
+	return nil
+}
+
+// AvailableStock SKU可售库存
+func (s *ProductSku) AvailableStock() int {
+	if available := s.Stock - s.ReservedStock; available > 0 {
+		return available
+	}
+	return 0
+}
+
+// SpecKey 按规格定义的顺序拼接属性取值，作为SKU在商品内的唯一标识
+// 属性名或取值不在规格定义中、缺少属性时返回错误
+func (specs JSONSpecs) SpecKey(attributes map[string]string) (string, error) {
+	if len(attributes) != len(specs) {
+		return "", errors.New("SKU属性必须包含全部规格属性")
+	}
+
+	parts := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		value, ok := attributes[spec.Name]
+		if !ok {
+			return "", fmt.Errorf("SKU缺少规格属性 %s", spec.Name)
+		}
+		found := false
+		for _, v := range spec.Values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("规格属性 %s 没有取值 %s", spec.Name, value)
+		}
+		parts = append(parts, spec.Name+":"+value)
+	}
+	return strings.Join(parts, ";"), nil
+}
+
+// Validate 验证规格属性定义：属性名和同一属性的取值都不能重复
+func (specs JSONSpecs) Validate() error {
+	if len(specs) == 0 {
+		return errors.New("至少需要一个规格属性")
+	}
+
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if spec.Name == "" || strings.ContainsAny(spec.Name, ":;") {
+			return errors.New("规格属性名不能为空，且不能包含 : 或 ;")
+		}
+		if names[spec.Name] {
+			return fmt.Errorf("规格属性 %s 重复", spec.Name)
+		}
+		names[spec.Name] = true
+
+		values := make(map[string]bool, len(spec.Values))
+		for _, value := range spec.Values {
+			if value == "" || strings.ContainsAny(value, ":;") {
+				return fmt.Errorf("规格属性 %s 的取值不能为空，且不能包含 : 或 ;", spec.Name)
+			}
+			if values[value] {
+				return fmt.Errorf("规格属性 %s 的取值 %s 重复", spec.Name, value)
+			}
+			values[value] = true
+		}
+	}
+	return nil
+}
+
+// SkuRequest 规格矩阵中的一个SKU
+type SkuRequest struct {
+	Attributes map[string]string `json:"attributes" binding:"required"`     // 属性取值
+	SkuCode    string            `json:"sku_code" binding:"max=64"`         // SKU编码
+	Price      float64           `json:"price" binding:"required,min=0"`    // 价格
+	Stock      int               `json:"stock" binding:"min=0"`             // 库存数量
+	Image      *string           `json:"image" binding:"omitempty,max=255"` // SKU图片
+}
+
+// SetProductSkusRequest 设置商品规格矩阵请求
+// 整体替换：请求中没有的属性组合下架，已有组合更新价格、库存和图片
+type SetProductSkusRequest struct {
+	Specs []ProductSpec `json:"specs" binding:"required,min=1,dive"` // 规格属性定义
+	Skus  []SkuRequest  `json:"skus" binding:"required,min=1,dive"`  // SKU列表，每个属性组合一个
+}
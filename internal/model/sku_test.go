@@ -0,0 +1,49 @@
+package model
+
+import "testing"
+
+func TestJSONSpecs_SpecKey(t *testing.T) {
+	specs := JSONSpecs{
+		{Name: "颜色", Values: []string{"红", "蓝"}},
+		{Name: "尺码", Values: []string{"M", "L"}},
+	}
+
+	// 属性组合按规格定义的顺序拼接，与map的遍历顺序无关
+	key, err := specs.SpecKey(map[string]string{"尺码": "L", "颜色": "蓝"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "颜色:蓝;尺码:L" {
+		t.Errorf("got %q", key)
+	}
+
+	invalid := []map[string]string{
+		{"颜色": "红"},                       // 缺少属性
+		{"颜色": "红", "尺码": "XL"},           // 取值不在定义中
+		{"颜色": "红", "版本": "M"},            // 属性名不在定义中
+		{"颜色": "红", "尺码": "M", "版本": "A"}, // 多余属性
+	}
+	for _, attributes := range invalid {
+		if _, err := specs.SpecKey(attributes); err == nil {
+			t.Errorf("%v: expected error", attributes)
+		}
+	}
+}
+
+func TestJSONSpecs_Validate(t *testing.T) {
+	cases := []struct {
+		specs JSONSpecs
+		ok    bool
+	}{
+		{JSONSpecs{{Name: "颜色", Values: []string{"红", "蓝"}}}, true},
+		{JSONSpecs{}, false},
+		{JSONSpecs{{Name: "颜色", Values: []string{"红"}}, {Name: "颜色", Values: []string{"蓝"}}}, false},
+		{JSONSpecs{{Name: "颜色", Values: []string{"红", "红"}}}, false},
+		{JSONSpecs{{Name: "颜色", Values: []string{"红;蓝"}}}, false},
+	}
+	for i, c := range cases {
+		if err := c.specs.Validate(); (err == nil) != c.ok {
+			t.Errorf("case %d: got %v, want ok=%v", i, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package model
+
+import "time"
+
+// 仓库状态常量
+const (
+	WarehouseStatusDisabled = 0 // 停用，库存不参与分配
+	WarehouseStatusActive   = 1 // 启用
+)
+
+// UnassignedWarehouseID 未分配到仓库的库存
+// 商品库存 = 各仓库库存之和 + 未分配库存，未启用多仓的商品全部库存都是未分配库存
+const UnassignedWarehouseID uint = 0
+
+// Warehouse 仓库模型
+type Warehouse struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`                     // 仓库ID
+	Code           string    `json:"code" gorm:"size:32;not null;uniqueIndex"` // 仓库编码
+	Name           string    `json:"name" gorm:"size:100;not null"`            // 仓库名称
+	Province       string    `json:"province" gorm:"size:50;not null"`         // 所在省份
+	CoverProvinces JSONArray `json:"cover_provinces" gorm:"type:json"`         // 就近发货的其他省份
+	Status         int       `json:"status" gorm:"default:1;index"`            // 仓库状态
+	CreatedAt      time.Time `json:"created_at"`                               // 创建时间
+	UpdatedAt      time.Time `json:"updated_at"`                               // 更新时间
+}
+
+// Distance 仓库到收货省份的距离等级，越小越近
+// 0 同省，1 就近发货省份，2 其他省份
+func (w *Warehouse) Distance(province string) int {
+	if province != "" && w.Province == province {
+		return 0
+	}
+	for _, p := range w.CoverProvinces {
+		if p == province {
+			return 1
+		}
+	}
+	return 2
+}
+
+// WarehouseStock 仓库库存模型
+type WarehouseStock struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`                                               // 记录ID
+	WarehouseID   uint      `json:"warehouse_id" gorm:"not null;uniqueIndex:idx_warehouse_product"`     // 仓库ID
+	ProductID     uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_warehouse_product;index"` // 商品ID
+	Stock         int       `json:"stock" gorm:"not null;default:0"`                                    // 库存数量（包含已预占的库存）
+	ReservedStock int       `json:"reserved_stock" gorm:"not null;default:0"`                           // 已预占库存
+	CreatedAt     time.Time `json:"created_at"`                                                         // 创建时间
+	UpdatedAt     time.Time `json:"updated_at"`                                                         // 更新时间
+
+	// 关联关系
+	Warehouse Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID"` // 所属仓库
+}
+
+// AvailableStock 仓库可售库存
+func (s *WarehouseStock) AvailableStock() int {
+	if available := s.Stock - s.ReservedStock; available > 0 {
+		return available
+	}
+	return 0
+}
+
+// StockAllocation 订单商品在一个仓库的分配数量
+type StockAllocation struct {
+	WarehouseID uint // 仓库ID，UnassignedWarehouseID表示未分配库存
+	Quantity    int  // 分配数量
+}
+
+// CreateWarehouseRequest 创建仓库请求
+type CreateWarehouseRequest struct {
+	Code           string   `json:"code" binding:"required,max=32"`     // 仓库编码
+	Name           string   `json:"name" binding:"required,max=100"`    // 仓库名称
+	Province       string   `json:"province" binding:"required,max=50"` // 所在省份
+	CoverProvinces []string `json:"cover_provinces"`                    // 就近发货的其他省份
+}
+
+// UpdateWarehouseRequest 更新仓库请求
+type UpdateWarehouseRequest struct {
+	Name           *string  `json:"name" binding:"omitempty,max=100"`     // 仓库名称
+	Province       *string  `json:"province" binding:"omitempty,max=50"`  // 所在省份
+	CoverProvinces []string `json:"cover_provinces"`                      // 就近发货的其他省份
+	Status         *int     `json:"status" binding:"omitempty,oneof=0 1"` // 仓库状态
+}
+
+// SetWarehouseStockRequest 设置仓库库存请求
+type SetWarehouseStockRequest struct {
+	Stock *int `json:"stock" binding:"required,min=0"` // 库存数量
+}
+
+// ProductStockResponse 商品分仓库存（管理员）
+type ProductStockResponse struct {
+	ProductID     uint                    `json:"product_id"`      // 商品ID
+	Stock         int                     `json:"stock"`           // 总库存
+	ReservedStock int                     `json:"reserved_stock"`  // 总预占库存
+	Available     int                     `json:"available_stock"` // 总可售库存
+	Unassigned    ProductWarehouseStock   `json:"unassigned"`      // 未分配到仓库的库存
+	Warehouses    []ProductWarehouseStock `json:"warehouses"`      // 各仓库库存
+}
+
+// ProductWarehouseStock 商品在一个仓库的库存
+type ProductWarehouseStock struct {
+	WarehouseID   uint   `json:"warehouse_id"`    // 仓库ID
+	WarehouseCode string `json:"warehouse_code"`  // 仓库编码
+	WarehouseName string `json:"warehouse_name"`  // 仓库名称
+	Province      string `json:"province"`        // 所在省份
+	Status        int    `json:"status"`          // 仓库状态
+	Stock         int    `json:"stock"`           // 库存
+	ReservedStock int    `json:"reserved_stock"`  // 已预占库存
+	Available     int    `json:"available_stock"` // 可售库存
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AddressRepository 收货地址数据访问层接口
+type AddressRepository interface {
+	Create(address *model.Address) error               // 新增收货地址
+	GetByID(id uint) (*model.Address, error)           // 根据ID获取收货地址
+	GetByUserID(userID uint) ([]*model.Address, error) // 获取用户的所有收货地址
+	CountByUserID(userID uint) (int64, error)          // 统计用户的收货地址数量
+	Update(address *model.Address) error               // 更新收货地址
+	Delete(address *model.Address) error               // 删除收货地址
+	SetDefault(userID, addressID uint) error           // 设置默认地址
+	GetDefault(userID uint) (*model.Address, error)    // 获取用户的默认地址
+}
+
+// addressRepository 收货地址数据访问层实现
+type addressRepository struct {
+	db *gorm.DB
+}
+
+// NewAddressRepository 创建收货地址数据访问层实例
+func NewAddressRepository(db *gorm.DB) AddressRepository {
+	return &addressRepository{
+		db: db,
+	}
+}
+
+// Create 新增收货地址
+// 设为默认地址时在同一事务中取消其他默认地址
+func (r *addressRepository) Create(address *model.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+}
+
+// GetByID 根据ID获取收货地址
+func (r *addressRepository) GetByID(id uint) (*model.Address, error) {
+	var address model.Address
+
+	err := r.db.First(&address, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// GetByUserID 获取用户的所有收货地址，默认地址排在最前
+func (r *addressRepository) GetByUserID(userID uint) ([]*model.Address, error) {
+	var addresses []*model.Address
+
+	err := r.db.Where("user_id = ?", userID).
+		Order("is_default DESC, updated_at DESC").
+		Find(&addresses).Error
+
+	return addresses, err
+}
+
+// CountByUserID 统计用户的收货地址数量
+func (r *addressRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Address{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update 更新收货地址
+// 设为默认地址时在同一事务中取消其他默认地址
+func (r *addressRepository) Update(address *model.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(address).Error
+	})
+}
+
+// Delete 删除收货地址
+// 删除的是默认地址时，将最近更新的另一个地址设为默认
+func (r *addressRepository) Delete(address *model.Address) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next model.Address
+		err := tx.Where("user_id = ?", address.UserID).Order("updated_at DESC").First(&next).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+// SetDefault 设置默认地址
+func (r *addressRepository) SetDefault(userID, addressID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAddress(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&model.Address{}).
+			Where("id = ? AND user_id = ?", addressID, userID).
+			Update("is_default", true).Error
+	})
+}
+
+// GetDefault 获取用户的默认地址
+func (r *addressRepository) GetDefault(userID uint) (*model.Address, error) {
+	var address model.Address
+
+	err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// clearDefaultAddress 取消用户的默认地址
+func clearDefaultAddress(tx *gorm.DB, userID uint) error {
+	return tx.Model(&model.Address{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
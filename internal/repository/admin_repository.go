@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdminRepository 管理后台数据访问层接口
+// 管理后台的查询不按当前用户过滤，只能在需要管理权限的接口中使用
+type AdminRepository interface {
+	SearchOrders(req *model.AdminOrderSearchRequest) ([]*model.Order, int64, error) // 搜索全部用户的订单
+	ListUsers(req *model.AdminUserListRequest) ([]*model.User, int64, error)        // 分页查询用户
+	UpdateUserStatus(userID uint, status int) error                                 // 更新用户状态
+	UpdateProductStatus(ids []uint, status int) (int64, error)                      // 批量更新商品状态，返回实际变更的数量
+	DailySales(start, end time.Time) ([]*model.DailySales, error)                   // 按天统计下单数和成交额
+	TopProducts(start, end time.Time, limit int) ([]*model.TopProduct, error)       // 按成交件数统计热销商品
+}
+
+// adminRepository 管理后台数据访问层实现
+type adminRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminRepository 创建管理后台数据访问层实例
+func NewAdminRepository(db *gorm.DB) AdminRepository {
+	return &adminRepository{
+		db: db,
+	}
+}
+
+// SearchOrders 搜索全部用户的订单，最新的在前
+func (r *adminRepository) SearchOrders(req *model.AdminOrderSearchRequest) ([]*model.Order, int64, error) {
+	var orders []*model.Order
+	var total int64
+
+	// 1. 构建查询条件
+	query := r.db.Model(&model.Order{})
+	if req.OrderNo != "" {
+		query = query.Where("order_no = ?", req.OrderNo)
+	}
+	if req.UserID != nil {
+		query = query.Where("user_id = ?", *req.UserID)
+	}
+	if req.Username != "" {
+		query = query.Where("user_id IN (?)", r.db.Model(&model.User{}).Select("id").Where("username = ?", req.Username))
+	}
+	if req.Phone != "" {
+		query = query.Where("contact_phone = ?", req.Phone)
+	}
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+	if req.PaymentMethod != "" {
+		query = query.Where("payment_method = ?", req.PaymentMethod)
+	}
+	if req.MinAmount != nil {
+		query = query.Where("total_amount >= ?", *req.MinAmount)
+	}
+	if req.MaxAmount != nil {
+		query = query.Where("total_amount <= ?", *req.MaxAmount)
+	}
+	if req.StartDate != nil {
+		query = query.Where("created_at >= ?", *req.StartDate)
+	}
+	if req.EndDate != nil {
+		query = query.Where("created_at <= ?", *req.EndDate)
+	}
+
+	// 2. 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 3. 分页查询
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Preload("User").
+		Preload("OrderItems").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&orders).Error
+	return orders, total, err
+}
+
+// ListUsers 分页查询用户，最新注册的在前
+func (r *adminRepository) ListUsers(req *model.AdminUserListRequest) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	query := r.db.Model(&model.User{})
+	if req.Keyword != "" {
+		prefix := req.Keyword + "%"
+		query = query.Where("username LIKE ? OR email LIKE ? OR phone LIKE ?", prefix, prefix, prefix)
+	}
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("id DESC").Offset(offset).Limit(req.PageSize).Find(&users).Error
+	return users, total, err
+}
+
+// UpdateUserStatus 更新用户状态
+func (r *adminRepository) UpdateUserStatus(userID uint, status int) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userID).Update("status", status).Error
+}
+
+// UpdateProductStatus 批量更新商品状态，状态已经相同的商品不更新
+func (r *adminRepository) UpdateProductStatus(ids []uint, status int) (int64, error) {
+	result := r.db.Model(&model.Product{}).
+		Where("id IN ? AND status <> ?", ids, status).
+		Update("status", status)
+	return result.RowsAffected, result.Error
+}
+
+// DailySales 按天统计[start, end)内的下单数、成交订单数和成交额
+// 只返回有订单的日期
+func (r *adminRepository) DailySales(start, end time.Time) ([]*model.DailySales, error) {
+	var rows []*model.DailySales
+	err := r.db.Model(&model.Order{}).
+		Select("DATE_FORMAT(created_at, '%Y-%m-%d') AS date, COUNT(*) AS orders, "+
+			"SUM(CASE WHEN status IN ? THEN 1 ELSE 0 END) AS paid_orders, "+
+			"SUM(CASE WHEN status IN ? THEN total_amount - discount_amount + shipping_fee ELSE 0 END) AS gmv",
+			model.GMVOrderStatuses, model.GMVOrderStatuses).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("date").
+		Order("date ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// TopProducts 统计[start, end)内成交订单中件数最多的商品
+func (r *adminRepository) TopProducts(start, end time.Time, limit int) ([]*model.TopProduct, error) {
+	var rows []*model.TopProduct
+	err := r.db.Table("order_items").
+		Select("order_items.product_id, MAX(order_items.product_name) AS product_name, "+
+			"SUM(order_items.quantity) AS quantity, SUM(order_items.total_price - order_items.discount_amount) AS amount").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ? AND orders.created_at >= ? AND orders.created_at < ? AND orders.deleted_at IS NULL",
+			model.GMVOrderStatuses, start, end).
+		Group("order_items.product_id").
+		Order("quantity DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository 管理后台审计日志数据访问层接口
+type AuditLogRepository interface {
+	Create(log *model.AdminAuditLog) error                                           // 写入审计日志
+	List(req *model.AdminAuditLogListRequest) ([]*model.AdminAuditLog, int64, error) // 分页查询审计日志
+}
+
+// auditLogRepository 管理后台审计日志数据访问层实现
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建管理后台审计日志数据访问层实例
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{
+		db: db,
+	}
+}
+
+// Create 写入审计日志
+func (r *auditLogRepository) Create(log *model.AdminAuditLog) error {
+	return r.db.Create(log).Error
+}
+
+// List 分页查询审计日志，最新的在前
+func (r *auditLogRepository) List(req *model.AdminAuditLogListRequest) ([]*model.AdminAuditLog, int64, error) {
+	var logs []*model.AdminAuditLog
+	var total int64
+
+	query := r.db.Model(&model.AdminAuditLog{})
+	if req.AdminID != nil {
+		query = query.Where("admin_id = ?", *req.AdminID)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.TargetID != "" {
+		query = query.Where("target_id = ?", req.TargetID)
+	}
+	if req.StartDate != nil {
+		query = query.Where("created_at >= ?", *req.StartDate)
+	}
+	if req.EndDate != nil {
+		query = query.Where("created_at <= ?", *req.EndDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("id DESC").Offset(offset).Limit(req.PageSize).Find(&logs).Error
+	return logs, total, err
+}
@@ -11,7 +11,7 @@ import (
 type CartRepository interface {
 	Create(cartItem *model.CartItem) error                           // 添加商品到购物车
 	GetByUserID(userID uint) ([]*model.CartItem, error)            // 获取用户购物车
-	GetByUserAndProduct(userID, productID uint) (*model.CartItem, error) // 获取用户特定商品的购物车项
+	GetByUserAndSku(userID, productID, skuID uint) (*model.CartItem, error) // 获取用户特定商品规格的购物车项
 	Update(cartItem *model.CartItem) error                          // 更新购物车项
 	Delete(id uint) error                                           // 删除购物车项
 	DeleteByUserAndProduct(userID, productID uint) error           // 删除用户特定商品
@@ -48,17 +48,19 @@ func (r *cartRepository) GetByUserID(userID uint) ([]*model.CartItem, error) {
 	err := r.db.Where("user_id = ?", userID).
 		Preload("Product", "status = ?", model.ProductStatusOnline).
 		Preload("Product.Category").
+		Preload("Sku").
 		Order("created_at DESC").
 		Find(&cartItems).Error
 	
 	return cartItems, err
 }
 
-// GetByUserAndProduct 获取用户特定商品的购物车项
-func (r *cartRepository) GetByUserAndProduct(userID, productID uint) (*model.CartItem, error) {
+// GetByUserAndSku 获取用户特定商品规格的购物车项
+// 同一商品的不同规格是不同的购物车项，商品没有规格时skuID为0
+func (r *cartRepository) GetByUserAndSku(userID, productID, skuID uint) (*model.CartItem, error) {
 	var cartItem model.CartItem
 	
-	err := r.db.Where("user_id = ? AND product_id = ?", userID, productID).
+	err := r.db.Where("user_id = ? AND product_id = ? AND sku_id = ?", userID, productID, skuID).
 		First(&cartItem).Error
 	
 	if err != nil {
@@ -100,6 +102,7 @@ func (r *cartRepository) GetByIDs(ids []uint) ([]*model.CartItem, error) {
 	err := r.db.Where("id IN ?", ids).
 		Preload("Product", "status = ?", model.ProductStatusOnline).
 		Preload("Product.Category").
+		Preload("Sku").
 		Find(&cartItems).Error
 	
 	return cartItems, err
@@ -114,6 +117,7 @@ func (r *cartRepository) GetCartSummary(userID uint) (*model.CartSummary, error)
 	var cartItems []*model.CartItem
 	err := r.db.Where("user_id = ?", userID).
 		Preload("Product", "status = ?", model.ProductStatusOnline).
+		Preload("Sku").
 		Find(&cartItems).Error
 	
 	if err != nil {
@@ -129,7 +133,7 @@ func (r *cartRepository) GetCartSummary(userID uint) (*model.CartSummary, error)
 	for _, item := range cartItems {
 		if item.Product.ID != 0 { // 确保商品存在且上架
 			summary.TotalItems += item.Quantity
-			summary.TotalAmount += float64(item.Quantity) * item.Product.Price
+			summary.TotalAmount += float64(item.Quantity) * item.UnitPrice()
 		}
 	}
 	
@@ -145,6 +149,7 @@ func (r *cartRepository) GetCartItemsWithValidation(userID uint) ([]*model.CartI
 	err := r.db.Where("user_id = ?", userID).
 		Preload("Product").
 		Preload("Product.Category").
+		Preload("Sku").
 		Find(&cartItems).Error
 	
 	if err != nil {
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CouponRepository 优惠券数据访问层接口
+type CouponRepository interface {
+	CreateTemplate(template *model.CouponTemplate) error                                        // 创建优惠券模板
+	GetTemplate(id uint) (*model.CouponTemplate, error)                                         // 根据ID获取优惠券模板
+	ListTemplates(req *model.CouponTemplateListRequest) ([]*model.CouponTemplate, int64, error) // 分页获取优惠券模板
+	ListClaimableTemplates(now time.Time) ([]*model.CouponTemplate, error)                      // 获取当前可领取的优惠券模板
+	UpdateTemplateStatus(id uint, status model.CouponTemplateStatus) error                      // 更新优惠券模板状态
+	GetUserCoupon(id uint) (*model.UserCoupon, error)                                           // 获取用户优惠券（包含模板）
+	ListUserCoupons(userID uint, state string, now time.Time) ([]*model.UserCoupon, error)      // 获取用户优惠券列表
+}
+
+// couponRepository 优惠券数据访问层实现
+type couponRepository struct {
+	db *gorm.DB
+}
+
+// NewCouponRepository 创建优惠券数据访问层实例
+func NewCouponRepository(db *gorm.DB) CouponRepository {
+	return &couponRepository{
+		db: db,
+	}
+}
+
+// CreateTemplate 创建优惠券模板
+func (r *couponRepository) CreateTemplate(template *model.CouponTemplate) error {
+	return r.db.Create(template).Error
+}
+
+// GetTemplate 根据ID获取优惠券模板
+func (r *couponRepository) GetTemplate(id uint) (*model.CouponTemplate, error) {
+	var template model.CouponTemplate
+
+	err := r.db.First(&template, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// ListTemplates 分页获取优惠券模板
+func (r *couponRepository) ListTemplates(req *model.CouponTemplateListRequest) ([]*model.CouponTemplate, int64, error) {
+	var templates []*model.CouponTemplate
+	var total int64
+
+	query := r.db.Model(&model.CouponTemplate{})
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("id DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&templates).Error
+
+	return templates, total, err
+}
+
+// ListClaimableTemplates 获取当前可领取的优惠券模板
+func (r *couponRepository) ListClaimableTemplates(now time.Time) ([]*model.CouponTemplate, error) {
+	var templates []*model.CouponTemplate
+
+	err := r.db.Where("status = ? AND start_at <= ? AND end_at > ?", model.CouponTemplateActive, now, now).
+		Where("total_count = 0 OR issued_count < total_count").
+		Order("end_at ASC").
+		Find(&templates).Error
+
+	return templates, err
+}
+
+// UpdateTemplateStatus 更新优惠券模板状态
+func (r *couponRepository) UpdateTemplateStatus(id uint, status model.CouponTemplateStatus) error {
+	return r.db.Model(&model.CouponTemplate{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// GetUserCoupon 获取用户优惠券（包含模板）
+func (r *couponRepository) GetUserCoupon(id uint) (*model.UserCoupon, error) {
+	var coupon model.UserCoupon
+
+	err := r.db.Preload("Template").First(&coupon, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+// ListUserCoupons 获取用户优惠券列表
+// state: usable 可用、used 已使用、expired 已过期，为空时返回全部
+func (r *couponRepository) ListUserCoupons(userID uint, state string, now time.Time) ([]*model.UserCoupon, error) {
+	var coupons []*model.UserCoupon
+
+	query := r.db.Where("user_id = ?", userID)
+	switch state {
+	case "usable":
+		query = query.Where("status = ? AND expire_at > ?", model.UserCouponStatusUnused, now)
+	case "used":
+		query = query.Where("status = ?", model.UserCouponStatusUsed)
+	case "expired":
+		query = query.Where("status = ? AND expire_at <= ?", model.UserCouponStatusUnused, now)
+	}
+
+	err := query.Preload("Template").
+		Order("expire_at ASC").
+		Find(&coupons).Error
+
+	return coupons, err
+}
+
+// ReleaseOrderCoupons 退回订单使用的优惠券
+// 需要在取消订单的事务中调用，保证订单取消和优惠券退回同时生效
+func ReleaseOrderCoupons(tx *gorm.DB, orderIDs []uint) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&model.UserCoupon{}).
+		Where("order_id IN ? AND status = ?", orderIDs, model.UserCouponStatusUsed).
+		Updates(map[string]interface{}{
+			"status":   model.UserCouponStatusUnused,
+			"order_id": nil,
+			"used_at":  nil,
+		}).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"math"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FavoriteRepository 商品收藏数据访问层接口
+type FavoriteRepository interface {
+	Create(favorite *model.Favorite) error                                              // 收藏商品，已收藏时忽略
+	Delete(userID, productID uint) error                                                // 取消收藏
+	List(userID uint, req *model.FavoriteListRequest) ([]*model.Favorite, int64, error) // 分页查询用户的收藏
+	ListByProducts(productIDs []uint) ([]*model.Favorite, error)                        // 获取收藏了这些商品的记录
+	UpdateSnapshot(favorite *model.Favorite, price float64, inStock bool) (bool, error) // 更新记录的价格和有货状态，已被其他进程更新时返回false
+}
+
+// favoriteRepository 商品收藏数据访问层实现
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+// NewFavoriteRepository 创建商品收藏数据访问层实例
+func NewFavoriteRepository(db *gorm.DB) FavoriteRepository {
+	return &favoriteRepository{
+		db: db,
+	}
+}
+
+// Create 收藏商品
+// 依靠(user_id, product_id)唯一索引，重复收藏时保留原来的记录
+func (r *favoriteRepository) Create(favorite *model.Favorite) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(favorite).Error
+}
+
+// Delete 取消收藏
+func (r *favoriteRepository) Delete(userID, productID uint) error {
+	return r.db.Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&model.Favorite{}).Error
+}
+
+// List 分页查询用户的收藏，包含商品信息
+func (r *favoriteRepository) List(userID uint, req *model.FavoriteListRequest) ([]*model.Favorite, int64, error) {
+	var favorites []*model.Favorite
+	var total int64
+
+	query := r.db.Model(&model.Favorite{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Preload("Product").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&favorites).Error
+
+	return favorites, total, err
+}
+
+// ListByProducts 获取收藏了这些商品的记录
+func (r *favoriteRepository) ListByProducts(productIDs []uint) ([]*model.Favorite, error) {
+	var favorites []*model.Favorite
+	if len(productIDs) == 0 {
+		return favorites, nil
+	}
+
+	err := r.db.Where("product_id IN ?", productIDs).Find(&favorites).Error
+	return favorites, err
+}
+
+// UpdateSnapshot 更新记录的价格和有货状态
+// 以读取时的值为条件更新，多个实例同时处理同一次商品变化时只有一个会成功，避免重复提醒
+func (r *favoriteRepository) UpdateSnapshot(favorite *model.Favorite, price float64, inStock bool) (bool, error) {
+	result := r.db.Model(&model.Favorite{}).
+		Where("id = ? AND ROUND(last_price * 100) = ? AND last_in_stock = ?", favorite.ID, math.Round(favorite.LastPrice*100), favorite.LastInStock).
+		Updates(map[string]interface{}{
+			"last_price":    price,
+			"last_in_stock": inStock,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
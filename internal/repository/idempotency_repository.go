@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository 幂等记录数据访问层接口
+type IdempotencyRepository interface {
+	Acquire(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) // 占用幂等键，已被占用时返回已有记录
+	Complete(id uint, status int, contentType string, body []byte) error       // 保存请求的响应
+	Release(id uint) error                                                     // 释放幂等键，允许重新提交
+	DeleteExpired(before time.Time, limit int) (int64, error)                  // 分批删除过期的记录
+}
+
+// idempotencyRepository 幂等记录数据访问层实现
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository 创建幂等记录数据访问层实例
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+// Acquire 占用幂等键
+// 依靠(user_id, key)唯一索引保证并发的重复请求只有一个能占用成功；
+// 占用成功时返回nil，幂等键已被占用时返回已有记录；
+// 已过期或处理中租约已到期的记录会被删除后重新占用
+func (r *idempotencyRepository) Acquire(record *model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil, nil
+		}
+
+		var existing model.IdempotencyRecord
+		err := r.db.Where("user_id = ? AND `key` = ?", record.UserID, record.Key).First(&existing).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// 已有记录刚好被释放，重新占用
+				continue
+			}
+			return nil, err
+		}
+		now := time.Now()
+		if !existing.Reusable(now) {
+			return &existing, nil
+		}
+
+		// 过期或租约到期的记录不再生效，删除后重新占用
+		err = r.db.Where("id = ? AND (expire_at <= ? OR (status = ? AND lease_until <= ?))",
+			existing.ID, now, model.IdempotencyStatusProcessing, now).
+			Delete(&model.IdempotencyRecord{}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, errors.New("幂等键占用失败，请重试")
+}
+
+// Complete 保存请求的响应
+func (r *idempotencyRepository) Complete(id uint, status int, contentType string, body []byte) error {
+	return r.db.Model(&model.IdempotencyRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          model.IdempotencyStatusCompleted,
+			"response_status": status,
+			"content_type":    contentType,
+			"response_body":   string(body),
+		}).Error
+}
+
+// Release 释放幂等键
+func (r *idempotencyRepository) Release(id uint) error {
+	return r.db.Delete(&model.IdempotencyRecord{}, id).Error
+}
+
+// DeleteExpired 分批删除过期的记录，返回删除数量
+func (r *idempotencyRepository) DeleteExpired(before time.Time, limit int) (int64, error) {
+	result := r.db.Where("expire_at <= ?", before).
+		Limit(limit).
+		Delete(&model.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
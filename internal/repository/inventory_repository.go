@@ -0,0 +1,528 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InventoryRepository 库存预占数据访问层接口
+type InventoryRepository interface {
+	FindStaleReservations(limit int) ([]model.StaleReservation, error)             // 查找订单已不是待支付状态但仍在预占的记录
+	ReconcileReservedStock(afterID uint, limit int) (*model.ReconcileBatch, error) // 按预占记录修正商品的预占库存
+}
+
+// inventoryRepository 库存预占数据访问层实现
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+// NewInventoryRepository 创建库存预占数据访问层实例
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{
+		db: db,
+	}
+}
+
+// FindStaleReservations 查找订单已不是待支付状态但仍在预占的记录
+// 正常流程中订单支付或取消时会同时处理预占，这里用于对账兜底
+func (r *inventoryRepository) FindStaleReservations(limit int) ([]model.StaleReservation, error) {
+	var stale []model.StaleReservation
+
+	err := r.db.Model(&model.InventoryReservation{}).
+		Select("DISTINCT inventory_reservations.order_id, orders.status AS order_status").
+		Joins("JOIN orders ON orders.id = inventory_reservations.order_id").
+		Where("inventory_reservations.status = ? AND orders.status <> ?", model.ReservationStatusReserved, model.OrderStatusPending).
+		Order("inventory_reservations.order_id ASC").
+		Limit(limit).
+		Scan(&stale).Error
+
+	return stale, err
+}
+
+// ReconcileReservedStock 按预占记录修正商品的预占库存
+// 处理afterID之后的最多limit个商品。在同一个事务快照中读取预占库存和预占记录，
+// 只有预占库存在此期间没有变化时才修正，避免覆盖并发下单的结果
+func (r *inventoryRepository) ReconcileReservedStock(afterID uint, limit int) (*model.ReconcileBatch, error) {
+	batch := &model.ReconcileBatch{LastID: afterID}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. 读取本批商品的预占库存
+		var products []model.Product
+		err := tx.Select("id", "reserved_stock").
+			Where("id > ?", afterID).
+			Order("id ASC").
+			Limit(limit).
+			Find(&products).Error
+		if err != nil || len(products) == 0 {
+			return err
+		}
+		batch.Scanned = len(products)
+		batch.LastID = products[len(products)-1].ID
+
+		// 2. 汇总预占记录
+		ids := make([]uint, 0, len(products))
+		for _, product := range products {
+			ids = append(ids, product.ID)
+		}
+		var rows []struct {
+			ProductID uint
+			Quantity  int
+		}
+		err = tx.Model(&model.InventoryReservation{}).
+			Select("product_id, SUM(quantity) AS quantity").
+			Where("product_id IN ? AND status = ?", ids, model.ReservationStatusReserved).
+			Group("product_id").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		reserved := make(map[uint]int, len(rows))
+		for _, row := range rows {
+			reserved[row.ProductID] = row.Quantity
+		}
+
+		// 3. 修正不一致的商品
+		for _, product := range products {
+			expected := reserved[product.ID]
+			if product.ReservedStock == expected {
+				continue
+			}
+			result := tx.Model(&model.Product{}).
+				Where("id = ? AND reserved_stock = ?", product.ID, product.ReservedStock).
+				Update("reserved_stock", expected)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				batch.ProductIDs = append(batch.ProductIDs, product.ID)
+			}
+		}
+
+		// 4. 修正这些商品的仓库预占库存和SKU预占库存
+		if err := reconcileWarehouseReservedStock(tx, ids); err != nil {
+			return err
+		}
+		return reconcileSkuReservedStock(tx, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// reconcileWarehouseReservedStock 按预占记录修正商品在各仓库的预占库存
+// 与商品的修正在同一个事务快照中进行，同样只在预占库存没有变化时修正
+func reconcileWarehouseReservedStock(tx *gorm.DB, productIDs []uint) error {
+	var stocks []model.WarehouseStock
+	err := tx.Select("id", "warehouse_id", "product_id", "reserved_stock").
+		Where("product_id IN ?", productIDs).
+		Find(&stocks).Error
+	if err != nil || len(stocks) == 0 {
+		return err
+	}
+
+	var rows []struct {
+		WarehouseID uint
+		ProductID   uint
+		Quantity    int
+	}
+	err = tx.Model(&model.InventoryReservation{}).
+		Select("warehouse_id, product_id, SUM(quantity) AS quantity").
+		Where("product_id IN ? AND warehouse_id <> ? AND status = ?", productIDs, model.UnassignedWarehouseID, model.ReservationStatusReserved).
+		Group("warehouse_id, product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	reserved := make(map[warehouseProduct]int, len(rows))
+	for _, row := range rows {
+		reserved[warehouseProduct{row.WarehouseID, row.ProductID}] = row.Quantity
+	}
+
+	for _, stock := range stocks {
+		expected := reserved[warehouseProduct{stock.WarehouseID, stock.ProductID}]
+		if stock.ReservedStock == expected {
+			continue
+		}
+		err = tx.Model(&model.WarehouseStock{}).
+			Where("id = ? AND reserved_stock = ?", stock.ID, stock.ReservedStock).
+			Update("reserved_stock", expected).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileSkuReservedStock 按预占记录修正商品各SKU的预占库存
+func reconcileSkuReservedStock(tx *gorm.DB, productIDs []uint) error {
+	var skus []model.ProductSku
+	err := tx.Select("id", "reserved_stock").
+		Where("product_id IN ?", productIDs).
+		Find(&skus).Error
+	if err != nil || len(skus) == 0 {
+		return err
+	}
+
+	var rows []struct {
+		SkuID    uint
+		Quantity int
+	}
+	err = tx.Model(&model.InventoryReservation{}).
+		Select("sku_id, SUM(quantity) AS quantity").
+		Where("product_id IN ? AND sku_id <> 0 AND status = ?", productIDs, model.ReservationStatusReserved).
+		Group("sku_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	reserved := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		reserved[row.SkuID] = row.Quantity
+	}
+
+	for _, sku := range skus {
+		expected := reserved[sku.ID]
+		if sku.ReservedStock == expected {
+			continue
+		}
+		err = tx.Model(&model.ProductSku{}).
+			Where("id = ? AND reserved_stock = ?", sku.ID, sku.ReservedStock).
+			Update("reserved_stock", expected).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockStockSources 锁定商品及其各仓库库存，返回的仓库库存带有所属仓库
+// 需要在下单事务中调用。先锁商品再锁仓库库存，与支付、取消时更新库存的顺序一致
+func LockStockSources(tx *gorm.DB, productID uint) (*model.Product, []model.WarehouseStock, error) {
+	// 1. 锁定商品
+	var product model.Product
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "stock", "reserved_stock").
+		First(&product, productID).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 2. 锁定各仓库库存
+	var stocks []model.WarehouseStock
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).
+		Order("warehouse_id ASC").
+		Find(&stocks).Error
+	if err != nil || len(stocks) == 0 {
+		return &product, stocks, err
+	}
+
+	// 3. 加载所属仓库，仓库信息不需要加锁
+	warehouseIDs := make([]uint, 0, len(stocks))
+	for _, stock := range stocks {
+		warehouseIDs = append(warehouseIDs, stock.WarehouseID)
+	}
+	var warehouses []model.Warehouse
+	if err := tx.Where("id IN ?", warehouseIDs).Find(&warehouses).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint]model.Warehouse, len(warehouses))
+	for _, warehouse := range warehouses {
+		byID[warehouse.ID] = warehouse
+	}
+	for i := range stocks {
+		stocks[i].Warehouse = byID[stocks[i].WarehouseID]
+	}
+
+	return &product, stocks, nil
+}
+
+// ReserveStock 按分配结果预占商品库存
+// 仓库库存和商品库存都按可售库存条件更新，并发下单时不会超卖；可售库存不足时返回false
+func ReserveStock(tx *gorm.DB, productID uint, allocations []model.StockAllocation) (bool, error) {
+	total := 0
+	for _, allocation := range allocations {
+		total += allocation.Quantity
+		if allocation.WarehouseID == model.UnassignedWarehouseID {
+			continue
+		}
+		result := tx.Model(&model.WarehouseStock{}).
+			Where("warehouse_id = ? AND product_id = ? AND stock - reserved_stock >= ?", allocation.WarehouseID, productID, allocation.Quantity).
+			Update("reserved_stock", gorm.Expr("reserved_stock + ?", allocation.Quantity))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return false, result.Error
+		}
+	}
+
+	result := tx.Model(&model.Product{}).
+		Where("id = ? AND stock - reserved_stock >= ?", productID, total).
+		Update("reserved_stock", gorm.Expr("reserved_stock + ?", total))
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReserveSkuStock 预占SKU库存
+// 需要在锁定商品之后调用；SKU已下架或可售库存不足时返回false
+func ReserveSkuStock(tx *gorm.DB, skuID uint, quantity int) (bool, error) {
+	result := tx.Model(&model.ProductSku{}).
+		Where("id = ? AND status = ? AND stock - reserved_stock >= ?", skuID, model.SkuStatusOnline, quantity).
+		Update("reserved_stock", gorm.Expr("reserved_stock + ?", quantity))
+	return result.RowsAffected > 0, result.Error
+}
+
+// RestockOrderItem 退款时把商品退回库存
+// 有规格的商品同时退回SKU库存；按订单扣减时的分配退回到原仓库，超出部分退回未分配库存。
+// 每条预占记录记下已退回的数量，多次部分退款不会把同一仓库退回超过扣减的数量
+func RestockOrderItem(tx *gorm.DB, orderID, productID, skuID uint, quantity int) error {
+	// 1. 商品库存和SKU库存
+	err := tx.Model(&model.Product{}).
+		Where("id = ?", productID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+	if err != nil {
+		return err
+	}
+	if skuID != 0 {
+		err = tx.Model(&model.ProductSku{}).
+			Where("id = ?", skuID).
+			Update("stock", gorm.Expr("stock + ?", quantity)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// 2. 仓库库存
+	var reservations []model.InventoryReservation
+	err = tx.Where("order_id = ? AND product_id = ? AND sku_id = ? AND warehouse_id <> ? AND status = ?",
+		orderID, productID, skuID, model.UnassignedWarehouseID, model.ReservationStatusCommitted).
+		Order("id ASC").
+		Find(&reservations).Error
+	if err != nil {
+		return err
+	}
+	for i, restock := range model.AllocateRestock(reservations, quantity) {
+		if restock == 0 {
+			continue
+		}
+		reservation := reservations[i]
+
+		// 记录已退回的数量，后续部分退款只退回剩余部分
+		result := tx.Model(&model.InventoryReservation{}).
+			Where("id = ? AND restocked + ? <= quantity", reservation.ID, restock).
+			Update("restocked", gorm.Expr("restocked + ?", restock))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("库存预占记录已被并发修改，请重试")
+		}
+
+		err = tx.Model(&model.WarehouseStock{}).
+			Where("warehouse_id = ? AND product_id = ?", reservation.WarehouseID, productID).
+			Update("stock", gorm.Expr("stock + ?", restock)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CommitOrderReservations 订单支付后从库存中扣减预占的数量
+// 需要在订单状态变更的事务中调用，返回库存发生变化的商品ID
+func CommitOrderReservations(tx *gorm.DB, orderIDs []uint) ([]uint, error) {
+	return settleReservations(tx, orderIDs, model.ReservationStatusCommitted)
+}
+
+// ReleaseOrderReservations 订单取消后释放预占的库存
+// 需要在取消订单的事务中调用，返回可售库存发生变化的商品ID。
+// 没有预占记录的订单是库存预占上线前创建的，下单时已直接扣减库存，取消时按原方式恢复库存
+func ReleaseOrderReservations(tx *gorm.DB, orderIDs []uint) ([]uint, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	// 1. 区分有预占记录的订单
+	var reservedOrderIDs []uint
+	err := tx.Model(&model.InventoryReservation{}).
+		Where("order_id IN ?", orderIDs).
+		Distinct().
+		Pluck("order_id", &reservedOrderIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	hasReservation := make(map[uint]bool, len(reservedOrderIDs))
+	for _, id := range reservedOrderIDs {
+		hasReservation[id] = true
+	}
+	var legacyOrderIDs []uint
+	for _, id := range orderIDs {
+		if !hasReservation[id] {
+			legacyOrderIDs = append(legacyOrderIDs, id)
+		}
+	}
+
+	// 2. 释放预占
+	productIDs, err := settleReservations(tx, reservedOrderIDs, model.ReservationStatusReleased)
+	if err != nil {
+		return nil, err
+	}
+	if len(legacyOrderIDs) == 0 {
+		return productIDs, nil
+	}
+
+	// 3. 恢复旧订单直接扣减的库存
+	var items []model.OrderItem
+	if err := tx.Where("order_id IN ?", legacyOrderIDs).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	quantities := make(map[uint]int)
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	restored := sortedProductIDs(quantities)
+	if len(restored) == 0 {
+		return productIDs, nil
+	}
+	err = tx.Model(&model.Product{}).
+		Where("id IN ?", restored).
+		Update("stock", stockCaseExpr("stock", "+", restored, quantities)).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return append(productIDs, restored...), nil
+}
+
+// settleReservations 将订单仍在预占的记录转为已扣减或已释放
+// 锁定预占记录后按商品汇总，一条CASE语句批量更新商品库存，按商品ID排序减少死锁
+func settleReservations(tx *gorm.DB, orderIDs []uint, to model.ReservationStatus) ([]uint, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	// 1. 锁定仍在预占的记录
+	var reservations []model.InventoryReservation
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id IN ? AND status = ?", orderIDs, model.ReservationStatusReserved).
+		Find(&reservations).Error
+	if err != nil || len(reservations) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(reservations))
+	quantities := make(map[uint]int)
+	warehouseQuantities := make(map[warehouseProduct]int)
+	skuQuantities := make(map[uint]int)
+	for _, reservation := range reservations {
+		ids = append(ids, reservation.ID)
+		quantities[reservation.ProductID] += reservation.Quantity
+		if reservation.SkuID != 0 {
+			skuQuantities[reservation.SkuID] += reservation.Quantity
+		}
+		if reservation.WarehouseID != model.UnassignedWarehouseID {
+			warehouseQuantities[warehouseProduct{reservation.WarehouseID, reservation.ProductID}] += reservation.Quantity
+		}
+	}
+	productIDs := sortedProductIDs(quantities)
+
+	// 2. 更新商品库存：扣减时库存和预占库存同时减少，释放时只减少预占库存
+	updates := map[string]interface{}{
+		"reserved_stock": stockCaseExpr("reserved_stock", "-", productIDs, quantities),
+	}
+	if to == model.ReservationStatusCommitted {
+		updates["stock"] = stockCaseExpr("stock", "-", productIDs, quantities)
+	}
+	err = tx.Model(&model.Product{}).Where("id IN ?", productIDs).Updates(updates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 同样更新各仓库库存
+	for _, key := range sortedWarehouseProducts(warehouseQuantities) {
+		quantity := warehouseQuantities[key]
+		updates := map[string]interface{}{
+			"reserved_stock": gorm.Expr("reserved_stock - ?", quantity),
+		}
+		if to == model.ReservationStatusCommitted {
+			updates["stock"] = gorm.Expr("stock - ?", quantity)
+		}
+		err = tx.Model(&model.WarehouseStock{}).
+			Where("warehouse_id = ? AND product_id = ?", key.warehouseID, key.productID).
+			Updates(updates).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. 同样更新SKU库存
+	if skuIDs := sortedProductIDs(skuQuantities); len(skuIDs) > 0 {
+		updates := map[string]interface{}{
+			"reserved_stock": stockCaseExpr("reserved_stock", "-", skuIDs, skuQuantities),
+		}
+		if to == model.ReservationStatusCommitted {
+			updates["stock"] = stockCaseExpr("stock", "-", skuIDs, skuQuantities)
+		}
+		err = tx.Model(&model.ProductSku{}).Where("id IN ?", skuIDs).Updates(updates).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. 更新预占记录状态
+	err = tx.Model(&model.InventoryReservation{}).
+		Where("id IN ?", ids).
+		Update("status", to).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return productIDs, nil
+}
+
+// stockCaseExpr 按商品ID（或SKU ID）分别增减库存字段的CASE表达式
+func stockCaseExpr(column, op string, productIDs []uint, quantities map[uint]int) clause.Expr {
+	expr := "CASE id"
+	args := make([]interface{}, 0, len(productIDs)*2)
+	for _, productID := range productIDs {
+		expr += " WHEN ? THEN " + column + " " + op + " ?"
+		args = append(args, productID, quantities[productID])
+	}
+	expr += " ELSE " + column + " END"
+	return gorm.Expr(expr, args...)
+}
+
+// sortedProductIDs 按ID升序返回商品ID（或SKU ID）
+func sortedProductIDs(quantities map[uint]int) []uint {
+	ids := make([]uint, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// warehouseProduct 仓库库存的唯一键
+type warehouseProduct struct {
+	warehouseID uint
+	productID   uint
+}
+
+// sortedWarehouseProducts 按商品ID、仓库ID升序返回仓库库存的键
+func sortedWarehouseProducts(quantities map[warehouseProduct]int) []warehouseProduct {
+	keys := make([]warehouseProduct, 0, len(quantities))
+	for key := range quantities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].productID != keys[j].productID {
+			return keys[i].productID < keys[j].productID
+		}
+		return keys[i].warehouseID < keys[j].warehouseID
+	})
+	return keys
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MessageRepository 邮件、短信消息数据访问层接口
+type MessageRepository interface {
+	GetPreference(userID uint) (*model.MessagePreference, error) // 获取用户的消息渠道偏好，未设置时返回nil
+	SavePreference(preference *model.MessagePreference) error    // 保存用户的消息渠道偏好
+	ListPending(limit int) ([]*model.OutboxMessage, error)       // 按创建顺序获取待发送的消息
+	UpdateResult(message *model.OutboxMessage) error             // 保存消息的发送结果
+}
+
+// messageRepository 邮件、短信消息数据访问层实现
+type messageRepository struct {
+	db *gorm.DB
+}
+
+// NewMessageRepository 创建邮件、短信消息数据访问层实例
+func NewMessageRepository(db *gorm.DB) MessageRepository {
+	return &messageRepository{
+		db: db,
+	}
+}
+
+// GetPreference 获取用户的消息渠道偏好
+func (r *messageRepository) GetPreference(userID uint) (*model.MessagePreference, error) {
+	return GetMessagePreference(r.db, userID)
+}
+
+// SavePreference 保存用户的消息渠道偏好，不存在时创建
+func (r *messageRepository) SavePreference(preference *model.MessagePreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"email", "sms", "updated_at"}),
+	}).Create(preference).Error
+}
+
+// ListPending 按创建顺序获取待发送的消息
+func (r *messageRepository) ListPending(limit int) ([]*model.OutboxMessage, error) {
+	var messages []*model.OutboxMessage
+	err := r.db.Where("status = ?", model.OutboxMessagePending).
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// UpdateResult 保存消息的发送结果
+func (r *messageRepository) UpdateResult(message *model.OutboxMessage) error {
+	return r.db.Model(message).
+		Select("status", "attempts", "last_error", "sent_at").
+		Updates(message).Error
+}
+
+// GetMessagePreference 获取用户的消息渠道偏好，未设置时返回nil，可在业务事务中调用
+func GetMessagePreference(tx *gorm.DB, userID uint) (*model.MessagePreference, error) {
+	var preference model.MessagePreference
+	err := tx.Where("user_id = ?", userID).First(&preference).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// CreateOutboxMessages 批量写入待发消息，可在业务事务中调用
+func CreateOutboxMessages(tx *gorm.DB, messages []*model.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(messages, 500).Error
+}
+
+// GetMessageRecipient 在事务中获取订单及下单用户的联系方式，用于生成订单相关的消息
+func GetMessageRecipient(tx *gorm.DB, orderID uint) (*model.Order, error) {
+	var order model.Order
+	err := tx.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "email", "phone")
+	}).First(&order, orderID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
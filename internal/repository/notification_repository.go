@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 站内通知数据访问层接口
+type NotificationRepository interface {
+	Create(notifications []*model.Notification) error                                           // 批量创建通知
+	List(userID uint, req *model.NotificationListRequest) ([]*model.Notification, int64, error) // 分页查询用户的通知
+	CountUnread(userID uint) (int64, error)                                                     // 统计用户的未读通知数
+	MarkRead(userID uint, ids []uint) (int64, error)                                            // 标记指定通知已读
+	MarkAllRead(userID uint) (int64, error)                                                     // 标记用户全部通知已读
+}
+
+// notificationRepository 站内通知数据访问层实现
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建站内通知数据访问层实例
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+// Create 批量创建通知
+func (r *notificationRepository) Create(notifications []*model.Notification) error {
+	return CreateNotifications(r.db, notifications)
+}
+
+// List 分页查询用户的通知
+func (r *notificationRepository) List(userID uint, req *model.NotificationListRequest) ([]*model.Notification, int64, error) {
+	var notifications []*model.Notification
+	var total int64
+
+	query := r.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if req.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+// CountUnread 统计用户的未读通知数
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 标记指定通知已读，只处理属于该用户的未读通知
+func (r *notificationRepository) MarkRead(userID uint, ids []uint) (int64, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND id IN ? AND read_at IS NULL", userID, ids).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// MarkAllRead 标记用户全部通知已读
+func (r *notificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// CreateNotifications 批量创建通知，可在业务事务中调用
+func CreateNotifications(tx *gorm.DB, notifications []*model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(notifications, 500).Error
+}
+
+// GetOrderRecipient 在事务中获取订单的用户和订单号，用于发送订单相关的通知
+func GetOrderRecipient(tx *gorm.DB, orderID uint) (*model.Order, error) {
+	var order model.Order
+	err := tx.Select("id", "user_id", "order_no").First(&order, orderID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
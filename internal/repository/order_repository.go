@@ -15,11 +15,11 @@ type OrderRepository interface {
 	GetByOrderNo(orderNo string) (*model.Order, error)                 // 根据订单号获取订单
 	GetByUserID(userID uint, req *model.OrderListRequest) ([]*model.Order, int64, error) // 获取用户订单列表
 	Update(order *model.Order) error                                    // 更新订单
-	UpdateStatus(id uint, status model.OrderStatus) error              // 更新订单状态
 	GetOrderItems(orderID uint) ([]*model.OrderItem, error)           // 获取订单项
 	CreateOrderItems(items []*model.OrderItem) error                   // 创建订单项
 	GetOrderStatistics(userID uint) (*model.OrderStatistics, error)   // 获取订单统计
-	CancelExpiredOrders() error                                         // 取消过期订单
+	GetStatusLogs(orderID uint) ([]*model.OrderStatusLog, error)      // 获取订单状态变更记录
+	FindExpiredOrderIDs(deadlines map[string]time.Time, defaultDeadline time.Time, afterID uint, limit int) ([]uint, error) // 查找超过支付时限的待支付订单
 }
 
 // orderRepository 订单数据访问层实现
@@ -66,6 +66,7 @@ func (r *orderRepository) GetByID(id uint) (*model.Order, error) {
 		Preload("OrderItems").
 		Preload("OrderItems.Product").
 		Preload("OrderItems.Product.Category").
+		Preload("Allocations").
 		First(&order).Error
 	
 	if err != nil {
@@ -86,6 +87,7 @@ func (r *orderRepository) GetByOrderNo(orderNo string) (*model.Order, error) {
 		Preload("OrderItems").
 		Preload("OrderItems.Product").
 		Preload("OrderItems.Product.Category").
+		Preload("Allocations").
 		First(&order).Error
 	
 	if err != nil {
@@ -142,16 +144,6 @@ func (r *orderRepository) Update(order *model.Order) error {
 	return r.db.Save(order).Error
 }
 
-// UpdateStatus 更新订单状态
-func (r *orderRepository) UpdateStatus(id uint, status model.OrderStatus) error {
-	return r.db.Model(&model.Order{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":     status,
-			"updated_at": time.Now(),
-		}).Error
-}
-
 // GetOrderItems 获取订单项
 func (r *orderRepository) GetOrderItems(orderID uint) ([]*model.OrderItem, error) {
 	var items []*model.OrderItem
@@ -206,66 +198,54 @@ func (r *orderRepository) GetOrderStatistics(userID uint) (*model.OrderStatistic
 		}
 	}
 	
-	// 计算总订单数和总金额
+	// 计算总订单数和总金额（按优惠后的应付金额）
 	err = r.db.Model(&model.Order{}).
-		Select("count(*) as total_orders, COALESCE(sum(total_amount), 0) as total_amount").
+		Select("count(*) as total_orders, COALESCE(sum(total_amount - discount_amount), 0) as total_amount").
 		Where("user_id = ?", userID).
 		Row().Scan(&stats.TotalOrders, &stats.TotalAmount)
 	
 	return &stats, err
 }
 
-// CancelExpiredOrders 取消过期订单
-// 取消超过30分钟未支付的订单
-func (r *orderRepository) CancelExpiredOrders() error {
-	expiredTime := time.Now().Add(-30 * time.Minute)
+// GetStatusLogs 获取订单状态变更记录
+// 按时间升序返回
+func (r *orderRepository) GetStatusLogs(orderID uint) ([]*model.OrderStatusLog, error) {
+	var logs []*model.OrderStatusLog
 	
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// 1. 查找过期的待支付订单
-		var expiredOrders []model.Order
-		err := tx.Where("status = ? AND created_at < ?", model.OrderStatusPending, expiredTime).
-			Find(&expiredOrders).Error
-		if err != nil {
-			return err
-		}
-		
-		// 2. 更新订单状态为已取消
-		if len(expiredOrders) > 0 {
-			var orderIDs []uint
-			for _, order := range expiredOrders {
-				orderIDs = append(orderIDs, order.ID)
-			}
-			
-			err = tx.Model(&model.Order{}).
-				Where("id IN ?", orderIDs).
-				Updates(map[string]interface{}{
-					"status":     model.OrderStatusCancelled,
-					"updated_at": time.Now(),
-				}).Error
-			if err != nil {
-				return err
-			}
-			
-			// 3. 恢复库存（这里需要获取订单项信息）
-			var orderItems []model.OrderItem
-			err = tx.Where("order_id IN ?", orderIDs).Find(&orderItems).Error
-			if err != nil {
-				return err
-			}
-			
-			// 4. 批量恢复库存
-			for _, item := range orderItems {
-				err = tx.Model(&model.Product{}).
-					Where("id = ?", item.ProductID).
-					Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
-				if err != nil {
-					return err
-				}
-			}
-		}
-		
-		return nil
-	})
+	err := r.db.Where("order_id = ?", orderID).
+		Order("id ASC").
+		Find(&logs).Error
+	
+	return logs, err
+}
+
+// FindExpiredOrderIDs 查找超过支付时限的待支付订单
+// deadlines按支付方式指定截止时间，其余支付方式使用defaultDeadline；
+// 按ID升序返回afterID之后的最多limit个订单ID，便于分批处理
+func (r *orderRepository) FindExpiredOrderIDs(deadlines map[string]time.Time, defaultDeadline time.Time, afterID uint, limit int) ([]uint, error) {
+	methods := make([]string, 0, len(deadlines))
+	for method := range deadlines {
+		methods = append(methods, method)
+	}
+	
+	// 未单独配置的支付方式使用默认截止时间
+	expired := r.db.Where("created_at < ?", defaultDeadline)
+	if len(methods) > 0 {
+		expired = r.db.Where("payment_method NOT IN ? AND created_at < ?", methods, defaultDeadline)
+	}
+	for method, deadline := range deadlines {
+		expired = expired.Or("payment_method = ? AND created_at < ?", method, deadline)
+	}
+	
+	var ids []uint
+	err := r.db.Model(&model.Order{}).
+		Where("status = ? AND id > ?", model.OrderStatusPending, afterID).
+		Where(expired).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	
+	return ids, err
 }
 
 // GetOrdersByStatus 根据状态获取订单列表（管理员功能）
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PaymentRepository 支付记录数据访问层接口
+type PaymentRepository interface {
+	Create(payment *model.Payment) error                         // 创建支付记录
+	Update(payment *model.Payment) error                         // 更新支付记录
+	GetByPaymentNo(paymentNo string) (*model.Payment, error)     // 根据支付单号获取支付记录
+	GetByOrderID(orderID uint) ([]*model.Payment, error)         // 获取订单的全部支付记录
+	GetRefundableByOrderID(orderID uint) (*model.Payment, error) // 获取订单可退款的支付记录
+	GetActiveByOrderID(orderID uint) ([]*model.Payment, error)   // 获取订单进行中的支付记录
+	ListRefundPending(limit int) ([]*model.Payment, error)       // 获取待自动退款的支付记录
+}
+
+// paymentRepository 支付记录数据访问层实现
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository 创建支付记录数据访问层实例
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+// Create 创建支付记录
+func (r *paymentRepository) Create(payment *model.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+// Update 更新支付记录
+func (r *paymentRepository) Update(payment *model.Payment) error {
+	return r.db.Save(payment).Error
+}
+
+// GetByPaymentNo 根据支付单号获取支付记录
+func (r *paymentRepository) GetByPaymentNo(paymentNo string) (*model.Payment, error) {
+	var payment model.Payment
+
+	err := r.db.Where("payment_no = ?", paymentNo).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetByOrderID 获取订单的全部支付记录
+// 按创建时间倒序，最近一次支付尝试在前
+func (r *paymentRepository) GetByOrderID(orderID uint) ([]*model.Payment, error) {
+	var payments []*model.Payment
+
+	err := r.db.Where("order_id = ?", orderID).
+		Order("created_at DESC, id DESC").
+		Find(&payments).Error
+
+	return payments, err
+}
+
+// GetRefundableByOrderID 获取订单可退款（已完成、部分退款）的支付记录
+// 待自动退款的支付不是订单的实付款，不在其中
+func (r *paymentRepository) GetRefundableByOrderID(orderID uint) (*model.Payment, error) {
+	var payment model.Payment
+
+	err := r.db.Where("order_id = ? AND status IN ? AND refund_pending = ?", orderID,
+		[]model.PaymentStatus{model.PaymentStatusCompleted, model.PaymentStatusPartiallyRefunded}, false).
+		Order("id DESC").
+		First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// GetActiveByOrderID 获取订单进行中（待支付、处理中）的支付记录
+func (r *paymentRepository) GetActiveByOrderID(orderID uint) ([]*model.Payment, error) {
+	var payments []*model.Payment
+
+	err := r.db.Where("order_id = ? AND status IN ?", orderID,
+		[]model.PaymentStatus{model.PaymentStatusPending, model.PaymentStatusProcessing}).
+		Find(&payments).Error
+
+	return payments, err
+}
+
+// ListRefundPending 获取待自动退款的支付记录，按ID升序
+func (r *paymentRepository) ListRefundPending(limit int) ([]*model.Payment, error) {
+	var payments []*model.Payment
+
+	err := r.db.Where("refund_pending = ?", true).
+		Order("id ASC").
+		Limit(limit).
+		Find(&payments).Error
+
+	return payments, err
+}
+
+// TransitPayment 条件更新支付记录的状态
+// 只有当前状态在from中时才写入payment的状态、渠道交易号、支付时间、失败原因和待退款标记，
+// 返回false表示支付记录已被并发处理（如重复通知同时到达）
+func TransitPayment(tx *gorm.DB, payment *model.Payment, from ...model.PaymentStatus) (bool, error) {
+	result := tx.Model(payment).
+		Select("status", "transaction_id", "paid_at", "failure_reason", "refund_pending").
+		Where("status IN ?", from).
+		Updates(payment)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecordPaymentRefund 条件登记支付记录的退款
+// 只有状态和累计退款金额仍是退款前的值时才写入，返回false表示支付记录已被并发修改（如另一笔退款先登记）
+func RecordPaymentRefund(tx *gorm.DB, payment *model.Payment, fromStatus model.PaymentStatus, fromRefundAmount float64) (bool, error) {
+	result := tx.Model(payment).
+		Select("status", "refund_id", "refund_amount", "refund_reason", "refund_pending").
+		Where("status = ? AND refund_amount = ?", fromStatus, fromRefundAmount).
+		Updates(payment)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
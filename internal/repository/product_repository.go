@@ -2,11 +2,20 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"ryan-mall/internal/model"
+	"sort"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
+// ErrStockBelowReserved 库存少于待支付订单已预占的数量
+var ErrStockBelowReserved = errors.New("库存不能少于待支付订单已预占的数量")
+
+// ErrStockBelowWarehouses 商品库存少于各仓库库存与未分配库存已预占数量之和
+var ErrStockBelowWarehouses = errors.New("商品库存不能少于各仓库库存与未分配库存已预占数量之和")
+
 // ProductRepository 商品数据访问层接口
 type ProductRepository interface {
 	Create(product *model.Product) error                                    // 创建商品
@@ -17,6 +26,10 @@ type ProductRepository interface {
 	GetByCategoryID(categoryID uint) ([]*model.Product, error)            // 根据分类ID获取商品
 	UpdateStock(id uint, stock int) error                                 // 更新库存
 	UpdateSalesCount(id uint, count int) error                            // 更新销售数量
+	GetByIDs(ids []uint) ([]*model.Product, error)                        // 批量获取商品，包含已下架的
+	ListAfterID(afterID uint, limit int) ([]*model.Product, error)        // 按ID顺序分批获取上架的商品
+	Facets(req *model.ProductListRequest) (*model.ProductFacets, error)   // 统计商品列表的分面
+	ListSuggestSources() ([]*model.Product, error)                        // 获取上架商品的名称、品牌、分类和销量，用于搜索建议
 }
 
 // productRepository 商品数据访问层实现
@@ -41,8 +54,12 @@ func (r *productRepository) Create(product *model.Product) error {
 func (r *productRepository) GetByID(id uint) (*model.Product, error) {
 	var product model.Product
 	
-	// 使用Preload预加载关联的分类信息
-	err := r.db.Preload("Category").First(&product, id).Error
+	// 使用Preload预加载关联的分类信息和上架的SKU
+	err := r.db.Preload("Category").
+		Preload("Skus", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ?", model.SkuStatusOnline).Order("id ASC")
+		}).
+		First(&product, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // 商品不存在
@@ -54,8 +71,10 @@ func (r *productRepository) GetByID(id uint) (*model.Product, error) {
 }
 
 // Update 更新商品
+// 库存通过UpdateStock按条件更新，预占库存只由下单、支付、取消流程原子更新，
+// 规格只通过规格矩阵整体替换，这里都不覆盖
 func (r *productRepository) Update(product *model.Product) error {
-	return r.db.Save(product).Error
+	return r.db.Omit("stock", "reserved_stock", "specs", "Skus").Save(product).Error
 }
 
 // Delete 删除商品（软删除）
@@ -64,55 +83,274 @@ func (r *productRepository) Delete(id uint) error {
 }
 
 // List 分页查询商品列表
-// 支持关键词搜索、分类筛选、价格筛选、排序
+// 支持关键词搜索、分类、品牌、价格、规格属性筛选，只看有货，排序
 func (r *productRepository) List(req *model.ProductListRequest) ([]*model.Product, int64, error) {
 	var products []*model.Product
 	var total int64
 	
-	// 构建查询条件
-	query := r.db.Model(&model.Product{})
+	// 1. 构建查询条件
+	attrs, err := model.ParseAttrFilters(req.Attrs)
+	if err != nil {
+		return nil, 0, err
+	}
+	query := r.applyListFilters(r.db.Model(&model.Product{}), req, attrs, facetNone)
+	
+	// 2. 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	
+	// 3. 排序
+	orderBy := r.buildOrderBy(req.SortBy, req.SortOrder)
+	query = query.Order(orderBy)
+	
+	// 4. 分页
+	offset := (req.Page - 1) * req.PageSize
+	query = query.Offset(offset).Limit(req.PageSize)
 	
+	// 5. 预加载分类信息并执行查询
+	err = query.Preload("Category").Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	return products, total, nil
+}
+
+// listFacet 商品列表的分面，统计某个分面时不应用它自身的筛选条件
+type listFacet int
+
+const (
+	facetNone listFacet = iota
+	facetPrice
+	facetCategory
+	facetBrand
+	facetAttribute
+)
+
+// applyListFilters 应用商品列表的筛选条件，skip指定的分面不应用
+func (r *productRepository) applyListFilters(query *gorm.DB, req *model.ProductListRequest, attrs []model.AttrFilter, skip listFacet) *gorm.DB {
 	// 1. 关键词搜索（商品名称）
 	if req.Keyword != "" {
 		query = query.Where("name LIKE ?", "%"+req.Keyword+"%")
 	}
 	
 	// 2. 分类筛选
-	if req.CategoryID != nil {
+	if req.CategoryID != nil && skip != facetCategory {
 		query = query.Where("category_id = ?", *req.CategoryID)
 	}
 	
-	// 3. 价格筛选
-	if req.MinPrice != nil {
-		query = query.Where("price >= ?", *req.MinPrice)
+	// 3. 品牌筛选
+	if len(req.Brands) > 0 && skip != facetBrand {
+		query = query.Where("brand IN ?", req.Brands)
 	}
-	if req.MaxPrice != nil {
-		query = query.Where("price <= ?", *req.MaxPrice)
+	
+	// 4. 价格筛选
+	if skip != facetPrice {
+		if req.MinPrice != nil {
+			query = query.Where("price >= ?", *req.MinPrice)
+		}
+		if req.MaxPrice != nil {
+			query = query.Where("price <= ?", *req.MaxPrice)
+		}
 	}
 	
-	// 4. 只查询上架的商品（状态为1）
-	query = query.Where("status = ?", model.ProductStatusOnline)
+	// 5. 规格属性筛选：存在上架的SKU满足该属性的任一取值
+	if skip != facetAttribute {
+		for _, attr := range attrs {
+			conditions := make([]string, 0, len(attr.Values))
+			args := []interface{}{model.SkuStatusOnline}
+			for _, value := range attr.Values {
+				conditions = append(conditions, "CONCAT(';', product_skus.spec_key, ';') LIKE ?")
+				args = append(args, "%;"+escapeLike(attr.Name+":"+value)+";%")
+			}
+			query = query.Where("EXISTS (SELECT 1 FROM product_skus WHERE product_skus.product_id = products.id AND product_skus.status = ? AND ("+strings.Join(conditions, " OR ")+"))", args...)
+		}
+	}
 	
-	// 5. 统计总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	// 6. 只看有货
+	if req.InStock {
+		query = query.Where("stock > reserved_stock")
 	}
 	
-	// 6. 排序
-	orderBy := r.buildOrderBy(req.SortBy, req.SortOrder)
-	query = query.Order(orderBy)
+	// 7. 只查询上架的商品
+	return query.Where("status = ?", model.ProductStatusOnline)
+}
+
+// escapeLike 转义LIKE模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// Facets 统计商品列表的分面
+// 每个分面应用除自身以外的全部筛选条件；规格属性分面不应用任何规格属性筛选
+func (r *productRepository) Facets(req *model.ProductListRequest) (*model.ProductFacets, error) {
+	attrs, err := model.ParseAttrFilters(req.Attrs)
+	if err != nil {
+		return nil, err
+	}
+	facets := &model.ProductFacets{}
 	
-	// 7. 分页
-	offset := (req.Page - 1) * req.PageSize
-	query = query.Offset(offset).Limit(req.PageSize)
+	// 1. 价格区间
+	if facets.PriceRanges, err = r.priceRangeFacets(req, attrs); err != nil {
+		return nil, err
+	}
 	
-	// 8. 预加载分类信息并执行查询
-	err := query.Preload("Category").Find(&products).Error
+	// 2. 分类
+	if facets.Categories, err = r.categoryFacets(req, attrs); err != nil {
+		return nil, err
+	}
+	
+	// 3. 品牌
+	facets.Brands = []model.ValueFacet{}
+	err = r.applyListFilters(r.db.Model(&model.Product{}), req, attrs, facetBrand).
+		Select("brand AS value, COUNT(*) AS count").
+		Where("brand <> ''").
+		Group("brand").
+		Order("count DESC, brand ASC").
+		Limit(maxValueFacets).
+		Scan(&facets.Brands).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	
-	return products, total, nil
+	// 4. 规格属性
+	if facets.Attributes, err = r.attributeFacets(req); err != nil {
+		return nil, err
+	}
+	
+	return facets, nil
+}
+
+// maxValueFacets 品牌和每个规格属性最多返回的取值数量
+const maxValueFacets = 50
+
+// priceRangeFacets 按 model.PriceRangeBounds 统计各价格区间的商品数量
+func (r *productRepository) priceRangeFacets(req *model.ProductListRequest, attrs []model.AttrFilter) ([]model.PriceRangeFacet, error) {
+	bounds := model.PriceRangeBounds
+	bucket := "CASE"
+	for i, bound := range bounds {
+		bucket += fmt.Sprintf(" WHEN price < %g THEN %d", bound, i)
+	}
+	bucket += fmt.Sprintf(" ELSE %d END", len(bounds))
+
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	err := r.applyListFilters(r.db.Model(&model.Product{}), req, attrs, facetPrice).
+		Select(bucket + " AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]model.PriceRangeFacet, len(bounds)+1)
+	for i := range ranges {
+		if i > 0 {
+			ranges[i].Min = bounds[i-1]
+		}
+		if i < len(bounds) {
+			max := bounds[i]
+			ranges[i].Max = &max
+		}
+	}
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < len(ranges) {
+			ranges[row.Bucket].Count = row.Count
+		}
+	}
+	return ranges, nil
+}
+
+// categoryFacets 统计各分类的商品数量，按数量降序
+func (r *productRepository) categoryFacets(req *model.ProductListRequest, attrs []model.AttrFilter) ([]model.CategoryFacet, error) {
+	facets := []model.CategoryFacet{}
+	err := r.applyListFilters(r.db.Model(&model.Product{}), req, attrs, facetCategory).
+		Select("category_id AS id, COUNT(*) AS count").
+		Group("category_id").
+		Order("count DESC, category_id ASC").
+		Scan(&facets).Error
+	if err != nil || len(facets) == 0 {
+		return facets, err
+	}
+
+	// 分类名称单独查询，避免与商品表的同名字段冲突
+	ids := make([]uint, 0, len(facets))
+	for _, facet := range facets {
+		ids = append(ids, facet.ID)
+	}
+	var categories []model.Category
+	if err := r.db.Select("id", "name").Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	for i := range facets {
+		facets[i].Name = names[facets[i].ID]
+	}
+	return facets, nil
+}
+
+// attributeFacets 统计各规格属性取值的商品数量
+// 一个商品有多个SKU满足同一取值时只计一次；属性按首次出现的顺序，取值按数量降序
+func (r *productRepository) attributeFacets(req *model.ProductListRequest) ([]model.AttributeFacet, error) {
+	matched := r.applyListFilters(r.db.Model(&model.Product{}).Select("products.id"), req, nil, facetAttribute)
+	var rows []struct {
+		ProductID uint
+		SpecKey   string
+	}
+	err := r.db.Model(&model.ProductSku{}).
+		Distinct("product_id", "spec_key").
+		Where("status = ? AND product_id IN (?)", model.SkuStatusOnline, matched).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 按 属性名 -> 取值 -> 商品ID 去重计数
+	var names []string
+	counted := make(map[string]map[string]map[uint]bool)
+	for _, row := range rows {
+		for _, part := range strings.Split(row.SpecKey, ";") {
+			kv := strings.SplitN(part, ":", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			values, ok := counted[kv[0]]
+			if !ok {
+				values = make(map[string]map[uint]bool)
+				counted[kv[0]] = values
+				names = append(names, kv[0])
+			}
+			if values[kv[1]] == nil {
+				values[kv[1]] = make(map[uint]bool)
+			}
+			values[kv[1]][row.ProductID] = true
+		}
+	}
+
+	facets := make([]model.AttributeFacet, 0, len(names))
+	for _, name := range names {
+		values := make([]model.ValueFacet, 0, len(counted[name]))
+		for value, products := range counted[name] {
+			values = append(values, model.ValueFacet{Value: value, Count: int64(len(products))})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+		if len(values) > maxValueFacets {
+			values = values[:maxValueFacets]
+		}
+		facets = append(facets, model.AttributeFacet{Name: name, Values: values})
+	}
+	return facets, nil
 }
 
 // buildOrderBy 构建排序条件
@@ -147,12 +385,86 @@ func (r *productRepository) GetByCategoryID(categoryID uint) ([]*model.Product,
 	return products, err
 }
 
+// GetByIDs 批量获取商品，包含已下架的，不存在或已删除的商品不返回
+// 预加载分类和上架的SKU，供搜索索引同步和搜索结果回表使用
+func (r *productRepository) GetByIDs(ids []uint) ([]*model.Product, error) {
+	var products []*model.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	err := r.db.Preload("Category").
+		Preload("Skus", "status = ?", model.SkuStatusOnline).
+		Where("id IN ?", ids).
+		Find(&products).Error
+	return products, err
+}
+
+// ListAfterID 按ID顺序分批获取上架的商品
+// 用于全量重建搜索索引，afterID为上一批最后一个商品的ID
+func (r *productRepository) ListAfterID(afterID uint, limit int) ([]*model.Product, error) {
+	var products []*model.Product
+
+	err := r.db.Preload("Category").
+		Preload("Skus", "status = ?", model.SkuStatusOnline).
+		Where("id > ? AND status = ?", afterID, model.ProductStatusOnline).
+		Order("id ASC").
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+// ListSuggestSources 获取上架商品的ID、名称、品牌、分类和销量，用于搜索建议
+func (r *productRepository) ListSuggestSources() ([]*model.Product, error) {
+	var products []*model.Product
+
+	err := r.db.Select("id", "name", "brand", "category_id", "sales_count").
+		Where("status = ?", model.ProductStatusOnline).
+		Find(&products).Error
+	return products, err
+}
+
 // UpdateStock 更新库存
-// 使用原子操作确保库存更新的安全性
+// 使用原子操作确保库存更新的安全性。商品库存包含各仓库库存，
+// 更新后未分配到仓库的库存不能少于其已预占的数量；启用规格的商品库存由各SKU库存汇总，不能直接修改
 func (r *productRepository) UpdateStock(id uint, stock int) error {
-	return r.db.Model(&model.Product{}).
-		Where("id = ?", id).
-		Update("stock", stock).Error
+	result := r.db.Model(&model.Product{}).
+		Where("id = ? AND specs IS NULL AND reserved_stock + (?) <= ?", id, warehouseAvailableSum(r.db, id), stock).
+		Update("stock", stock)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+
+	// 没有更新到记录时区分库存未变化和新库存不足
+	var product model.Product
+	err := r.db.Select("id", "reserved_stock", "specs").First(&product, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if product.HasSkus() {
+		return ErrProductHasSkus
+	}
+	var warehouseAvailable int
+	if err := warehouseAvailableSum(r.db, id).Scan(&warehouseAvailable).Error; err != nil {
+		return err
+	}
+	if product.ReservedStock > stock {
+		return ErrStockBelowReserved
+	}
+	if product.ReservedStock+warehouseAvailable > stock {
+		return ErrStockBelowWarehouses
+	}
+	return nil
+}
+
+// warehouseAvailableSum 商品在各仓库可售库存之和的子查询
+func warehouseAvailableSum(db *gorm.DB, productID uint) *gorm.DB {
+	return db.Model(&model.WarehouseStock{}).
+		Select("COALESCE(SUM(stock - reserved_stock), 0)").
+		Where("product_id = ?", productID)
 }
 
 // UpdateSalesCount 更新销售数量
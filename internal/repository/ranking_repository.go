@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RankingRepository 热度排行数据访问层接口
+type RankingRepository interface {
+	SaveSnapshots(snapshots []*model.ProductRankingSnapshot) error // 保存排行快照，同一时间点重复保存时覆盖
+}
+
+// rankingRepository 热度排行数据访问层实现
+type rankingRepository struct {
+	db *gorm.DB
+}
+
+// NewRankingRepository 创建热度排行数据访问层实例
+func NewRankingRepository(db *gorm.DB) RankingRepository {
+	return &rankingRepository{
+		db: db,
+	}
+}
+
+// SaveSnapshots 保存排行快照
+// 依靠(snapshot_at, window, category_id, rank)唯一索引，同一小时内重复执行时覆盖之前的结果
+func (r *rankingRepository) SaveSnapshots(snapshots []*model.ProductRankingSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"product_id", "score"}),
+	}).CreateInBatches(snapshots, 500).Error
+}
+
+// ListOrderedProducts 在事务中获取订单各商品的分类和购买件数
+func ListOrderedProducts(tx *gorm.DB, orderID uint) ([]model.OrderedProduct, error) {
+	var products []model.OrderedProduct
+	err := tx.Table("order_items").
+		Select("order_items.product_id, products.category_id, SUM(order_items.quantity) AS quantity").
+		Joins("JOIN products ON products.id = order_items.product_id").
+		Where("order_items.order_id = ?", orderID).
+		Group("order_items.product_id, products.category_id").
+		Scan(&products).Error
+	return products, err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// RefundRepository 售后退款数据访问层接口
+type RefundRepository interface {
+	GetByID(id uint) (*model.Refund, error)                            // 根据ID获取退款申请
+	GetByOrderID(orderID uint) ([]*model.Refund, error)                // 获取订单的退款申请
+	List(req *model.RefundListRequest) ([]*model.Refund, int64, error) // 分页查询退款申请
+	HasOpen(orderID uint) (bool, error)                                // 订单是否有未结束的退款申请
+	ListRefundedProcessing(limit int) ([]*model.Refund, error)         // 获取渠道已退款但仍在退款中的申请
+}
+
+// refundRepository 售后退款数据访问层实现
+type refundRepository struct {
+	db *gorm.DB
+}
+
+// NewRefundRepository 创建售后退款数据访问层实例
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{
+		db: db,
+	}
+}
+
+// GetByID 根据ID获取退款申请
+func (r *refundRepository) GetByID(id uint) (*model.Refund, error) {
+	var refund model.Refund
+
+	err := r.db.Preload("Items").First(&refund, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// GetByOrderID 获取订单的退款申请
+func (r *refundRepository) GetByOrderID(orderID uint) ([]*model.Refund, error) {
+	var refunds []*model.Refund
+
+	err := r.db.Where("order_id = ?", orderID).
+		Preload("Items").
+		Order("created_at DESC").
+		Find(&refunds).Error
+
+	return refunds, err
+}
+
+// List 分页查询退款申请
+func (r *refundRepository) List(req *model.RefundListRequest) ([]*model.Refund, int64, error) {
+	var refunds []*model.Refund
+	var total int64
+
+	query := r.db.Model(&model.Refund{})
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Preload("Items").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&refunds).Error
+
+	return refunds, total, err
+}
+
+// HasOpen 订单是否有未结束（待审核、退款中）的退款申请
+func (r *refundRepository) HasOpen(orderID uint) (bool, error) {
+	var count int64
+
+	err := r.db.Model(&model.Refund{}).
+		Where("order_id = ? AND status IN ?", orderID,
+			[]model.RefundStatus{model.RefundStatusPending, model.RefundStatusProcessing}).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// ListRefundedProcessing 获取渠道已退款（已有渠道退款单号）但仍在退款中的申请，按ID升序
+func (r *refundRepository) ListRefundedProcessing(limit int) ([]*model.Refund, error) {
+	var refunds []*model.Refund
+
+	err := r.db.Where("status = ? AND payment_ref_id <> ?", model.RefundStatusProcessing, "").
+		Preload("Items").
+		Order("id ASC").
+		Limit(limit).
+		Find(&refunds).Error
+
+	return refunds, err
+}
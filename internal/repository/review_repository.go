@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewRepository 商品评价数据访问层接口
+type ReviewRepository interface {
+	Create(review *model.ProductReview) (bool, error)                                                         // 创建评价，订单商品已评价时返回false
+	GetByID(id uint) (*model.ProductReview, error)                                                            // 根据ID获取评价
+	GetOrderItem(id uint) (*model.OrderItem, error)                                                           // 获取订单商品及其订单，用于校验评价资格
+	IsOrderDelivered(orderID uint) (bool, error)                                                              // 订单是否确认收货过
+	ListByProduct(productID uint, req *model.ProductReviewListRequest) ([]*model.ProductReview, int64, error) // 分页查询商品审核通过的评价
+	List(userID uint, req *model.ReviewListRequest) ([]*model.ProductReview, int64, error)                    // 分页查询评价，userID为0时查询全部用户
+	RatingCounts(productID uint) (map[int]int64, error)                                                       // 统计商品审核通过的评价各星级数量
+	Moderate(id uint, status model.ReviewStatus, moderatorID uint, reason string) (bool, error)               // 审核评价，状态未变化时返回false
+	Reply(id uint, content string) error                                                                      // 商家回复评价
+	AddHelpfulVote(reviewID, userID uint) (bool, error)                                                       // 投票评价有用，已投过时返回false
+	RemoveHelpfulVote(reviewID, userID uint) (bool, error)                                                    // 取消有用投票，未投过时返回false
+}
+
+// reviewRepository 商品评价数据访问层实现
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository 创建商品评价数据访问层实例
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{
+		db: db,
+	}
+}
+
+// Create 创建评价
+// 依靠order_item_id唯一索引保证并发提交时每个订单商品只有一条评价
+func (r *reviewRepository) Create(review *model.ProductReview) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(review)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetByID 根据ID获取评价
+func (r *reviewRepository) GetByID(id uint) (*model.ProductReview, error) {
+	var review model.ProductReview
+
+	err := r.db.First(&review, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// GetOrderItem 获取订单商品及其订单
+func (r *reviewRepository) GetOrderItem(id uint) (*model.OrderItem, error) {
+	var item model.OrderItem
+
+	err := r.db.Preload("Order").First(&item, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// IsOrderDelivered 订单是否确认收货过
+// 根据订单状态历史判断，确认收货后申请退款或部分退款的订单也算已收货
+func (r *reviewRepository) IsOrderDelivered(orderID uint) (bool, error) {
+	var count int64
+
+	err := r.db.Model(&model.OrderStatusLog{}).
+		Where("order_id = ? AND to_status = ?", orderID, model.OrderStatusDelivered).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// ListByProduct 分页查询商品审核通过的评价
+func (r *reviewRepository) ListByProduct(productID uint, req *model.ProductReviewListRequest) ([]*model.ProductReview, int64, error) {
+	var reviews []*model.ProductReview
+	var total int64
+
+	query := r.db.Model(&model.ProductReview{}).
+		Where("product_id = ? AND status = ?", productID, model.ReviewStatusApproved)
+	if req.Rating > 0 {
+		query = query.Where("rating = ?", req.Rating)
+	}
+	if req.WithImages {
+		query = query.Where("images IS NOT NULL AND JSON_LENGTH(images) > 0")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if req.Sort == model.ReviewSortHelpful {
+		query = query.Order("helpful_count DESC")
+	}
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+// List 分页查询评价
+func (r *reviewRepository) List(userID uint, req *model.ReviewListRequest) ([]*model.ProductReview, int64, error) {
+	var reviews []*model.ProductReview
+	var total int64
+
+	query := r.db.Model(&model.ProductReview{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if req.ProductID != 0 {
+		query = query.Where("product_id = ?", req.ProductID)
+	}
+	if req.Status != nil {
+		query = query.Where("status = ?", *req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (req.Page - 1) * req.PageSize
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+// RatingCounts 统计商品审核通过的评价各星级数量
+func (r *reviewRepository) RatingCounts(productID uint) (map[int]int64, error) {
+	var rows []struct {
+		Rating int
+		Count  int64
+	}
+
+	err := r.db.Model(&model.ProductReview{}).
+		Select("rating, COUNT(*) AS count").
+		Where("product_id = ? AND status = ?", productID, model.ReviewStatusApproved).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Rating] = row.Count
+	}
+	return counts, nil
+}
+
+// Moderate 审核评价
+// 只在状态发生变化时更新，审核通过时清除之前的驳回原因
+func (r *reviewRepository) Moderate(id uint, status model.ReviewStatus, moderatorID uint, reason string) (bool, error) {
+	result := r.db.Model(&model.ProductReview{}).
+		Where("id = ? AND status <> ?", id, status).
+		Updates(map[string]interface{}{
+			"status":        status,
+			"reject_reason": reason,
+			"moderator_id":  moderatorID,
+			"moderated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Reply 商家回复评价，再次回复时覆盖
+func (r *reviewRepository) Reply(id uint, content string) error {
+	return r.db.Model(&model.ProductReview{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"reply":      content,
+			"replied_at": time.Now(),
+		}).Error
+}
+
+// AddHelpfulVote 投票评价有用
+// 依靠(review_id, user_id)唯一索引防止重复投票，投票成功时在同一事务中增加有用数
+func (r *reviewRepository) AddHelpfulVote(reviewID, userID uint) (bool, error) {
+	added := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.ReviewHelpfulVote{ReviewID: reviewID, UserID: userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		added = true
+		return tx.Model(&model.ProductReview{}).
+			Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+	return added, err
+}
+
+// RemoveHelpfulVote 取消有用投票，同时减少有用数
+func (r *reviewRepository) RemoveHelpfulVote(reviewID, userID uint) (bool, error) {
+	removed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).
+			Delete(&model.ReviewHelpfulVote{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		removed = true
+		return tx.Model(&model.ProductReview{}).
+			Where("id = ? AND helpful_count > 0", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error
+	})
+	return removed, err
+}
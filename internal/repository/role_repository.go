@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository 角色权限数据访问层接口
+type RoleRepository interface {
+	EnsurePermissions(permissions []model.Permission) error           // 写入权限，已存在的忽略
+	ListPermissions() ([]*model.Permission, error)                    // 获取全部权限
+	GetPermissionsByCodes(codes []string) ([]model.Permission, error) // 按编码获取权限
+	List() ([]*model.Role, error)                                     // 获取全部角色及其权限
+	GetByID(id uint) (*model.Role, error)                             // 根据ID获取角色及其权限
+	GetByNames(names []string) ([]*model.Role, error)                 // 按名称获取角色
+	Create(role *model.Role) error                                    // 创建角色及其权限
+	Update(role *model.Role, permissions []model.Permission) error    // 更新角色，permissions不为nil时替换角色的权限
+	Delete(id uint) error                                             // 删除角色，同时移除用户的该角色
+	ListRolePermissions() (map[string][]string, error)                // 获取各角色的权限编码
+	ListUserRoleNames(userID uint) ([]string, error)                  // 获取用户的角色名称
+	SetUserRoles(userID uint, roleIDs []uint) error                   // 覆盖设置用户的角色
+	AddUserRole(userID, roleID uint) error                            // 给用户添加角色，已有时忽略
+}
+
+// roleRepository 角色权限数据访问层实现
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建角色权限数据访问层实例
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+// EnsurePermissions 写入权限，依靠编码唯一索引忽略已存在的权限
+func (r *roleRepository) EnsurePermissions(permissions []model.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&permissions).Error
+}
+
+// ListPermissions 获取全部权限
+func (r *roleRepository) ListPermissions() ([]*model.Permission, error) {
+	var permissions []*model.Permission
+	err := r.db.Order("code ASC").Find(&permissions).Error
+	return permissions, err
+}
+
+// GetPermissionsByCodes 按编码获取权限
+func (r *roleRepository) GetPermissionsByCodes(codes []string) ([]model.Permission, error) {
+	var permissions []model.Permission
+	if len(codes) == 0 {
+		return permissions, nil
+	}
+	err := r.db.Where("code IN ?", codes).Find(&permissions).Error
+	return permissions, err
+}
+
+// List 获取全部角色及其权限
+func (r *roleRepository) List() ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetByID 根据ID获取角色及其权限
+func (r *roleRepository) GetByID(id uint) (*model.Role, error) {
+	var role model.Role
+	err := r.db.Preload("Permissions").First(&role, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetByNames 按名称获取角色
+func (r *roleRepository) GetByNames(names []string) ([]*model.Role, error) {
+	var roles []*model.Role
+	if len(names) == 0 {
+		return roles, nil
+	}
+	err := r.db.Where("name IN ?", names).Find(&roles).Error
+	return roles, err
+}
+
+// Create 创建角色及其权限
+// 权限已存在，只写入角色权限关联
+func (r *roleRepository) Create(role *model.Role) error {
+	return r.db.Omit("Permissions.*").Create(role).Error
+}
+
+// Update 更新角色
+func (r *roleRepository) Update(role *model.Role, permissions []model.Permission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Select("description").Updates(role).Error; err != nil {
+			return err
+		}
+		if permissions == nil {
+			return nil
+		}
+		return tx.Model(role).Omit("Permissions.*").Association("Permissions").Replace(permissions)
+	})
+}
+
+// Delete 删除角色，同时移除角色权限关联和用户的该角色
+func (r *roleRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Role{ID: id}).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, id).Error
+	})
+}
+
+// ListRolePermissions 获取各角色的权限编码
+func (r *roleRepository) ListRolePermissions() (map[string][]string, error) {
+	var rows []struct {
+		Role       string
+		Permission string
+	}
+	err := r.db.Table("role_permissions").
+		Select("roles.name AS role, permissions.code AS permission").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, row := range rows {
+		result[row.Role] = append(result[row.Role], row.Permission)
+	}
+	return result, nil
+}
+
+// ListUserRoleNames 获取用户的角色名称
+func (r *roleRepository) ListUserRoleNames(userID uint) ([]string, error) {
+	var names []string
+	err := r.db.Table("user_roles").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name ASC").
+		Pluck("roles.name", &names).Error
+	return names, err
+}
+
+// SetUserRoles 覆盖设置用户的角色
+func (r *roleRepository) SetUserRoles(userID uint, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		userRoles := make([]model.UserRole, 0, len(roleIDs))
+		for _, roleID := range roleIDs {
+			userRoles = append(userRoles, model.UserRole{UserID: userID, RoleID: roleID})
+		}
+		return tx.Create(&userRoles).Error
+	})
+}
+
+// AddUserRole 给用户添加角色，依靠主键忽略已有的角色
+func (r *roleRepository) AddUserRole(userID, roleID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.UserRole{UserID: userID, RoleID: roleID}).Error
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShipmentRepository 包裹数据访问层接口
+type ShipmentRepository interface {
+	GetByID(id uint) (*model.Shipment, error)                                          // 根据ID获取包裹
+	GetByOrderID(orderID uint) ([]*model.Shipment, error)                              // 获取订单的所有包裹
+	GetByTracking(carrier, trackingNo string) (*model.Shipment, error)                 // 根据运单号获取包裹
+	HasEvent(shipmentID uint, status model.ShipmentStatus, at time.Time) (bool, error) // 轨迹是否已记录
+	FindAutoConfirmOrders(before time.Time, limit int) ([]*model.Order, error)         // 查找可自动确认收货的订单
+}
+
+// shipmentRepository 包裹数据访问层实现
+type shipmentRepository struct {
+	db *gorm.DB
+}
+
+// NewShipmentRepository 创建包裹数据访问层实例
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &shipmentRepository{
+		db: db,
+	}
+}
+
+// GetByID 根据ID获取包裹
+func (r *shipmentRepository) GetByID(id uint) (*model.Shipment, error) {
+	var shipment model.Shipment
+
+	err := r.db.Preload("Items").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("occurred_at ASC")
+		}).
+		First(&shipment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+// GetByOrderID 获取订单的所有包裹，包含商品和物流轨迹
+func (r *shipmentRepository) GetByOrderID(orderID uint) ([]*model.Shipment, error) {
+	var shipments []*model.Shipment
+
+	err := r.db.Where("order_id = ?", orderID).
+		Preload("Items").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("occurred_at ASC")
+		}).
+		Order("id ASC").
+		Find(&shipments).Error
+
+	return shipments, err
+}
+
+// GetByTracking 根据物流公司和运单号获取包裹
+func (r *shipmentRepository) GetByTracking(carrier, trackingNo string) (*model.Shipment, error) {
+	var shipment model.Shipment
+
+	err := r.db.Where("carrier = ? AND tracking_no = ?", carrier, trackingNo).First(&shipment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+// HasEvent 轨迹是否已记录，用于忽略物流公司的重复推送
+func (r *shipmentRepository) HasEvent(shipmentID uint, status model.ShipmentStatus, at time.Time) (bool, error) {
+	var count int64
+
+	err := r.db.Model(&model.ShipmentEvent{}).
+		Where("shipment_id = ? AND status = ? AND occurred_at = ?", shipmentID, status, at).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// FindAutoConfirmOrders 查找可自动确认收货的订单，只返回订单ID和状态
+// 已发货订单，以及发货后部分退款、尚未确认收货的订单，所有包裹最近一条轨迹（没有轨迹时为发货时间）都早于before
+func (r *shipmentRepository) FindAutoConfirmOrders(before time.Time, limit int) ([]*model.Order, error) {
+	var orders []*model.Order
+
+	reached := func(status model.OrderStatus) *gorm.DB {
+		return r.db.Model(&model.OrderStatusLog{}).
+			Select("1").
+			Where("order_status_logs.order_id = orders.id AND order_status_logs.to_status = ?", status)
+	}
+	err := r.db.Model(&model.Shipment{}).
+		Select("orders.id, orders.status").
+		Joins("JOIN orders ON orders.id = shipments.order_id").
+		Where("orders.status = ? OR (orders.status = ? AND EXISTS (?) AND NOT EXISTS (?))",
+			model.OrderStatusShipped, model.OrderStatusPartiallyRefunded,
+			reached(model.OrderStatusShipped), reached(model.OrderStatusDelivered)).
+		Group("orders.id, orders.status").
+		Having("MAX(COALESCE(shipments.last_event_at, shipments.shipped_at)) < ?", before).
+		Order("orders.id ASC").
+		Limit(limit).
+		Scan(&orders).Error
+
+	return orders, err
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrProductHasSkus 启用规格的商品不能直接修改商品库存
+var ErrProductHasSkus = errors.New("商品已启用规格，请修改规格的库存")
+
+// SkuRepository 商品规格数据访问层接口
+type SkuRepository interface {
+	GetByID(id uint) (*model.ProductSku, error)                                       // 根据ID获取SKU
+	ListByProduct(productID uint) ([]*model.ProductSku, error)                        // 获取商品的全部SKU（包含已下架）
+	SaveMatrix(productID uint, specs model.JSONSpecs, skus []*model.ProductSku) error // 整体替换商品的规格矩阵
+}
+
+// skuRepository 商品规格数据访问层实现
+type skuRepository struct {
+	db *gorm.DB
+}
+
+// NewSkuRepository 创建商品规格数据访问层实例
+func NewSkuRepository(db *gorm.DB) SkuRepository {
+	return &skuRepository{
+		db: db,
+	}
+}
+
+// GetByID 根据ID获取SKU
+func (r *skuRepository) GetByID(id uint) (*model.ProductSku, error) {
+	var sku model.ProductSku
+
+	err := r.db.First(&sku, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &sku, nil
+}
+
+// ListByProduct 获取商品的全部SKU（包含已下架）
+func (r *skuRepository) ListByProduct(productID uint) ([]*model.ProductSku, error) {
+	var skus []*model.ProductSku
+	err := r.db.Where("product_id = ?", productID).Order("id ASC").Find(&skus).Error
+	return skus, err
+}
+
+// SaveMatrix 整体替换商品的规格矩阵
+// 按属性组合匹配已有SKU：已有的更新，新的创建，请求中没有的下架并只保留已预占的库存。
+// 商品库存更新为各SKU库存之和，商品价格更新为上架SKU的最低价。
+// 先锁商品再锁SKU，与下单时的加锁顺序一致
+func (r *skuRepository) SaveMatrix(productID uint, specs model.JSONSpecs, skus []*model.ProductSku) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定商品和已有SKU
+		var product model.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "stock", "reserved_stock").
+			First(&product, productID).Error
+		if err != nil {
+			return err
+		}
+		var existing []model.ProductSku
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ?", productID).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		bySpecKey := make(map[string]*model.ProductSku, len(existing))
+		for i := range existing {
+			bySpecKey[existing[i].SpecKey] = &existing[i]
+		}
+
+		// 2. 更新或创建请求中的SKU
+		stock := 0
+		price := 0.0
+		for i, sku := range skus {
+			if i == 0 || sku.Price < price {
+				price = sku.Price
+			}
+			stock += sku.Stock
+
+			current, ok := bySpecKey[sku.SpecKey]
+			if !ok {
+				sku.ProductID = productID
+				sku.Status = model.SkuStatusOnline
+				if err := tx.Create(sku).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			delete(bySpecKey, sku.SpecKey)
+
+			if sku.Stock < current.ReservedStock {
+				return fmt.Errorf("规格 %s 的库存不能少于待支付订单已预占的数量 %d", sku.SpecKey, current.ReservedStock)
+			}
+			err = tx.Model(&model.ProductSku{}).
+				Where("id = ?", current.ID).
+				Updates(map[string]interface{}{
+					"sku_code":   sku.SkuCode,
+					"attributes": sku.Attributes,
+					"price":      sku.Price,
+					"stock":      sku.Stock,
+					"image":      sku.Image,
+					"status":     model.SkuStatusOnline,
+				}).Error
+			if err != nil {
+				return err
+			}
+			sku.ID = current.ID
+		}
+
+		// 3. 下架请求中没有的SKU，已预占的库存保留到订单支付或取消
+		for _, removed := range bySpecKey {
+			stock += removed.ReservedStock
+			err = tx.Model(&model.ProductSku{}).
+				Where("id = ?", removed.ID).
+				Updates(map[string]interface{}{
+					"stock":  removed.ReservedStock,
+					"status": model.SkuStatusOffline,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// 4. 未分配到仓库的库存不能少于其已预占的数量
+		var warehouseAvailable int
+		if err := warehouseAvailableSum(tx, productID).Scan(&warehouseAvailable).Error; err != nil {
+			return err
+		}
+		if product.ReservedStock > stock {
+			return ErrStockBelowReserved
+		}
+		if product.ReservedStock+warehouseAvailable > stock {
+			return ErrStockBelowWarehouses
+		}
+
+		// 5. 更新商品的规格定义、库存和价格
+		return tx.Model(&model.Product{}).
+			Where("id = ?", productID).
+			Updates(map[string]interface{}{
+				"specs": specs,
+				"stock": stock,
+				"price": price,
+			}).Error
+	})
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWarehouseStockBelowReserved 仓库库存少于待支付订单已预占的数量
+var ErrWarehouseStockBelowReserved = errors.New("仓库库存不能少于待支付订单已预占的数量")
+
+// WarehouseRepository 仓库数据访问层接口
+type WarehouseRepository interface {
+	Create(warehouse *model.Warehouse) error                          // 创建仓库
+	GetByID(id uint) (*model.Warehouse, error)                        // 根据ID获取仓库
+	GetByCode(code string) (*model.Warehouse, error)                  // 根据编码获取仓库
+	List() ([]*model.Warehouse, error)                                // 获取全部仓库
+	Update(warehouse *model.Warehouse) error                          // 更新仓库信息
+	SetStock(warehouseID, productID uint, stock int) error            // 设置商品在仓库的库存
+	ListProductStocks(productID uint) ([]model.WarehouseStock, error) // 获取商品在各仓库的库存
+}
+
+// warehouseRepository 仓库数据访问层实现
+type warehouseRepository struct {
+	db *gorm.DB
+}
+
+// NewWarehouseRepository 创建仓库数据访问层实例
+func NewWarehouseRepository(db *gorm.DB) WarehouseRepository {
+	return &warehouseRepository{
+		db: db,
+	}
+}
+
+// Create 创建仓库
+func (r *warehouseRepository) Create(warehouse *model.Warehouse) error {
+	return r.db.Create(warehouse).Error
+}
+
+// GetByID 根据ID获取仓库
+func (r *warehouseRepository) GetByID(id uint) (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+
+	err := r.db.First(&warehouse, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+// GetByCode 根据编码获取仓库
+func (r *warehouseRepository) GetByCode(code string) (*model.Warehouse, error) {
+	var warehouse model.Warehouse
+
+	err := r.db.Where("code = ?", code).First(&warehouse).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &warehouse, nil
+}
+
+// List 获取全部仓库
+func (r *warehouseRepository) List() ([]*model.Warehouse, error) {
+	var warehouses []*model.Warehouse
+	err := r.db.Order("id ASC").Find(&warehouses).Error
+	return warehouses, err
+}
+
+// Update 更新仓库信息
+func (r *warehouseRepository) Update(warehouse *model.Warehouse) error {
+	return r.db.Save(warehouse).Error
+}
+
+// SetStock 设置商品在仓库的库存
+// 商品库存包含各仓库库存，仓库库存的变化量同时计入商品库存，未分配库存保持不变。
+// 先锁商品再锁仓库库存，与下单时的加锁顺序一致
+func (r *warehouseRepository) SetStock(warehouseID, productID uint, stock int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定商品
+		var product model.Product
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&product, productID).Error
+		if err != nil {
+			return err
+		}
+
+		// 2. 锁定仓库库存，不存在时创建
+		var current model.WarehouseStock
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("warehouse_id = ? AND product_id = ?", warehouseID, productID).
+			First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			current = model.WarehouseStock{WarehouseID: warehouseID, ProductID: productID}
+			err = tx.Create(&current).Error
+		}
+		if err != nil {
+			return err
+		}
+		if stock < current.ReservedStock {
+			return ErrWarehouseStockBelowReserved
+		}
+		delta := stock - current.Stock
+		if delta == 0 {
+			return nil
+		}
+
+		// 3. 更新仓库库存和商品库存
+		err = tx.Model(&model.WarehouseStock{}).
+			Where("id = ?", current.ID).
+			Update("stock", stock).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.Product{}).
+			Where("id = ?", productID).
+			Update("stock", gorm.Expr("stock + ?", delta)).Error
+	})
+}
+
+// ListProductStocks 获取商品在各仓库的库存
+func (r *warehouseRepository) ListProductStocks(productID uint) ([]model.WarehouseStock, error) {
+	var stocks []model.WarehouseStock
+	err := r.db.Preload("Warehouse").
+		Where("product_id = ?", productID).
+		Order("warehouse_id ASC").
+		Find(&stocks).Error
+	return stocks, err
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+)
+
+// AddressService 收货地址业务逻辑层接口
+type AddressService interface {
+	ListAddresses(userID uint) ([]*model.Address, error)                                     // 获取收货地址列表
+	GetAddress(userID, addressID uint) (*model.Address, error)                               // 获取收货地址详情
+	CreateAddress(userID uint, req *model.AddressRequest) (*model.Address, error)            // 新增收货地址
+	UpdateAddress(userID, addressID uint, req *model.AddressRequest) (*model.Address, error) // 修改收货地址
+	DeleteAddress(userID, addressID uint) error                                              // 删除收货地址
+	SetDefaultAddress(userID, addressID uint) error                                          // 设置默认地址
+}
+
+// addressService 收货地址业务逻辑层实现
+type addressService struct {
+	addressRepo repository.AddressRepository
+}
+
+// NewAddressService 创建收货地址业务逻辑层实例
+func NewAddressService(addressRepo repository.AddressRepository) AddressService {
+	return &addressService{
+		addressRepo: addressRepo,
+	}
+}
+
+// ListAddresses 获取收货地址列表
+func (s *addressService) ListAddresses(userID uint) ([]*model.Address, error) {
+	return s.addressRepo.GetByUserID(userID)
+}
+
+// GetAddress 获取收货地址详情
+func (s *addressService) GetAddress(userID, addressID uint) (*model.Address, error) {
+	address, err := s.addressRepo.GetByID(addressID)
+	if err != nil {
+		return nil, err
+	}
+	// 不属于当前用户的地址按不存在处理
+	if address == nil || address.UserID != userID {
+		return nil, errors.New("收货地址不存在")
+	}
+	return address, nil
+}
+
+// CreateAddress 新增收货地址
+// 用户的第一个地址自动设为默认地址
+func (s *addressService) CreateAddress(userID uint, req *model.AddressRequest) (*model.Address, error) {
+	// 1. 校验地址
+	info := req.ToJSONAddress()
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 2. 检查地址数量上限
+	count, err := s.addressRepo.CountByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= model.MaxAddressesPerUser {
+		return nil, fmt.Errorf("最多只能保存%d个收货地址", model.MaxAddressesPerUser)
+	}
+
+	// 3. 保存地址
+	address := &model.Address{UserID: userID}
+	applyAddress(address, info)
+	address.IsDefault = req.IsDefault || count == 0
+
+	if err := s.addressRepo.Create(address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// UpdateAddress 修改收货地址
+// 已下单的订单保存的是地址快照，不受修改影响
+func (s *addressService) UpdateAddress(userID, addressID uint, req *model.AddressRequest) (*model.Address, error) {
+	// 1. 校验地址
+	info := req.ToJSONAddress()
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 2. 获取地址并检查归属
+	address, err := s.GetAddress(userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 更新地址，默认地址只能通过设置其他地址为默认来取消
+	applyAddress(address, info)
+	address.IsDefault = address.IsDefault || req.IsDefault
+
+	if err := s.addressRepo.Update(address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// DeleteAddress 删除收货地址
+func (s *addressService) DeleteAddress(userID, addressID uint) error {
+	address, err := s.GetAddress(userID, addressID)
+	if err != nil {
+		return err
+	}
+	return s.addressRepo.Delete(address)
+}
+
+// SetDefaultAddress 设置默认地址
+func (s *addressService) SetDefaultAddress(userID, addressID uint) error {
+	if _, err := s.GetAddress(userID, addressID); err != nil {
+		return err
+	}
+	return s.addressRepo.SetDefault(userID, addressID)
+}
+
+// applyAddress 将地址信息写入地址簿记录
+func applyAddress(address *model.Address, info model.JSONAddress) {
+	address.Name = info.Name
+	address.Phone = info.Phone
+	address.Province = info.Province
+	address.City = info.City
+	address.District = info.District
+	address.Address = info.Address
+	address.Zipcode = info.Zipcode
+}
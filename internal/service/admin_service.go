@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdminService 管理后台业务逻辑层接口
+type AdminService interface {
+	SearchOrders(req *model.AdminOrderSearchRequest) (*model.AdminOrderSearchResponse, error)                      // 搜索全部用户的订单
+	ForceOrderStatus(adminID, orderID uint, req *model.ForceOrderStatusRequest) error                              // 强制变更订单状态
+	ListUsers(req *model.AdminUserListRequest) (*model.AdminUserListResponse, error)                               // 分页查询用户
+	UpdateUserStatus(adminID, userID uint, req *model.UpdateUserStatusRequest) (*model.UserProfileResponse, error) // 禁用、启用用户
+	BulkUpdateProductStatus(req *model.BulkProductStatusRequest) (*model.BulkProductStatusResponse, error)         // 批量上下架商品
+	SalesDashboard(req *model.SalesDashboardRequest) (*model.SalesDashboard, error)                                // 销售看板
+	ListAuditLogs(req *model.AdminAuditLogListRequest) (*model.AdminAuditLogListResponse, error)                   // 查询审计日志
+}
+
+// adminService 管理后台业务逻辑层实现
+type adminService struct {
+	adminRepo      repository.AdminRepository
+	auditRepo      repository.AuditLogRepository
+	orderRepo      repository.OrderRepository
+	userRepo       repository.UserRepository
+	userService    UserService
+	productService *CachedProductService
+	states         *OrderStateMachine
+	db             *gorm.DB
+}
+
+// NewAdminService 创建管理后台业务逻辑层实例
+func NewAdminService(adminRepo repository.AdminRepository, auditRepo repository.AuditLogRepository, orderRepo repository.OrderRepository, userRepo repository.UserRepository, userService UserService, productService *CachedProductService, states *OrderStateMachine, db *gorm.DB) AdminService {
+	return &adminService{
+		adminRepo:      adminRepo,
+		auditRepo:      auditRepo,
+		orderRepo:      orderRepo,
+		userRepo:       userRepo,
+		userService:    userService,
+		productService: productService,
+		states:         states,
+		db:             db,
+	}
+}
+
+// SearchOrders 搜索全部用户的订单
+func (s *adminService) SearchOrders(req *model.AdminOrderSearchRequest) (*model.AdminOrderSearchResponse, error) {
+	orders, total, err := s.adminRepo.SearchOrders(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AdminOrderSearchResponse{
+		Orders:     orders,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages(total, req.PageSize),
+	}, nil
+}
+
+// ForceOrderStatus 强制变更订单状态
+// 跳过状态校验（如线下发货时不要求全部商品已登记发货），用于处理异常订单；
+// 只允许不涉及资金和库存变动的变更，见model.CanForceOrder。仍然记录订单时间线并执行状态钩子
+func (s *adminService) ForceOrderStatus(adminID, orderID uint, req *model.ForceOrderStatusRequest) error {
+	// 1. 检查目标状态
+	if !model.IsValidOrderStatus(req.Status) {
+		return errors.New("订单状态不存在")
+	}
+
+	// 2. 获取订单
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return errors.New("订单不存在")
+	}
+	if order.Status == req.Status {
+		return errors.New("订单已经是" + model.GetOrderStatusText(req.Status) + "状态")
+	}
+	if !model.CanForceOrder(order.Status, req.Status) {
+		return errors.New("不允许将订单从" + model.GetOrderStatusText(order.Status) +
+			"强制变更为" + model.GetOrderStatusText(req.Status) + "，请走正常流程")
+	}
+
+	// 3. 通过状态机变更状态
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.states.Transit(tx, &OrderTransition{
+			OrderID: order.ID,
+			From:    order.Status,
+			To:      req.Status,
+			Actor:   model.OrderActorAdmin(adminID),
+			Reason:  "管理员强制变更: " + req.Reason,
+			Force:   true,
+		})
+	})
+}
+
+// ListUsers 分页查询用户
+func (s *adminService) ListUsers(req *model.AdminUserListRequest) (*model.AdminUserListResponse, error) {
+	users, total, err := s.adminRepo.ListUsers(req)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*model.UserProfileResponse, 0, len(users))
+	for _, user := range users {
+		profiles = append(profiles, user.ToProfileResponse())
+	}
+	return &model.AdminUserListResponse{
+		Users:      profiles,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages(total, req.PageSize),
+	}, nil
+}
+
+// UpdateUserStatus 禁用、启用用户
+// 禁用后用户不能登录，已签发的令牌在本实例立即被拒绝，其他实例在用户状态缓存过期后（最多30秒）拒绝
+func (s *adminService) UpdateUserStatus(adminID, userID uint, req *model.UpdateUserStatusRequest) (*model.UserProfileResponse, error) {
+	// 1. 获取用户
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 2. 不能禁用自己，避免管理员把自己锁在后台之外
+	status := *req.Status
+	if userID == adminID && status == model.UserStatusDisabled {
+		return nil, errors.New("不能禁用自己的账户")
+	}
+
+	// 3. 更新状态
+	if err := s.adminRepo.UpdateUserStatus(userID, status); err != nil {
+		return nil, err
+	}
+	s.userService.InvalidateStatus(userID)
+
+	user.Status = status
+	return user.ToProfileResponse(), nil
+}
+
+// BulkUpdateProductStatus 批量上下架商品，同时清除商品缓存并同步搜索索引
+func (s *adminService) BulkUpdateProductStatus(req *model.BulkProductStatusRequest) (*model.BulkProductStatusResponse, error) {
+	ids := uniqueIDs(req.IDs)
+	updated, err := s.adminRepo.UpdateProductStatus(ids, *req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated > 0 {
+		s.productService.InvalidateProducts(ids...)
+	}
+	return &model.BulkProductStatusResponse{Updated: updated}, nil
+}
+
+// SalesDashboard 统计最近req.Days天（含今天）的销售数据
+func (s *adminService) SalesDashboard(req *model.SalesDashboardRequest) (*model.SalesDashboard, error) {
+	// 1. 计算统计区间[start, end)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, 1-req.Days)
+	end := today.AddDate(0, 0, 1)
+
+	// 2. 按天统计并汇总
+	rows, err := s.adminRepo.DailySales(start, end)
+	if err != nil {
+		return nil, err
+	}
+	dashboard := buildSalesDashboard(start, req.Days, rows)
+
+	// 3. 热销商品
+	dashboard.TopProducts, err = s.adminRepo.TopProducts(start, end, req.Top)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range dashboard.TopProducts {
+		product.Amount = roundAmount(product.Amount)
+	}
+
+	return dashboard, nil
+}
+
+// ListAuditLogs 查询管理后台审计日志
+func (s *adminService) ListAuditLogs(req *model.AdminAuditLogListRequest) (*model.AdminAuditLogListResponse, error) {
+	logs, total, err := s.auditRepo.List(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AdminAuditLogListResponse{
+		Logs:       logs,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages(total, req.PageSize),
+	}, nil
+}
+
+// buildSalesDashboard 从start开始的days天的每日数据汇总出销售看板
+// rows只包含有订单的日期，没有订单的日期补0
+func buildSalesDashboard(start time.Time, days int, rows []*model.DailySales) *model.SalesDashboard {
+	byDate := make(map[string]*model.DailySales, len(rows))
+	for _, row := range rows {
+		byDate[row.Date] = row
+	}
+
+	dashboard := &model.SalesDashboard{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   start.AddDate(0, 0, days-1).Format("2006-01-02"),
+		Daily:     make([]*model.DailySales, 0, days),
+	}
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format("2006-01-02")
+		day, ok := byDate[date]
+		if !ok {
+			day = &model.DailySales{Date: date}
+		}
+		day.GMV = roundAmount(day.GMV)
+
+		dashboard.Orders += day.Orders
+		dashboard.PaidOrders += day.PaidOrders
+		dashboard.GMV += day.GMV
+		dashboard.Daily = append(dashboard.Daily, day)
+	}
+
+	dashboard.GMV = roundAmount(dashboard.GMV)
+	if dashboard.PaidOrders > 0 {
+		dashboard.AvgOrderValue = roundAmount(dashboard.GMV / float64(dashboard.PaidOrders))
+	}
+	return dashboard
+}
+
+// roundAmount 金额保留两位小数
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// totalPages 计算总页数
+func totalPages(total int64, pageSize int) int {
+	return int((total + int64(pageSize) - 1) / int64(pageSize))
+}
+
+// uniqueIDs 去掉重复的ID，保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	result := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"testing"
+	"time"
+)
+
+func TestBuildSalesDashboard_FillsMissingDays(t *testing.T) {
+	start := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+	rows := []*model.DailySales{
+		{Date: "2026-10-14", Orders: 3, PaidOrders: 2, GMV: 100.005},
+		{Date: "2026-10-16", Orders: 1, PaidOrders: 1, GMV: 50.5},
+	}
+
+	d := buildSalesDashboard(start, 3, rows)
+
+	if d.StartDate != "2026-10-14" || d.EndDate != "2026-10-16" {
+		t.Fatalf("range = %s..%s", d.StartDate, d.EndDate)
+	}
+	if len(d.Daily) != 3 {
+		t.Fatalf("daily rows = %d, want 3", len(d.Daily))
+	}
+	if d.Daily[1].Date != "2026-10-15" || d.Daily[1].Orders != 0 || d.Daily[1].GMV != 0 {
+		t.Errorf("missing day = %+v, want zero row for 2026-10-15", d.Daily[1])
+	}
+	if d.Orders != 4 || d.PaidOrders != 3 {
+		t.Errorf("orders = %d, paid = %d, want 4 and 3", d.Orders, d.PaidOrders)
+	}
+	if d.GMV != 150.51 {
+		t.Errorf("gmv = %v, want 150.51", d.GMV)
+	}
+	if d.AvgOrderValue != 50.17 {
+		t.Errorf("avg order value = %v, want 50.17", d.AvgOrderValue)
+	}
+}
+
+func TestBuildSalesDashboard_NoPaidOrders(t *testing.T) {
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	d := buildSalesDashboard(start, 1, []*model.DailySales{{Date: "2026-10-16", Orders: 2}})
+
+	if d.PaidOrders != 0 || d.GMV != 0 || d.AvgOrderValue != 0 {
+		t.Errorf("dashboard = %+v, want no sales", d)
+	}
+}
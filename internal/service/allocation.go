@@ -0,0 +1,76 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"sort"
+)
+
+// AllocationStrategy 分仓策略
+type AllocationStrategy string
+
+const (
+	AllocateNearest   AllocationStrategy = "nearest"    // 优先从离收货省份最近的仓库发货
+	AllocateMostStock AllocationStrategy = "most_stock" // 优先从可售库存最多的仓库发货
+)
+
+// unassignedDistance 未分配库存的距离等级，排在所有仓库之后
+const unassignedDistance = 3
+
+// stockSource 可分配的库存来源：一个仓库或未分配库存
+type stockSource struct {
+	warehouseID uint
+	distance    int // 到收货省份的距离等级，见 model.Warehouse.Distance
+	available   int // 可售库存
+}
+
+// allocateStock 按分仓策略把购买数量分配到各库存来源
+// 优先由排在最前且库存足够的一个来源整单发货；没有这样的来源时按策略顺序拆分到多个来源。
+// 可售库存不足时返回false
+func allocateStock(sources []stockSource, quantity int, strategy AllocationStrategy) ([]model.StockAllocation, bool) {
+	// 1. 按策略排序
+	ordered := make([]stockSource, 0, len(sources))
+	for _, source := range sources {
+		if source.available > 0 {
+			ordered = append(ordered, source)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if strategy == AllocateMostStock {
+			if a.available != b.available {
+				return a.available > b.available
+			}
+			return a.distance < b.distance
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		return a.available > b.available
+	})
+
+	// 2. 一个来源能满足时不拆分
+	for _, source := range ordered {
+		if source.available >= quantity {
+			return []model.StockAllocation{{WarehouseID: source.warehouseID, Quantity: quantity}}, true
+		}
+	}
+
+	// 3. 按顺序拆分
+	var allocations []model.StockAllocation
+	remaining := quantity
+	for _, source := range ordered {
+		if remaining == 0 {
+			break
+		}
+		take := source.available
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, model.StockAllocation{WarehouseID: source.warehouseID, Quantity: take})
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, false
+	}
+	return allocations, true
+}
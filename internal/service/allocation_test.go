@@ -0,0 +1,45 @@
+package service
+
+import (
+	"reflect"
+	"ryan-mall/internal/model"
+	"testing"
+)
+
+func TestAllocateStock(t *testing.T) {
+	sources := []stockSource{
+		{warehouseID: 1, distance: 2, available: 50}, // 外省大仓
+		{warehouseID: 2, distance: 0, available: 3},  // 同省小仓
+		{warehouseID: 3, distance: 1, available: 8},  // 就近发货仓
+		{warehouseID: model.UnassignedWarehouseID, distance: unassignedDistance, available: 100},
+	}
+
+	tests := []struct {
+		name     string
+		quantity int
+		strategy AllocationStrategy
+		want     []model.StockAllocation
+	}{
+		{"nearest fits in one", 3, AllocateNearest, []model.StockAllocation{{WarehouseID: 2, Quantity: 3}}},
+		{"nearest skips to first warehouse that fits", 5, AllocateNearest, []model.StockAllocation{{WarehouseID: 3, Quantity: 5}}},
+		{"most stock", 5, AllocateMostStock, []model.StockAllocation{{WarehouseID: model.UnassignedWarehouseID, Quantity: 5}}},
+		{"split in strategy order", 160, AllocateNearest, []model.StockAllocation{
+			{WarehouseID: 2, Quantity: 3}, {WarehouseID: 3, Quantity: 8}, {WarehouseID: 1, Quantity: 50}, {WarehouseID: model.UnassignedWarehouseID, Quantity: 99},
+		}},
+	}
+
+	for _, tt := range tests {
+		got, ok := allocateStock(sources, tt.quantity, tt.strategy)
+		if !ok {
+			t.Errorf("%s: unexpected insufficient stock", tt.name)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, ok := allocateStock(sources, 162, AllocateNearest); ok {
+		t.Error("expected insufficient stock")
+	}
+}
@@ -2,17 +2,29 @@ package service
 
 import (
 	"fmt"
+	"log"
 	"ryan-mall/internal/model"
 	"ryan-mall/internal/repository"
 	"ryan-mall/pkg/cache"
+	"sort"
+	"strings"
 	"time"
 )
 
+// ProductObserver 商品变更观察者
+// 商品创建、更新、删除或库存、销量变化后收到通知，如同步搜索索引
+type ProductObserver interface {
+	ProductsChanged(ids ...uint) // 商品发生变化，实现方不应阻塞调用方
+}
+
 // CachedProductService 带缓存的商品服务
 type CachedProductService struct {
 	productRepo  repository.ProductRepository
 	categoryRepo repository.CategoryRepository
 	cache        cache.CacheManager
+	observers    []ProductObserver
+	ranking      RankingService
+	ratings      ProductRatingSource
 }
 
 // NewCachedProductService 创建带缓存的商品服务
@@ -27,14 +39,30 @@ func NewCachedProductService(
 	}
 }
 
+// AddObserver 注册商品变更观察者，需要在处理请求之前注册
+func (s *CachedProductService) AddObserver(observer ProductObserver) {
+	s.observers = append(s.observers, observer)
+}
+
+// SetRankingService 设置热度排行服务，商品浏览计入热度，热门商品按热度排行
+func (s *CachedProductService) SetRankingService(ranking RankingService) {
+	s.ranking = ranking
+}
+
+// SetRatingSource 设置评分汇总来源，商品详情中展示评分汇总
+func (s *CachedProductService) SetRatingSource(ratings ProductRatingSource) {
+	s.ratings = ratings
+}
+
 // GetByID 获取商品详情（带缓存）
+// 启用规格的商品同时返回规格定义和上架的SKU，前端据此渲染规格选择
 func (s *CachedProductService) GetByID(id uint) (*model.Product, error) {
 	// 1. 尝试从缓存获取
 	cacheKey := fmt.Sprintf("product:%d", id)
 	var product model.Product
 	if err := s.cache.GetJSON(cacheKey, &product); err == nil {
 		// 缓存命中，记录浏览次数
-		go s.incrementViewCount(id)
+		go s.incrementViewCount(&product)
 		return &product, nil
 	}
 	
@@ -48,11 +76,20 @@ func (s *CachedProductService) GetByID(id uint) (*model.Product, error) {
 		return nil, fmt.Errorf("product not found")
 	}
 	
+	// 评分汇总随商品一起缓存，评价审核后清除
+	if s.ratings != nil {
+		rating, err := s.ratings.RatingSummary(id)
+		if err != nil {
+			log.Printf("获取商品%d评分汇总失败: %v", id, err)
+		}
+		productPtr.Rating = rating
+	}
+	
 	// 3. 存入缓存（5分钟过期）
 	s.cache.SetJSON(cacheKey, productPtr, 5*time.Minute)
 	
 	// 4. 异步记录浏览次数
-	go s.incrementViewCount(id)
+	go s.incrementViewCount(productPtr)
 	
 	return productPtr, nil
 }
@@ -94,6 +131,7 @@ func (s *CachedProductService) CreateProduct(req *model.ProductCreateRequest) (*
 	// 转换请求为商品模型
 	product := &model.Product{
 		Name:        req.Name,
+		Brand:       strings.TrimSpace(req.Brand),
 		Description: &req.Description,
 		CategoryID:  req.CategoryID,
 		Price:       req.Price,
@@ -110,6 +148,7 @@ func (s *CachedProductService) CreateProduct(req *model.ProductCreateRequest) (*
 
 	// 清除相关缓存
 	s.clearProductCaches()
+	s.notifyObservers(product.ID)
 
 	return product, nil
 }
@@ -134,6 +173,9 @@ func (s *CachedProductService) UpdateProduct(id uint, req *model.ProductUpdateRe
 	if req.Name != nil {
 		existingProduct.Name = *req.Name
 	}
+	if req.Brand != nil {
+		existingProduct.Brand = strings.TrimSpace(*req.Brand)
+	}
 	if req.Description != nil {
 		existingProduct.Description = req.Description
 	}
@@ -141,17 +183,24 @@ func (s *CachedProductService) UpdateProduct(id uint, req *model.ProductUpdateRe
 		existingProduct.CategoryID = *req.CategoryID
 	}
 	if req.Price != nil {
+		if existingProduct.HasSkus() {
+			return errSkuPriceManaged
+		}
 		existingProduct.Price = *req.Price
 	}
-	if req.Stock != nil {
-		existingProduct.Stock = *req.Stock
-	}
 	if req.MainImage != nil {
 		existingProduct.MainImage = req.MainImage
 	}
 	if req.Images != nil {
 		existingProduct.Images = req.Images
 	}
+	if req.Stock != nil {
+		// 库存单独按条件更新，不能少于已预占的数量
+		if err := s.productRepo.UpdateStock(id, *req.Stock); err != nil {
+			return err
+		}
+		existingProduct.Stock = *req.Stock
+	}
 
 	err = s.productRepo.Update(existingProduct)
 	if err != nil {
@@ -161,6 +210,7 @@ func (s *CachedProductService) UpdateProduct(id uint, req *model.ProductUpdateRe
 	// 清除相关缓存
 	s.clearProductCache(id)
 	s.clearProductCaches()
+	s.notifyObservers(id)
 
 	return nil
 }
@@ -175,16 +225,22 @@ func (s *CachedProductService) DeleteProduct(id uint) error {
 	// 清除相关缓存
 	s.clearProductCache(id)
 	s.clearProductCaches()
+	s.notifyObservers(id)
 
 	return nil
 }
 
 // GetProductList 获取商品列表（实现接口）
+// 同时返回价格区间、分类、品牌、规格属性的分面统计
 func (s *CachedProductService) GetProductList(req *model.ProductListRequest) (*model.ProductListResponse, error) {
 	products, total, err := s.List(req)
 	if err != nil {
 		return nil, err
 	}
+	facets, err := s.Facets(req)
+	if err != nil {
+		return nil, err
+	}
 
 	// 计算总页数
 	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
@@ -195,9 +251,32 @@ func (s *CachedProductService) GetProductList(req *model.ProductListRequest) (*m
 		Page:       req.Page,
 		PageSize:   req.PageSize,
 		TotalPages: totalPages,
+		Facets:     facets,
 	}, nil
 }
 
+// Facets 统计商品列表的分面（带缓存）
+// 分面与分页、排序无关，缓存键不包含这两项
+func (s *CachedProductService) Facets(req *model.ProductListRequest) (*model.ProductFacets, error) {
+	// 1. 尝试从缓存获取
+	cacheKey := "product_facets" + s.filterCacheKey(req)
+	var facets model.ProductFacets
+	if err := s.cache.GetJSON(cacheKey, &facets); err == nil {
+		return &facets, nil
+	}
+
+	// 2. 缓存未命中，从数据库统计
+	facetsPtr, err := s.productRepo.Facets(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 存入缓存（2分钟过期，与列表一致）
+	s.cache.SetJSON(cacheKey, facetsPtr, 2*time.Minute)
+
+	return facetsPtr, nil
+}
+
 // GetProductsByCategory 根据分类获取商品
 func (s *CachedProductService) GetProductsByCategory(categoryID uint) ([]*model.Product, error) {
 	req := &model.ProductListRequest{
@@ -224,7 +303,7 @@ func (s *CachedProductService) DecrementStock(id uint, quantity int) error {
 	}
 
 	// 检查库存是否充足
-	if product.Stock < quantity {
+	if product.AvailableStock() < quantity {
 		return fmt.Errorf("insufficient stock")
 	}
 
@@ -259,6 +338,7 @@ func (s *CachedProductService) IncrementSalesCount(id uint, quantity int) error
 	// 清除缓存
 	s.clearProductCache(id)
 	s.clearProductCaches()
+	s.notifyObservers(id)
 
 	return nil
 }
@@ -272,12 +352,26 @@ func (s *CachedProductService) UpdateStock(id uint, stock int) error {
 	
 	// 清除商品缓存
 	s.clearProductCache(id)
+	s.notifyObservers(id)
 	
 	return nil
 }
 
 // GetHotProducts 获取热门商品（带缓存）
+// 设置了热度排行服务时按最近24小时的热度排行，否则按销量排序
 func (s *CachedProductService) GetHotProducts(limit int) ([]*model.Product, error) {
+	if s.ranking != nil {
+		resp, err := s.ranking.HotProducts(&model.HotProductsRequest{Window: model.RankingWindowDaily, Limit: limit})
+		if err != nil {
+			return nil, err
+		}
+		products := make([]*model.Product, 0, len(resp.Products))
+		for _, ranked := range resp.Products {
+			products = append(products, ranked.Product)
+		}
+		return products, nil
+	}
+	
 	cacheKey := fmt.Sprintf("hot_products:%d", limit)
 	
 	// 1. 尝试从缓存获取
@@ -356,7 +450,22 @@ func (s *CachedProductService) GetCacheStats() map[string]interface{} {
 
 // generateListCacheKey 生成列表缓存键
 func (s *CachedProductService) generateListCacheKey(req *model.ProductListRequest) string {
-	key := fmt.Sprintf("product_list:%d:%d", req.Page, req.PageSize)
+	key := fmt.Sprintf("product_list:%d:%d", req.Page, req.PageSize) + s.filterCacheKey(req)
+	
+	if req.SortBy != "" {
+		key += fmt.Sprintf(":sort:%s", req.SortBy)
+		if req.SortOrder == "desc" {
+			key += ":desc"
+		}
+	}
+	
+	return key
+}
+
+// filterCacheKey 生成筛选条件部分的缓存键
+// 品牌和规格属性与参数顺序无关，排序后拼接
+func (s *CachedProductService) filterCacheKey(req *model.ProductListRequest) string {
+	key := ""
 	
 	if req.Keyword != "" {
 		key += fmt.Sprintf(":kw:%s", req.Keyword)
@@ -374,16 +483,44 @@ func (s *CachedProductService) generateListCacheKey(req *model.ProductListReques
 		key += fmt.Sprintf(":maxp:%.2f", *req.MaxPrice)
 	}
 	
-	if req.SortBy != "" {
-		key += fmt.Sprintf(":sort:%s", req.SortBy)
-		if req.SortOrder == "desc" {
-			key += ":desc"
-		}
+	if len(req.Brands) > 0 {
+		brands := append([]string(nil), req.Brands...)
+		sort.Strings(brands)
+		key += fmt.Sprintf(":brand:%q", brands)
+	}
+	
+	if len(req.Attrs) > 0 {
+		attrs := append([]string(nil), req.Attrs...)
+		sort.Strings(attrs)
+		key += fmt.Sprintf(":attr:%q", attrs)
+	}
+	
+	if req.InStock {
+		key += ":instock"
 	}
 	
 	return key
 }
 
+// InvalidateProducts 清除指定商品的缓存并通知观察者
+// 供在商品服务之外修改了库存的流程（如过期订单恢复库存）调用
+func (s *CachedProductService) InvalidateProducts(ids ...uint) {
+	for _, id := range ids {
+		s.clearProductCache(id)
+	}
+	if len(ids) > 0 {
+		s.clearProductCaches()
+		s.notifyObservers(ids...)
+	}
+}
+
+// notifyObservers 通知观察者商品发生了变化
+func (s *CachedProductService) notifyObservers(ids ...uint) {
+	for _, observer := range s.observers {
+		observer.ProductsChanged(ids...)
+	}
+}
+
 // clearProductCache 清除单个商品缓存
 func (s *CachedProductService) clearProductCache(id uint) {
 	cacheKey := fmt.Sprintf("product:%d", id)
@@ -402,10 +539,14 @@ func (s *CachedProductService) clearProductCaches() {
 	// 比如使用缓存标签或者模式匹配删除
 }
 
-// incrementViewCount 增加浏览次数
-func (s *CachedProductService) incrementViewCount(productID uint) {
+// incrementViewCount 增加浏览次数，同时计入热度排行
+func (s *CachedProductService) incrementViewCount(product *model.Product) {
+	if s.ranking != nil {
+		s.ranking.RecordView(product.ID, product.CategoryID)
+	}
+	
 	// 使用缓存计数器
-	countKey := fmt.Sprintf("product_view_count:%d", productID)
+	countKey := fmt.Sprintf("product_view_count:%d", product.ID)
 	
 	// 获取当前计数
 	var count int
@@ -421,6 +562,6 @@ func (s *CachedProductService) incrementViewCount(productID uint) {
 	// 每10次浏览同步一次到数据库
 	if count%10 == 0 {
 		// 这里可以异步更新数据库
-		// s.productRepo.IncrementViewCount(productID, 10)
+		// s.productRepo.IncrementViewCount(product.ID, 10)
 	}
 }
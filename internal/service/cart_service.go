@@ -22,13 +22,16 @@ type CartService interface {
 type cartService struct {
 	cartRepo    repository.CartRepository
 	productRepo repository.ProductRepository
+	ranking     RankingService
 }
 
 // NewCartService 创建购物车业务逻辑层实例
-func NewCartService(cartRepo repository.CartRepository, productRepo repository.ProductRepository) CartService {
+// 加入购物车计入商品热度排行
+func NewCartService(cartRepo repository.CartRepository, productRepo repository.ProductRepository, ranking RankingService) CartService {
 	return &cartService{
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
+		ranking:     ranking,
 	}
 }
 
@@ -46,13 +49,34 @@ func (s *cartService) AddToCart(userID uint, req *model.AddToCartRequest) error
 		return errors.New("商品已下架")
 	}
 	
-	// 2. 检查库存是否充足
-	if product.Stock < req.Quantity {
+	// 2. 商品有规格时必须选择上架的规格，库存以规格为准
+	available := product.AvailableStock()
+	var skuID uint
+	if product.HasSkus() {
+		if req.SkuID == 0 {
+			return errors.New("请选择商品规格")
+		}
+		var sku *model.ProductSku
+		for i := range product.Skus {
+			if product.Skus[i].ID == req.SkuID {
+				sku = &product.Skus[i]
+				break
+			}
+		}
+		if sku == nil {
+			return errors.New("商品规格不存在或已下架")
+		}
+		available = sku.AvailableStock()
+		skuID = sku.ID
+	}
+	
+	// 3. 检查库存是否充足
+	if available < req.Quantity {
 		return errors.New("库存不足")
 	}
 	
-	// 3. 检查购物车中是否已存在该商品
-	existingItem, err := s.cartRepo.GetByUserAndProduct(userID, req.ProductID)
+	// 4. 检查购物车中是否已存在该商品规格
+	existingItem, err := s.cartRepo.GetByUserAndSku(userID, req.ProductID, skuID)
 	if err != nil {
 		return err
 	}
@@ -62,22 +86,30 @@ func (s *cartService) AddToCart(userID uint, req *model.AddToCartRequest) error
 		newQuantity := existingItem.Quantity + req.Quantity
 		
 		// 检查新数量是否超过库存
-		if newQuantity > product.Stock {
+		if newQuantity > available {
 			return errors.New("添加数量超过库存限制")
 		}
 		
 		// 更新数量
 		existingItem.Quantity = newQuantity
-		return s.cartRepo.Update(existingItem)
+		err = s.cartRepo.Update(existingItem)
 	} else {
 		// 商品不存在，创建新的购物车项
 		cartItem := &model.CartItem{
 			UserID:    userID,
 			ProductID: req.ProductID,
+			SkuID:     skuID,
 			Quantity:  req.Quantity,
 		}
-		return s.cartRepo.Create(cartItem)
+		err = s.cartRepo.Create(cartItem)
 	}
+	if err != nil {
+		return err
+	}
+	
+	// 5. 计入商品热度
+	s.ranking.RecordAddToCart(product.ID, product.CategoryID)
+	return nil
 }
 
 // GetCart 获取用户购物车
@@ -93,9 +125,9 @@ func (s *cartService) GetCart(userID uint) (*model.CartListResponse, error) {
 	var totalPrice float64
 	
 	for _, item := range cartItems {
-		// 检查商品库存，如果库存不足则调整数量
-		if item.Quantity > item.Product.Stock {
-			item.Quantity = item.Product.Stock
+		// 检查商品库存（有规格时为规格库存），如果库存不足则调整数量
+		if item.Quantity > item.AvailableStock() {
+			item.Quantity = item.AvailableStock()
 			if item.Quantity > 0 {
 				s.cartRepo.Update(item) // 更新数量
 			} else {
@@ -104,23 +136,21 @@ func (s *cartService) GetCart(userID uint) (*model.CartListResponse, error) {
 			}
 		}
 		
-		itemTotalPrice := float64(item.Quantity) * item.Product.Price
+		itemTotalPrice := float64(item.Quantity) * item.UnitPrice()
 		totalPrice += itemTotalPrice
 		
 		cartResponse := &model.CartResponse{
-			ID:          item.ID,
-			ProductID:   item.ProductID,
-			ProductName: item.Product.Name,
-			Price:       item.Product.Price,
-			Quantity:    item.Quantity,
-			TotalPrice:  itemTotalPrice,
-			Stock:       item.Product.Stock,
-			CreatedAt:   item.CreatedAt,
-		}
-		
-		// 设置商品图片
-		if item.Product.MainImage != nil {
-			cartResponse.ProductImage = item.Product.MainImage
+			ID:           item.ID,
+			ProductID:    item.ProductID,
+			SkuID:        item.SkuID,
+			SpecKey:      item.SpecKey(),
+			ProductName:  item.Product.Name,
+			ProductImage: item.Image(),
+			Price:        item.UnitPrice(),
+			Quantity:     item.Quantity,
+			TotalPrice:   itemTotalPrice,
+			Stock:        item.AvailableStock(),
+			CreatedAt:    item.CreatedAt,
 		}
 		
 		cartResponses = append(cartResponses, cartResponse)
@@ -162,7 +192,7 @@ func (s *cartService) UpdateCartItem(userID, cartItemID uint, req *model.UpdateC
 	}
 	
 	// 3. 检查库存
-	if req.Quantity > targetItem.Product.Stock {
+	if req.Quantity > targetItem.AvailableStock() {
 		return errors.New("数量超过库存限制")
 	}
 	
@@ -225,14 +255,9 @@ func (s *cartService) ValidateCartItems(userID uint, cartItemIDs []uint) ([]*mod
 			return nil, errors.New("购物车项不属于当前用户")
 		}
 		
-		// 3. 验证商品状态
-		if item.Product.Status != model.ProductStatusOnline {
-			return nil, errors.New("商品 " + item.Product.Name + " 已下架")
-		}
-		
-		// 4. 验证库存
-		if item.Quantity > item.Product.Stock {
-			return nil, errors.New("商品 " + item.Product.Name + " 库存不足")
+		// 3. 验证商品和规格状态、库存
+		if reason := item.Unavailable(); reason != "" {
+			return nil, errors.New(reason)
 		}
 		
 		validItems = append(validItems, item)
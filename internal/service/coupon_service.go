@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CouponService 优惠券业务逻辑层接口
+type CouponService interface {
+	CreateTemplate(req *model.CreateCouponTemplateRequest) (*model.CouponTemplate, error)          // 创建优惠券模板
+	ListTemplates(req *model.CouponTemplateListRequest) (*model.CouponTemplateListResponse, error) // 获取优惠券模板列表
+	UpdateTemplateStatus(templateID uint, status model.CouponTemplateStatus) error                 // 启用/停用优惠券模板
+	IssueCoupons(templateID uint, userIDs []uint) (int, error)                                     // 管理员发放优惠券
+	ListClaimable() ([]*model.CouponTemplate, error)                                               // 获取可领取的优惠券
+	ClaimCoupon(userID, templateID uint) (*model.UserCoupon, error)                                // 领取优惠券
+	ListUserCoupons(userID uint, req *model.UserCouponListRequest) ([]*model.UserCoupon, error)    // 获取我的优惠券
+	CouponStage(userID, userCouponID uint) (PricingStage, error)                                   // 获取用户优惠券的计价环节
+	Redeem(tx *gorm.DB, userID, userCouponID, orderID uint) error                                  // 在下单事务中核销优惠券
+}
+
+// couponService 优惠券业务逻辑层实现
+type couponService struct {
+	couponRepo repository.CouponRepository
+	db         *gorm.DB
+}
+
+// NewCouponService 创建优惠券业务逻辑层实例
+func NewCouponService(couponRepo repository.CouponRepository, states *OrderStateMachine, db *gorm.DB) CouponService {
+	s := &couponService{
+		couponRepo: couponRepo,
+		db:         db,
+	}
+
+	// 订单取消后退回使用的优惠券
+	states.AddHook(model.OrderStatusCancelled, s.releaseCoupons)
+
+	return s
+}
+
+// CreateTemplate 创建优惠券模板
+func (s *couponService) CreateTemplate(req *model.CreateCouponTemplateRequest) (*model.CouponTemplate, error) {
+	template := &model.CouponTemplate{
+		Name:         req.Name,
+		Type:         req.Type,
+		Amount:       req.Amount,
+		Percent:      req.Percent,
+		MaxDiscount:  req.MaxDiscount,
+		MinAmount:    req.MinAmount,
+		Scope:        req.Scope,
+		ScopeIDs:     model.JSONIDs(req.ScopeIDs),
+		TotalCount:   req.TotalCount,
+		PerUserLimit: req.PerUserLimit,
+		ValidDays:    req.ValidDays,
+		StartAt:      req.StartAt,
+		EndAt:        req.EndAt,
+		Status:       model.CouponTemplateActive,
+	}
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ListTemplates 获取优惠券模板列表
+func (s *couponService) ListTemplates(req *model.CouponTemplateListRequest) (*model.CouponTemplateListResponse, error) {
+	templates, total, err := s.couponRepo.ListTemplates(req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &model.CouponTemplateListResponse{
+		Templates:  templates,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// UpdateTemplateStatus 启用/停用优惠券模板
+// 停用只影响领取，已领取的优惠券在有效期内仍可使用
+func (s *couponService) UpdateTemplateStatus(templateID uint, status model.CouponTemplateStatus) error {
+	template, err := s.couponRepo.GetTemplate(templateID)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return errors.New("优惠券不存在")
+	}
+	return s.couponRepo.UpdateTemplateStatus(templateID, status)
+}
+
+// IssueCoupons 管理员发放优惠券
+// 受发放总量和每人限领数量限制，已达到限领数量的用户会被跳过，返回实际发放数量
+func (s *couponService) IssueCoupons(templateID uint, userIDs []uint) (int, error) {
+	var issued []*model.UserCoupon
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		issued, err = s.issue(tx, templateID, userIDs)
+		return err
+	})
+	return len(issued), err
+}
+
+// ListClaimable 获取可领取的优惠券
+func (s *couponService) ListClaimable() ([]*model.CouponTemplate, error) {
+	return s.couponRepo.ListClaimableTemplates(time.Now())
+}
+
+// ClaimCoupon 领取优惠券
+func (s *couponService) ClaimCoupon(userID, templateID uint) (*model.UserCoupon, error) {
+	var issued []*model.UserCoupon
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		issued, err = s.issue(tx, templateID, []uint{userID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(issued) == 0 {
+		return nil, errors.New("已达到该优惠券的领取上限")
+	}
+	return issued[0], nil
+}
+
+// ListUserCoupons 获取我的优惠券
+func (s *couponService) ListUserCoupons(userID uint, req *model.UserCouponListRequest) ([]*model.UserCoupon, error) {
+	return s.couponRepo.ListUserCoupons(userID, req.State, time.Now())
+}
+
+// CouponStage 获取用户优惠券的计价环节
+// 校验优惠券归属和有效期，实际核销在下单事务中通过Redeem完成
+func (s *couponService) CouponStage(userID, userCouponID uint) (PricingStage, error) {
+	coupon, err := s.couponRepo.GetUserCoupon(userCouponID)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil || coupon.UserID != userID {
+		return nil, errors.New("优惠券不存在")
+	}
+	if !coupon.Usable(time.Now()) {
+		return nil, errors.New("优惠券已使用或已过期")
+	}
+
+	return couponStage{coupon: coupon}, nil
+}
+
+// Redeem 在下单事务中核销优惠券
+// 按状态条件更新，同一张优惠券并发下单时只有一个订单能核销成功
+func (s *couponService) Redeem(tx *gorm.DB, userID, userCouponID, orderID uint) error {
+	now := time.Now()
+	result := tx.Model(&model.UserCoupon{}).
+		Where("id = ? AND user_id = ? AND status = ? AND expire_at > ?",
+			userCouponID, userID, model.UserCouponStatusUnused, now).
+		Updates(map[string]interface{}{
+			"status":   model.UserCouponStatusUsed,
+			"order_id": orderID,
+			"used_at":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("优惠券已使用或已过期")
+	}
+	return nil
+}
+
+// issue 在事务中发放优惠券
+// 锁定模板行，保证发放总量和每人限领数量在并发领取时不会超发
+func (s *couponService) issue(tx *gorm.DB, templateID uint, userIDs []uint) ([]*model.UserCoupon, error) {
+	// 1. 锁定模板并检查是否可领取
+	var template model.CouponTemplate
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, templateID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("优惠券不存在")
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if err := template.Claimable(now); err != nil {
+		return nil, err
+	}
+
+	// 2. 统计每个用户已领取的数量
+	owned := make(map[uint]int)
+	if template.PerUserLimit > 0 {
+		var rows []struct {
+			UserID uint
+			Count  int
+		}
+		err := tx.Model(&model.UserCoupon{}).
+			Select("user_id, count(*) as count").
+			Where("template_id = ? AND user_id IN ?", templateID, userIDs).
+			Group("user_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			owned[row.UserID] = row.Count
+		}
+	}
+
+	// 3. 按限制生成用户优惠券
+	var issued []*model.UserCoupon
+	for _, userID := range userIDs {
+		if template.TotalCount > 0 && template.IssuedCount+len(issued) >= template.TotalCount {
+			break
+		}
+		if template.PerUserLimit > 0 && owned[userID] >= template.PerUserLimit {
+			continue
+		}
+		owned[userID]++
+		issued = append(issued, &model.UserCoupon{
+			UserID:     userID,
+			TemplateID: template.ID,
+			Status:     model.UserCouponStatusUnused,
+			ExpireAt:   template.ExpireAtFor(now),
+		})
+	}
+	if len(issued) == 0 {
+		return nil, nil
+	}
+
+	// 4. 保存并更新已发放数量
+	if err := tx.Create(&issued).Error; err != nil {
+		return nil, err
+	}
+	err = tx.Model(&model.CouponTemplate{}).
+		Where("id = ?", template.ID).
+		Update("issued_count", gorm.Expr("issued_count + ?", len(issued))).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+// releaseCoupons 订单取消后退回使用的优惠券
+func (s *couponService) releaseCoupons(tx *gorm.DB, t *OrderTransition) error {
+	return repository.ReleaseOrderCoupons(tx, []uint{t.OrderID})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+)
+
+// FavoriteService 商品收藏业务逻辑层接口
+type FavoriteService interface {
+	AddFavorite(userID uint, req *model.AddFavoriteRequest) error                                  // 收藏商品
+	RemoveFavorite(userID, productID uint) error                                                   // 取消收藏
+	GetFavorites(userID uint, req *model.FavoriteListRequest) (*model.FavoriteListResponse, error) // 获取收藏列表
+	ProductsChanged(ids ...uint)                                                                   // 商品变化后检查收藏的商品是否降价或到货
+}
+
+// favoriteService 商品收藏业务逻辑层实现
+type favoriteService struct {
+	favoriteRepo  repository.FavoriteRepository
+	productRepo   repository.ProductRepository
+	notifications NotificationService
+}
+
+// NewFavoriteService 创建商品收藏业务逻辑层实例
+// 注册为商品变更观察者后，收藏的商品降价或重新到货时给用户发送站内通知
+func NewFavoriteService(favoriteRepo repository.FavoriteRepository, productRepo repository.ProductRepository, notifications NotificationService) FavoriteService {
+	return &favoriteService{
+		favoriteRepo:  favoriteRepo,
+		productRepo:   productRepo,
+		notifications: notifications,
+	}
+}
+
+// AddFavorite 收藏商品，重复收藏不报错
+// 记录当前的价格和有货状态，之后降价或到货时提醒
+func (s *favoriteService) AddFavorite(userID uint, req *model.AddFavoriteRequest) error {
+	product, err := s.productRepo.GetByID(req.ProductID)
+	if err != nil {
+		return err
+	}
+	if product == nil || product.Status != model.ProductStatusOnline {
+		return errors.New("商品不存在或已下架")
+	}
+
+	return s.favoriteRepo.Create(&model.Favorite{
+		UserID:      userID,
+		ProductID:   product.ID,
+		LastPrice:   product.Price,
+		LastInStock: favoriteInStock(product),
+	})
+}
+
+// RemoveFavorite 取消收藏，未收藏时不报错
+func (s *favoriteService) RemoveFavorite(userID, productID uint) error {
+	return s.favoriteRepo.Delete(userID, productID)
+}
+
+// GetFavorites 获取收藏列表
+func (s *favoriteService) GetFavorites(userID uint, req *model.FavoriteListRequest) (*model.FavoriteListResponse, error) {
+	favorites, total, err := s.favoriteRepo.List(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &model.FavoriteListResponse{
+		Favorites:  favorites,
+		Total:      int(total),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ProductsChanged 商品变化后检查收藏的商品是否降价或到货
+// 在后台处理，不阻塞修改商品的请求
+func (s *favoriteService) ProductsChanged(ids ...uint) {
+	if len(ids) == 0 {
+		return
+	}
+	go func() {
+		if err := s.checkProducts(ids); err != nil {
+			log.Printf("检查收藏商品的价格和库存失败: %v", err)
+		}
+	}()
+}
+
+// checkProducts 对比收藏时记录的价格和有货状态，降价或重新到货时发送站内通知
+func (s *favoriteService) checkProducts(ids []uint) error {
+	// 1. 只处理被收藏的商品
+	favorites, err := s.favoriteRepo.ListByProducts(ids)
+	if err != nil || len(favorites) == 0 {
+		return err
+	}
+
+	productIDs := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool)
+	for _, favorite := range favorites {
+		if !seen[favorite.ProductID] {
+			seen[favorite.ProductID] = true
+			productIDs = append(productIDs, favorite.ProductID)
+		}
+	}
+	products, err := s.productRepo.GetByIDs(productIDs)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*model.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	// 2. 逐条对比并更新记录，更新成功的才发送通知，避免多个实例重复提醒
+	var notifications []*model.Notification
+	for _, favorite := range favorites {
+		product, ok := byID[favorite.ProductID]
+		if !ok {
+			continue
+		}
+		inStock := favoriteInStock(product)
+		if math.Round(product.Price*100) == math.Round(favorite.LastPrice*100) && inStock == favorite.LastInStock {
+			continue
+		}
+		priceDropped, restocked := favorite.Changes(product.Price, inStock)
+
+		updated, err := s.favoriteRepo.UpdateSnapshot(favorite, product.Price, inStock)
+		if err != nil {
+			return err
+		}
+		if !updated || product.Status != model.ProductStatusOnline {
+			continue
+		}
+
+		if priceDropped {
+			notifications = append(notifications, &model.Notification{
+				UserID:  favorite.UserID,
+				Type:    model.NotificationTypePriceDrop,
+				Title:   "收藏的商品降价了",
+				Content: fmt.Sprintf("您收藏的「%s」从¥%.2f降到了¥%.2f", product.Name, favorite.LastPrice, product.Price),
+				RefID:   product.ID,
+			})
+		}
+		if restocked {
+			notifications = append(notifications, &model.Notification{
+				UserID:  favorite.UserID,
+				Type:    model.NotificationTypeBackInStock,
+				Title:   "收藏的商品到货了",
+				Content: fmt.Sprintf("您收藏的「%s」已到货", product.Name),
+				RefID:   product.ID,
+			})
+		}
+	}
+
+	// 3. 发送通知
+	return s.notifications.Send(notifications...)
+}
+
+// favoriteInStock 商品是否上架且有可售库存
+func favoriteInStock(product *model.Product) bool {
+	return product.Status == model.ProductStatusOnline && product.AvailableStock() > 0
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// InventoryService 库存预占业务逻辑层接口
+// 下单时预占库存，支付后扣减，取消或超时后释放；可售库存 = 库存 - 已预占库存
+type InventoryService interface {
+	Reserve(tx *gorm.DB, order *model.Order) error                                 // 在下单事务中预占订单商品的库存
+	SettleStaleReservations(limit int) (int, []uint, error)                        // 处理订单已支付或已取消但仍在预占的记录
+	ReconcileReservedStock(afterID uint, limit int) (*model.ReconcileBatch, error) // 按预占记录修正商品的预占库存
+}
+
+// inventoryService 库存预占业务逻辑层实现
+type inventoryService struct {
+	inventoryRepo repository.InventoryRepository
+	strategy      AllocationStrategy
+	db            *gorm.DB
+}
+
+// NewInventoryService 创建库存预占业务逻辑层实例
+// strategy为多仓库分配策略，未知的策略按就近发货处理
+func NewInventoryService(inventoryRepo repository.InventoryRepository, strategy AllocationStrategy, states *OrderStateMachine, db *gorm.DB) InventoryService {
+	if strategy != AllocateMostStock {
+		strategy = AllocateNearest
+	}
+	s := &inventoryService{
+		inventoryRepo: inventoryRepo,
+		strategy:      strategy,
+		db:            db,
+	}
+
+	// 订单支付后扣减库存，取消后释放预占
+	states.AddHook(model.OrderStatusPaid, s.commitReservations)
+	states.AddHook(model.OrderStatusCancelled, s.releaseReservations)
+
+	return s
+}
+
+// Reserve 在下单事务中预占订单商品的库存
+// 按商品ID顺序锁定库存，减少并发下单时的死锁；有规格的商品同时预占SKU库存。
+// 每个商品按分仓策略分配到仓库，一个仓库不够时拆分到多个仓库，每个仓库一条预占记录
+func (s *inventoryService) Reserve(tx *gorm.DB, order *model.Order) error {
+	items := make([]model.OrderItem, len(order.OrderItems))
+	copy(items, order.OrderItems)
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].ProductID != items[j].ProductID {
+			return items[i].ProductID < items[j].ProductID
+		}
+		return items[i].SkuID < items[j].SkuID
+	})
+
+	reservations := make([]model.InventoryReservation, 0, len(items))
+	for _, item := range items {
+		// 1. 锁定商品和仓库库存，计算各来源的可售库存
+		product, stocks, err := repository.LockStockSources(tx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		sources := stockSources(product, stocks, order.ShippingAddress.Province)
+
+		// 2. 有规格的商品先预占SKU库存
+		if item.SkuID != 0 {
+			ok, err := repository.ReserveSkuStock(tx, item.SkuID, item.Quantity)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				var sku model.ProductSku
+				if err := tx.Select("id", "stock", "reserved_stock").First(&sku, item.SkuID).Error; err != nil {
+					return err
+				}
+				return fmt.Errorf("商品 %s（%s）库存不足，当前库存：%d", item.ProductName, item.SpecKey, sku.AvailableStock())
+			}
+		}
+
+		// 3. 按分仓策略分配
+		allocations, ok := allocateStock(sources, item.Quantity, s.strategy)
+		if !ok {
+			return fmt.Errorf("商品 %s 库存不足，当前库存：%d", item.ProductName, product.AvailableStock())
+		}
+
+		// 4. 预占商品和仓库库存
+		ok, err = repository.ReserveStock(tx, item.ProductID, allocations)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("商品 %s 库存不足，当前库存：%d", item.ProductName, product.AvailableStock())
+		}
+
+		for _, allocation := range allocations {
+			reservations = append(reservations, model.InventoryReservation{
+				OrderID:     order.ID,
+				ProductID:   item.ProductID,
+				SkuID:       item.SkuID,
+				WarehouseID: allocation.WarehouseID,
+				Quantity:    allocation.Quantity,
+				Status:      model.ReservationStatusReserved,
+			})
+		}
+	}
+	if len(reservations) == 0 {
+		return nil
+	}
+	if err := tx.Create(&reservations).Error; err != nil {
+		return err
+	}
+	order.Allocations = reservations
+
+	return nil
+}
+
+// stockSources 商品可分配的库存来源：启用的仓库和未分配库存
+// 未分配库存 = 商品库存 - 各仓库库存之和，预占同理
+func stockSources(product *model.Product, stocks []model.WarehouseStock, province string) []stockSource {
+	sources := make([]stockSource, 0, len(stocks)+1)
+	unassignedStock := product.Stock
+	unassignedReserved := product.ReservedStock
+	for i := range stocks {
+		stock := &stocks[i]
+		unassignedStock -= stock.Stock
+		unassignedReserved -= stock.ReservedStock
+		if stock.Warehouse.Status != model.WarehouseStatusActive {
+			continue
+		}
+		sources = append(sources, stockSource{
+			warehouseID: stock.WarehouseID,
+			distance:    stock.Warehouse.Distance(province),
+			available:   stock.AvailableStock(),
+		})
+	}
+
+	return append(sources, stockSource{
+		warehouseID: model.UnassignedWarehouseID,
+		distance:    unassignedDistance,
+		available:   unassignedStock - unassignedReserved,
+	})
+}
+
+// SettleStaleReservations 处理订单已支付或已取消但仍在预占的记录
+// 已取消的订单释放预占，其余订单扣减库存；返回处理的订单数和库存发生变化的商品ID
+func (s *inventoryService) SettleStaleReservations(limit int) (int, []uint, error) {
+	stale, err := s.inventoryRepo.FindStaleReservations(limit)
+	if err != nil || len(stale) == 0 {
+		return 0, nil, err
+	}
+
+	var cancelled, committed []uint
+	for _, r := range stale {
+		if r.OrderStatus == model.OrderStatusCancelled {
+			cancelled = append(cancelled, r.OrderID)
+		} else {
+			committed = append(committed, r.OrderID)
+		}
+	}
+
+	var productIDs []uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		released, err := repository.ReleaseOrderReservations(tx, cancelled)
+		if err != nil {
+			return err
+		}
+		deducted, err := repository.CommitOrderReservations(tx, committed)
+		if err != nil {
+			return err
+		}
+		productIDs = append(released, deducted...)
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return len(stale), productIDs, nil
+}
+
+// ReconcileReservedStock 按预占记录修正商品的预占库存
+func (s *inventoryService) ReconcileReservedStock(afterID uint, limit int) (*model.ReconcileBatch, error) {
+	return s.inventoryRepo.ReconcileReservedStock(afterID, limit)
+}
+
+// commitReservations 订单支付后从库存中扣减预占的数量
+func (s *inventoryService) commitReservations(tx *gorm.DB, t *OrderTransition) error {
+	_, err := repository.CommitOrderReservations(tx, []uint{t.OrderID})
+	return err
+}
+
+// releaseReservations 订单取消后释放预占的库存
+func (s *inventoryService) releaseReservations(tx *gorm.DB, t *OrderTransition) error {
+	_, err := repository.ReleaseOrderReservations(tx, []uint{t.OrderID})
+	return err
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/messaging"
+	"ryan-mall/pkg/retry"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessageService 邮件、短信消息业务逻辑层接口
+type MessageService interface {
+	GetPreference(userID uint) (*model.MessagePreference, error)                                               // 获取消息渠道偏好
+	UpdatePreference(userID uint, req *model.UpdateMessagePreferenceRequest) (*model.MessagePreference, error) // 更新消息渠道偏好
+	DispatchPending(ctx context.Context, limit int) (sent, failed int, err error)                              // 发送一批待发消息
+}
+
+// messageService 邮件、短信消息业务逻辑层实现
+type messageService struct {
+	messageRepo repository.MessageRepository
+	senders     *messaging.Registry
+	retry       retry.Policy
+}
+
+// messageTemplate 一个渠道的消息模板
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// messageData 渲染消息模板的数据
+type messageData struct {
+	UserName string  // 用户名
+	OrderNo  string  // 订单号
+	Amount   float64 // 订单应付金额
+}
+
+// newMessageTemplate 解析消息模板，模板有误时panic
+func newMessageTemplate(subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+// messageTemplates 各事件在各渠道的消息模板，短信没有主题
+var messageTemplates = map[string]map[string]messageTemplate{
+	model.MessageEventOrderCreated: {
+		model.MessageChannelEmail: newMessageTemplate(
+			"订单{{.OrderNo}}已提交",
+			"{{.UserName}}您好：\n\n您的订单{{.OrderNo}}已提交，应付金额¥{{printf \"%.2f\" .Amount}}，请在支付时限内完成支付，超时订单将自动取消。"),
+		model.MessageChannelSMS: newMessageTemplate("",
+			"【Ryan Mall】您的订单{{.OrderNo}}已提交，应付¥{{printf \"%.2f\" .Amount}}，请及时支付。"),
+	},
+	model.MessageEventOrderPaid: {
+		model.MessageChannelEmail: newMessageTemplate(
+			"订单{{.OrderNo}}支付成功",
+			"{{.UserName}}您好：\n\n您的订单{{.OrderNo}}已支付成功，实付金额¥{{printf \"%.2f\" .Amount}}，我们会尽快为您发货。"),
+		model.MessageChannelSMS: newMessageTemplate("",
+			"【Ryan Mall】您的订单{{.OrderNo}}已支付成功，实付¥{{printf \"%.2f\" .Amount}}，我们会尽快为您发货。"),
+	},
+	model.MessageEventOrderShipped: {
+		model.MessageChannelEmail: newMessageTemplate(
+			"订单{{.OrderNo}}已发货",
+			"{{.UserName}}您好：\n\n您的订单{{.OrderNo}}已发货，可在订单详情中查看物流信息，请留意查收。"),
+		model.MessageChannelSMS: newMessageTemplate("",
+			"【Ryan Mall】您的订单{{.OrderNo}}已发货，请留意查收。"),
+	},
+}
+
+// orderMessageEvents 订单进入这些状态时给用户发送消息
+var orderMessageEvents = map[model.OrderStatus]string{
+	model.OrderStatusPending: model.MessageEventOrderCreated,
+	model.OrderStatusPaid:    model.MessageEventOrderPaid,
+	model.OrderStatusShipped: model.MessageEventOrderShipped,
+}
+
+// NewMessageService 创建邮件、短信消息业务逻辑层实例
+// 订单创建、支付成功、发货时按用户的渠道偏好生成待发消息，由后台任务通过senders发送，失败时按retryPolicy重试
+func NewMessageService(messageRepo repository.MessageRepository, senders *messaging.Registry, retryPolicy retry.Policy, states *OrderStateMachine) MessageService {
+	s := &messageService{
+		messageRepo: messageRepo,
+		senders:     senders,
+		retry:       retryPolicy,
+	}
+
+	for status := range orderMessageEvents {
+		states.AddHook(status, s.enqueueOrderMessages)
+	}
+
+	return s
+}
+
+// GetPreference 获取消息渠道偏好，未设置时返回默认偏好
+func (s *messageService) GetPreference(userID uint) (*model.MessagePreference, error) {
+	preference, err := s.messageRepo.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+	if preference == nil {
+		preference = model.DefaultMessagePreference(userID)
+	}
+	return preference, nil
+}
+
+// UpdatePreference 更新消息渠道偏好
+func (s *messageService) UpdatePreference(userID uint, req *model.UpdateMessagePreferenceRequest) (*model.MessagePreference, error) {
+	preference, err := s.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Apply(preference)
+	if err := s.messageRepo.SavePreference(preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// DispatchPending 按创建顺序发送一批待发消息
+// 每条消息失败时按指数退避重试，重试用尽后标记为发送失败；ctx取消时未发送的消息留待下次处理
+func (s *messageService) DispatchPending(ctx context.Context, limit int) (sent, failed int, err error) {
+	messages, err := s.messageRepo.ListPending(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, message := range messages {
+		if err := ctx.Err(); err != nil {
+			return sent, failed, err
+		}
+
+		// 1. 发送，失败时重试
+		sendErr := s.send(ctx, message)
+		if sendErr != nil && ctx.Err() != nil {
+			return sent, failed, ctx.Err()
+		}
+
+		// 2. 保存发送结果
+		if sendErr != nil {
+			message.MarkFailed(sendErr.Error())
+			failed++
+			log.Printf("发送消息%d（%s/%s）失败: %v", message.ID, message.Event, message.Channel, sendErr)
+		} else {
+			message.MarkSent(time.Now())
+			sent++
+		}
+		if err := s.messageRepo.UpdateResult(message); err != nil {
+			return sent, failed, err
+		}
+	}
+
+	return sent, failed, nil
+}
+
+// send 通过消息对应的渠道发送，失败时按退避策略重试
+func (s *messageService) send(ctx context.Context, message *model.OutboxMessage) error {
+	sender, err := s.senders.Get(message.Channel)
+	if err != nil {
+		return err
+	}
+
+	return retry.WithBackoff(ctx, s.retry, func() error {
+		message.Attempts++
+		return sender.Send(ctx, &messaging.Message{
+			Channel: message.Channel,
+			To:      message.Recipient,
+			Subject: message.Subject,
+			Body:    message.Body,
+		})
+	})
+}
+
+// enqueueOrderMessages 订单状态变更后按用户的渠道偏好生成待发消息
+// 与状态变更在同一事务中写入，订单回滚时不会发出消息；生成失败只记录日志，不影响订单状态变更。
+// 拒绝退款后恢复为已支付、已发货时之前已经发过消息，不再重复发送
+func (s *messageService) enqueueOrderMessages(tx *gorm.DB, t *OrderTransition) error {
+	event, ok := orderMessageEvents[t.To]
+	if !ok || t.From == model.OrderStatusRefundRequested {
+		return nil
+	}
+
+	// 1. 获取订单、用户联系方式和渠道偏好
+	order, err := repository.GetMessageRecipient(tx, t.OrderID)
+	if err != nil || order == nil {
+		log.Printf("order %d: load recipient for %s messages failed: %v", t.OrderID, event, err)
+		return nil
+	}
+	preference, err := repository.GetMessagePreference(tx, order.UserID)
+	if err != nil {
+		log.Printf("order %d: load message preference failed: %v", t.OrderID, err)
+		return nil
+	}
+	if preference == nil {
+		preference = model.DefaultMessagePreference(order.UserID)
+	}
+
+	// 2. 按渠道渲染消息，没有对应联系方式的渠道跳过
+	data := &messageData{
+		UserName: order.User.Username,
+		OrderNo:  order.OrderNo,
+		Amount:   order.PayableAmount(),
+	}
+	var messages []*model.OutboxMessage
+	for _, channel := range preference.Channels() {
+		recipient := messageRecipient(&order.User, channel)
+		if recipient == "" {
+			continue
+		}
+		subject, body, err := renderMessage(event, channel, data)
+		if err != nil {
+			log.Printf("order %d: render %s message for %s failed: %v", t.OrderID, event, channel, err)
+			continue
+		}
+		messages = append(messages, &model.OutboxMessage{
+			UserID:    order.UserID,
+			Event:     event,
+			Channel:   channel,
+			Recipient: recipient,
+			Subject:   subject,
+			Body:      body,
+			RefID:     order.ID,
+			Status:    model.OutboxMessagePending,
+		})
+	}
+
+	// 3. 写入待发消息
+	if err := repository.CreateOutboxMessages(tx, messages); err != nil {
+		log.Printf("order %d: enqueue %s messages failed: %v", t.OrderID, event, err)
+	}
+	return nil
+}
+
+// messageRecipient 用户在指定渠道的联系方式，没有时返回空字符串
+func messageRecipient(user *model.User, channel string) string {
+	switch channel {
+	case model.MessageChannelEmail:
+		return user.Email
+	case model.MessageChannelSMS:
+		if user.Phone != nil {
+			return *user.Phone
+		}
+	}
+	return ""
+}
+
+// renderMessage 渲染事件在指定渠道的消息
+func renderMessage(event, channel string, data *messageData) (subject, body string, err error) {
+	tmpl, ok := messageTemplates[event][channel]
+	if !ok {
+		return "", "", fmt.Errorf("事件%s没有%s渠道的消息模板", event, channel)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.subject.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	subject = buf.String()
+
+	buf.Reset()
+	if err := tmpl.body.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
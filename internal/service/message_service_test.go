@@ -0,0 +1,61 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"strings"
+	"testing"
+)
+
+func TestRenderMessage_AllEventsHaveEmailAndSMSTemplates(t *testing.T) {
+	data := &messageData{UserName: "ryan", OrderNo: "202401010001", Amount: 99.5}
+
+	for _, event := range orderMessageEvents {
+		for _, channel := range []string{model.MessageChannelEmail, model.MessageChannelSMS} {
+			subject, body, err := renderMessage(event, channel, data)
+			if err != nil {
+				t.Fatalf("render %s/%s: %v", event, channel, err)
+			}
+			if !strings.Contains(body, data.OrderNo) {
+				t.Errorf("%s/%s body %q does not contain order no", event, channel, body)
+			}
+			if channel == model.MessageChannelEmail && subject == "" {
+				t.Errorf("%s email has no subject", event)
+			}
+			if channel == model.MessageChannelSMS && subject != "" {
+				t.Errorf("%s sms has subject %q", event, subject)
+			}
+		}
+	}
+}
+
+func TestRenderMessage_FormatsAmount(t *testing.T) {
+	_, body, err := renderMessage(model.MessageEventOrderPaid, model.MessageChannelSMS, &messageData{OrderNo: "1", Amount: 99.5})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(body, "¥99.50") {
+		t.Fatalf("body %q does not contain formatted amount", body)
+	}
+}
+
+func TestRenderMessage_UnknownEvent(t *testing.T) {
+	if _, _, err := renderMessage("unknown", model.MessageChannelEmail, &messageData{}); err == nil {
+		t.Fatal("expected error for event without template")
+	}
+}
+
+func TestEnqueueOrderMessages_SkipsRejectedRefund(t *testing.T) {
+	s := &messageService{}
+
+	// 拒绝退款恢复为已支付、已发货时不读取收件人（tx为nil），也不生成消息
+	for _, to := range []model.OrderStatus{model.OrderStatusPaid, model.OrderStatusShipped} {
+		err := s.enqueueOrderMessages(nil, &OrderTransition{
+			OrderID: 1,
+			From:    model.OrderStatusRefundRequested,
+			To:      to,
+		})
+		if err != nil {
+			t.Fatalf("enqueueOrderMessages to %s: %v", model.GetOrderStatusText(to), err)
+		}
+	}
+}
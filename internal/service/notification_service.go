@@ -0,0 +1,188 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// NotificationPusher 站内通知实时推送
+// 推送是尽力而为的，用户离线或推送失败时仍可在收件箱中查看
+type NotificationPusher interface {
+	Push(userID uint, payload []byte) error // 推送给用户的所有在线连接
+}
+
+// NotificationService 站内通知业务逻辑层接口
+type NotificationService interface {
+	Send(notifications ...*model.Notification) error                                                           // 发送站内通知，保存到用户的收件箱并实时推送
+	SendTx(tx *gorm.DB, notifications ...*model.Notification) error                                            // 在业务事务中发送站内通知
+	GetNotifications(userID uint, req *model.NotificationListRequest) (*model.NotificationListResponse, error) // 获取通知列表
+	UnreadCount(userID uint) (int64, error)                                                                    // 获取未读通知数
+	MarkRead(userID uint, ids []uint) error                                                                    // 标记指定通知已读
+	MarkAllRead(userID uint) error                                                                             // 标记全部通知已读
+}
+
+// notificationService 站内通知业务逻辑层实现
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	pusher           NotificationPusher
+}
+
+// orderNotification 订单状态变更通知的内容模板，%s为订单号
+type orderNotification struct {
+	Type    string
+	Title   string
+	Content string
+}
+
+// orderNotifications 需要通知用户的订单状态
+var orderNotifications = map[model.OrderStatus]orderNotification{
+	model.OrderStatusPaid:              {model.NotificationTypePayment, "支付成功", "订单%s已支付成功，我们会尽快为您发货"},
+	model.OrderStatusShipped:           {model.NotificationTypeOrder, "订单已发货", "订单%s已发货，可在订单详情中查看物流"},
+	model.OrderStatusDelivered:         {model.NotificationTypeOrder, "订单已完成", "订单%s已确认收货，欢迎评价商品"},
+	model.OrderStatusCancelled:         {model.NotificationTypeOrder, "订单已取消", "订单%s已取消"},
+	model.OrderStatusRefunded:          {model.NotificationTypeOrder, "订单已退款", "订单%s已退款，款项将原路退回"},
+	model.OrderStatusPartiallyRefunded: {model.NotificationTypeOrder, "订单部分退款", "订单%s的部分商品已退款，款项将原路退回"},
+}
+
+// refundRejectedNotification 拒绝退款、订单恢复到申请前状态时的通知
+var refundRejectedNotification = orderNotification{model.NotificationTypeOrder, "退款申请未通过", "订单%s的退款申请未通过，订单已恢复为申请前的状态"}
+
+// NewNotificationService 创建站内通知业务逻辑层实例
+// pusher为nil时只保存到收件箱，不实时推送；订单状态变更时通知下单用户
+func NewNotificationService(notificationRepo repository.NotificationRepository, pusher NotificationPusher, states *OrderStateMachine) NotificationService {
+	s := &notificationService{
+		notificationRepo: notificationRepo,
+		pusher:           pusher,
+	}
+
+	for status := range orderNotifications {
+		states.AddHook(status, s.notifyOrder)
+	}
+
+	return s
+}
+
+// Send 发送站内通知
+func (s *notificationService) Send(notifications ...*model.Notification) error {
+	if err := s.notificationRepo.Create(notifications); err != nil {
+		return err
+	}
+	s.push(notifications)
+	return nil
+}
+
+// SendTx 在业务事务中发送站内通知，通知随事务一起提交或回滚
+// 推送在事务提交前发出，事务回滚时用户可能收到收件箱中没有的通知，以收件箱为准
+func (s *notificationService) SendTx(tx *gorm.DB, notifications ...*model.Notification) error {
+	if err := repository.CreateNotifications(tx, notifications); err != nil {
+		return err
+	}
+	s.push(notifications)
+	return nil
+}
+
+// GetNotifications 获取通知列表
+func (s *notificationService) GetNotifications(userID uint, req *model.NotificationListRequest) (*model.NotificationListResponse, error) {
+	notifications, total, err := s.notificationRepo.List(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &model.NotificationListResponse{
+		Notifications: notifications,
+		Total:         int(total),
+		UnreadCount:   int(unread),
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// UnreadCount 获取未读通知数
+func (s *notificationService) UnreadCount(userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead 标记指定通知已读，已读或不属于该用户的通知忽略
+func (s *notificationService) MarkRead(userID uint, ids []uint) error {
+	if len(ids) == 0 {
+		return errors.New("请选择要标记的通知")
+	}
+	_, err := s.notificationRepo.MarkRead(userID, ids)
+	return err
+}
+
+// MarkAllRead 标记全部通知已读
+func (s *notificationService) MarkAllRead(userID uint) error {
+	_, err := s.notificationRepo.MarkAllRead(userID)
+	return err
+}
+
+// notifyOrder 订单状态变更后通知下单用户
+// 在状态变更的事务中保存通知，通知失败只记录日志，不影响订单状态变更
+func (s *notificationService) notifyOrder(tx *gorm.DB, t *OrderTransition) error {
+	tmpl, ok := orderNotificationFor(t)
+	if !ok {
+		return nil
+	}
+
+	order, err := repository.GetOrderRecipient(tx, t.OrderID)
+	if err != nil || order == nil {
+		log.Printf("order %d: load recipient for notification failed: %v", t.OrderID, err)
+		return nil
+	}
+
+	err = s.SendTx(tx, &model.Notification{
+		UserID:  order.UserID,
+		Type:    tmpl.Type,
+		Title:   tmpl.Title,
+		Content: fmt.Sprintf(tmpl.Content, order.OrderNo),
+		RefID:   order.ID,
+	})
+	if err != nil {
+		log.Printf("order %d: send notification failed: %v", t.OrderID, err)
+	}
+	return nil
+}
+
+// orderNotificationFor 订单状态变更对应的通知
+// 拒绝退款恢复订单状态时只通知退款未通过，不再重复发送支付成功、已发货等通知
+func orderNotificationFor(t *OrderTransition) (orderNotification, bool) {
+	if t.Restore {
+		return refundRejectedNotification, true
+	}
+	tmpl, ok := orderNotifications[t.To]
+	return tmpl, ok
+}
+
+// push 在后台实时推送通知，失败只记录日志
+func (s *notificationService) push(notifications []*model.Notification) {
+	if s.pusher == nil || len(notifications) == 0 {
+		return
+	}
+	go func() {
+		for _, notification := range notifications {
+			payload, err := json.Marshal(notification)
+			if err != nil {
+				log.Printf("编码通知%d失败: %v", notification.ID, err)
+				continue
+			}
+			if err := s.pusher.Push(notification.UserID, payload); err != nil {
+				log.Printf("推送通知%d失败: %v", notification.ID, err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"testing"
+)
+
+func TestOrderNotificationFor_RejectedRefund(t *testing.T) {
+	// 拒绝退款恢复订单状态时只通知退款未通过
+	for _, to := range []model.OrderStatus{model.OrderStatusPaid, model.OrderStatusShipped, model.OrderStatusDelivered} {
+		tmpl, ok := orderNotificationFor(&OrderTransition{From: model.OrderStatusRefundRequested, To: to, Restore: true})
+		if !ok || tmpl != refundRejectedNotification {
+			t.Errorf("restore to %s notifies %+v, want refund rejected", model.GetOrderStatusText(to), tmpl)
+		}
+	}
+
+	// 正常支付仍然通知支付成功
+	tmpl, ok := orderNotificationFor(&OrderTransition{From: model.OrderStatusPending, To: model.OrderStatusPaid})
+	if !ok || tmpl != orderNotifications[model.OrderStatusPaid] {
+		t.Errorf("payment notifies %+v, want payment succeeded", tmpl)
+	}
+}
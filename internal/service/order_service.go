@@ -2,9 +2,11 @@ package service
 
 import (
 	"errors"
-	"fmt"
+	"log"
 	"ryan-mall/internal/model"
 	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/idgen"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,111 +15,137 @@ import (
 // OrderService 订单业务逻辑层接口
 type OrderService interface {
 	CreateOrder(userID uint, req *model.CreateOrderRequest) (*model.Order, error)     // 创建订单
+	PreviewOrder(userID uint, req *model.OrderPreviewRequest) (*model.OrderPreviewResponse, error) // 订单预览
 	GetOrder(userID, orderID uint) (*model.Order, error)                             // 获取订单详情
 	GetOrderByNo(userID uint, orderNo string) (*model.Order, error)                  // 根据订单号获取订单
 	GetOrderList(userID uint, req *model.OrderListRequest) (*model.OrderListResponse, error) // 获取订单列表
 	CancelOrder(userID, orderID uint) error                                          // 取消订单
-	PayOrder(userID, orderID uint, req *model.PayOrderRequest) error                 // 支付订单
+	PayOrder(userID, orderID uint, req *model.PayOrderRequest) (*model.PayOrderResponse, error) // 发起支付
 	ConfirmOrder(userID, orderID uint) error                                         // 确认收货
+	GetOrderTimeline(userID, orderID uint) (*model.OrderTimeline, error)             // 获取订单时间线
+	GetOrderTimelineForStaff(orderID uint) (*model.OrderTimeline, error)             // 获取订单时间线（客服/管理员）
 	GetOrderStatistics(userID uint) (*model.OrderStatistics, error)                 // 获取订单统计
-	ProcessExpiredOrders() error                                                     // 处理过期订单
+	CancelExpiredOrders(afterID uint, limit int) (*model.ExpiredOrderBatch, error)   // 分批取消超过支付时限的订单
+	CancelTimeoutOrders(orderIDs []uint) (*model.ExpiredOrderBatch, error)           // 取消延时队列中到期的订单
 }
 
+// OrderExpiryPolicy 待支付订单的支付时限
+type OrderExpiryPolicy struct {
+	Default  time.Duration            // 默认支付时限
+	ByMethod map[string]time.Duration // 按支付方式配置的支付时限
+}
+
+// TTL 获取支付方式对应的支付时限
+func (p OrderExpiryPolicy) TTL(paymentMethod string) time.Duration {
+	if ttl, ok := p.ByMethod[paymentMethod]; ok {
+		return ttl
+	}
+	return p.Default
+}
+
+// OrderTimeoutQueue 订单支付超时延时队列，消息为订单ID
+// redis.DelayQueue 实现了该接口
+type OrderTimeoutQueue interface {
+	Push(member string, dueAt time.Time) error // 添加超时消息
+	Remove(member string) error                // 删除超时消息
+}
+
+// orderNoPrefix 订单号前缀，订单号不带前缀
+const orderNoPrefix = ""
+
 // orderService 订单业务逻辑层实现
 type orderService struct {
 	orderRepo   repository.OrderRepository
 	cartRepo    repository.CartRepository
 	productRepo repository.ProductRepository
+	addressRepo repository.AddressRepository
+	paymentSvc  PaymentService
+	couponSvc   CouponService
+	inventory   InventoryService
+	states      *OrderStateMachine
+	expiry      OrderExpiryPolicy
+	checkout    CheckoutPolicy
+	timeouts    OrderTimeoutQueue
+	ids         *idgen.Generator
 	db          *gorm.DB
 }
 
 // NewOrderService 创建订单业务逻辑层实例
-func NewOrderService(orderRepo repository.OrderRepository, cartRepo repository.CartRepository, productRepo repository.ProductRepository, db *gorm.DB) OrderService {
-	return &orderService{
+func NewOrderService(orderRepo repository.OrderRepository, cartRepo repository.CartRepository, productRepo repository.ProductRepository, addressRepo repository.AddressRepository, paymentSvc PaymentService, couponSvc CouponService, inventory InventoryService, states *OrderStateMachine, expiry OrderExpiryPolicy, checkout CheckoutPolicy, timeouts OrderTimeoutQueue, ids *idgen.Generator, db *gorm.DB) OrderService {
+	s := &orderService{
 		orderRepo:   orderRepo,
 		cartRepo:    cartRepo,
 		productRepo: productRepo,
+		addressRepo: addressRepo,
+		paymentSvc:  paymentSvc,
+		couponSvc:   couponSvc,
+		inventory:   inventory,
+		states:      states,
+		expiry:      expiry,
+		checkout:    checkout,
+		timeouts:    timeouts,
+		ids:         ids,
 		db:          db,
 	}
+	
+	// 订单支付或取消后不再需要支付超时
+	states.AddHook(model.OrderStatusPaid, s.clearTimeout)
+	states.AddHook(model.OrderStatusCancelled, s.clearTimeout)
+	
+	return s
 }
 
 // CreateOrder 创建订单
 // 从购物车创建订单，包含库存扣减和购物车清理
 func (s *orderService) CreateOrder(userID uint, req *model.CreateOrderRequest) (*model.Order, error) {
-	// 1. 验证购物车项
-	cartItems, err := s.cartRepo.GetByIDs(req.CartItemIDs)
+	// 1. 结算：验证购物车项、确定收货地址并计价
+	result, err := s.settle(userID, &req.OrderPreviewRequest)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(cartItems) == 0 {
-		return nil, errors.New("购物车为空")
-	}
-
-	// 验证购物车项是否属于当前用户
-	for _, item := range cartItems {
-		if item.UserID != userID {
-			return nil, errors.New("购物车项不属于当前用户")
-		}
-
-		// 验证商品状态
-		if item.Product.Status != model.ProductStatusOnline {
-			return nil, errors.New("商品 " + item.Product.Name + " 已下架")
+	for _, warning := range result.warnings {
+		if warning != "" {
+			return nil, errors.New(warning)
 		}
+	}
+	cartItems, shippingAddress, quote := result.cartItems, result.address, result.quote
 
-		// 验证库存
-		if item.Quantity > item.Product.Stock {
-			return nil, errors.New("商品 " + item.Product.Name + " 库存不足")
-		}
+	// 联系电话默认使用收货人电话
+	contactPhone := req.ContactPhone
+	if contactPhone == "" {
+		contactPhone = shippingAddress.Phone
+	}
+	if err := model.ValidatePhone(contactPhone); err != nil {
+		return nil, errors.New("联系电话格式错误")
 	}
 	
 	// 2. 使用事务处理订单创建
 	var order *model.Order
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// 3. 计算订单金额和创建订单项
-		var totalAmount float64
+		// 3. 创建订单项
 		var orderItems []*model.OrderItem
 		
-		for _, cartItem := range cartItems {
-			// 再次检查库存（防止并发问题）
-			var currentStock int
-			err := tx.Model(&model.Product{}).
-				Select("stock").
-				Where("id = ?", cartItem.ProductID).
-				Row().Scan(&currentStock)
-			if err != nil {
-				return err
-			}
-			
-			if currentStock < cartItem.Quantity {
-				return fmt.Errorf("商品 %s 库存不足，当前库存：%d", cartItem.Product.Name, currentStock)
-			}
-			
-			// 扣减库存
-			err = tx.Model(&model.Product{}).
-				Where("id = ?", cartItem.ProductID).
-				Update("stock", gorm.Expr("stock - ?", cartItem.Quantity)).Error
-			if err != nil {
-				return err
-			}
-			
-			// 创建订单项
-			itemTotal := cartItem.Product.Price * float64(cartItem.Quantity)
-			totalAmount += itemTotal
-			
+		for i, cartItem := range cartItems {
+			// 创建订单项，金额使用计价结果
 			orderItem := &model.OrderItem{
-				ProductID:    cartItem.ProductID,
-				ProductName:  cartItem.Product.Name,
-				ProductImage: cartItem.Product.MainImage,
-				Price:        cartItem.Product.Price,
-				Quantity:     cartItem.Quantity,
-				TotalPrice:   itemTotal,
+				ProductID:      cartItem.ProductID,
+				SkuID:          cartItem.SkuID,
+				ProductName:    cartItem.Product.Name,
+				SpecKey:        cartItem.SpecKey(),
+				ProductImage:   cartItem.Image(),
+				Price:          quote.Lines[i].Price,
+				Quantity:       cartItem.Quantity,
+				TotalPrice:     quote.Lines[i].Subtotal,
+				DiscountAmount: quote.Lines[i].Discount,
 			}
 			orderItems = append(orderItems, orderItem)
 		}
 		
 		// 4. 生成订单号
-		orderNo := s.generateOrderNo()
+		orderNo, err := s.ids.Next(orderNoPrefix)
+		if err != nil {
+			return err
+		}
 		
 		// 5. 创建订单
 		var remark *string
@@ -131,22 +159,46 @@ func (s *orderService) CreateOrder(userID uint, req *model.CreateOrderRequest) (
 			orderItemsSlice = append(orderItemsSlice, *item)
 		}
 
+		var userCouponID *uint
+		if quote.UserCouponID != 0 {
+			userCouponID = &quote.UserCouponID
+		}
+
 		order = &model.Order{
 			OrderNo:         orderNo,
 			UserID:          userID,
-			TotalAmount:     totalAmount,
+			TotalAmount:     quote.TotalAmount,
+			DiscountAmount:  quote.DiscountAmount,
+			ShippingFee:     quote.ShippingFee,
+			TaxAmount:       quote.TaxAmount,
+			UserCouponID:    userCouponID,
 			Status:          model.OrderStatusPending,
 			PaymentMethod:   req.PaymentMethod,
-			ContactPhone:    req.ContactPhone,
-			ShippingAddress: req.ShippingAddress,
+			ContactPhone:    contactPhone,
+			ShippingAddress: shippingAddress,
 			Remark:          remark,
 			OrderItems:      orderItemsSlice,
 		}
 		
-		// 6. 保存订单
+		// 6. 保存订单并记录订单创建
 		if err := tx.Create(order).Error; err != nil {
 			return err
 		}
+		if err := s.states.Created(tx, order, model.OrderActorUser(userID)); err != nil {
+			return err
+		}
+		
+		// 预占库存（按可售库存条件更新，防止并发超卖），支付后扣减，取消或超时后释放
+		if err := s.inventory.Reserve(tx, order); err != nil {
+			return err
+		}
+		
+		// 核销优惠券，与订单创建同时生效
+		if quote.UserCouponID != 0 {
+			if err := s.couponSvc.Redeem(tx, userID, quote.UserCouponID, order.ID); err != nil {
+				return err
+			}
+		}
 		
 		// 7. 清理购物车
 		for _, cartItem := range cartItems {
@@ -162,10 +214,167 @@ func (s *orderService) CreateOrder(userID uint, req *model.CreateOrderRequest) (
 		return nil, err
 	}
 	
-	// 8. 重新查询订单（包含关联数据）
+	// 8. 登记支付超时，到期未支付的订单由延时队列触发取消
+	s.scheduleTimeout(order)
+	
+	// 9. 重新查询订单（包含关联数据）
 	return s.orderRepo.GetByID(order.ID)
 }
 
+// PreviewOrder 订单预览
+// 与下单使用同一套结算逻辑，返回的金额就是下单后的应付金额；
+// 商品下架、库存不足等问题以提示返回，不影响其他商品的计价
+func (s *orderService) PreviewOrder(userID uint, req *model.OrderPreviewRequest) (*model.OrderPreviewResponse, error) {
+	result, err := s.settle(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := result.quote
+	preview := &model.OrderPreviewResponse{
+		Items:           make([]*model.OrderPreviewItem, 0, len(result.cartItems)),
+		ShippingAddress: result.address,
+		Allocations:     quote.Allocations,
+		TotalAmount:     quote.TotalAmount,
+		DiscountAmount:  quote.DiscountAmount,
+		ShippingFee:     quote.ShippingFee,
+		TaxAmount:       quote.TaxAmount,
+		PayAmount:       quote.PayAmount,
+		UserCouponID:    quote.UserCouponID,
+		Purchasable:     true,
+	}
+	for i, item := range result.cartItems {
+		line := quote.Lines[i]
+		preview.Items = append(preview.Items, &model.OrderPreviewItem{
+			CartItemID:   item.ID,
+			ProductID:    item.ProductID,
+			SkuID:        item.SkuID,
+			SpecKey:      item.SpecKey(),
+			ProductName:  item.Product.Name,
+			ProductImage: item.Image(),
+			Price:        line.Price,
+			Quantity:     line.Quantity,
+			Stock:        item.AvailableStock(),
+			Subtotal:     line.Subtotal,
+			Discount:     line.Discount,
+			PayAmount:    line.PayAmount,
+			Warning:      result.warnings[i],
+		})
+		if result.warnings[i] != "" {
+			preview.Purchasable = false
+		}
+	}
+
+	return preview, nil
+}
+
+// settlement 结算结果
+type settlement struct {
+	cartItems []*model.CartItem // 结算的购物车项
+	warnings  []string          // 每个购物车项不能下单的原因，为空表示可以下单
+	address   model.JSONAddress // 收货地址
+	quote     *model.PriceQuote // 计价结果，计价行与购物车项一一对应
+}
+
+// settle 结算：验证购物车项、确定收货地址并计价
+// 订单预览和下单都经过这里，保证预览金额与实际下单金额一致
+func (s *orderService) settle(userID uint, req *model.OrderPreviewRequest) (*settlement, error) {
+	// 1. 验证购物车项
+	cartItems, err := s.cartRepo.GetByIDs(req.CartItemIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(cartItems) == 0 {
+		return nil, errors.New("购物车为空")
+	}
+
+	warnings := make([]string, len(cartItems))
+	for i, item := range cartItems {
+		// 验证购物车项是否属于当前用户
+		if item.UserID != userID {
+			return nil, errors.New("购物车项不属于当前用户")
+		}
+
+		// 验证商品和规格状态、库存
+		warnings[i] = item.Unavailable()
+	}
+
+	// 2. 确定收货地址（地址簿或直接填写）
+	address, err := s.resolveShippingAddress(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 计价：优惠券 -> 运费 -> 税额
+	lines := make([]*model.PriceLine, 0, len(cartItems))
+	for _, item := range cartItems {
+		lines = append(lines, &model.PriceLine{
+			ProductID:  item.ProductID,
+			CategoryID: item.Product.CategoryID,
+			Price:      item.UnitPrice(),
+			Quantity:   item.Quantity,
+		})
+	}
+
+	var stages []PricingStage
+	if req.CouponID != 0 {
+		stage, err := s.couponSvc.CouponStage(userID, req.CouponID)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, stage)
+	}
+	stages = append(stages, s.checkout.Stages(address)...)
+
+	quote, err := RunPricing(lines, stages...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &settlement{
+		cartItems: cartItems,
+		warnings:  warnings,
+		address:   address,
+		quote:     quote,
+	}, nil
+}
+
+// resolveShippingAddress 确定订单收货地址
+// 优先使用指定的地址簿地址，其次是直接填写的地址，都没有时使用默认地址；
+// 地址簿中的地址保存为快照，之后修改或删除地址不影响订单
+func (s *orderService) resolveShippingAddress(userID uint, req *model.OrderPreviewRequest) (model.JSONAddress, error) {
+	var address model.JSONAddress
+
+	switch {
+	case req.AddressID != 0:
+		saved, err := s.addressRepo.GetByID(req.AddressID)
+		if err != nil {
+			return address, err
+		}
+		if saved == nil || saved.UserID != userID {
+			return address, errors.New("收货地址不存在")
+		}
+		address = saved.Snapshot()
+	case req.ShippingAddress != nil:
+		address = *req.ShippingAddress
+	default:
+		// 未指定地址时使用默认地址
+		saved, err := s.addressRepo.GetDefault(userID)
+		if err != nil {
+			return address, err
+		}
+		if saved == nil {
+			return address, errors.New("请选择收货地址")
+		}
+		address = saved.Snapshot()
+	}
+
+	if err := address.Validate(); err != nil {
+		return address, err
+	}
+	return address, nil
+}
+
 // GetOrder 获取订单详情
 func (s *orderService) GetOrder(userID, orderID uint) (*model.Order, error) {
 	order, err := s.orderRepo.GetByID(orderID)
@@ -231,57 +440,35 @@ func (s *orderService) CancelOrder(userID, orderID uint) error {
 		return err
 	}
 	
-	// 2. 检查订单状态
-	if order.Status != model.OrderStatusPending {
-		return errors.New("只能取消待支付的订单")
-	}
-	
-	// 3. 使用事务处理取消逻辑
+	// 2. 使用事务更新订单状态
+	// 预占的库存和使用的优惠券由状态机钩子在同一事务中释放
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		// 4. 更新订单状态
-		err := tx.Model(order).Updates(map[string]interface{}{
-			"status":     model.OrderStatusCancelled,
-			"updated_at": time.Now(),
-		}).Error
-		if err != nil {
-			return err
-		}
-		
-		// 5. 恢复库存
-		for _, item := range order.OrderItems {
-			err = tx.Model(&model.Product{}).
-				Where("id = ?", item.ProductID).
-				Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error
-			if err != nil {
-				return err
-			}
-		}
-		
-		return nil
+		return s.states.Transit(tx, &OrderTransition{
+			OrderID: order.ID,
+			From:    order.Status,
+			To:      model.OrderStatusCancelled,
+			Actor:   model.OrderActorUser(userID),
+			Reason:  "用户取消订单",
+		})
 	})
 }
 
-// PayOrder 支付订单
-func (s *orderService) PayOrder(userID, orderID uint, req *model.PayOrderRequest) error {
+// PayOrder 发起支付
+// 向支付渠道创建支付单，订单在收到渠道的签名回调后才会变为已支付
+func (s *orderService) PayOrder(userID, orderID uint, req *model.PayOrderRequest) (*model.PayOrderResponse, error) {
 	// 1. 获取订单
 	order, err := s.GetOrder(userID, orderID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	
 	// 2. 检查订单状态
-	if order.Status != model.OrderStatusPending {
-		return errors.New("订单状态不正确，无法支付")
+	if !s.states.Can(order.Status, model.OrderStatusPaid) {
+		return nil, errors.New("订单状态不正确，无法支付")
 	}
 	
-	// 3. 模拟支付处理
-	// 在实际项目中，这里会调用第三方支付接口
-	if !s.simulatePayment(req.PaymentMethod, order.TotalAmount) {
-		return errors.New("支付失败")
-	}
-	
-	// 4. 更新订单状态
-	return s.orderRepo.UpdateStatus(orderID, model.OrderStatusPaid)
+	// 3. 调用支付渠道创建支付单
+	return s.paymentSvc.CreateCharge(order, req.PaymentMethod)
 }
 
 // ConfirmOrder 确认收货
@@ -292,13 +479,48 @@ func (s *orderService) ConfirmOrder(userID, orderID uint) error {
 		return err
 	}
 	
-	// 2. 检查订单状态
-	if order.Status != model.OrderStatusShipped {
-		return errors.New("只能确认已发货的订单")
+	// 2. 更新订单状态
+	return s.states.Transit(s.db, &OrderTransition{
+		OrderID: order.ID,
+		From:    order.Status,
+		To:      model.OrderStatusDelivered,
+		Actor:   model.OrderActorUser(userID),
+		Reason:  "用户确认收货",
+	})
+}
+
+// GetOrderTimeline 获取订单时间线
+func (s *orderService) GetOrderTimeline(userID, orderID uint) (*model.OrderTimeline, error) {
+	order, err := s.GetOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	
+	return s.buildTimeline(order)
+}
+
+// GetOrderTimelineForStaff 获取订单时间线（客服/管理员）
+// 不校验订单所有权
+func (s *orderService) GetOrderTimelineForStaff(orderID uint) (*model.OrderTimeline, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("订单不存在")
+	}
+	
+	return s.buildTimeline(order)
+}
+
+// buildTimeline 根据订单状态历史生成时间线
+func (s *orderService) buildTimeline(order *model.Order) (*model.OrderTimeline, error) {
+	logs, err := s.orderRepo.GetStatusLogs(order.ID)
+	if err != nil {
+		return nil, err
 	}
 	
-	// 3. 更新订单状态
-	return s.orderRepo.UpdateStatus(orderID, model.OrderStatusDelivered)
+	return model.NewOrderTimeline(order, logs), nil
 }
 
 // GetOrderStatistics 获取订单统计
@@ -306,31 +528,120 @@ func (s *orderService) GetOrderStatistics(userID uint) (*model.OrderStatistics,
 	return s.orderRepo.GetOrderStatistics(userID)
 }
 
-// ProcessExpiredOrders 处理过期订单
-func (s *orderService) ProcessExpiredOrders() error {
-	return s.orderRepo.CancelExpiredOrders()
+// CancelExpiredOrders 分批取消超过支付时限的订单
+// 处理afterID之后的最多limit个过期订单，调用方根据LastID继续处理下一批
+func (s *orderService) CancelExpiredOrders(afterID uint, limit int) (*model.ExpiredOrderBatch, error) {
+	// 1. 计算各支付方式的截止时间
+	now := time.Now()
+	deadlines := make(map[string]time.Time, len(s.expiry.ByMethod))
+	for method, ttl := range s.expiry.ByMethod {
+		deadlines[method] = now.Add(-ttl)
+	}
+	
+	// 2. 查找本批过期订单
+	orderIDs, err := s.orderRepo.FindExpiredOrderIDs(deadlines, now.Add(-s.expiry.Default), afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	
+	batch := &model.ExpiredOrderBatch{LastID: afterID, Scanned: len(orderIDs)}
+	if len(orderIDs) == 0 {
+		return batch, nil
+	}
+	batch.LastID = orderIDs[len(orderIDs)-1]
+	
+	// 3. 取消订单，预占的库存和使用的优惠券由状态机钩子释放
+	batch.Cancelled, batch.ProductIDs, err = s.cancelUnpaidOrders(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	return batch, nil
 }
 
-// generateOrderNo 生成订单号
-// 格式：年月日时分秒 + 6位随机数
-func (s *orderService) generateOrderNo() string {
-	now := time.Now()
-	return fmt.Sprintf("%s%06d", 
-		now.Format("20060102150405"), 
-		now.Nanosecond()%1000000)
+// CancelTimeoutOrders 取消延时队列中到期的订单
+// 已支付或已取消的订单会被跳过
+func (s *orderService) CancelTimeoutOrders(orderIDs []uint) (*model.ExpiredOrderBatch, error) {
+	batch := &model.ExpiredOrderBatch{Scanned: len(orderIDs)}
+	if len(orderIDs) == 0 {
+		return batch, nil
+	}
+	batch.LastID = orderIDs[len(orderIDs)-1]
+	
+	var err error
+	batch.Cancelled, batch.ProductIDs, err = s.cancelUnpaidOrders(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	return batch, nil
 }
 
-// simulatePayment 模拟支付处理
-func (s *orderService) simulatePayment(paymentMethod string, amount float64) bool {
-	// 模拟支付逻辑
-	// 在实际项目中，这里会调用支付宝、微信支付等第三方接口
+// cancelUnpaidOrders 通过状态机逐个取消仍为待支付的订单
+// 每个订单一个事务，与支付回调并发时只有一方能变更状态，已支付或已取消的订单会被跳过；
+// 返回实际取消的订单数和释放了库存的商品ID
+func (s *orderService) cancelUnpaidOrders(orderIDs []uint) (int, []uint, error) {
+	cancelled := 0
+	var productIDs []uint
+	for _, orderID := range orderIDs {
+		// 1. 获取订单，已不是待支付的直接跳过
+		order, err := s.orderRepo.GetByID(orderID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if order == nil || order.Status != model.OrderStatusPending {
+			continue
+		}
+		
+		// 2. 取消订单
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			return s.states.Transit(tx, &OrderTransition{
+				OrderID: order.ID,
+				From:    model.OrderStatusPending,
+				To:      model.OrderStatusCancelled,
+				Actor:   model.OrderActorSystem,
+				Reason:  "超时未支付，自动取消",
+			})
+		})
+		if errors.Is(err, ErrOrderStatusChanged) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		
+		cancelled++
+		for _, item := range order.OrderItems {
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
 	
-	// 简单模拟：金额大于0且支付方式有效
-	validMethods := map[string]bool{
-		"alipay":  true,
-		"wechat":  true,
-		"balance": true,
+	return cancelled, uniqueIDs(productIDs), nil
+}
+
+// scheduleTimeout 登记订单支付超时
+// 登记失败不影响下单，过期订单兜底扫描任务会处理
+func (s *orderService) scheduleTimeout(order *model.Order) {
+	if s.timeouts == nil {
+		return
 	}
 	
-	return amount > 0 && validMethods[paymentMethod]
+	dueAt := order.CreatedAt.Add(s.expiry.TTL(order.PaymentMethod))
+	if err := s.timeouts.Push(strconv.FormatUint(uint64(order.ID), 10), dueAt); err != nil {
+		log.Printf("order %s: schedule payment timeout failed: %v", order.OrderNo, err)
+	}
 }
+
+// clearTimeout 删除订单支付超时，注册为订单支付、取消时的状态机钩子
+// 删除失败不影响状态变更，到期的消息会因订单不再是待支付而被跳过
+func (s *orderService) clearTimeout(tx *gorm.DB, t *OrderTransition) error {
+	if s.timeouts == nil {
+		return nil
+	}
+	
+	if err := s.timeouts.Remove(strconv.FormatUint(uint64(t.OrderID), 10)); err != nil {
+		log.Printf("order %d: remove payment timeout failed: %v", t.OrderID, err)
+	}
+	return nil
+}
+
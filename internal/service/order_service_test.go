@@ -0,0 +1,42 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/logistics"
+	"testing"
+)
+
+// stubOrderRepo 只实现GetByID的订单数据访问层，其他方法调用时会panic
+type stubOrderRepo struct {
+	repository.OrderRepository
+	order *model.Order
+}
+
+func (r *stubOrderRepo) GetByID(id uint) (*model.Order, error) {
+	if r.order == nil || r.order.ID != id {
+		return nil, nil
+	}
+	return r.order, nil
+}
+
+func TestOpenRefundBlocksPayShipAndConfirm(t *testing.T) {
+	order := &model.Order{ID: 1, UserID: 7, Status: model.OrderStatusRefundRequested}
+	repo := &stubOrderRepo{order: order}
+	states := NewOrderStateMachine()
+
+	orders := &orderService{orderRepo: repo, states: states}
+	if _, err := orders.PayOrder(7, 1, &model.PayOrderRequest{}); err == nil {
+		t.Error("PayOrder succeeded while a refund is open")
+	}
+	if err := orders.ConfirmOrder(7, 1); err == nil {
+		t.Error("ConfirmOrder succeeded while a refund is open")
+	}
+
+	carriers := logistics.NewRegistry()
+	carriers.Register(logistics.NewFakeCarrier("fake", "secret", ""))
+	shipments := &shipmentService{orderRepo: repo, carriers: carriers, states: states}
+	if _, err := shipments.ShipOrder(1, 1, &model.ShipOrderRequest{Carrier: "fake", TrackingNo: "T1"}); err == nil {
+		t.Error("ShipOrder succeeded while a refund is open")
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"ryan-mall/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrOrderStatusChanged 订单状态已被其他操作修改
+var ErrOrderStatusChanged = errors.New("订单状态已变化，请刷新后重试")
+
+// OrderTransition 一次订单状态变更
+type OrderTransition struct {
+	OrderID uint                   // 订单ID
+	From    model.OrderStatus      // 当前状态
+	To      model.OrderStatus      // 目标状态
+	Actor   string                 // 操作人，如 user:1、admin:2、system
+	Reason  string                 // 变更原因
+	Updates map[string]interface{} // 随状态一起更新的其他字段
+	Force   bool                   // 管理员强制变更：跳过流转表和校验，仍然记录状态历史并执行钩子
+	Restore bool                   // 拒绝退款：按恢复流转表恢复到申请退款前的状态
+}
+
+// OrderGuard 状态变更前的校验，返回错误时终止变更
+type OrderGuard func(tx *gorm.DB, t *OrderTransition) error
+
+// OrderHook 状态变更后的处理，与状态变更在同一事务中执行
+type OrderHook func(tx *gorm.DB, t *OrderTransition) error
+
+// OrderStateMachine 订单状态机
+// 所有订单状态变更都通过状态机完成：按流转表校验、执行校验和钩子、记录状态历史
+type OrderStateMachine struct {
+	guards map[model.OrderStatus][]OrderGuard
+	hooks  map[model.OrderStatus][]OrderHook
+}
+
+// NewOrderStateMachine 创建订单状态机
+func NewOrderStateMachine() *OrderStateMachine {
+	return &OrderStateMachine{
+		guards: make(map[model.OrderStatus][]OrderGuard),
+		hooks:  make(map[model.OrderStatus][]OrderHook),
+	}
+}
+
+// AddGuard 注册进入指定状态前的校验
+func (m *OrderStateMachine) AddGuard(to model.OrderStatus, guard OrderGuard) {
+	m.guards[to] = append(m.guards[to], guard)
+}
+
+// AddHook 注册进入指定状态后的处理
+func (m *OrderStateMachine) AddHook(to model.OrderStatus, hook OrderHook) {
+	m.hooks[to] = append(m.hooks[to], hook)
+}
+
+// Can 订单状态是否允许从from变更为to
+func (m *OrderStateMachine) Can(from, to model.OrderStatus) bool {
+	return model.CanTransitOrder(from, to)
+}
+
+// Transit 在事务中执行订单状态变更
+// 只有订单仍处于From状态时才会变更，否则返回ErrOrderStatusChanged
+func (m *OrderStateMachine) Transit(tx *gorm.DB, t *OrderTransition) error {
+	// 1. 检查流转表，拒绝退款时检查恢复流转表，强制变更时跳过
+	allowed := m.Can(t.From, t.To)
+	if t.Restore {
+		allowed = model.CanRestoreOrder(t.From, t.To)
+	}
+	if !t.Force && !allowed {
+		return fmt.Errorf("订单状态不能从%s变更为%s",
+			model.GetOrderStatusText(t.From), model.GetOrderStatusText(t.To))
+	}
+
+	// 2. 执行校验，强制变更时跳过
+	if !t.Force {
+		for _, guard := range m.guards[t.To] {
+			if err := guard(tx, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 3. 按当前状态条件更新，防止并发修改
+	updates := map[string]interface{}{
+		"status":     t.To,
+		"updated_at": time.Now(),
+	}
+	for key, value := range t.Updates {
+		updates[key] = value
+	}
+	result := tx.Model(&model.Order{}).
+		Where("id = ? AND status = ?", t.OrderID, t.From).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrderStatusChanged
+	}
+
+	// 4. 记录状态历史
+	if err := m.record(tx, t.OrderID, t.From, t.To, t.Actor, t.Reason); err != nil {
+		return err
+	}
+
+	// 5. 执行钩子
+	for _, hook := range m.hooks[t.To] {
+		if err := hook(tx, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Created 记录订单创建，作为时间线的第一条记录，并执行进入初始状态的钩子
+func (m *OrderStateMachine) Created(tx *gorm.DB, order *model.Order, actor string) error {
+	const reason = "创建订单"
+	if err := m.record(tx, order.ID, 0, order.Status, actor, reason); err != nil {
+		return err
+	}
+
+	t := &OrderTransition{
+		OrderID: order.ID,
+		To:      order.Status,
+		Actor:   actor,
+		Reason:  reason,
+	}
+	for _, hook := range m.hooks[order.Status] {
+		if err := hook(tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record 写入订单状态历史
+func (m *OrderStateMachine) record(tx *gorm.DB, orderID uint, from, to model.OrderStatus, actor, reason string) error {
+	return tx.Create(&model.OrderStatusLog{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Actor:      actor,
+		Reason:     reason,
+	}).Error
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/idgen"
+	"ryan-mall/pkg/payment"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PaymentService 支付业务逻辑层接口
+type PaymentService interface {
+	CreateCharge(order *model.Order, paymentMethod string) (*model.PayOrderResponse, error)           // 为订单创建支付单
+	HandleNotify(paymentMethod string, header http.Header, body []byte) error                         // 处理支付渠道异步通知
+	SimulatePay(userID uint, paymentMethod, paymentNo string, success bool) error                     // 沙箱模拟付款
+	GetOrderPayments(userID, orderID uint) ([]*model.Payment, error)                                  // 获取订单的支付记录
+	RefundOrder(orderID uint, refundNo string, amount float64, reason string) (*model.Payment, error) // 订单退款
+	RetryPendingRefunds(limit int) (refunded, failed int, err error)                                  // 重试待自动退款的支付
+}
+
+// paymentNoPrefix 支付单号前缀
+const paymentNoPrefix = "P"
+
+// autoRefundSuffix 自动退款的渠道退款单号后缀
+// 每笔支付最多自动全额退款一次，退款单号为支付单号加后缀，重试时渠道按退款单号去重
+const autoRefundSuffix = "R"
+
+// paymentService 支付业务逻辑层实现
+type paymentService struct {
+	paymentRepo   repository.PaymentRepository
+	orderRepo     repository.OrderRepository
+	providers     *payment.Registry
+	notifyBaseURL string
+	states        *OrderStateMachine
+	notifications NotificationService
+	ids           *idgen.Generator
+	db            *gorm.DB
+}
+
+// NewPaymentService 创建支付业务逻辑层实例
+// notifyBaseURL: 支付渠道回调本服务的外部访问地址
+func NewPaymentService(paymentRepo repository.PaymentRepository, orderRepo repository.OrderRepository, providers *payment.Registry, notifyBaseURL string, states *OrderStateMachine, notifications NotificationService, ids *idgen.Generator, db *gorm.DB) PaymentService {
+	return &paymentService{
+		paymentRepo:   paymentRepo,
+		orderRepo:     orderRepo,
+		providers:     providers,
+		notifyBaseURL: strings.TrimRight(notifyBaseURL, "/"),
+		states:        states,
+		notifications: notifications,
+		ids:           ids,
+		db:            db,
+	}
+}
+
+// CreateCharge 为订单创建支付单
+// 每次发起支付都会生成一条支付记录，订单状态要等到签名回调到达后才会变更
+func (s *paymentService) CreateCharge(order *model.Order, paymentMethod string) (*model.PayOrderResponse, error) {
+	provider, err := s.providers.Get(paymentMethod)
+	if err != nil {
+		return nil, errors.New("不支持的支付方式")
+	}
+
+	if order.PayableAmount() <= 0 {
+		return nil, errors.New("订单金额不正确，无法支付")
+	}
+
+	// 1. 关闭该订单之前未完成的支付尝试，保证同一时间只有一笔进行中的支付
+	activePayments, err := s.paymentRepo.GetActiveByOrderID(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, active := range activePayments {
+		if err := s.closePayment(active); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 创建支付记录
+	paymentNo, err := s.ids.Next(paymentNoPrefix)
+	if err != nil {
+		return nil, err
+	}
+	record := &model.Payment{
+		PaymentNo: paymentNo,
+		OrderID:   order.ID,
+		OrderNo:   order.OrderNo,
+		UserID:    order.UserID,
+		Amount:    order.PayableAmount(),
+		Method:    paymentMethod,
+		Status:    model.PaymentStatusPending,
+	}
+	if err := s.paymentRepo.Create(record); err != nil {
+		return nil, err
+	}
+
+	// 3. 在支付渠道下单
+	charge, err := provider.CreateCharge(&payment.ChargeRequest{
+		OutTradeNo: record.PaymentNo,
+		Amount:     record.Amount,
+		Subject:    fmt.Sprintf("Ryan Mall 订单 %s", order.OrderNo),
+		NotifyURL:  s.notifyURL(paymentMethod),
+	})
+	if err != nil {
+		// 渠道下单失败，记录原因后关闭本次支付
+		record.FailureReason = err.Error()
+		if cancelErr := record.Cancel(); cancelErr == nil {
+			repository.TransitPayment(s.db, record, model.PaymentStatusPending)
+		}
+		return nil, fmt.Errorf("创建支付单失败: %w", err)
+	}
+
+	// 4. 记录渠道交易号；本次支付在下单期间已被新的支付请求关闭时，关闭刚创建的渠道支付单
+	if err := record.Process(charge.TradeNo); err != nil {
+		return nil, err
+	}
+	updated, err := repository.TransitPayment(s.db, record, model.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		if err := provider.CloseCharge(record.PaymentNo); err != nil {
+			log.Printf("payment: close superseded charge %s failed: %v", record.PaymentNo, err)
+		}
+		return nil, errors.New("支付已被新的支付请求取代")
+	}
+
+	return &model.PayOrderResponse{
+		OrderID:       order.ID,
+		OrderNo:       order.OrderNo,
+		PaymentMethod: paymentMethod,
+		OutTradeNo:    record.PaymentNo,
+		TradeNo:       charge.TradeNo,
+		Amount:        record.Amount,
+		PayURL:        charge.PayURL,
+	}, nil
+}
+
+// HandleNotify 处理支付渠道异步通知
+// 验签通过且金额一致时，才将订单从待支付变为已支付；重复通知直接忽略。
+// 已被取代的支付单仍然支付成功，或到账时订单已不再待支付的，资金原路全额退回
+func (s *paymentService) HandleNotify(paymentMethod string, header http.Header, body []byte) error {
+	provider, err := s.providers.Get(paymentMethod)
+	if err != nil {
+		return err
+	}
+
+	// 1. 验证签名
+	notification, err := provider.VerifyNotify(header, body)
+	if err != nil {
+		return err
+	}
+
+	// 2. 查找支付记录
+	record, err := s.paymentRepo.GetByPaymentNo(notification.OutTradeNo)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return fmt.Errorf("支付单 %s 不存在", notification.OutTradeNo)
+	}
+
+	// 3. 支付未成功，记录失败原因，订单保持待支付，用户可重新发起支付
+	if notification.Status != payment.ChargeStatusSucceeded {
+		if record.Status != model.PaymentStatusProcessing {
+			log.Printf("payment notify: payment %s already %s, skip", record.PaymentNo, record.Status)
+			return nil
+		}
+		if err := record.Fail(fmt.Sprintf("渠道返回状态: %s", notification.Status)); err != nil {
+			return err
+		}
+		updated, err := repository.TransitPayment(s.db, record, model.PaymentStatusProcessing)
+		if err != nil {
+			return err
+		}
+		if updated {
+			s.notifyFailure(record)
+		}
+		return nil
+	}
+
+	// 4. 校验金额
+	if math.Abs(notification.Amount-record.Amount) > 0.001 {
+		return fmt.Errorf("支付单 %s 支付金额不一致", record.PaymentNo)
+	}
+
+	// 5. 重复通知
+	from := record.Status
+	if from != model.PaymentStatusProcessing && from != model.PaymentStatusCancelled && from != model.PaymentStatusFailed {
+		log.Printf("payment notify: payment %s already %s, skip", record.PaymentNo, record.Status)
+		return nil
+	}
+
+	// 6. 在同一事务中完成支付记录并更新订单状态
+	completed := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 6.1 条件更新支付记录，并发到达的重复通知只有一个能更新成功
+		//     已被取代的支付单不再变更订单，直接标记待退款
+		var err error
+		if from == model.PaymentStatusProcessing {
+			err = record.Complete(notification.PaidAt)
+		} else {
+			err = record.CompleteForRefund(notification.PaidAt)
+		}
+		if err != nil {
+			return err
+		}
+		completed, err = repository.TransitPayment(tx, record, from)
+		if err != nil || !completed || record.RefundPending {
+			return err
+		}
+
+		// 6.2 订单从待支付变为已支付
+		err = s.states.Transit(tx, &OrderTransition{
+			OrderID: record.OrderID,
+			From:    model.OrderStatusPending,
+			To:      model.OrderStatusPaid,
+			Actor:   model.OrderActorSystem,
+			Reason:  fmt.Sprintf("支付成功，支付单 %s", record.PaymentNo),
+			Updates: map[string]interface{}{
+				"payment_method": record.Method,
+				"payment_time":   notification.PaidAt,
+			},
+		})
+		if errors.Is(err, ErrOrderStatusChanged) {
+			// 订单已取消或已由其他支付单完成，资金已到账，标记待退款
+			record.RefundPending = true
+			return tx.Model(record).Update("refund_pending", true).Error
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !completed {
+		log.Printf("payment notify: payment %s handled concurrently, skip", record.PaymentNo)
+		return nil
+	}
+
+	// 7. 需要退回的支付立即发起退款，失败时由定时任务重试
+	if record.RefundPending {
+		log.Printf("payment notify: order %s is no longer waiting for payment %s, refund it", record.OrderNo, record.PaymentNo)
+		if err := s.refundPayment(record); err != nil {
+			log.Printf("payment notify: refund payment %s failed, will retry: %v", record.PaymentNo, err)
+		}
+	}
+	return nil
+}
+
+// closePayment 关闭进行中的支付尝试
+// 先关闭渠道支付单保证不能再支付，再条件更新为已取消；渠道侧已支付的不能关闭，等待其支付通知
+func (s *paymentService) closePayment(record *model.Payment) error {
+	provider, err := s.providers.Get(record.Method)
+	if err != nil {
+		return err
+	}
+
+	// 待支付的记录可能还没有在渠道下单，渠道支付单不存在时直接取消
+	err = provider.CloseCharge(record.PaymentNo)
+	if errors.Is(err, payment.ErrChargePaid) {
+		return errors.New("订单已有支付成功的支付单，请稍后查看支付结果")
+	}
+	if err != nil && !errors.Is(err, payment.ErrChargeNotFound) {
+		return fmt.Errorf("关闭支付单失败: %w", err)
+	}
+
+	from := record.Status
+	if err := record.Cancel(); err != nil {
+		return err
+	}
+	_, err = repository.TransitPayment(s.db, record, from)
+	return err
+}
+
+// refundPayment 将到账后不再需要的支付原路全额退回
+// 退款单号由支付单号生成，重试时不会重复退款
+func (s *paymentService) refundPayment(record *model.Payment) error {
+	provider, err := s.providers.Get(record.Method)
+	if err != nil {
+		return err
+	}
+
+	// 1. 调用渠道退款
+	amount := record.RefundableAmount()
+	reason := "订单已不再等待该笔支付，原路退回"
+	result, err := provider.Refund(&payment.RefundRequest{
+		OutTradeNo:  record.PaymentNo,
+		OutRefundNo: record.PaymentNo + autoRefundSuffix,
+		Amount:      amount,
+		Reason:      reason,
+	})
+	if err != nil {
+		return fmt.Errorf("渠道退款失败: %w", err)
+	}
+
+	// 2. 登记退款，全额退回后清除待退款标记；已由并发的重试登记过时不再重复通知
+	recorded, err := s.recordRefund(record, result.RefundNo, amount, reason)
+	if err != nil || !recorded {
+		return err
+	}
+
+	// 3. 通知用户，通知失败只记录日志
+	err = s.notifications.Send(&model.Notification{
+		UserID:  record.UserID,
+		Type:    model.NotificationTypePayment,
+		Title:   "支付已退回",
+		Content: fmt.Sprintf("订单%s已取消或已通过其他支付完成，支付单%s的%.2f元已原路退回", record.OrderNo, record.PaymentNo, amount),
+		RefID:   record.OrderID,
+	})
+	if err != nil {
+		log.Printf("payment refund: notify refund of payment %s failed: %v", record.PaymentNo, err)
+	}
+	return nil
+}
+
+// RetryPendingRefunds 重试一批待自动退款的支付
+// 单笔退款失败只记录日志，留待下次重试
+func (s *paymentService) RetryPendingRefunds(limit int) (refunded, failed int, err error) {
+	records, err := s.paymentRepo.ListRefundPending(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, record := range records {
+		if err := s.refundPayment(record); err != nil {
+			log.Printf("payment refund: refund payment %s failed: %v", record.PaymentNo, err)
+			failed++
+			continue
+		}
+		refunded++
+	}
+	return refunded, failed, nil
+}
+
+// notifyFailure 通知用户支付失败，通知失败只记录日志
+func (s *paymentService) notifyFailure(record *model.Payment) {
+	err := s.notifications.Send(&model.Notification{
+		UserID:  record.UserID,
+		Type:    model.NotificationTypePayment,
+		Title:   "支付失败",
+		Content: fmt.Sprintf("订单%s支付未成功，请在支付时限内重新支付", record.OrderNo),
+		RefID:   record.OrderID,
+	})
+	if err != nil {
+		log.Printf("payment notify: notify failure of payment %s failed: %v", record.PaymentNo, err)
+	}
+}
+
+// SimulatePay 沙箱模拟付款
+// 仅对实现了payment.Simulator的渠道可用
+func (s *paymentService) SimulatePay(userID uint, paymentMethod, paymentNo string, success bool) error {
+	provider, err := s.providers.Get(paymentMethod)
+	if err != nil {
+		return errors.New("不支持的支付方式")
+	}
+
+	simulator, ok := provider.(payment.Simulator)
+	if !ok {
+		return errors.New("该支付方式不支持模拟付款")
+	}
+
+	record, err := s.paymentRepo.GetByPaymentNo(paymentNo)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.UserID != userID {
+		return errors.New("支付单不存在")
+	}
+
+	return simulator.Simulate(paymentNo, success)
+}
+
+// GetOrderPayments 获取订单的支付记录
+func (s *paymentService) GetOrderPayments(userID, orderID uint) ([]*model.Payment, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.UserID != userID {
+		return nil, errors.New("订单不存在")
+	}
+
+	return s.paymentRepo.GetByOrderID(orderID)
+}
+
+// RefundOrder 订单退款
+// 通过支付渠道原路退回，并在支付记录上登记退款信息，支持多次部分退款
+// 渠道退款单号使用售后退款单号，重试同一笔退款时渠道按它去重
+func (s *paymentService) RefundOrder(orderID uint, refundNo string, amount float64, reason string) (*model.Payment, error) {
+	// 1. 查找可退款的支付记录
+	record, err := s.paymentRepo.GetRefundableByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors.New("订单没有可退款的支付记录")
+	}
+	if amount > record.RefundableAmount()+0.001 {
+		return nil, errors.New("退款金额超过剩余可退金额")
+	}
+
+	provider, err := s.providers.Get(record.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 调用渠道退款
+	result, err := provider.Refund(&payment.RefundRequest{
+		OutTradeNo:  record.PaymentNo,
+		OutRefundNo: refundNo,
+		Amount:      amount,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渠道退款失败: %w", err)
+	}
+
+	// 3. 登记退款
+	if _, err := s.recordRefund(record, result.RefundNo, amount, reason); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// recordRefund 登记渠道退款结果
+// 按退款前的状态和累计退款金额条件更新；没有更新成功时重新读取支付记录，
+// 同一笔渠道退款已由并发请求登记时返回false，否则返回错误，重试时渠道按退款单号去重
+func (s *paymentService) recordRefund(record *model.Payment, refundID string, amount float64, reason string) (bool, error) {
+	fromStatus, fromRefundAmount := record.Status, record.RefundAmount
+	if err := record.Refund(refundID, amount, reason); err != nil {
+		return false, err
+	}
+	updated, err := repository.RecordPaymentRefund(s.db, record, fromStatus, fromRefundAmount)
+	if err != nil || updated {
+		return updated, err
+	}
+
+	latest, err := s.paymentRepo.GetByPaymentNo(record.PaymentNo)
+	if err != nil {
+		return false, err
+	}
+	if latest != nil && latest.RefundID == refundID {
+		*record = *latest
+		return false, nil
+	}
+	return false, errors.New("支付记录已被并发修改，请重试")
+}
+
+// notifyURL 生成支付方式对应的异步通知地址
+func (s *paymentService) notifyURL(paymentMethod string) string {
+	return fmt.Sprintf("%s/api/v1/payments/notify/%s", s.notifyBaseURL, paymentMethod)
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"math"
+	"ryan-mall/internal/model"
+	"sort"
+)
+
+// minPayCents 订单最低应付金额（分），优惠不能把订单减到0元
+const minPayCents = 1
+
+// PricingStage 计价流水线中的一个环节
+// 每个环节在上一环节的应付金额基础上计算优惠，并把优惠分摊到计价行
+type PricingStage interface {
+	Apply(quote *model.PriceQuote) error
+}
+
+// RunPricing 对商品行依次执行计价环节，返回计价结果
+// 下单和订单预览都通过这里计价，保证两边金额一致
+func RunPricing(lines []*model.PriceLine, stages ...PricingStage) (*model.PriceQuote, error) {
+	quote := &model.PriceQuote{Lines: lines}
+	for _, line := range lines {
+		line.Subtotal = fromCents(toCents(line.Price) * int64(line.Quantity))
+		line.Discount = 0
+		line.PayAmount = line.Subtotal
+	}
+
+	for _, stage := range stages {
+		if err := stage.Apply(quote); err != nil {
+			return nil, err
+		}
+	}
+
+	var total, discount int64
+	for _, line := range lines {
+		total += toCents(line.Subtotal)
+		discount += toCents(line.Discount)
+	}
+	quote.TotalAmount = fromCents(total)
+	quote.DiscountAmount = fromCents(discount)
+	quote.PayAmount = fromCents(total - discount + toCents(quote.ShippingFee))
+	return quote, nil
+}
+
+// CheckoutPolicy 结算规则：运费和税率
+type CheckoutPolicy struct {
+	ShippingFee           float64  // 基础运费
+	FreeShippingThreshold float64  // 包邮门槛（优惠后商品金额），0表示不包邮
+	RemoteShippingFee     float64  // 偏远地区运费
+	RemoteProvinces       []string // 偏远地区省份，不参与包邮
+	TaxRate               float64  // 商品价格中包含的税率，如13表示13%
+}
+
+// Stages 结算规则对应的计价环节，需要放在优惠环节之后
+func (p CheckoutPolicy) Stages(address model.JSONAddress) []PricingStage {
+	return []PricingStage{
+		shippingStage{policy: p, province: address.Province},
+		taxStage{rate: p.TaxRate},
+	}
+}
+
+// shippingStage 运费计价环节
+type shippingStage struct {
+	policy   CheckoutPolicy
+	province string
+}
+
+// Apply 按收货省份和优惠后的商品金额计算运费
+func (s shippingStage) Apply(quote *model.PriceQuote) error {
+	for _, province := range s.policy.RemoteProvinces {
+		if province == s.province {
+			quote.ShippingFee = s.policy.RemoteShippingFee
+			return nil
+		}
+	}
+
+	if s.policy.FreeShippingThreshold > 0 && goodsPayCents(quote) >= toCents(s.policy.FreeShippingThreshold) {
+		quote.ShippingFee = 0
+		return nil
+	}
+	quote.ShippingFee = s.policy.ShippingFee
+	return nil
+}
+
+// taxStage 税额计价环节
+// 商品价格已包含税，这里只从优惠后的商品金额中拆出税额用于展示，不改变应付金额
+type taxStage struct {
+	rate float64
+}
+
+// Apply 计算价内税额
+func (s taxStage) Apply(quote *model.PriceQuote) error {
+	if s.rate <= 0 {
+		quote.TaxAmount = 0
+		return nil
+	}
+	cents := float64(goodsPayCents(quote))
+	quote.TaxAmount = fromCents(int64(math.Round(cents * s.rate / (100 + s.rate))))
+	return nil
+}
+
+// goodsPayCents 优惠后的商品应付金额（分）
+func goodsPayCents(quote *model.PriceQuote) int64 {
+	var cents int64
+	for _, line := range quote.Lines {
+		cents += toCents(line.PayAmount)
+	}
+	return cents
+}
+
+// couponStage 优惠券计价环节
+type couponStage struct {
+	coupon *model.UserCoupon
+}
+
+// Apply 计算优惠券减免金额并按适用商品的应付金额比例分摊
+func (s couponStage) Apply(quote *model.PriceQuote) error {
+	template := &s.coupon.Template
+
+	// 1. 找出适用的计价行
+	var eligible []int
+	var weights []int64
+	var eligibleCents int64
+	for i, line := range quote.Lines {
+		cents := toCents(line.PayAmount)
+		if cents > 0 && template.Applies(line.ProductID, line.CategoryID) {
+			eligible = append(eligible, i)
+			weights = append(weights, cents)
+			eligibleCents += cents
+		}
+	}
+
+	// 2. 计算减免金额，保留最低应付金额
+	amount, err := template.Discount(fromCents(eligibleCents))
+	if err != nil {
+		return err
+	}
+	discount := toCents(amount)
+	if limit := goodsPayCents(quote) - minPayCents; discount > limit {
+		discount = limit
+	}
+	if discount <= 0 {
+		return nil
+	}
+
+	// 3. 分摊到计价行
+	for k, share := range allocateCents(discount, weights) {
+		if share == 0 {
+			continue
+		}
+		line := quote.Lines[eligible[k]]
+		line.Discount = fromCents(toCents(line.Discount) + share)
+		line.PayAmount = fromCents(toCents(line.PayAmount) - share)
+		quote.Allocations = append(quote.Allocations, model.DiscountAllocation{
+			Line:         eligible[k],
+			ProductID:    line.ProductID,
+			UserCouponID: s.coupon.ID,
+			Amount:       fromCents(share),
+		})
+	}
+	quote.UserCouponID = s.coupon.ID
+	return nil
+}
+
+// allocateCents 按权重分摊金额（最大余额法）
+// 分摊结果之和等于total，且total不超过权重之和时每份都不超过对应权重
+func allocateCents(total int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum <= 0 || total <= 0 {
+		return shares
+	}
+
+	remainders := make([]int64, len(weights))
+	order := make([]int, len(weights))
+	allocated := int64(0)
+	for i, w := range weights {
+		shares[i] = total * w / sum
+		remainders[i] = total * w % sum
+		allocated += shares[i]
+		order[i] = i
+	}
+
+	// 剩余的分按余数从大到小逐个分配
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for k := int64(0); k < total-allocated; k++ {
+		shares[order[k]]++
+	}
+	return shares
+}
+
+// toCents 元转换为分
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// fromCents 分转换为元
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
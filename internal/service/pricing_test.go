@@ -0,0 +1,139 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"testing"
+	"time"
+)
+
+func TestAllocateCents(t *testing.T) {
+	tests := []struct {
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{100, []int64{100, 100}, []int64{50, 50}},
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{200, []int64{100, 100, 1}, []int64{100, 99, 1}},
+		{201, []int64{100, 100, 1}, []int64{100, 100, 1}},
+		{0, []int64{10}, []int64{0}},
+	}
+
+	for _, tt := range tests {
+		got := allocateCents(tt.total, tt.weights)
+		var sum int64
+		for i := range got {
+			sum += got[i]
+			if got[i] != tt.want[i] {
+				t.Errorf("allocateCents(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+				break
+			}
+		}
+		if sum != tt.total {
+			t.Errorf("allocateCents(%d, %v) sums to %d", tt.total, tt.weights, sum)
+		}
+	}
+}
+
+func TestRunPricingWithCoupon(t *testing.T) {
+	coupon := &model.UserCoupon{
+		ID: 7,
+		Template: model.CouponTemplate{
+			Type:      model.CouponTypeThreshold,
+			Amount:    20,
+			MinAmount: 100,
+			Scope:     model.CouponScopeCategory,
+			ScopeIDs:  model.JSONIDs{1},
+			EndAt:     time.Now().Add(time.Hour),
+		},
+	}
+
+	lines := []*model.PriceLine{
+		{ProductID: 1, CategoryID: 1, Price: 30, Quantity: 2}, // 60
+		{ProductID: 2, CategoryID: 1, Price: 45, Quantity: 1}, // 45
+		{ProductID: 3, CategoryID: 2, Price: 99.9, Quantity: 1},
+	}
+
+	quote, err := RunPricing(lines, couponStage{coupon: coupon})
+	if err != nil {
+		t.Fatalf("RunPricing() error = %v", err)
+	}
+
+	if quote.TotalAmount != 204.9 || quote.DiscountAmount != 20 || quote.PayAmount != 184.9 {
+		t.Errorf("quote = total %.2f discount %.2f pay %.2f, want 204.90 20.00 184.90",
+			quote.TotalAmount, quote.DiscountAmount, quote.PayAmount)
+	}
+	// 20元按 60:45 分摊
+	if lines[0].Discount != 11.43 || lines[1].Discount != 8.57 || lines[2].Discount != 0 {
+		t.Errorf("line discounts = %.2f %.2f %.2f, want 11.43 8.57 0",
+			lines[0].Discount, lines[1].Discount, lines[2].Discount)
+	}
+	if len(quote.Allocations) != 2 || quote.UserCouponID != 7 {
+		t.Errorf("allocations = %+v, coupon = %d", quote.Allocations, quote.UserCouponID)
+	}
+
+	// 适用商品金额未达到门槛
+	lines = []*model.PriceLine{{ProductID: 1, CategoryID: 1, Price: 50, Quantity: 1}}
+	if _, err := RunPricing(lines, couponStage{coupon: coupon}); err == nil {
+		t.Error("RunPricing() below threshold: want error")
+	}
+}
+
+func TestRunPricingKeepsMinimumPay(t *testing.T) {
+	coupon := &model.UserCoupon{
+		ID:       1,
+		Template: model.CouponTemplate{Type: model.CouponTypeFixed, Amount: 50, Scope: model.CouponScopeAll},
+	}
+	lines := []*model.PriceLine{{ProductID: 1, Price: 10, Quantity: 1}}
+
+	quote, err := RunPricing(lines, couponStage{coupon: coupon})
+	if err != nil {
+		t.Fatalf("RunPricing() error = %v", err)
+	}
+	if quote.PayAmount != 0.01 || quote.DiscountAmount != 9.99 {
+		t.Errorf("quote = discount %.2f pay %.2f, want 9.99 0.01", quote.DiscountAmount, quote.PayAmount)
+	}
+}
+
+func TestRunPricingShippingAndTax(t *testing.T) {
+	policy := CheckoutPolicy{
+		ShippingFee:           10,
+		FreeShippingThreshold: 99,
+		RemoteShippingFee:     20,
+		RemoteProvinces:       []string{"西藏自治区"},
+		TaxRate:               13,
+	}
+	newLines := func(price float64) []*model.PriceLine {
+		return []*model.PriceLine{{ProductID: 1, Price: price, Quantity: 1}}
+	}
+
+	tests := []struct {
+		name     string
+		price    float64
+		province string
+		shipping float64
+		pay      float64
+	}{
+		{"below threshold", 50, "广东省", 10, 60},
+		{"free shipping", 113, "广东省", 0, 113},
+		{"remote province", 113, "西藏自治区", 20, 133},
+	}
+
+	for _, tt := range tests {
+		stages := policy.Stages(model.JSONAddress{Province: tt.province})
+		quote, err := RunPricing(newLines(tt.price), stages...)
+		if err != nil {
+			t.Fatalf("%s: RunPricing() error = %v", tt.name, err)
+		}
+		if quote.ShippingFee != tt.shipping || quote.PayAmount != tt.pay {
+			t.Errorf("%s: shipping %.2f pay %.2f, want %.2f %.2f",
+				tt.name, quote.ShippingFee, quote.PayAmount, tt.shipping, tt.pay)
+		}
+	}
+
+	// 价内税：113元含13%的税，税额13元
+	quote, _ := RunPricing(newLines(113), policy.Stages(model.JSONAddress{})...)
+	if quote.TaxAmount != 13 {
+		t.Errorf("tax = %.2f, want 13.00", quote.TaxAmount)
+	}
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/search"
+	"strconv"
+	"time"
+)
+
+// reindexBatchSize 全量重建索引时每批导入的商品数量
+const reindexBatchSize = 500
+
+// syncTimeout 增量同步一批商品的超时时间
+const syncTimeout = 10 * time.Second
+
+// ProductSearchConfig 商品搜索索引配置
+type ProductSearchConfig struct {
+	Index          string // 索引别名，实际索引为 别名_时间戳，重建后切换别名
+	Analyzer       string // 建索引使用的分词器，如 cjk、ik_max_word
+	SearchAnalyzer string // 搜索时使用的分词器，如 cjk、ik_smart
+}
+
+// ProductSearchService 商品搜索业务逻辑层接口
+// Elasticsearch不可用或查询失败时降级为数据库模糊查询
+type ProductSearchService interface {
+	Search(ctx context.Context, req *model.ProductSearchRequest) (*model.ProductSearchResponse, error) // 搜索商品
+	ProductsChanged(ids ...uint)                                                                       // 异步同步商品到索引
+	Reindex(ctx context.Context) (int, error)                                                          // 全量重建索引，返回导入的商品数
+	EnsureIndex(ctx context.Context) error                                                             // 索引不存在时创建并全量导入
+}
+
+// productSearchService 商品搜索业务逻辑层实现
+type productSearchService struct {
+	client         *search.Client
+	productRepo    repository.ProductRepository
+	productService *CachedProductService
+	config         ProductSearchConfig
+}
+
+// NewProductSearchService 创建商品搜索业务逻辑层实例
+// client为nil表示未启用Elasticsearch，搜索直接使用数据库
+func NewProductSearchService(client *search.Client, productRepo repository.ProductRepository, productService *CachedProductService, config ProductSearchConfig) ProductSearchService {
+	if config.Analyzer == "" {
+		config.Analyzer = "cjk"
+	}
+	if config.SearchAnalyzer == "" {
+		config.SearchAnalyzer = config.Analyzer
+	}
+	return &productSearchService{
+		client:         client,
+		productRepo:    productRepo,
+		productService: productService,
+		config:         config,
+	}
+}
+
+// Search 搜索商品
+// 命中的商品按ID回表获取最新的价格和库存，索引中已下架或删除的商品被过滤掉
+func (s *productSearchService) Search(ctx context.Context, req *model.ProductSearchRequest) (*model.ProductSearchResponse, error) {
+	if !s.client.Available() {
+		return s.searchDatabase(req)
+	}
+
+	// 1. 查询Elasticsearch
+	result, err := s.client.Search(ctx, s.config.Index, buildSearchQuery(req))
+	if err != nil {
+		log.Printf("商品搜索失败，降级为数据库查询: %v", err)
+		return s.searchDatabase(req)
+	}
+
+	// 2. 按命中的ID回表
+	ids := make([]uint, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		if id, err := strconv.ParseUint(hit.ID, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	products, err := s.productRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	// 3. 按搜索结果的顺序组装
+	items := make([]*model.ProductSearchItem, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		id, _ := strconv.ParseUint(hit.ID, 10, 32)
+		product := byID[uint(id)]
+		if product == nil || product.Status != model.ProductStatusOnline {
+			continue
+		}
+		items = append(items, &model.ProductSearchItem{Product: product, Highlight: hit.Highlight})
+	}
+
+	return newSearchResponse(items, result.Hits.Total.Value, req, model.SearchEngineElasticsearch), nil
+}
+
+// searchDatabase 降级为数据库模糊查询
+func (s *productSearchService) searchDatabase(req *model.ProductSearchRequest) (*model.ProductSearchResponse, error) {
+	listReq := &model.ProductListRequest{
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		CategoryID: req.CategoryID,
+		Keyword:    req.Keyword,
+		MinPrice:   req.MinPrice,
+		MaxPrice:   req.MaxPrice,
+		SortBy:     model.SortByCreatedAt,
+		SortOrder:  model.SortOrderDesc,
+	}
+	switch req.Sort {
+	case model.SearchSortSales:
+		listReq.SortBy = model.SortBySalesCount
+	case model.SearchSortPriceAsc:
+		listReq.SortBy, listReq.SortOrder = model.SortByPrice, model.SortOrderAsc
+	case model.SearchSortPriceDesc:
+		listReq.SortBy = model.SortByPrice
+	}
+
+	products, total, err := s.productService.List(listReq)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*model.ProductSearchItem, 0, len(products))
+	for _, product := range products {
+		items = append(items, &model.ProductSearchItem{Product: product})
+	}
+
+	return newSearchResponse(items, total, req, model.SearchEngineDatabase), nil
+}
+
+// newSearchResponse 组装搜索响应
+func newSearchResponse(items []*model.ProductSearchItem, total int64, req *model.ProductSearchRequest, engine string) *model.ProductSearchResponse {
+	return &model.ProductSearchResponse{
+		Products:   items,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: int((total + int64(req.PageSize) - 1) / int64(req.PageSize)),
+		Engine:     engine,
+	}
+}
+
+// buildSearchQuery 构建搜索请求
+// 关键词同时匹配名称、品牌、规格值、分类和描述，允许少量拼写错误；按相关度排序时销量越高得分越高
+func buildSearchQuery(req *model.ProductSearchRequest) map[string]interface{} {
+	// 1. 筛选条件
+	var filters []interface{}
+	if req.CategoryID != nil {
+		filters = append(filters, map[string]interface{}{"term": map[string]interface{}{"category_id": *req.CategoryID}})
+	}
+	if req.MinPrice != nil || req.MaxPrice != nil {
+		priceRange := map[string]interface{}{}
+		if req.MinPrice != nil {
+			priceRange["gte"] = *req.MinPrice
+		}
+		if req.MaxPrice != nil {
+			priceRange["lte"] = *req.MaxPrice
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"price": priceRange}})
+	}
+
+	// 2. 关键词匹配
+	boolQuery := map[string]interface{}{"filter": filters}
+	if req.Keyword != "" {
+		boolQuery["must"] = map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":                req.Keyword,
+				"fields":               []string{"name^3", "name.standard^2", "brand^2", "spec_values^2", "category_name", "description"},
+				"fuzziness":            "AUTO",
+				"prefix_length":        1,
+				"minimum_should_match": "75%",
+			},
+		}
+	}
+	query := map[string]interface{}{"bool": boolQuery}
+
+	// 3. 排序
+	var sort []interface{}
+	switch req.Sort {
+	case model.SearchSortSales:
+		sort = []interface{}{map[string]string{"sales_count": "desc"}, "_score"}
+	case model.SearchSortPriceAsc:
+		sort = []interface{}{map[string]string{"price": "asc"}, "_score"}
+	case model.SearchSortPriceDesc:
+		sort = []interface{}{map[string]string{"price": "desc"}, "_score"}
+	case model.SearchSortNewest:
+		sort = []interface{}{map[string]string{"created_at": "desc"}, "_score"}
+	default:
+		if req.Keyword == "" {
+			sort = []interface{}{map[string]string{"sales_count": "desc"}, map[string]string{"created_at": "desc"}}
+			break
+		}
+		// 相关度乘以 log10(销量+2)，销量为0的商品不会被压到0分
+		query = map[string]interface{}{
+			"function_score": map[string]interface{}{
+				"query": query,
+				"field_value_factor": map[string]interface{}{
+					"field":    "sales_count",
+					"modifier": "log2p",
+					"missing":  0,
+				},
+				"boost_mode": "multiply",
+			},
+		}
+		sort = []interface{}{"_score", map[string]string{"sales_count": "desc"}}
+	}
+
+	body := map[string]interface{}{
+		"query":            query,
+		"sort":             sort,
+		"from":             (req.Page - 1) * req.PageSize,
+		"size":             req.PageSize,
+		"track_total_hits": true,
+		"_source":          false,
+	}
+	if req.Keyword != "" {
+		body["highlight"] = map[string]interface{}{
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields": map[string]interface{}{
+				"name":        map[string]interface{}{"number_of_fragments": 0},
+				"description": map[string]interface{}{"fragment_size": 100, "number_of_fragments": 2},
+			},
+		}
+	}
+	return body
+}
+
+// indexDefinition 商品索引的settings和mappings
+// 中文字段使用配置的分词器（默认内置的cjk，安装IK插件后可使用ik_max_word/ik_smart），
+// 名称另有standard分词的子字段，按单字匹配兜底
+func (s *productSearchService) indexDefinition() map[string]interface{} {
+	text := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":            "text",
+			"analyzer":        s.config.Analyzer,
+			"search_analyzer": s.config.SearchAnalyzer,
+		}
+	}
+
+	name := text()
+	name["fields"] = map[string]interface{}{
+		"standard": map[string]interface{}{"type": "text", "analyzer": "standard"},
+		"keyword":  map[string]interface{}{"type": "keyword", "ignore_above": 256},
+	}
+	categoryName := text()
+	categoryName["fields"] = map[string]interface{}{
+		"keyword": map[string]interface{}{"type": "keyword"},
+	}
+
+	return map[string]interface{}{
+		"settings": map[string]interface{}{
+			"number_of_shards":   1,
+			"number_of_replicas": 1,
+		},
+		"mappings": map[string]interface{}{
+			"dynamic": "strict",
+			"properties": map[string]interface{}{
+				"id":              map[string]interface{}{"type": "long"},
+				"name":            name,
+				"brand":           map[string]interface{}{"type": "keyword"},
+				"description":     text(),
+				"category_id":     map[string]interface{}{"type": "long"},
+				"category_name":   categoryName,
+				"spec_values":     text(),
+				"price":           map[string]interface{}{"type": "scaled_float", "scaling_factor": 100},
+				"sales_count":     map[string]interface{}{"type": "integer"},
+				"available_stock": map[string]interface{}{"type": "integer"},
+				"main_image":      map[string]interface{}{"type": "keyword", "index": false},
+				"created_at":      map[string]interface{}{"type": "date"},
+			},
+		},
+	}
+}
+
+// ProductsChanged 异步同步商品到索引
+// 上架的商品写入索引，已下架或删除的商品从索引中删除；同步失败只记录日志，由全量重建修正
+func (s *productSearchService) ProductsChanged(ids ...uint) {
+	if len(ids) == 0 || !s.client.Available() {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+		defer cancel()
+		if err := s.syncProducts(ctx, ids); err != nil {
+			log.Printf("同步商品搜索索引失败 %v: %v", ids, err)
+		}
+	}()
+}
+
+// syncProducts 同步指定商品到索引
+func (s *productSearchService) syncProducts(ctx context.Context, ids []uint) error {
+	products, err := s.productRepo.GetByIDs(ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]*model.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	actions := make([]search.BulkAction, 0, len(ids))
+	for _, id := range ids {
+		action := search.BulkAction{ID: strconv.FormatUint(uint64(id), 10)}
+		if product := byID[id]; product != nil && product.Status == model.ProductStatusOnline {
+			action.Document = model.NewProductDocument(product)
+		} else {
+			action.Delete = true
+		}
+		actions = append(actions, action)
+	}
+	return s.client.Bulk(ctx, s.config.Index, actions)
+}
+
+// Reindex 全量重建索引，返回导入的商品数
+// 先导入到新索引，完成后把别名原子地切换过去再删除旧索引，重建期间搜索不受影响。
+// 重建期间的增量同步写入旧索引，可能在新索引中丢失，在低峰期执行或之后再执行一次
+func (s *productSearchService) Reindex(ctx context.Context) (int, error) {
+	if s.client == nil {
+		return 0, fmt.Errorf("未启用Elasticsearch")
+	}
+
+	// 1. 创建新索引
+	index := fmt.Sprintf("%s_%s", s.config.Index, time.Now().Format("20060102150405"))
+	if err := s.client.CreateIndex(ctx, index, s.indexDefinition()); err != nil {
+		return 0, err
+	}
+
+	// 2. 分批导入上架的商品
+	count, err := s.importProducts(ctx, index)
+	if err == nil {
+		err = s.client.Refresh(ctx, index)
+	}
+	if err != nil {
+		s.client.DeleteIndex(ctx, index)
+		return 0, err
+	}
+
+	// 3. 切换别名并删除旧索引
+	oldIndices, err := s.client.AliasIndices(ctx, s.config.Index)
+	if err != nil {
+		s.client.DeleteIndex(ctx, index)
+		return 0, err
+	}
+	if len(oldIndices) == 0 {
+		// 与别名同名的索引（如被自动创建的）会导致别名创建失败，先删除
+		if err := s.client.DeleteIndex(ctx, s.config.Index); err != nil {
+			s.client.DeleteIndex(ctx, index)
+			return 0, err
+		}
+	}
+	if err := s.client.SwitchAlias(ctx, s.config.Index, index, oldIndices); err != nil {
+		s.client.DeleteIndex(ctx, index)
+		return 0, err
+	}
+	if err := s.client.DeleteIndex(ctx, oldIndices...); err != nil {
+		log.Printf("删除旧商品索引失败 %v: %v", oldIndices, err)
+	}
+
+	return count, nil
+}
+
+// importProducts 按ID顺序分批导入上架的商品
+func (s *productSearchService) importProducts(ctx context.Context, index string) (int, error) {
+	var count int
+	var afterID uint
+	for {
+		products, err := s.productRepo.ListAfterID(afterID, reindexBatchSize)
+		if err != nil {
+			return count, err
+		}
+		if len(products) == 0 {
+			return count, nil
+		}
+
+		actions := make([]search.BulkAction, 0, len(products))
+		for _, product := range products {
+			actions = append(actions, search.BulkAction{
+				ID:       strconv.FormatUint(uint64(product.ID), 10),
+				Document: model.NewProductDocument(product),
+			})
+		}
+		if err := s.client.Bulk(ctx, index, actions); err != nil {
+			return count, err
+		}
+		count += len(products)
+		afterID = products[len(products)-1].ID
+	}
+}
+
+// EnsureIndex 索引不存在时创建并全量导入
+func (s *productSearchService) EnsureIndex(ctx context.Context) error {
+	if s.client == nil {
+		return nil
+	}
+	exists, err := s.client.IndexExists(ctx, s.config.Index)
+	if err != nil || exists {
+		return err
+	}
+
+	count, err := s.Reindex(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ 商品搜索索引已创建，导入 %d 个商品", count)
+	return nil
+}
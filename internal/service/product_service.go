@@ -5,6 +5,7 @@ import (
 	"math"
 	"ryan-mall/internal/model"
 	"ryan-mall/internal/repository"
+	"strings"
 )
 
 // ProductService 商品业务逻辑层接口
@@ -48,6 +49,7 @@ func (s *productService) CreateProduct(req *model.ProductCreateRequest) (*model.
 	// 2. 创建商品对象
 	product := &model.Product{
 		Name:          req.Name,
+		Brand:         strings.TrimSpace(req.Brand),
 		CategoryID:    req.CategoryID,
 		Price:         req.Price,
 		Stock:         req.Stock,
@@ -117,18 +119,21 @@ func (s *productService) UpdateProduct(id uint, req *model.ProductUpdateRequest)
 	if req.Name != nil {
 		product.Name = *req.Name
 	}
+	if req.Brand != nil {
+		product.Brand = strings.TrimSpace(*req.Brand)
+	}
 	if req.Description != nil {
 		product.Description = req.Description
 	}
 	if req.Price != nil {
+		if product.HasSkus() {
+			return errSkuPriceManaged
+		}
 		product.Price = *req.Price
 	}
 	if req.OriginalPrice != nil {
 		product.OriginalPrice = req.OriginalPrice
 	}
-	if req.Stock != nil {
-		product.Stock = *req.Stock
-	}
 	if req.MainImage != nil {
 		product.MainImage = req.MainImage
 	}
@@ -138,6 +143,13 @@ func (s *productService) UpdateProduct(id uint, req *model.ProductUpdateRequest)
 	if req.Status != nil {
 		product.Status = *req.Status
 	}
+	if req.Stock != nil {
+		// 库存单独按条件更新，不能少于已预占的数量
+		if err := s.productRepo.UpdateStock(id, *req.Stock); err != nil {
+			return err
+		}
+		product.Stock = *req.Stock
+	}
 	
 	// 4. 保存更新
 	return s.productRepo.Update(product)
@@ -183,16 +195,23 @@ func (s *productService) GetProductList(req *model.ProductListRequest) (*model.P
 		return nil, err
 	}
 	
-	// 3. 计算总页数
+	// 3. 统计分面
+	facets, err := s.productRepo.Facets(req)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 4. 计算总页数
 	totalPages := int(math.Ceil(float64(total) / float64(req.PageSize)))
 	
-	// 4. 构建响应
+	// 5. 构建响应
 	response := &model.ProductListResponse{
 		Products:   products,
 		Total:      total,
 		Page:       req.Page,
 		PageSize:   req.PageSize,
 		TotalPages: totalPages,
+		Facets:     facets,
 	}
 	
 	return response, nil
@@ -250,7 +269,7 @@ func (s *productService) DecrementStock(id uint, quantity int) error {
 	}
 	
 	// 3. 检查库存是否充足
-	if product.Stock < quantity {
+	if product.AvailableStock() < quantity {
 		return errors.New("库存不足")
 	}
 	
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/cache"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHotProductsLimit = 10          // 热门商品默认返回数量
+	hotProductsCacheTTL     = time.Minute // 热门商品结果的本地缓存时间
+)
+
+// ProductRankingStore 商品热度排行存储
+type ProductRankingStore interface {
+	Record(productID, categoryID uint, score float64) error                   // 记录商品热度，同时计入全站和分类排行
+	Top(window string, categoryID uint, limit int) ([]uint, []float64, error) // 获取排行榜前limit个商品ID和热度
+}
+
+// RankingService 商品热度排行业务逻辑层接口
+type RankingService interface {
+	RecordView(productID, categoryID uint)                                         // 记录一次商品浏览
+	RecordAddToCart(productID, categoryID uint)                                    // 记录一次加入购物车
+	HotProducts(req *model.HotProductsRequest) (*model.HotProductsResponse, error) // 获取热门商品排行
+	SnapshotRankings(limit int) (int, error)                                       // 将各排行榜前limit名写回数据库，返回写入的记录数
+}
+
+// rankingService 商品热度排行业务逻辑层实现
+// 浏览、加入购物车、支付订单按权重计入Redis中的分时热度，查询时按时间衰减合并；
+// 排行不足时（冷启动或Redis不可用）按销量补足
+type rankingService struct {
+	store        ProductRankingStore
+	rankingRepo  repository.RankingRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	cache        cache.CacheManager
+}
+
+// NewRankingService 创建商品热度排行业务逻辑层实例
+// store为nil时（Redis不可用）不记录热度，热门商品按销量排序
+func NewRankingService(store ProductRankingStore, rankingRepo repository.RankingRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, states *OrderStateMachine) RankingService {
+	s := &rankingService{
+		store:        store,
+		rankingRepo:  rankingRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		cache:        cache.GetCache(),
+	}
+
+	// 订单支付后按购买件数计入热度
+	states.AddHook(model.OrderStatusPaid, s.recordPurchases)
+
+	return s
+}
+
+// RecordView 记录一次商品浏览
+func (s *rankingService) RecordView(productID, categoryID uint) {
+	s.record(productID, categoryID, model.RankingWeightView)
+}
+
+// RecordAddToCart 记录一次加入购物车
+func (s *rankingService) RecordAddToCart(productID, categoryID uint) {
+	s.record(productID, categoryID, model.RankingWeightCart)
+}
+
+// recordPurchases 订单支付后按购买件数计入热度
+// 在支付事务中读取订单商品，写Redis放到后台，不延长事务也不影响支付结果；
+// 拒绝退款后恢复为已支付的订单之前已经计入过，不再重复计入
+func (s *rankingService) recordPurchases(tx *gorm.DB, t *OrderTransition) error {
+	if s.store == nil || t.From == model.OrderStatusRefundRequested {
+		return nil
+	}
+
+	products, err := repository.ListOrderedProducts(tx, t.OrderID)
+	if err != nil {
+		log.Printf("order %d: load products for ranking failed: %v", t.OrderID, err)
+		return nil
+	}
+	go func() {
+		for _, p := range products {
+			s.record(p.ProductID, p.CategoryID, model.RankingWeightPurchase*float64(p.Quantity))
+		}
+	}()
+	return nil
+}
+
+// record 记录商品热度，失败时只记录日志
+func (s *rankingService) record(productID, categoryID uint, score float64) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Record(productID, categoryID, score); err != nil {
+		log.Printf("记录商品%d热度失败: %v", productID, err)
+	}
+}
+
+// HotProducts 获取热门商品排行
+// 按热度取上架的商品，不足时按销量补足；结果在本地缓存一分钟
+func (s *rankingService) HotProducts(req *model.HotProductsRequest) (*model.HotProductsResponse, error) {
+	window := req.Window
+	if window == "" {
+		window = model.RankingWindowDaily
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultHotProductsLimit
+	}
+
+	// 1. 尝试从缓存获取
+	cacheKey := fmt.Sprintf("hot_ranking:%s:%d:%d", window, req.CategoryID, limit)
+	var cached model.HotProductsResponse
+	if err := s.cache.GetJSON(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	resp := &model.HotProductsResponse{
+		Window:     window,
+		CategoryID: req.CategoryID,
+		Products:   []*model.RankedProduct{},
+	}
+	seen := make(map[uint]bool)
+
+	// 2. 按热度排行，多取一些以跳过已下架的商品
+	if s.store != nil {
+		ids, scores, err := s.store.Top(window, req.CategoryID, limit*2)
+		if err != nil {
+			log.Printf("获取热度排行失败，按销量排序: %v", err)
+		} else if len(ids) > 0 {
+			products, err := s.productRepo.GetByIDs(ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[uint]*model.Product, len(products))
+			for _, p := range products {
+				byID[p.ID] = p
+			}
+			for i, id := range ids {
+				p, ok := byID[id]
+				if !ok || p.Status != model.ProductStatusOnline || len(resp.Products) >= limit {
+					continue
+				}
+				seen[id] = true
+				resp.Products = append(resp.Products, &model.RankedProduct{Product: p, Rank: len(resp.Products) + 1, Score: scores[i]})
+			}
+		}
+	}
+
+	// 3. 不足时按销量补足
+	if len(resp.Products) < limit {
+		listReq := &model.ProductListRequest{
+			Page:      1,
+			PageSize:  limit + len(seen),
+			SortBy:    "sales_count",
+			SortOrder: "desc",
+		}
+		if req.CategoryID != 0 {
+			listReq.CategoryID = &req.CategoryID
+		}
+		products, _, err := s.productRepo.List(listReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range products {
+			if len(resp.Products) >= limit {
+				break
+			}
+			if seen[p.ID] {
+				continue
+			}
+			resp.Products = append(resp.Products, &model.RankedProduct{Product: p, Rank: len(resp.Products) + 1})
+		}
+	}
+
+	// 4. 存入缓存
+	s.cache.SetJSON(cacheKey, resp, hotProductsCacheTTL)
+
+	return resp, nil
+}
+
+// SnapshotRankings 将全站和各分类每个时间窗口的排行前limit名写回数据库
+// 快照时间按小时取整，同一小时内重复执行时覆盖
+func (s *rankingService) SnapshotRankings(limit int) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	// 1. 全站排行和各分类排行
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	categoryIDs := []uint{0}
+	for _, category := range categories {
+		categoryIDs = append(categoryIDs, category.ID)
+	}
+
+	// 2. 读取排行
+	snapshotAt := time.Now().Truncate(time.Hour)
+	var snapshots []*model.ProductRankingSnapshot
+	for _, window := range model.RankingWindows {
+		for _, categoryID := range categoryIDs {
+			ids, scores, err := s.store.Top(window, categoryID, limit)
+			if err != nil {
+				return 0, err
+			}
+			for i, id := range ids {
+				snapshots = append(snapshots, &model.ProductRankingSnapshot{
+					SnapshotAt: snapshotAt,
+					Window:     window,
+					CategoryID: categoryID,
+					Rank:       i + 1,
+					ProductID:  id,
+					Score:      scores[i],
+				})
+			}
+		}
+	}
+
+	// 3. 保存快照
+	if err := s.rankingRepo.SaveSnapshots(snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
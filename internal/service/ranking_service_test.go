@@ -0,0 +1,35 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"testing"
+)
+
+// countingRankingStore 记录调用次数的商品热度存储
+type countingRankingStore struct {
+	ProductRankingStore
+	records int
+}
+
+func (s *countingRankingStore) Record(productID, categoryID uint, score float64) error {
+	s.records++
+	return nil
+}
+
+func TestRecordPurchases_SkipsRejectedRefund(t *testing.T) {
+	store := &countingRankingStore{}
+	s := &rankingService{store: store}
+
+	// 拒绝退款恢复为已支付时不读取订单商品（tx为nil），也不计入热度
+	err := s.recordPurchases(nil, &OrderTransition{
+		OrderID: 1,
+		From:    model.OrderStatusRefundRequested,
+		To:      model.OrderStatusPaid,
+	})
+	if err != nil {
+		t.Fatalf("recordPurchases: %v", err)
+	}
+	if store.records != 0 {
+		t.Fatalf("recorded %d purchases for a rejected refund, want 0", store.records)
+	}
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/idgen"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefundService 售后退款业务逻辑层接口
+type RefundService interface {
+	CreateRefund(userID, orderID uint, req *model.CreateRefundRequest) (*model.Refund, error)       // 申请退款
+	GetOrderRefunds(userID, orderID uint) ([]*model.Refund, error)                                  // 获取订单的退款申请
+	ListRefunds(req *model.RefundListRequest) (*model.RefundListResponse, error)                    // 分页查询退款申请（管理员）
+	ApproveRefund(reviewerID, refundID uint, req *model.ReviewRefundRequest) (*model.Refund, error) // 审核通过并退款（管理员）
+	RejectRefund(reviewerID, refundID uint, req *model.ReviewRefundRequest) error                   // 拒绝退款（管理员）
+	CompleteRefunds(limit int) (completed, failed int, err error)                                   // 完成渠道已退款但本地未更新的退款申请
+}
+
+// refundNoPrefix 退款单号前缀
+const refundNoPrefix = "R"
+
+// refundService 售后退款业务逻辑层实现
+type refundService struct {
+	refundRepo repository.RefundRepository
+	orderRepo  repository.OrderRepository
+	paymentSvc PaymentService
+	states     *OrderStateMachine
+	ids        *idgen.Generator
+	db         *gorm.DB
+}
+
+// NewRefundService 创建售后退款业务逻辑层实例
+func NewRefundService(refundRepo repository.RefundRepository, orderRepo repository.OrderRepository, paymentSvc PaymentService, states *OrderStateMachine, ids *idgen.Generator, db *gorm.DB) RefundService {
+	return &refundService{
+		refundRepo: refundRepo,
+		orderRepo:  orderRepo,
+		paymentSvc: paymentSvc,
+		states:     states,
+		ids:        ids,
+		db:         db,
+	}
+}
+
+// CreateRefund 申请退款
+// 未指定商品时退还订单全部剩余商品；退还订单最后的商品时一并退还运费
+func (s *refundService) CreateRefund(userID, orderID uint, req *model.CreateRefundRequest) (*model.Refund, error) {
+	// 1. 获取订单并验证所有权
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.UserID != userID {
+		return nil, errors.New("订单不存在")
+	}
+
+	// 2. 检查订单状态
+	if !s.states.Can(order.Status, model.OrderStatusRefundRequested) {
+		return nil, errors.New("当前订单状态不能申请退款")
+	}
+	open, err := s.refundRepo.HasOpen(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, errors.New("订单已有处理中的退款申请")
+	}
+
+	// 3. 计算退款商品和金额
+	items, err := s.buildRefundItems(order, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	shippingFee := order.RefundShippingFee(items)
+	amount := shippingFee
+	for _, item := range items {
+		amount += item.Amount
+	}
+
+	refundNo, err := s.ids.Next(refundNoPrefix)
+	if err != nil {
+		return nil, err
+	}
+	refund := &model.Refund{
+		RefundNo:    refundNo,
+		OrderID:     order.ID,
+		UserID:      userID,
+		Amount:      amount,
+		ShippingFee: shippingFee,
+		Reason:      req.Reason,
+		Status:      model.RefundStatusPending,
+		OrderStatus: order.Status,
+		Items:       items,
+	}
+
+	// 4. 保存申请并将订单置为退款申请中
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		return s.states.Transit(tx, &OrderTransition{
+			OrderID: order.ID,
+			From:    order.Status,
+			To:      model.OrderStatusRefundRequested,
+			Actor:   model.OrderActorUser(userID),
+			Reason:  "申请退款: " + req.Reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// GetOrderRefunds 获取订单的退款申请
+func (s *refundService) GetOrderRefunds(userID, orderID uint) ([]*model.Refund, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.UserID != userID {
+		return nil, errors.New("订单不存在")
+	}
+
+	return s.refundRepo.GetByOrderID(orderID)
+}
+
+// ListRefunds 分页查询退款申请
+func (s *refundService) ListRefunds(req *model.RefundListRequest) (*model.RefundListResponse, error) {
+	refunds, total, err := s.refundRepo.List(req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &model.RefundListResponse{
+		Refunds:    refunds,
+		Total:      int(total),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ApproveRefund 审核通过并退款
+// 先确认订单仍在退款申请中并锁定申请为退款中，再通过支付渠道退款，最后更新订单商品、库存和订单状态；
+// 渠道退款成功但本地更新失败时，申请保持退款中并记录渠道退款单号，由定时任务重试本地更新
+func (s *refundService) ApproveRefund(reviewerID, refundID uint, req *model.ReviewRefundRequest) (*model.Refund, error) {
+	// 1. 获取退款申请
+	refund, err := s.refundRepo.GetByID(refundID)
+	if err != nil {
+		return nil, err
+	}
+	if refund == nil {
+		return nil, errors.New("退款申请不存在")
+	}
+
+	// 2. 检查订单状态，钱退出去之前确认订单仍在等待这笔退款
+	order, err := s.orderRepo.GetByID(refund.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.Status != model.OrderStatusRefundRequested {
+		return nil, errors.New("订单已不在退款申请中，不能退款")
+	}
+
+	// 3. 锁定申请并保存审核结果，防止重复审核导致重复退款
+	result := s.db.Model(&model.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, model.RefundStatusPending).
+		Updates(map[string]interface{}{
+			"status":        model.RefundStatusProcessing,
+			"restock":       req.Restock,
+			"reviewer_id":   reviewerID,
+			"review_remark": req.Remark,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("退款申请已被处理")
+	}
+	refund.Restock = req.Restock
+	refund.ReviewerID = &reviewerID
+
+	// 4. 通过支付渠道退款（Payment.Refund），渠道按退款单号去重
+	payment, err := s.paymentSvc.RefundOrder(refund.OrderID, refund.RefundNo, refund.Amount, refund.Reason)
+	if err != nil {
+		// 退款失败，申请退回待审核，便于重试
+		s.transitRefund(refund.ID, model.RefundStatusProcessing, model.RefundStatusPending)
+		return nil, err
+	}
+
+	// 5. 记录渠道退款单号，之后的本地更新失败时由定时任务重试
+	refund.PaymentRefID = payment.RefundID
+	err = s.db.Model(&model.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, model.RefundStatusProcessing).
+		Update("payment_ref_id", refund.PaymentRefID).Error
+	if err != nil {
+		// 渠道已退款但没有记下渠道退款单号，需人工核对
+		log.Printf("refund %s: payment refunded as %s but saving the refund id failed: %v", refund.RefundNo, refund.PaymentRefID, err)
+		return nil, err
+	}
+
+	// 6. 更新申请、订单商品、库存和订单状态
+	if err := s.completeRefund(refund); err != nil {
+		log.Printf("refund %s: payment refunded but local update failed, will retry: %v", refund.RefundNo, err)
+		return nil, err
+	}
+
+	return s.refundRepo.GetByID(refund.ID)
+}
+
+// CompleteRefunds 完成一批渠道已退款但本地未更新的退款申请
+// 单笔失败只记录日志，留待下次重试
+func (s *refundService) CompleteRefunds(limit int) (completed, failed int, err error) {
+	refunds, err := s.refundRepo.ListRefundedProcessing(limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, refund := range refunds {
+		if err := s.completeRefund(refund); err != nil {
+			log.Printf("refund %s: complete refund failed: %v", refund.RefundNo, err)
+			failed++
+			continue
+		}
+		completed++
+	}
+	return completed, failed, nil
+}
+
+// completeRefund 渠道退款成功后更新申请、订单商品、库存和订单状态
+func (s *refundService) completeRefund(refund *model.Refund) error {
+	reviewerID := uint(0)
+	if refund.ReviewerID != nil {
+		reviewerID = *refund.ReviewerID
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, model.RefundStatusProcessing).
+			Updates(map[string]interface{}{
+				"status":         model.RefundStatusRefunded,
+				"payment_ref_id": refund.PaymentRefID,
+				"reviewed_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("退款申请已被处理")
+		}
+
+		for _, item := range refund.Items {
+			err := tx.Model(&model.OrderItem{}).
+				Where("id = ?", item.OrderItemID).
+				Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", item.Quantity)).Error
+			if err != nil {
+				return err
+			}
+
+			// 退回库存，按下单时的分配退回原仓库
+			if refund.Restock {
+				err = repository.RestockOrderItem(tx, refund.OrderID, item.ProductID, item.SkuID, item.Quantity)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// 全部商品都已退款则整单已退款，否则部分退款；
+		// 部分退款的订单剩余商品继续履约，发货后仍会自动确认收货，已收货的商品仍可评价
+		var remaining int64
+		err := tx.Model(&model.OrderItem{}).
+			Where("order_id = ? AND refunded_quantity < quantity", refund.OrderID).
+			Count(&remaining).Error
+		if err != nil {
+			return err
+		}
+		toStatus := model.OrderStatusPartiallyRefunded
+		if remaining == 0 {
+			toStatus = model.OrderStatusRefunded
+		}
+
+		return s.states.Transit(tx, &OrderTransition{
+			OrderID: refund.OrderID,
+			From:    model.OrderStatusRefundRequested,
+			To:      toStatus,
+			Actor:   model.OrderActorAdmin(reviewerID),
+			Reason:  fmt.Sprintf("退款 %.2f 元", refund.Amount),
+		})
+	})
+}
+
+// RejectRefund 拒绝退款
+// 订单恢复到申请前的状态
+func (s *refundService) RejectRefund(reviewerID, refundID uint, req *model.ReviewRefundRequest) error {
+	refund, err := s.refundRepo.GetByID(refundID)
+	if err != nil {
+		return err
+	}
+	if refund == nil {
+		return errors.New("退款申请不存在")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, model.RefundStatusPending).
+			Updates(map[string]interface{}{
+				"status":        model.RefundStatusRejected,
+				"reviewer_id":   reviewerID,
+				"review_remark": req.Remark,
+				"reviewed_at":   time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("退款申请已被处理")
+		}
+
+		return s.states.Transit(tx, &OrderTransition{
+			OrderID: refund.OrderID,
+			From:    model.OrderStatusRefundRequested,
+			To:      refund.OrderStatus,
+			Actor:   model.OrderActorAdmin(reviewerID),
+			Reason:  "拒绝退款: " + req.Remark,
+			Restore: true,
+		})
+	})
+}
+
+// buildRefundItems 根据申请明细计算退款商品
+// 单件退款金额按订单商品实付单价计算
+func (s *refundService) buildRefundItems(order *model.Order, requested []model.RefundItemRequest) ([]model.RefundItem, error) {
+	orderItems := make(map[uint]model.OrderItem, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		orderItems[item.ID] = item
+	}
+
+	var items []model.RefundItem
+
+	// 整单退款：退还全部剩余商品
+	if len(requested) == 0 {
+		for _, item := range order.OrderItems {
+			if remaining := item.RemainingQuantity(); remaining > 0 {
+				items = append(items, model.RefundItem{
+					OrderItemID: item.ID,
+					ProductID:   item.ProductID,
+					SkuID:       item.SkuID,
+					Quantity:    remaining,
+					Amount:      item.RefundAmount(remaining),
+				})
+			}
+		}
+		if len(items) == 0 {
+			return nil, errors.New("订单没有可退款的商品")
+		}
+		return items, nil
+	}
+
+	// 按商品退款
+	seen := make(map[uint]bool, len(requested))
+	for _, req := range requested {
+		item, ok := orderItems[req.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("订单商品 %d 不存在", req.OrderItemID)
+		}
+		if seen[req.OrderItemID] {
+			return nil, fmt.Errorf("订单商品 %d 重复申请", req.OrderItemID)
+		}
+		seen[req.OrderItemID] = true
+
+		if req.Quantity > item.RemainingQuantity() {
+			return nil, fmt.Errorf("商品 %s 可退数量不足", item.ProductName)
+		}
+
+		items = append(items, model.RefundItem{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			SkuID:       item.SkuID,
+			Quantity:    req.Quantity,
+			Amount:      item.RefundAmount(req.Quantity),
+		})
+	}
+
+	return items, nil
+}
+
+// transitRefund 按预期状态更新退款申请状态，返回是否更新成功
+func (s *refundService) transitRefund(refundID uint, from, to model.RefundStatus) (bool, error) {
+	result := s.db.Model(&model.Refund{}).
+		Where("id = ? AND status = ?", refundID, from).
+		Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
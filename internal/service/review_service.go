@@ -0,0 +1,234 @@
+package service
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"strings"
+)
+
+// ProductRatingSource 商品评分汇总来源，商品详情据此展示评分
+type ProductRatingSource interface {
+	RatingSummary(productID uint) (*model.ProductRatingSummary, error) // 获取商品的评分汇总
+}
+
+// ReviewService 商品评价业务逻辑层接口
+type ReviewService interface {
+	CreateReview(userID uint, req *model.CreateReviewRequest) (*model.ProductReview, error)                          // 发表评价
+	GetProductReviews(productID uint, req *model.ProductReviewListRequest) (*model.ProductReviewListResponse, error) // 获取商品的评价列表
+	GetUserReviews(userID uint, req *model.ReviewListRequest) (*model.ReviewListResponse, error)                     // 获取用户自己的评价
+	ListReviews(req *model.ReviewListRequest) (*model.ReviewListResponse, error)                                     // 获取评价列表（管理员）
+	ApproveReview(moderatorID, reviewID uint) error                                                                  // 审核通过
+	RejectReview(moderatorID, reviewID uint, req *model.ModerateReviewRequest) error                                 // 驳回评价
+	ReplyReview(reviewID uint, req *model.ReplyReviewRequest) error                                                  // 商家回复
+	VoteHelpful(userID, reviewID uint) error                                                                         // 投票评价有用
+	UnvoteHelpful(userID, reviewID uint) error                                                                       // 取消有用投票
+	RatingSummary(productID uint) (*model.ProductRatingSummary, error)                                               // 获取商品的评分汇总
+}
+
+// reviewService 商品评价业务逻辑层实现
+type reviewService struct {
+	reviewRepo     repository.ReviewRepository
+	userRepo       repository.UserRepository
+	productService *CachedProductService
+}
+
+// NewReviewService 创建商品评价业务逻辑层实例
+// 评价审核后清除商品缓存，商品详情中的评分汇总随之更新
+func NewReviewService(reviewRepo repository.ReviewRepository, userRepo repository.UserRepository, productService *CachedProductService) ReviewService {
+	return &reviewService{
+		reviewRepo:     reviewRepo,
+		userRepo:       userRepo,
+		productService: productService,
+	}
+}
+
+// CreateReview 发表评价
+// 只有确认收货后的订单商品才能评价，订单之后申请退款或部分退款不影响其他商品；
+// 已全部退款的商品不能评价；评价审核通过后公开展示
+func (s *reviewService) CreateReview(userID uint, req *model.CreateReviewRequest) (*model.ProductReview, error) {
+	// 1. 校验订单商品属于当前用户且订单已确认收货
+	item, err := s.reviewRepo.GetOrderItem(req.OrderItemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.Order.UserID != userID {
+		return nil, errors.New("订单商品不存在")
+	}
+	delivered := item.Order.Status == model.OrderStatusDelivered
+	if !delivered {
+		delivered, err = s.reviewRepo.IsOrderDelivered(item.OrderID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !delivered {
+		return nil, errors.New("订单确认收货后才能评价")
+	}
+	if item.RefundedQuantity >= item.Quantity {
+		return nil, errors.New("商品已退款，不能评价")
+	}
+
+	// 2. 评价中展示脱敏的用户名
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	userName := ""
+	if user != nil {
+		userName = user.Username
+	}
+
+	// 3. 创建评价，等待审核
+	review := &model.ProductReview{
+		ProductID:   item.ProductID,
+		SkuID:       item.SkuID,
+		SpecKey:     item.SpecKey,
+		OrderID:     item.OrderID,
+		OrderItemID: item.ID,
+		UserID:      userID,
+		UserName:    model.MaskUserName(userName),
+		Rating:      req.Rating,
+		Content:     strings.TrimSpace(req.Content),
+		Images:      model.JSONArray(req.Images),
+		Status:      model.ReviewStatusPending,
+	}
+	created, err := s.reviewRepo.Create(review)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errors.New("该商品已评价")
+	}
+
+	return review, nil
+}
+
+// GetProductReviews 获取商品审核通过的评价列表和评分汇总
+func (s *reviewService) GetProductReviews(productID uint, req *model.ProductReviewListRequest) (*model.ProductReviewListResponse, error) {
+	summary, err := s.RatingSummary(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, total, err := s.reviewRepo.ListByProduct(productID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &model.ProductReviewListResponse{
+		Summary:    summary,
+		Reviews:    reviews,
+		Total:      int(total),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetUserReviews 获取用户自己的评价，包含待审核和已驳回的
+func (s *reviewService) GetUserReviews(userID uint, req *model.ReviewListRequest) (*model.ReviewListResponse, error) {
+	req.ProductID = 0
+	return s.list(userID, req)
+}
+
+// ListReviews 获取评价列表（管理员）
+func (s *reviewService) ListReviews(req *model.ReviewListRequest) (*model.ReviewListResponse, error) {
+	return s.list(0, req)
+}
+
+// list 分页查询评价
+func (s *reviewService) list(userID uint, req *model.ReviewListRequest) (*model.ReviewListResponse, error) {
+	reviews, total, err := s.reviewRepo.List(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+
+	return &model.ReviewListResponse{
+		Reviews:    reviews,
+		Total:      int(total),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ApproveReview 审核通过，评价公开展示并计入评分
+func (s *reviewService) ApproveReview(moderatorID, reviewID uint) error {
+	return s.moderate(moderatorID, reviewID, model.ReviewStatusApproved, "")
+}
+
+// RejectReview 驳回评价，已公开的评价会被撤下
+func (s *reviewService) RejectReview(moderatorID, reviewID uint, req *model.ModerateReviewRequest) error {
+	return s.moderate(moderatorID, reviewID, model.ReviewStatusRejected, req.Reason)
+}
+
+// moderate 审核评价，状态变化后清除商品缓存以更新评分汇总
+func (s *reviewService) moderate(moderatorID, reviewID uint, status model.ReviewStatus, reason string) error {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return err
+	}
+	if review == nil {
+		return errors.New("评价不存在")
+	}
+
+	changed, err := s.reviewRepo.Moderate(reviewID, status, moderatorID, reason)
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.productService.InvalidateProducts(review.ProductID)
+	}
+	return nil
+}
+
+// ReplyReview 商家回复评价，再次回复时覆盖
+func (s *reviewService) ReplyReview(reviewID uint, req *model.ReplyReviewRequest) error {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return err
+	}
+	if review == nil {
+		return errors.New("评价不存在")
+	}
+
+	return s.reviewRepo.Reply(reviewID, strings.TrimSpace(req.Content))
+}
+
+// VoteHelpful 投票评价有用
+// 只能给审核通过的他人评价投票，重复投票不报错
+func (s *reviewService) VoteHelpful(userID, reviewID uint) error {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return err
+	}
+	if review == nil || review.Status != model.ReviewStatusApproved {
+		return errors.New("评价不存在")
+	}
+	if review.UserID == userID {
+		return errors.New("不能给自己的评价投票")
+	}
+
+	_, err = s.reviewRepo.AddHelpfulVote(reviewID, userID)
+	return err
+}
+
+// UnvoteHelpful 取消有用投票，未投过票时不报错
+func (s *reviewService) UnvoteHelpful(userID, reviewID uint) error {
+	_, err := s.reviewRepo.RemoveHelpfulVote(reviewID, userID)
+	return err
+}
+
+// RatingSummary 获取商品的评分汇总，只统计审核通过的评价
+func (s *reviewService) RatingSummary(productID uint) (*model.ProductRatingSummary, error) {
+	counts, err := s.reviewRepo.RatingCounts(productID)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewProductRatingSummary(counts), nil
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rolePermissionCacheTTL 角色权限和用户角色的缓存时间
+// 修改角色权限或用户角色后本实例立即生效，其他实例最多延迟这么久
+const rolePermissionCacheTTL = time.Minute
+
+// maxCachedUserRoles 最多缓存多少个用户的角色，超过时清空重新缓存
+const maxCachedUserRoles = 10000
+
+// RoleService 角色权限业务逻辑层接口
+type RoleService interface {
+	ListRoles() ([]*model.Role, error)                                          // 获取全部角色
+	ListPermissions() ([]*model.Permission, error)                              // 获取全部权限
+	CreateRole(req *model.CreateRoleRequest) (*model.Role, error)               // 创建角色
+	UpdateRole(id uint, req *model.UpdateRoleRequest) (*model.Role, error)      // 更新角色
+	DeleteRole(id uint) error                                                   // 删除角色
+	GetUserRoles(userID uint) ([]string, error)                                 // 获取用户的角色
+	SetUserRoles(userID uint, req *model.SetUserRolesRequest) ([]string, error) // 设置用户的角色
+	UserHasRole(userID uint, roles ...string) (bool, error)                     // 用户是否拥有任一指定角色
+	UserHasPermission(userID uint, permission string) (bool, error)             // 用户的角色中是否有任一拥有该权限
+	Bootstrap(adminUsernames []string) error                                    // 写入内置权限和角色，给指定用户分配管理员角色
+}
+
+// roleService 角色权限业务逻辑层实现
+type roleService struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+
+	mu          sync.RWMutex
+	permissions map[string]map[string]bool // 角色名称 -> 权限编码集合
+	loadedAt    time.Time
+	userRoles   map[uint]*cachedUserRoles // 用户ID -> 角色名称
+}
+
+// cachedUserRoles 缓存的用户角色
+type cachedUserRoles struct {
+	names    []string
+	loadedAt time.Time
+}
+
+// NewRoleService 创建角色权限业务逻辑层实例
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// ListRoles 获取全部角色及其权限
+func (s *roleService) ListRoles() ([]*model.Role, error) {
+	return s.roleRepo.List()
+}
+
+// ListPermissions 获取全部权限
+func (s *roleService) ListPermissions() ([]*model.Permission, error) {
+	return s.roleRepo.ListPermissions()
+}
+
+// CreateRole 创建角色
+func (s *roleService) CreateRole(req *model.CreateRoleRequest) (*model.Role, error) {
+	// 1. 检查名称
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("角色名称不能为空")
+	}
+	existing, err := s.roleRepo.GetByNames([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.New("角色名称已存在")
+	}
+
+	// 2. 检查权限
+	permissions, err := s.resolvePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 创建角色
+	role := &model.Role{
+		Name:        name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+// UpdateRole 更新角色，内置角色不能修改权限
+func (s *roleService) UpdateRole(id uint, req *model.UpdateRoleRequest) (*model.Role, error) {
+	// 1. 获取角色
+	role, err := s.roleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("角色不存在")
+	}
+
+	// 2. 检查权限
+	var permissions []model.Permission
+	if req.Permissions != nil {
+		if role.IsBuiltin() {
+			return nil, errors.New("内置角色拥有全部权限，不能修改")
+		}
+		permissions, err = s.resolvePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. 更新角色
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if err := s.roleRepo.Update(role, permissions); err != nil {
+		return nil, err
+	}
+	if req.Permissions != nil {
+		role.Permissions = permissions
+	}
+
+	s.invalidate()
+	return role, nil
+}
+
+// DeleteRole 删除角色，拥有该角色的用户同时失去该角色，内置角色不能删除
+func (s *roleService) DeleteRole(id uint) error {
+	role, err := s.roleRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return errors.New("角色不存在")
+	}
+	if role.IsBuiltin() {
+		return errors.New("内置角色不能删除")
+	}
+
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// GetUserRoles 获取用户的角色名称
+func (s *roleService) GetUserRoles(userID uint) ([]string, error) {
+	return s.roleRepo.ListUserRoleNames(userID)
+}
+
+// SetUserRoles 覆盖设置用户的角色，本实例立即生效
+func (s *roleService) SetUserRoles(userID uint, req *model.SetUserRolesRequest) ([]string, error) {
+	// 1. 检查用户
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 2. 检查角色
+	names := uniqueStrings(req.Roles)
+	roles, err := s.roleRepo.GetByNames(names)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) != len(names) {
+		found := make(map[string]bool, len(roles))
+		for _, role := range roles {
+			found[role.Name] = true
+		}
+		for _, name := range names {
+			if !found[name] {
+				return nil, fmt.Errorf("角色不存在: %s", name)
+			}
+		}
+	}
+
+	// 3. 保存
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+	if err := s.roleRepo.SetUserRoles(userID, roleIDs); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.userRoles, userID)
+	s.mu.Unlock()
+	return s.roleRepo.ListUserRoleNames(userID)
+}
+
+// UserHasRole 用户是否拥有任一指定角色，管理员角色视为拥有全部角色
+func (s *roleService) UserHasRole(userID uint, roles ...string) (bool, error) {
+	names, err := s.cachedUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+	return model.HasRole(names, model.RoleAdmin) || model.HasRole(names, roles...), nil
+}
+
+// UserHasPermission 用户的角色中是否有任一拥有该权限，管理员角色拥有全部权限
+func (s *roleService) UserHasPermission(userID uint, permission string) (bool, error) {
+	roles, err := s.cachedUserRoles(userID)
+	if err != nil {
+		return false, err
+	}
+	if model.HasRole(roles, model.RoleAdmin) {
+		return true, nil
+	}
+
+	permissions, err := s.rolePermissions()
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if permissions[role][permission] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Bootstrap 写入内置权限和管理员角色，给指定的用户分配管理员角色
+// 在服务启动时调用，可以重复执行
+func (s *roleService) Bootstrap(adminUsernames []string) error {
+	// 1. 写入内置权限
+	if err := s.roleRepo.EnsurePermissions(model.BuiltinPermissions); err != nil {
+		return err
+	}
+
+	// 2. 创建管理员角色并赋予全部权限
+	permissions, err := s.roleRepo.ListPermissions()
+	if err != nil {
+		return err
+	}
+	all := make([]model.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		all = append(all, *permission)
+	}
+
+	roles, err := s.roleRepo.GetByNames([]string{model.RoleAdmin})
+	if err != nil {
+		return err
+	}
+	var admin *model.Role
+	if len(roles) == 0 {
+		admin = &model.Role{Name: model.RoleAdmin, Description: "超级管理员，拥有全部权限", Permissions: all}
+		if err := s.roleRepo.Create(admin); err != nil {
+			return err
+		}
+	} else {
+		admin = roles[0]
+		if err := s.roleRepo.Update(admin, all); err != nil {
+			return err
+		}
+	}
+
+	// 3. 给指定用户分配管理员角色
+	for _, username := range adminUsernames {
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			log.Printf("⚠️  管理员用户%s不存在，跳过", username)
+			continue
+		}
+		if err := s.roleRepo.AddUserRole(user.ID, admin.ID); err != nil {
+			return err
+		}
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// resolvePermissions 按编码获取权限，有不存在的编码时返回错误
+func (s *roleService) resolvePermissions(codes []string) ([]model.Permission, error) {
+	codes = uniqueStrings(codes)
+	permissions, err := s.roleRepo.GetPermissionsByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) == len(codes) {
+		return permissions, nil
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		found[permission.Code] = true
+	}
+	for _, code := range codes {
+		if !found[code] {
+			return nil, fmt.Errorf("权限不存在: %s", code)
+		}
+	}
+	return permissions, nil
+}
+
+// rolePermissions 获取各角色的权限，缓存过期时从数据库重新加载
+func (s *roleService) rolePermissions() (map[string]map[string]bool, error) {
+	s.mu.RLock()
+	if s.permissions != nil && time.Since(s.loadedAt) < rolePermissionCacheTTL {
+		permissions := s.permissions
+		s.mu.RUnlock()
+		return permissions, nil
+	}
+	s.mu.RUnlock()
+
+	rows, err := s.roleRepo.ListRolePermissions()
+	if err != nil {
+		return nil, err
+	}
+	permissions := make(map[string]map[string]bool, len(rows))
+	for role, codes := range rows {
+		set := make(map[string]bool, len(codes))
+		for _, code := range codes {
+			set[code] = true
+		}
+		permissions[role] = set
+	}
+
+	s.mu.Lock()
+	s.permissions = permissions
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return permissions, nil
+}
+
+// cachedUserRoles 获取用户的角色名称，缓存过期时从数据库重新加载
+func (s *roleService) cachedUserRoles(userID uint) ([]string, error) {
+	s.mu.RLock()
+	cached, ok := s.userRoles[userID]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < rolePermissionCacheTTL {
+		return cached.names, nil
+	}
+
+	names, err := s.roleRepo.ListUserRoleNames(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.userRoles == nil || len(s.userRoles) >= maxCachedUserRoles {
+		s.userRoles = make(map[uint]*cachedUserRoles)
+	}
+	s.userRoles[userID] = &cachedUserRoles{names: names, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return names, nil
+}
+
+// invalidate 清除角色权限和用户角色缓存
+func (s *roleService) invalidate() {
+	s.mu.Lock()
+	s.permissions = nil
+	s.userRoles = nil
+	s.mu.Unlock()
+}
+
+// uniqueStrings 去掉空白和重复的字符串，保持原有顺序
+func uniqueStrings(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"log"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/pinyin"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	suggestRefreshInterval = 5 * time.Minute  // 建议词库定期刷新的间隔
+	suggestMinRefresh      = 30 * time.Second // 商品变化后刷新词库的最小间隔，避免频繁的库存变化反复刷新
+	maxSearchKeywordLength = 50               // 记录热门搜索词的最大长度（字符数）
+)
+
+// HotSearchStore 热门搜索词存储
+type HotSearchStore interface {
+	RecordSearch(keyword string) error          // 记录一次搜索
+	GetHotSearches(limit int) ([]string, error) // 获取热门搜索词
+}
+
+// SearchSuggestService 搜索建议业务逻辑层接口
+type SearchSuggestService interface {
+	Suggest(req *model.SuggestRequest) ([]*model.Suggestion, error) // 根据已输入的内容补全商品名称、分类和品牌
+	RecordSearch(keyword string)                                    // 记录一次搜索，用于热门搜索词
+	HotSearches(limit int) ([]string, error)                        // 获取热门搜索词
+	ProductsChanged(ids ...uint)                                    // 商品变化后刷新建议词库
+}
+
+// suggestEntry 建议词库中的一条记录
+type suggestEntry struct {
+	suggestion model.Suggestion
+	lower      string // 小写的名称
+	initials   string // 拼音首字母
+	weight     int    // 排序权重：商品销量，品牌和分类为其商品销量之和
+}
+
+// searchSuggestService 搜索建议业务逻辑层实现
+// 建议词库保存在内存中，定期从数据库重建；热门搜索词保存在Redis
+type searchSuggestService struct {
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	hotSearches  HotSearchStore
+
+	mu         sync.RWMutex
+	entries    []suggestEntry
+	builtAt    time.Time
+	stale      bool
+	refreshing int32
+}
+
+// NewSearchSuggestService 创建搜索建议业务逻辑层实例
+// hotSearches为nil时（Redis不可用）不记录搜索词，热门搜索词为空
+func NewSearchSuggestService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, hotSearches HotSearchStore) SearchSuggestService {
+	return &searchSuggestService{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		hotSearches:  hotSearches,
+	}
+}
+
+// Suggest 根据已输入的内容补全商品名称、分类和品牌
+// 依次匹配名称前缀、拼音首字母前缀（只输入字母数字时）、名称包含，同一层按权重排序
+func (s *searchSuggestService) Suggest(req *model.SuggestRequest) ([]*model.Suggestion, error) {
+	suggestions := []*model.Suggestion{}
+	q := normalizeSearchKeyword(req.Q)
+	if q == "" {
+		return suggestions, nil
+	}
+	entries, err := s.loadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 匹配
+	type match struct {
+		entry *suggestEntry
+		tier  int
+	}
+	ascii := isASCIIKeyword(q)
+	var matches []match
+	for i := range entries {
+		entry := &entries[i]
+		switch {
+		case strings.HasPrefix(entry.lower, q):
+			matches = append(matches, match{entry, 0})
+		case ascii && strings.HasPrefix(entry.initials, q):
+			matches = append(matches, match{entry, 1})
+		case strings.Contains(entry.lower, q):
+			matches = append(matches, match{entry, 2})
+		}
+	}
+
+	// 2. 排序
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.tier != b.tier {
+			return a.tier < b.tier
+		}
+		if a.entry.weight != b.entry.weight {
+			return a.entry.weight > b.entry.weight
+		}
+		if len(a.entry.lower) != len(b.entry.lower) {
+			return len(a.entry.lower) < len(b.entry.lower)
+		}
+		return a.entry.lower < b.entry.lower
+	})
+
+	// 3. 同名的只保留一条
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if len(suggestions) >= req.Limit {
+			break
+		}
+		if seen[m.entry.lower] {
+			continue
+		}
+		seen[m.entry.lower] = true
+		suggestion := m.entry.suggestion
+		suggestions = append(suggestions, &suggestion)
+	}
+	return suggestions, nil
+}
+
+// loadEntries 获取建议词库
+// 首次使用时同步构建；过期后先返回旧词库，在后台重建
+func (s *searchSuggestService) loadEntries() ([]suggestEntry, error) {
+	s.mu.RLock()
+	entries, builtAt, stale := s.entries, s.builtAt, s.stale
+	s.mu.RUnlock()
+
+	if builtAt.IsZero() {
+		return s.rebuild()
+	}
+
+	age := time.Since(builtAt)
+	if (age > suggestRefreshInterval || (stale && age > suggestMinRefresh)) && atomic.CompareAndSwapInt32(&s.refreshing, 0, 1) {
+		go func() {
+			defer atomic.StoreInt32(&s.refreshing, 0)
+			if _, err := s.rebuild(); err != nil {
+				log.Printf("刷新搜索建议词库失败: %v", err)
+			}
+		}()
+	}
+	return entries, nil
+}
+
+// rebuild 从数据库重建建议词库
+func (s *searchSuggestService) rebuild() ([]suggestEntry, error) {
+	s.mu.Lock()
+	s.stale = false
+	s.mu.Unlock()
+
+	// 1. 加载上架的商品和启用的分类
+	products, err := s.productRepo.ListSuggestSources()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 商品名称，同时汇总品牌和分类的销量
+	entries := make([]suggestEntry, 0, len(products)+len(categories))
+	brandWeights := make(map[string]int)
+	var brands []string
+	categoryWeights := make(map[uint]int)
+	for _, product := range products {
+		entries = append(entries, newSuggestEntry(model.SuggestionTypeProduct, product.ID, product.Name, product.SalesCount))
+		categoryWeights[product.CategoryID] += product.SalesCount
+		if product.Brand == "" {
+			continue
+		}
+		if _, ok := brandWeights[product.Brand]; !ok {
+			brands = append(brands, product.Brand)
+		}
+		brandWeights[product.Brand] += product.SalesCount
+	}
+
+	// 3. 品牌和分类
+	for _, brand := range brands {
+		entries = append(entries, newSuggestEntry(model.SuggestionTypeBrand, 0, brand, brandWeights[brand]))
+	}
+	for _, category := range categories {
+		entries = append(entries, newSuggestEntry(model.SuggestionTypeCategory, category.ID, category.Name, categoryWeights[category.ID]))
+	}
+
+	s.mu.Lock()
+	s.entries = entries
+	s.builtAt = time.Now()
+	s.mu.Unlock()
+
+	return entries, nil
+}
+
+// newSuggestEntry 创建建议词库记录
+func newSuggestEntry(suggestionType string, id uint, text string, weight int) suggestEntry {
+	return suggestEntry{
+		suggestion: model.Suggestion{Text: text, Type: suggestionType, ID: id},
+		lower:      strings.ToLower(text),
+		initials:   pinyin.Initials(text),
+		weight:     weight,
+	}
+}
+
+// ProductsChanged 商品变化后刷新建议词库
+// 只标记词库过期，下次请求时在后台重建
+func (s *searchSuggestService) ProductsChanged(ids ...uint) {
+	s.mu.Lock()
+	s.stale = true
+	s.mu.Unlock()
+}
+
+// RecordSearch 记录一次搜索，用于热门搜索词
+// 记录失败不影响搜索，只记录日志
+func (s *searchSuggestService) RecordSearch(keyword string) {
+	if s.hotSearches == nil {
+		return
+	}
+	keyword = normalizeSearchKeyword(keyword)
+	if keyword == "" || utf8.RuneCountInString(keyword) > maxSearchKeywordLength {
+		return
+	}
+	if err := s.hotSearches.RecordSearch(keyword); err != nil {
+		log.Printf("记录搜索词失败: %v", err)
+	}
+}
+
+// HotSearches 获取热门搜索词
+func (s *searchSuggestService) HotSearches(limit int) ([]string, error) {
+	if s.hotSearches == nil {
+		return []string{}, nil
+	}
+	keywords, err := s.hotSearches.GetHotSearches(limit)
+	if err != nil {
+		return nil, err
+	}
+	if keywords == nil {
+		keywords = []string{}
+	}
+	return keywords, nil
+}
+
+// normalizeSearchKeyword 规范化搜索词：去掉首尾空白，连续空白合并为一个空格，转为小写
+func normalizeSearchKeyword(keyword string) string {
+	return strings.ToLower(strings.Join(strings.Fields(keyword), " "))
+}
+
+// isASCIIKeyword 搜索词是否只包含字母、数字和空格
+func isASCIIKeyword(keyword string) bool {
+	for _, r := range keyword {
+		if r >= unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"testing"
+	"time"
+)
+
+func TestSearchSuggestService_Suggest(t *testing.T) {
+	s := &searchSuggestService{
+		entries: []suggestEntry{
+			newSuggestEntry(model.SuggestionTypeProduct, 1, "华为Mate 60", 100),
+			newSuggestEntry(model.SuggestionTypeProduct, 2, "华为Mate 60", 5), // 同名商品只保留一条
+			newSuggestEntry(model.SuggestionTypeProduct, 3, "荣耀90", 50),
+			newSuggestEntry(model.SuggestionTypeBrand, 0, "华为", 105),
+			newSuggestEntry(model.SuggestionTypeCategory, 4, "手机", 150),
+			newSuggestEntry(model.SuggestionTypeProduct, 5, "Huawei Watch", 10),
+		},
+		builtAt: time.Now(),
+	}
+
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"华为", []string{"华为", "华为Mate 60"}},
+		{"hw", []string{"华为", "华为Mate 60"}},
+		{"HU", []string{"Huawei Watch"}},
+		{"sj", []string{"手机"}},
+		{"mate", []string{"华为Mate 60"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		suggestions, err := s.Suggest(&model.SuggestRequest{Q: tt.q, Limit: 10})
+		if err != nil {
+			t.Fatalf("%q: %v", tt.q, err)
+		}
+		var got []string
+		for _, suggestion := range suggestions {
+			got = append(got, suggestion.Text)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.q, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q: got %v, want %v", tt.q, got, tt.want)
+				break
+			}
+		}
+	}
+}
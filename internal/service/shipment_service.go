@@ -0,0 +1,419 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"ryan-mall/pkg/logistics"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShipmentService 发货与物流业务逻辑层接口
+type ShipmentService interface {
+	ShipOrder(operatorID, orderID uint, req *model.ShipOrderRequest) (*model.Shipment, error) // 发货（一个包裹）
+	GetOrderShipments(userID, orderID uint) ([]*model.Shipment, error)                        // 获取订单的包裹和物流轨迹
+	HandleWebhook(carrier string, header http.Header, body []byte) error                      // 处理物流公司轨迹推送
+	SimulateTracking(shipmentID uint, req *model.SimulateTrackingRequest) error               // 模拟物流轨迹
+	AutoConfirmOrders(limit int) (int, error)                                                 // 自动确认收货
+}
+
+// shipmentService 发货与物流业务逻辑层实现
+type shipmentService struct {
+	shipmentRepo  repository.ShipmentRepository
+	orderRepo     repository.OrderRepository
+	carriers      *logistics.Registry
+	states        *OrderStateMachine
+	notifications NotificationService
+	autoConfirm   time.Duration
+	db            *gorm.DB
+}
+
+// NewShipmentService 创建发货与物流业务逻辑层实例
+// autoConfirm: 最后一条物流轨迹之后多久自动确认收货
+func NewShipmentService(shipmentRepo repository.ShipmentRepository, orderRepo repository.OrderRepository, carriers *logistics.Registry, states *OrderStateMachine, notifications NotificationService, autoConfirm time.Duration, db *gorm.DB) ShipmentService {
+	s := &shipmentService{
+		shipmentRepo:  shipmentRepo,
+		orderRepo:     orderRepo,
+		carriers:      carriers,
+		states:        states,
+		notifications: notifications,
+		autoConfirm:   autoConfirm,
+		db:            db,
+	}
+
+	// 订单商品全部分配到包裹后才能变为已发货
+	states.AddGuard(model.OrderStatusShipped, s.requireAllShipped)
+	// 确认收货（包括部分退款后的订单）前必须已经发货
+	states.AddGuard(model.OrderStatusDelivered, s.requireDelivered)
+
+	return s
+}
+
+// trackingStatuses 物流轨迹状态对应的包裹状态
+var trackingStatuses = map[logistics.TrackingStatus]model.ShipmentStatus{
+	logistics.TrackingStatusPickedUp:       model.ShipmentStatusInTransit,
+	logistics.TrackingStatusInTransit:      model.ShipmentStatusInTransit,
+	logistics.TrackingStatusOutForDelivery: model.ShipmentStatusInTransit,
+	logistics.TrackingStatusDelivered:      model.ShipmentStatusDelivered,
+	logistics.TrackingStatusException:      model.ShipmentStatusException,
+}
+
+// ShipOrder 发货
+// 每次发出一个包裹，订单商品全部发出后订单变为已发货
+func (s *shipmentService) ShipOrder(operatorID, orderID uint, req *model.ShipOrderRequest) (*model.Shipment, error) {
+	// 1. 检查物流公司
+	if _, err := s.carriers.Get(req.Carrier); err != nil {
+		return nil, errors.New("不支持的物流公司")
+	}
+
+	// 2. 获取订单并检查状态
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("订单不存在")
+	}
+	if !s.states.Can(order.Status, model.OrderStatusShipped) {
+		return nil, errors.New("当前订单状态不能发货")
+	}
+
+	// 3. 检查运单号是否已使用
+	existing, err := s.shipmentRepo.GetByTracking(req.Carrier, req.TrackingNo)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("运单号已被使用")
+	}
+
+	// 4. 分配包裹商品
+	items, err := s.allocateItems(order, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	shipment := &model.Shipment{
+		OrderID:    order.ID,
+		Carrier:    req.Carrier,
+		TrackingNo: req.TrackingNo,
+		Status:     model.ShipmentStatusShipped,
+		OperatorID: operatorID,
+		ShippedAt:  time.Now(),
+		Items:      items,
+	}
+
+	// 5. 保存包裹、更新已发货数量，全部发出后变更订单状态
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(shipment).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			result := tx.Model(&model.OrderItem{}).
+				Where("id = ? AND quantity - refunded_quantity - shipped_quantity >= ?", item.OrderItemID, item.Quantity).
+				Update("shipped_quantity", gorm.Expr("shipped_quantity + ?", item.Quantity))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errors.New("订单商品待发货数量已变化，请刷新后重试")
+			}
+		}
+
+		unshipped, err := s.countUnshipped(tx, order.ID)
+		if err != nil || unshipped > 0 {
+			return err
+		}
+
+		return s.states.Transit(tx, &OrderTransition{
+			OrderID: order.ID,
+			From:    order.Status,
+			To:      model.OrderStatusShipped,
+			Actor:   model.OrderActorAdmin(operatorID),
+			Reason:  fmt.Sprintf("全部发货，%s %s", req.Carrier, req.TrackingNo),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// GetOrderShipments 获取订单的包裹和物流轨迹
+func (s *shipmentService) GetOrderShipments(userID, orderID uint) ([]*model.Shipment, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.UserID != userID {
+		return nil, errors.New("订单不存在")
+	}
+
+	return s.shipmentRepo.GetByOrderID(orderID)
+}
+
+// HandleWebhook 处理物流公司轨迹推送
+// 重复推送和未知运单会被忽略，避免物流公司无限重试
+func (s *shipmentService) HandleWebhook(carrierCode string, header http.Header, body []byte) error {
+	carrier, err := s.carriers.Get(carrierCode)
+	if err != nil {
+		return err
+	}
+
+	// 1. 验证签名
+	events, err := carrier.VerifyWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	// 2. 逐条记录轨迹
+	for _, event := range events {
+		if err := s.recordEvent(carrierCode, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SimulateTracking 模拟物流轨迹
+// 仅对实现了logistics.Simulator的物流公司可用，轨迹会通过webhook回传
+func (s *shipmentService) SimulateTracking(shipmentID uint, req *model.SimulateTrackingRequest) error {
+	status := logistics.TrackingStatus(req.Status)
+	if _, ok := trackingStatuses[status]; !ok {
+		return errors.New("不支持的轨迹状态")
+	}
+
+	shipment, err := s.shipmentRepo.GetByID(shipmentID)
+	if err != nil {
+		return err
+	}
+	if shipment == nil {
+		return errors.New("包裹不存在")
+	}
+
+	carrier, err := s.carriers.Get(shipment.Carrier)
+	if err != nil {
+		return err
+	}
+	simulator, ok := carrier.(logistics.Simulator)
+	if !ok {
+		return errors.New("该物流公司不支持模拟轨迹")
+	}
+
+	return simulator.Emit(&logistics.TrackingEvent{
+		TrackingNo:  shipment.TrackingNo,
+		Status:      status,
+		Location:    req.Location,
+		Description: req.Description,
+	})
+}
+
+// AutoConfirmOrders 自动确认收货
+// 已发货订单（含发货后部分退款的订单）在最后一条物流轨迹之后超过设定时间仍未确认的，由系统确认收货
+func (s *shipmentService) AutoConfirmOrders(limit int) (int, error) {
+	orders, err := s.shipmentRepo.FindAutoConfirmOrders(time.Now().Add(-s.autoConfirm), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	confirmed := 0
+	for _, order := range orders {
+		err := s.states.Transit(s.db, &OrderTransition{
+			OrderID: order.ID,
+			From:    order.Status,
+			To:      model.OrderStatusDelivered,
+			Actor:   model.OrderActorSystem,
+			Reason:  "超时自动确认收货",
+		})
+		if errors.Is(err, ErrOrderStatusChanged) {
+			continue
+		}
+		if err != nil {
+			return confirmed, err
+		}
+		confirmed++
+	}
+
+	return confirmed, nil
+}
+
+// recordEvent 记录一条物流轨迹并更新包裹状态
+func (s *shipmentService) recordEvent(carrierCode string, event *logistics.TrackingEvent) error {
+	status, ok := trackingStatuses[event.Status]
+	if !ok {
+		log.Printf("logistics webhook: unknown status %s for %s, skip", event.Status, event.TrackingNo)
+		return nil
+	}
+
+	shipment, err := s.shipmentRepo.GetByTracking(carrierCode, event.TrackingNo)
+	if err != nil {
+		return err
+	}
+	if shipment == nil {
+		log.Printf("logistics webhook: shipment %s/%s not found, skip", carrierCode, event.TrackingNo)
+		return nil
+	}
+
+	// 重复推送
+	exists, err := s.shipmentRepo.HasEvent(shipment.ID, status, event.OccurredAt)
+	if err != nil || exists {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&model.ShipmentEvent{
+			ShipmentID:  shipment.ID,
+			Status:      status,
+			Location:    event.Location,
+			Description: event.Description,
+			OccurredAt:  event.OccurredAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// 轨迹可能乱序到达，只用更新的轨迹推进包裹状态，已签收的包裹不再回退
+		if shipment.LastEventAt != nil && !event.OccurredAt.After(*shipment.LastEventAt) {
+			return nil
+		}
+		updates := map[string]interface{}{
+			"last_event_at": event.OccurredAt,
+		}
+		if shipment.Status != model.ShipmentStatusDelivered {
+			updates["status"] = status
+			if status == model.ShipmentStatusDelivered {
+				updates["delivered_at"] = event.OccurredAt
+			}
+		}
+		if err := tx.Model(&model.Shipment{}).Where("id = ?", shipment.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		s.notifyEvent(tx, shipment, status, event)
+		return nil
+	})
+}
+
+// notifyEvent 通知用户包裹的最新物流轨迹，通知失败只记录日志
+func (s *shipmentService) notifyEvent(tx *gorm.DB, shipment *model.Shipment, status model.ShipmentStatus, event *logistics.TrackingEvent) {
+	order, err := repository.GetOrderRecipient(tx, shipment.OrderID)
+	if err != nil || order == nil {
+		log.Printf("logistics webhook: load recipient of order %d failed: %v", shipment.OrderID, err)
+		return
+	}
+
+	content := fmt.Sprintf("订单%s的包裹（运单号%s）%s", order.OrderNo, shipment.TrackingNo, model.GetShipmentStatusText(status))
+	if event.Description != "" {
+		content += "：" + event.Description
+	}
+	err = s.notifications.SendTx(tx, &model.Notification{
+		UserID:  order.UserID,
+		Type:    model.NotificationTypeShipment,
+		Title:   "物流更新",
+		Content: content,
+		RefID:   order.ID,
+	})
+	if err != nil {
+		log.Printf("logistics webhook: notify tracking of %s failed: %v", shipment.TrackingNo, err)
+	}
+}
+
+// allocateItems 分配包裹商品
+// 未指定商品时将订单剩余待发货商品全部放入包裹
+func (s *shipmentService) allocateItems(order *model.Order, requested []model.ShipmentItemRequest) ([]model.ShipmentItem, error) {
+	orderItems := make(map[uint]model.OrderItem, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		orderItems[item.ID] = item
+	}
+
+	var items []model.ShipmentItem
+
+	if len(requested) == 0 {
+		for _, item := range order.OrderItems {
+			if unshipped := item.UnshippedQuantity(); unshipped > 0 {
+				items = append(items, model.ShipmentItem{
+					OrderItemID: item.ID,
+					ProductID:   item.ProductID,
+					Quantity:    unshipped,
+				})
+			}
+		}
+		if len(items) == 0 {
+			return nil, errors.New("订单没有待发货的商品")
+		}
+		return items, nil
+	}
+
+	seen := make(map[uint]bool, len(requested))
+	for _, req := range requested {
+		item, ok := orderItems[req.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("订单商品 %d 不存在", req.OrderItemID)
+		}
+		if seen[req.OrderItemID] {
+			return nil, fmt.Errorf("订单商品 %d 重复分配", req.OrderItemID)
+		}
+		seen[req.OrderItemID] = true
+
+		if req.Quantity > item.UnshippedQuantity() {
+			return nil, fmt.Errorf("商品 %s 待发货数量不足", item.ProductName)
+		}
+
+		items = append(items, model.ShipmentItem{
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Quantity:    req.Quantity,
+		})
+	}
+
+	return items, nil
+}
+
+// countUnshipped 统计订单中仍有待发货数量的商品数
+func (s *shipmentService) countUnshipped(tx *gorm.DB, orderID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&model.OrderItem{}).
+		Where("order_id = ? AND quantity - refunded_quantity - shipped_quantity > 0", orderID).
+		Count(&count).Error
+	return count, err
+}
+
+// requireAllShipped 订单变为已发货前，所有商品都必须已分配到包裹
+func (s *shipmentService) requireAllShipped(tx *gorm.DB, t *OrderTransition) error {
+	unshipped, err := s.countUnshipped(tx, t.OrderID)
+	if err != nil {
+		return err
+	}
+	if unshipped > 0 {
+		return errors.New("订单还有未发货的商品")
+	}
+	return nil
+}
+
+// requireDelivered 订单变为已收货前，剩余商品都必须已发货，且至少发出过一个包裹
+// 部分退款的订单可能从未发货，不能直接确认收货
+func (s *shipmentService) requireDelivered(tx *gorm.DB, t *OrderTransition) error {
+	if err := s.requireAllShipped(tx, t); err != nil {
+		return err
+	}
+
+	var shipped int64
+	err := tx.Model(&model.OrderItem{}).
+		Where("order_id = ? AND shipped_quantity > 0", t.OrderID).
+		Count(&shipped).Error
+	if err != nil {
+		return err
+	}
+	if shipped == 0 {
+		return errors.New("订单还没有发货")
+	}
+	return nil
+}
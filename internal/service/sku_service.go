@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"strings"
+)
+
+// errSkuPriceManaged 启用规格的商品价格由SKU价格决定，不能直接修改
+var errSkuPriceManaged = errors.New("商品已启用规格，请修改规格的价格")
+
+// SkuService 商品规格业务逻辑层接口
+type SkuService interface {
+	SetProductSkus(productID uint, req *model.SetProductSkusRequest) ([]*model.ProductSku, error) // 设置商品的规格矩阵
+	ListProductSkus(productID uint) ([]*model.ProductSku, error)                                  // 获取商品的全部SKU（管理员）
+}
+
+// skuService 商品规格业务逻辑层实现
+type skuService struct {
+	skuRepo        repository.SkuRepository
+	productRepo    repository.ProductRepository
+	productService *CachedProductService
+}
+
+// NewSkuService 创建商品规格业务逻辑层实例
+// 规格矩阵变化会改变商品的价格和库存，设置后通过productService清除商品缓存
+func NewSkuService(skuRepo repository.SkuRepository, productRepo repository.ProductRepository, productService *CachedProductService) SkuService {
+	return &skuService{
+		skuRepo:        skuRepo,
+		productRepo:    productRepo,
+		productService: productService,
+	}
+}
+
+// SetProductSkus 设置商品的规格矩阵
+// 每个属性组合最多一个SKU，属性取值必须在规格定义中
+func (s *skuService) SetProductSkus(productID uint, req *model.SetProductSkusRequest) ([]*model.ProductSku, error) {
+	// 1. 检查商品是否存在
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("商品不存在")
+	}
+
+	// 2. 验证规格定义
+	specs := make(model.JSONSpecs, 0, len(req.Specs))
+	for _, spec := range req.Specs {
+		values := make([]string, 0, len(spec.Values))
+		for _, value := range spec.Values {
+			values = append(values, strings.TrimSpace(value))
+		}
+		specs = append(specs, model.ProductSpec{Name: strings.TrimSpace(spec.Name), Values: values})
+	}
+	if err := specs.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 3. 验证SKU的属性组合
+	skus := make([]*model.ProductSku, 0, len(req.Skus))
+	seen := make(map[string]bool, len(req.Skus))
+	for _, item := range req.Skus {
+		specKey, err := specs.SpecKey(item.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		if seen[specKey] {
+			return nil, fmt.Errorf("规格 %s 重复", specKey)
+		}
+		seen[specKey] = true
+
+		skus = append(skus, &model.ProductSku{
+			SpecKey:    specKey,
+			SkuCode:    strings.TrimSpace(item.SkuCode),
+			Attributes: model.JSONAttributes(item.Attributes),
+			Price:      item.Price,
+			Stock:      item.Stock,
+			Image:      item.Image,
+		})
+	}
+
+	// 4. 保存规格矩阵
+	if err := s.skuRepo.SaveMatrix(productID, specs, skus); err != nil {
+		return nil, err
+	}
+	s.productService.InvalidateProducts(productID)
+
+	return s.skuRepo.ListByProduct(productID)
+}
+
+// ListProductSkus 获取商品的全部SKU（管理员）
+func (s *skuService) ListProductSkus(productID uint) ([]*model.ProductSku, error) {
+	return s.skuRepo.ListByProduct(productID)
+}
@@ -5,6 +5,8 @@ import (
 	"ryan-mall/internal/model"
 	"ryan-mall/internal/repository"
 	"ryan-mall/pkg/jwt"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -18,19 +20,39 @@ type UserService interface {
 	UpdateProfile(userID uint, updates map[string]interface{}) error           // 更新用户资料
 	ChangePassword(userID uint, oldPassword, newPassword string) error         // 修改密码
 	ValidateToken(tokenString string) (*jwt.Claims, error)                     // 验证令牌
+	IsActive(userID uint) (bool, error)                                        // 用户是否存在且未被禁用
+	InvalidateStatus(userID uint)                                              // 清除用户状态缓存
 }
 
+// userStatusCacheTTL 用户状态缓存时间
+// 每次认证都要检查用户状态，缓存避免每个请求查询数据库；禁用用户后最多延迟这么久生效
+const userStatusCacheTTL = 30 * time.Second
+
+// maxCachedUserStatuses 最多缓存多少个用户的状态，超过时清空重新缓存
+const maxCachedUserStatuses = 10000
+
 // userService 用户业务逻辑层实现
 type userService struct {
 	userRepo   repository.UserRepository // 用户数据访问层
+	roleRepo   repository.RoleRepository // 角色数据访问层，登录时把角色写入令牌供客户端展示
 	jwtManager *jwt.JWTManager          // JWT管理器
+	
+	mu       sync.RWMutex
+	statuses map[uint]*cachedUserStatus // 用户ID -> 用户状态
+}
+
+// cachedUserStatus 缓存的用户状态
+type cachedUserStatus struct {
+	active   bool
+	loadedAt time.Time
 }
 
 // NewUserService 创建用户业务逻辑层实例
 // 使用依赖注入的方式传入所需的依赖
-func NewUserService(userRepo repository.UserRepository, jwtManager *jwt.JWTManager) UserService {
+func NewUserService(userRepo repository.UserRepository, roleRepo repository.RoleRepository, jwtManager *jwt.JWTManager) UserService {
 	return &userService{
 		userRepo:   userRepo,
+		roleRepo:   roleRepo,
 		jwtManager: jwtManager,
 	}
 }
@@ -84,7 +106,8 @@ func (s *userService) Register(req *model.UserRegisterRequest) (*model.UserLogin
 	}
 	
 	// 6. 生成JWT令牌
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email)
+	// 新注册的用户没有角色
+	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -130,13 +153,19 @@ func (s *userService) Login(req *model.UserLoginRequest) (*model.UserLoginRespon
 		return nil, errors.New("用户名或密码错误")
 	}
 	
-	// 4. 生成JWT令牌
-	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email)
+	// 4. 获取用户角色，写入令牌仅供客户端展示，鉴权时按数据库中的当前角色
+	roles, err := s.roleRepo.ListUserRoleNames(user.ID)
 	if err != nil {
 		return nil, err
 	}
 	
-	// 5. 返回登录响应
+	// 5. 生成JWT令牌
+	token, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email, roles)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 6. 返回登录响应
 	return &model.UserLoginResponse{
 		User:  user,
 		Token: token,
@@ -244,6 +273,42 @@ func (s *userService) ValidateToken(tokenString string) (*jwt.Claims, error) {
 	return s.jwtManager.ValidateToken(tokenString)
 }
 
+// IsActive 用户是否存在且未被禁用
+// 认证中间件用它拒绝已禁用用户的令牌，结果缓存userStatusCacheTTL
+func (s *userService) IsActive(userID uint) (bool, error) {
+	// 1. 读取缓存
+	s.mu.RLock()
+	cached, ok := s.statuses[userID]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < userStatusCacheTTL {
+		return cached.active, nil
+	}
+	
+	// 2. 从数据库加载
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	active := user != nil && user.Status == model.UserStatusActive
+	
+	// 3. 写入缓存
+	s.mu.Lock()
+	if s.statuses == nil || len(s.statuses) >= maxCachedUserStatuses {
+		s.statuses = make(map[uint]*cachedUserStatus)
+	}
+	s.statuses[userID] = &cachedUserStatus{active: active, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return active, nil
+}
+
+// InvalidateStatus 清除用户状态缓存
+// 禁用、启用用户后调用，本实例立即按新状态认证，其他实例在缓存过期后生效
+func (s *userService) InvalidateStatus(userID uint) {
+	s.mu.Lock()
+	delete(s.statuses, userID)
+	s.mu.Unlock()
+}
+
 // contains 检查字符串是否包含子字符串
 // 这是一个辅助函数，用于判断登录输入是邮箱还是用户名
 func contains(s, substr string) bool {
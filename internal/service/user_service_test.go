@@ -0,0 +1,44 @@
+package service
+
+import (
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"testing"
+)
+
+// stubUserRepo 只实现GetByID的用户数据访问层，其他方法调用时会panic
+type stubUserRepo struct {
+	repository.UserRepository
+	user  *model.User
+	loads int
+}
+
+func (r *stubUserRepo) GetByID(id uint) (*model.User, error) {
+	r.loads++
+	if r.user == nil || r.user.ID != id {
+		return nil, nil
+	}
+	copied := *r.user
+	return &copied, nil
+}
+
+func TestUserService_InvalidateStatus(t *testing.T) {
+	repo := &stubUserRepo{user: &model.User{ID: 1, Status: model.UserStatusActive}}
+	s := &userService{userRepo: repo}
+
+	if active, err := s.IsActive(1); err != nil || !active {
+		t.Fatalf("IsActive = %v, %v, want true", active, err)
+	}
+
+	// 缓存未清除时仍返回旧状态
+	repo.user.Status = model.UserStatusDisabled
+	if active, _ := s.IsActive(1); !active || repo.loads != 1 {
+		t.Fatalf("IsActive = %v after %d loads, want cached true", active, repo.loads)
+	}
+
+	// 清除缓存后立即按新状态返回
+	s.InvalidateStatus(1)
+	if active, err := s.IsActive(1); err != nil || active {
+		t.Fatalf("IsActive after invalidate = %v, %v, want false", active, err)
+	}
+}
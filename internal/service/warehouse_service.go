@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"ryan-mall/internal/model"
+	"ryan-mall/internal/repository"
+	"strings"
+)
+
+// WarehouseService 仓库业务逻辑层接口
+type WarehouseService interface {
+	CreateWarehouse(req *model.CreateWarehouseRequest) (*model.Warehouse, error)          // 创建仓库
+	ListWarehouses() ([]*model.Warehouse, error)                                          // 获取仓库列表
+	UpdateWarehouse(id uint, req *model.UpdateWarehouseRequest) (*model.Warehouse, error) // 更新仓库
+	SetStock(warehouseID, productID uint, stock int) error                                // 设置商品在仓库的库存
+	GetProductStocks(productID uint) (*model.ProductStockResponse, error)                 // 获取商品的分仓库存
+}
+
+// warehouseService 仓库业务逻辑层实现
+type warehouseService struct {
+	warehouseRepo  repository.WarehouseRepository
+	productRepo    repository.ProductRepository
+	productService *CachedProductService
+}
+
+// NewWarehouseService 创建仓库业务逻辑层实例
+// 仓库库存变化会改变商品库存，设置库存后通过productService清除商品缓存
+func NewWarehouseService(warehouseRepo repository.WarehouseRepository, productRepo repository.ProductRepository, productService *CachedProductService) WarehouseService {
+	return &warehouseService{
+		warehouseRepo:  warehouseRepo,
+		productRepo:    productRepo,
+		productService: productService,
+	}
+}
+
+// CreateWarehouse 创建仓库
+func (s *warehouseService) CreateWarehouse(req *model.CreateWarehouseRequest) (*model.Warehouse, error) {
+	// 1. 检查仓库编码是否已存在
+	code := strings.TrimSpace(req.Code)
+	existing, err := s.warehouseRepo.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("仓库编码已存在")
+	}
+
+	// 2. 创建仓库
+	warehouse := &model.Warehouse{
+		Code:           code,
+		Name:           strings.TrimSpace(req.Name),
+		Province:       strings.TrimSpace(req.Province),
+		CoverProvinces: model.JSONArray(req.CoverProvinces),
+		Status:         model.WarehouseStatusActive,
+	}
+	if err := s.warehouseRepo.Create(warehouse); err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+// ListWarehouses 获取仓库列表
+func (s *warehouseService) ListWarehouses() ([]*model.Warehouse, error) {
+	return s.warehouseRepo.List()
+}
+
+// UpdateWarehouse 更新仓库
+// 停用的仓库不再参与下单分配，已预占的库存仍按原仓库扣减或释放
+func (s *warehouseService) UpdateWarehouse(id uint, req *model.UpdateWarehouseRequest) (*model.Warehouse, error) {
+	// 1. 检查仓库是否存在
+	warehouse, err := s.warehouseRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse == nil {
+		return nil, errors.New("仓库不存在")
+	}
+
+	// 2. 更新字段
+	if req.Name != nil {
+		warehouse.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Province != nil {
+		warehouse.Province = strings.TrimSpace(*req.Province)
+	}
+	if req.CoverProvinces != nil {
+		warehouse.CoverProvinces = model.JSONArray(req.CoverProvinces)
+	}
+	if req.Status != nil {
+		warehouse.Status = *req.Status
+	}
+
+	// 3. 保存更新
+	if err := s.warehouseRepo.Update(warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// SetStock 设置商品在仓库的库存
+func (s *warehouseService) SetStock(warehouseID, productID uint, stock int) error {
+	// 1. 验证库存数量
+	if stock < 0 {
+		return errors.New("库存数量不能为负数")
+	}
+
+	// 2. 检查仓库和商品是否存在
+	warehouse, err := s.warehouseRepo.GetByID(warehouseID)
+	if err != nil {
+		return err
+	}
+	if warehouse == nil {
+		return errors.New("仓库不存在")
+	}
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("商品不存在")
+	}
+
+	// 3. 更新库存
+	if err := s.warehouseRepo.SetStock(warehouseID, productID, stock); err != nil {
+		return err
+	}
+	s.productService.InvalidateProducts(productID)
+
+	return nil
+}
+
+// GetProductStocks 获取商品的分仓库存
+func (s *warehouseService) GetProductStocks(productID uint) (*model.ProductStockResponse, error) {
+	// 1. 检查商品是否存在
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("商品不存在")
+	}
+
+	// 2. 获取各仓库库存
+	stocks, err := s.warehouseRepo.ListProductStocks(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 汇总，商品库存中不属于任何仓库的部分是未分配库存
+	resp := &model.ProductStockResponse{
+		ProductID:     product.ID,
+		Stock:         product.Stock,
+		ReservedStock: product.ReservedStock,
+		Available:     product.AvailableStock(),
+		Warehouses:    make([]model.ProductWarehouseStock, 0, len(stocks)),
+	}
+	unassigned := model.ProductWarehouseStock{
+		WarehouseID:   model.UnassignedWarehouseID,
+		Status:        model.WarehouseStatusActive,
+		Stock:         product.Stock,
+		ReservedStock: product.ReservedStock,
+	}
+	for i := range stocks {
+		stock := &stocks[i]
+		unassigned.Stock -= stock.Stock
+		unassigned.ReservedStock -= stock.ReservedStock
+		resp.Warehouses = append(resp.Warehouses, model.ProductWarehouseStock{
+			WarehouseID:   stock.WarehouseID,
+			WarehouseCode: stock.Warehouse.Code,
+			WarehouseName: stock.Warehouse.Name,
+			Province:      stock.Warehouse.Province,
+			Status:        stock.Warehouse.Status,
+			Stock:         stock.Stock,
+			ReservedStock: stock.ReservedStock,
+			Available:     stock.AvailableStock(),
+		})
+	}
+	if available := unassigned.Stock - unassigned.ReservedStock; available > 0 {
+		unassigned.Available = available
+	}
+	resp.Unassigned = unassigned
+
+	return resp, nil
+}
@@ -130,6 +130,26 @@ func AutoMigrate(models ...interface{}) error {
 	return nil
 }
 
+// DropIndexes 删除已被替换的旧索引
+// AutoMigrate不会删除索引，唯一索引的字段变化后旧索引仍会生效，需要手动删除
+func DropIndexes(model interface{}, names ...string) error {
+	if DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	migrator := DB.Migrator()
+	for _, name := range names {
+		if !migrator.HasIndex(model, name) {
+			continue
+		}
+		if err := migrator.DropIndex(model, name); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", name, err)
+		}
+		log.Printf("Dropped index %s", name)
+	}
+	return nil
+}
+
 // CheckConnection 检查数据库连接状态
 // 用于健康检查，确保数据库连接正常
 func CheckConnection() error {
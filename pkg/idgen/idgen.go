@@ -0,0 +1,139 @@
+// Package idgen 分布式单号生成器
+//
+// 单号格式：前缀 + 8位日期(yyyyMMdd) + 15位数字，数字部分按Snowflake思路由三段组成：
+//
+//	当天已过去的毫秒数(27位) | WorkerID(10位) | 毫秒内序列号(12位)
+//
+// 同一WorkerID在同一毫秒内最多生成4096个单号，不同实例通过不同的WorkerID保证不重复。
+// 日期前缀方便人工识别，也让同一天的单号按时间有序。
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	workerBits   = 10
+	sequenceBits = 12
+
+	// MaxWorkerID 最大WorkerID
+	MaxWorkerID = 1<<workerBits - 1
+	maxSequence = 1<<sequenceBits - 1
+
+	msPerDay = 24 * 60 * 60 * 1000
+
+	// maxClockBackward 允许的最大时钟回拨
+	// 回拨在此范围内时沿用上次的时间戳继续生成，超过时拒绝生成
+	maxClockBackward = time.Second
+)
+
+var (
+	ErrClockBackwards = errors.New("系统时钟回拨，暂停生成单号")
+	ErrLeaseExpired   = errors.New("WorkerID租约已失效，暂停生成单号")
+)
+
+// Generator 单号生成器，并发安全
+type Generator struct {
+	mu         sync.Mutex
+	workerID   int64
+	validUntil time.Time      // WorkerID租约有效期，零值表示固定WorkerID
+	lastMs     int64          // 上次生成使用的时间戳（毫秒）
+	sequence   int64          // 上次生成使用的序列号
+	zone       *time.Location // 计算日期使用的固定时区
+	offsetMs   int64          // 时区偏移（毫秒）
+	now        func() time.Time
+
+	lease *lease // 从Redis租用WorkerID时的续期任务
+}
+
+// New 创建使用固定WorkerID的生成器
+// 多实例部署时需要保证每个实例的WorkerID不同
+func New(workerID int64) (*Generator, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("WorkerID必须在0到%d之间", MaxWorkerID)
+	}
+	return newGenerator(workerID), nil
+}
+
+// newGenerator 创建生成器
+// 日期按创建时的本地时区偏移计算，运行期间时区偏移变化（夏令时）不会导致单号重复
+func newGenerator(workerID int64) *Generator {
+	_, offset := time.Now().Zone()
+	return &Generator{
+		workerID: workerID,
+		zone:     time.FixedZone("idgen", offset),
+		offsetMs: int64(offset) * 1000,
+		now:      time.Now,
+	}
+}
+
+// WorkerID 当前使用的WorkerID
+func (g *Generator) WorkerID() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.workerID
+}
+
+// Next 生成带前缀的单号
+func (g *Generator) Next(prefix string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// 1. 检查WorkerID租约
+	now := g.now()
+	if !g.validUntil.IsZero() && !now.Before(g.validUntil) {
+		return "", ErrLeaseExpired
+	}
+
+	// 2. 处理时钟回拨：小幅回拨沿用上次的时间戳，超过阈值拒绝生成
+	ms := now.UnixMilli()
+	if ms < g.lastMs {
+		if time.Duration(g.lastMs-ms)*time.Millisecond > maxClockBackward {
+			return "", ErrClockBackwards
+		}
+		ms = g.lastMs
+	}
+
+	// 3. 同一毫秒内递增序列号，序列号用完时借用下一毫秒
+	if ms == g.lastMs {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			ms++
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastMs = ms
+
+	// 4. 拼装单号
+	date := time.UnixMilli(ms).In(g.zone).Format("20060102")
+	msOfDay := (ms + g.offsetMs) % msPerDay
+	id := msOfDay<<(workerBits+sequenceBits) | g.workerID<<sequenceBits | g.sequence
+	return fmt.Sprintf("%s%s%015d", prefix, date, id), nil
+}
+
+// setWorker 更新WorkerID和租约有效期
+func (g *Generator) setWorker(workerID int64, validUntil time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.workerID = workerID
+	g.validUntil = validUntil
+}
+
+// extendLease 延长租约有效期
+func (g *Generator) extendLease(validUntil time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.validUntil = validUntil
+}
+
+// Close 停止WorkerID续期并释放租约
+func (g *Generator) Close() error {
+	if g.lease == nil {
+		return nil
+	}
+	return g.lease.close()
+}
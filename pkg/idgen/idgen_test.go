@@ -0,0 +1,94 @@
+package idgen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixedClock(g *Generator, now *time.Time) {
+	g.now = func() time.Time { return *now }
+}
+
+func TestGenerator_NextIsUniqueAndPrefixed(t *testing.T) {
+	g, err := New(7)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	now := time.Date(2026, 10, 16, 23, 59, 59, 0, g.zone)
+	fixedClock(g, &now)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3*(maxSequence+1); i++ {
+		no, err := g.Next("P")
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if !strings.HasPrefix(no, "P20261016") || len(no) != 1+8+15 {
+			t.Fatalf("unexpected format %q", no)
+		}
+		if seen[no] {
+			t.Fatalf("duplicate %q after %d ids", no, i)
+		}
+		seen[no] = true
+	}
+}
+
+func TestGenerator_DifferentWorkersDoNotCollide(t *testing.T) {
+	a, _ := New(1)
+	b, _ := New(2)
+	now := time.Now()
+	fixedClock(a, &now)
+	fixedClock(b, &now)
+
+	x, _ := a.Next("")
+	y, _ := b.Next("")
+	if x == y {
+		t.Fatalf("workers generated the same id %q", x)
+	}
+}
+
+func TestGenerator_ClockBackwards(t *testing.T) {
+	g, _ := New(1)
+	now := time.Now()
+	fixedClock(g, &now)
+	first, _ := g.Next("")
+
+	// 小幅回拨沿用上次的时间戳
+	now = now.Add(-100 * time.Millisecond)
+	second, err := g.Next("")
+	if err != nil {
+		t.Fatalf("small rollback should be tolerated: %v", err)
+	}
+	if second <= first {
+		t.Fatalf("ids should keep increasing: %q then %q", first, second)
+	}
+
+	// 超过阈值拒绝生成
+	now = now.Add(-2 * maxClockBackward)
+	if _, err := g.Next(""); !errors.Is(err, ErrClockBackwards) {
+		t.Fatalf("expected ErrClockBackwards, got %v", err)
+	}
+}
+
+func TestGenerator_LeaseExpired(t *testing.T) {
+	g, _ := New(1)
+	now := time.Now()
+	fixedClock(g, &now)
+	g.setWorker(3, now.Add(time.Second))
+
+	if _, err := g.Next(""); err != nil {
+		t.Fatalf("next within lease: %v", err)
+	}
+	now = now.Add(time.Second)
+	if _, err := g.Next(""); !errors.Is(err, ErrLeaseExpired) {
+		t.Fatalf("expected ErrLeaseExpired, got %v", err)
+	}
+}
+
+func TestNew_RejectsInvalidWorkerID(t *testing.T) {
+	if _, err := New(MaxWorkerID + 1); err == nil {
+		t.Fatal("expected error for worker id out of range")
+	}
+}
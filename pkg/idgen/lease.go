@@ -0,0 +1,119 @@
+package idgen
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"ryan-mall/pkg/redis"
+	"time"
+)
+
+// workerKeyPrefix WorkerID租约在Redis中的键前缀
+const workerKeyPrefix = "idgen:worker:"
+
+// lease 从Redis租用的WorkerID及其续期任务
+type lease struct {
+	rm         *redis.RedisManager
+	instanceID string
+	ttl        time.Duration
+	gen        *Generator
+	lock       *redis.DistributedLock
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewLeased 创建从Redis租用WorkerID的生成器
+// 租约按ttl/3的间隔续期；续期失败时生成器在租约到期前停止生成单号，
+// 租约被其他实例占用时重新租用一个空闲的WorkerID。ttl需要大于2秒
+func NewLeased(rm *redis.RedisManager, instanceID string, ttl time.Duration) (*Generator, error) {
+	if ttl <= 2*maxClockBackward {
+		return nil, fmt.Errorf("WorkerID租约有效期必须大于%s", 2*maxClockBackward)
+	}
+
+	g := newGenerator(0)
+	l := &lease{
+		rm:         rm,
+		instanceID: instanceID,
+		ttl:        ttl,
+		gen:        g,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if err := l.acquire(); err != nil {
+		return nil, err
+	}
+	g.lease = l
+
+	go l.run()
+	return g, nil
+}
+
+// acquire 租用一个空闲的WorkerID
+// 从随机位置开始尝试，减少多个实例同时启动时的冲突
+func (l *lease) acquire() error {
+	start := rand.Int63n(MaxWorkerID + 1)
+	for i := int64(0); i <= MaxWorkerID; i++ {
+		workerID := (start + i) % (MaxWorkerID + 1)
+		acquiredAt := time.Now()
+		lock := l.rm.NewDistributedLock(fmt.Sprintf("%s%d", workerKeyPrefix, workerID), l.instanceID, l.ttl)
+		ok, err := lock.TryLock()
+		if err != nil {
+			return err
+		}
+		if ok {
+			l.lock = lock
+			l.gen.setWorker(workerID, l.validUntil(acquiredAt))
+			return nil
+		}
+	}
+	return fmt.Errorf("没有空闲的WorkerID（共%d个）", MaxWorkerID+1)
+}
+
+// validUntil 租约在本实例看来的有效期
+// 扣除允许的时钟回拨，保证其他实例接手这个WorkerID前本实例已经停止生成
+func (l *lease) validUntil(acquiredAt time.Time) time.Time {
+	return acquiredAt.Add(l.ttl - maxClockBackward)
+}
+
+// run 定期续期租约
+func (l *lease) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.renew()
+		}
+	}
+}
+
+// renew 续期租约，租约已丢失时重新租用
+func (l *lease) renew() {
+	refreshedAt := time.Now()
+	ok, err := l.lock.Refresh()
+	if err != nil {
+		// Redis暂时不可用，等待下次续期，租约到期前生成器仍可使用
+		log.Printf("idgen: renew worker %d failed: %v", l.gen.WorkerID(), err)
+		return
+	}
+	if ok {
+		l.gen.extendLease(l.validUntil(refreshedAt))
+		return
+	}
+
+	log.Printf("idgen: worker %d lease lost, acquiring a new one", l.gen.WorkerID())
+	if err := l.acquire(); err != nil {
+		log.Printf("idgen: acquire worker failed: %v", err)
+	}
+}
+
+// close 停止续期并释放租约
+func (l *lease) close() error {
+	close(l.stop)
+	<-l.done
+	return l.lock.Unlock()
+}
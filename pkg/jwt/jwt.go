@@ -13,6 +13,7 @@ type Claims struct {
 	UserID   uint   `json:"user_id"`   // 用户ID
 	Username string `json:"username"`  // 用户名
 	Email    string `json:"email"`     // 邮箱
+	Roles    []string `json:"roles,omitempty"` // 角色名称，登录时写入，仅供客户端展示；服务端鉴权按数据库中的当前角色
 	jwt.RegisteredClaims                // 标准JWT声明（过期时间、签发者等）
 }
 
@@ -34,8 +35,8 @@ func NewJWTManager(secretKey string, expireHours int) *JWTManager {
 }
 
 // GenerateToken 生成JWT令牌
-// 根据用户信息生成包含用户身份的JWT令牌
-func (j *JWTManager) GenerateToken(userID uint, username, email string) (string, error) {
+// 根据用户信息生成包含用户身份和角色的JWT令牌
+func (j *JWTManager) GenerateToken(userID uint, username, email string, roles []string) (string, error) {
 	// 1. 设置过期时间
 	// 从当前时间开始计算，添加指定的小时数
 	expirationTime := time.Now().Add(time.Duration(j.expireHours) * time.Hour)
@@ -46,6 +47,7 @@ func (j *JWTManager) GenerateToken(userID uint, username, email string) (string,
 		UserID:   userID,
 		Username: username,
 		Email:    email,
+		Roles:    roles,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime), // 过期时间
 			IssuedAt:  jwt.NewNumericDate(time.Now()),      // 签发时间
@@ -117,8 +119,8 @@ func (j *JWTManager) RefreshToken(tokenString string) (string, error) {
 	}
 	
 	// 3. 生成新令牌
-	// 使用相同的用户信息生成新的令牌
-	return j.GenerateToken(claims.UserID, claims.Username, claims.Email)
+	// 使用相同的用户信息和角色生成新的令牌
+	return j.GenerateToken(claims.UserID, claims.Username, claims.Email, claims.Roles)
 }
 
 // ExtractUserID 从令牌中提取用户ID
@@ -0,0 +1,117 @@
+package logistics
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// FakeSignatureHeader 模拟物流公司推送签名请求头
+const FakeSignatureHeader = "X-Fake-Carrier-Signature"
+
+// webhookPayload 轨迹推送报文
+type webhookPayload struct {
+	Events []*TrackingEvent `json:"events"`
+}
+
+// FakeCarrier 本地模拟物流公司
+// 不连接任何真实物流网络，调用Emit后会像真实物流公司一样向webhook地址推送签名轨迹
+type FakeCarrier struct {
+	code       string
+	secret     []byte
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewFakeCarrier 创建模拟物流公司
+// code: 物流公司编码，secret: 推送签名密钥，webhookURL: 轨迹推送地址
+func NewFakeCarrier(code, secret, webhookURL string) *FakeCarrier {
+	return &FakeCarrier{
+		code:       code,
+		secret:     []byte(secret),
+		webhookURL: webhookURL,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// Code 物流公司编码
+func (c *FakeCarrier) Code() string {
+	return c.code
+}
+
+// VerifyWebhook 验证轨迹推送签名
+func (c *FakeCarrier) VerifyWebhook(header http.Header, body []byte) ([]*TrackingEvent, error) {
+	signature := header.Get(FakeSignatureHeader)
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(SignFakePayload(c.secret, body))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("logistics: invalid webhook body: %w", err)
+	}
+	return payload.Events, nil
+}
+
+// Emit 模拟物流公司产生一条轨迹并异步推送
+func (c *FakeCarrier) Emit(event *TrackingEvent) error {
+	if event.TrackingNo == "" {
+		return fmt.Errorf("logistics: tracking_no is required")
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	body, err := json.Marshal(webhookPayload{Events: []*TrackingEvent{event}})
+	if err != nil {
+		return err
+	}
+
+	// 真实物流公司的推送是异步的，这里同样在后台推送
+	go c.deliver(body)
+	return nil
+}
+
+// deliver 推送轨迹，失败时按固定间隔重试
+func (c *FakeCarrier) deliver(body []byte) {
+	if c.webhookURL == "" {
+		return
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, c.webhookURL, bytes.NewReader(body))
+		if err != nil {
+			log.Printf("fake carrier: build webhook request failed: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(FakeSignatureHeader, SignFakePayload(c.secret, body))
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+			err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+
+		log.Printf("fake carrier: webhook attempt %d to %s failed: %v", attempt, c.webhookURL, err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// SignFakePayload 计算模拟物流推送签名（HMAC-SHA256，十六进制）
+func SignFakePayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package logistics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFakeCarrier_EmitDeliversSignedWebhook(t *testing.T) {
+	received := make(chan []*TrackingEvent, 1)
+	var carrier *FakeCarrier
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		events, err := carrier.VerifyWebhook(r.Header, body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- events
+	}))
+	defer server.Close()
+
+	carrier = NewFakeCarrier("fake", "secret", server.URL)
+	err := carrier.Emit(&TrackingEvent{TrackingNo: "FK001", Status: TrackingStatusDelivered, Location: "上海"})
+	if err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	select {
+	case events := <-received:
+		if len(events) != 1 || events[0].TrackingNo != "FK001" || events[0].Status != TrackingStatusDelivered {
+			t.Fatalf("unexpected events: %+v", events)
+		}
+		if events[0].OccurredAt.IsZero() {
+			t.Fatal("occurred_at should default to now")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestFakeCarrier_VerifyWebhookRejectsBadSignature(t *testing.T) {
+	carrier := NewFakeCarrier("fake", "secret", "")
+	body := []byte(`{"events":[{"tracking_no":"FK001","status":"delivered"}]}`)
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, SignFakePayload([]byte("other"), body))
+	if _, err := carrier.VerifyWebhook(header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
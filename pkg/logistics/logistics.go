@@ -0,0 +1,93 @@
+package logistics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrackingStatus 物流轨迹状态
+type TrackingStatus string
+
+const (
+	TrackingStatusPickedUp       TrackingStatus = "picked_up"        // 已揽收
+	TrackingStatusInTransit      TrackingStatus = "in_transit"       // 运输中
+	TrackingStatusOutForDelivery TrackingStatus = "out_for_delivery" // 派送中
+	TrackingStatusDelivered      TrackingStatus = "delivered"        // 已签收
+	TrackingStatusException      TrackingStatus = "exception"        // 异常（拒收、丢件等）
+)
+
+// 常见错误
+var (
+	ErrInvalidSignature = errors.New("logistics: invalid webhook signature")
+	ErrCarrierNotFound  = errors.New("logistics: carrier not found")
+)
+
+// TrackingEvent 物流轨迹事件
+type TrackingEvent struct {
+	TrackingNo  string         `json:"tracking_no"` // 运单号
+	Status      TrackingStatus `json:"status"`      // 轨迹状态
+	Location    string         `json:"location"`    // 所在地点
+	Description string         `json:"description"` // 轨迹描述
+	OccurredAt  time.Time      `json:"occurred_at"` // 发生时间
+}
+
+// Carrier 物流公司接口
+// 每个物流公司（顺丰、中通等）实现该接口即可接入轨迹推送
+type Carrier interface {
+	Code() string                                                            // 物流公司编码
+	VerifyWebhook(header http.Header, body []byte) ([]*TrackingEvent, error) // 验证轨迹推送签名并解析
+}
+
+// Simulator 可模拟推送轨迹的物流公司（仅本地模拟实现）
+type Simulator interface {
+	Emit(event *TrackingEvent) error
+}
+
+// Registry 物流公司注册表
+type Registry struct {
+	mu       sync.RWMutex
+	carriers map[string]Carrier
+}
+
+// NewRegistry 创建物流公司注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		carriers: make(map[string]Carrier),
+	}
+}
+
+// Register 注册物流公司
+func (r *Registry) Register(carrier Carrier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.carriers[carrier.Code()] = carrier
+}
+
+// Get 按编码获取物流公司
+func (r *Registry) Get(code string) (Carrier, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	carrier, ok := r.carriers[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCarrierNotFound, code)
+	}
+	return carrier, nil
+}
+
+// Codes 获取所有已注册的物流公司编码
+func (r *Registry) Codes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := make([]string, 0, len(r.carriers))
+	for code := range r.carriers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,78 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 消息渠道
+const (
+	ChannelEmail = "email" // 邮件
+	ChannelSMS   = "sms"   // 短信
+)
+
+// ErrChannelNotFound 消息渠道未注册
+var ErrChannelNotFound = errors.New("message channel not found")
+
+// Message 一条待发送的消息
+type Message struct {
+	Channel string `json:"channel"`           // 消息渠道
+	To      string `json:"to"`                // 收件人，邮箱地址或手机号
+	Subject string `json:"subject,omitempty"` // 主题，短信没有主题
+	Body    string `json:"body"`              // 正文
+}
+
+// Sender 消息发送渠道
+// 接入真实的邮件、短信网关时实现该接口并注册到Registry
+type Sender interface {
+	Channel() string                              // 渠道名称
+	Send(ctx context.Context, msg *Message) error // 发送消息
+}
+
+// Registry 消息渠道注册表
+type Registry struct {
+	mu      sync.RWMutex
+	senders map[string]Sender
+}
+
+// NewRegistry 创建消息渠道注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		senders: make(map[string]Sender),
+	}
+}
+
+// Register 注册消息渠道
+func (r *Registry) Register(sender Sender) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.senders[sender.Channel()] = sender
+}
+
+// Get 按名称获取消息渠道
+func (r *Registry) Get(channel string) (Sender, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sender, ok := r.senders[channel]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, channel)
+	}
+	return sender, nil
+}
+
+// Channels 获取所有已注册的渠道名称
+func (r *Registry) Channels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	channels := make([]string, 0, len(r.senders))
+	for channel := range r.senders {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
@@ -0,0 +1,67 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Outbox 本地发件箱
+// 把消息以JSON行写入文件或标准输出，开发和测试环境用它代替真实的邮件、短信网关
+type Outbox struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// outboxRecord 发件箱中的一行
+type outboxRecord struct {
+	*Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+// NewOutbox 创建本地发件箱
+func NewOutbox(w io.Writer) *Outbox {
+	return &Outbox{w: w}
+}
+
+// Sender 获取写入本发件箱的消息渠道，多个渠道共用一个发件箱时按行写入不会交错
+func (o *Outbox) Sender(channel string) Sender {
+	return &outboxSender{
+		channel: channel,
+		outbox:  o,
+	}
+}
+
+// write 写入一条消息
+func (o *Outbox) write(msg *Message) error {
+	line, err := json.Marshal(outboxRecord{Message: msg, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, err = o.w.Write(append(line, '\n'))
+	return err
+}
+
+// outboxSender 写入本地发件箱的消息渠道
+type outboxSender struct {
+	channel string
+	outbox  *Outbox
+}
+
+// Channel 渠道名称
+func (s *outboxSender) Channel() string {
+	return s.channel
+}
+
+// Send 写入发件箱
+func (s *outboxSender) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.outbox.write(msg)
+}
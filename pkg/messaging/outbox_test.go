@@ -0,0 +1,58 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestOutbox_WritesOneJSONLinePerMessage(t *testing.T) {
+	var buf bytes.Buffer
+	outbox := NewOutbox(&buf)
+
+	registry := NewRegistry()
+	registry.Register(outbox.Sender(ChannelEmail))
+	registry.Register(outbox.Sender(ChannelSMS))
+
+	email, err := registry.Get(ChannelEmail)
+	if err != nil {
+		t.Fatalf("get email sender: %v", err)
+	}
+	sms, err := registry.Get(ChannelSMS)
+	if err != nil {
+		t.Fatalf("get sms sender: %v", err)
+	}
+
+	if err := email.Send(context.Background(), &Message{Channel: ChannelEmail, To: "a@example.com", Subject: "hi", Body: "hello"}); err != nil {
+		t.Fatalf("send email: %v", err)
+	}
+	if err := sms.Send(context.Background(), &Message{Channel: ChannelSMS, To: "13800000000", Body: "hello"}); err != nil {
+		t.Fatalf("send sms: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("outbox has %d lines, want 2", len(lines))
+	}
+	var record struct {
+		Channel string `json:"channel"`
+		To      string `json:"to"`
+		Subject string `json:"subject"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("decode line: %v", err)
+	}
+	if record.Channel != ChannelEmail || record.To != "a@example.com" || record.Subject != "hi" {
+		t.Fatalf("unexpected record %+v", record)
+	}
+}
+
+func TestRegistry_UnknownChannel(t *testing.T) {
+	_, err := NewRegistry().Get("fax")
+	if !errors.Is(err, ErrChannelNotFound) {
+		t.Fatalf("err = %v, want ErrChannelNotFound", err)
+	}
+}
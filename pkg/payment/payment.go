@@ -0,0 +1,142 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChargeStatus 支付单状态
+type ChargeStatus string
+
+const (
+	ChargeStatusPending   ChargeStatus = "pending"   // 等待用户支付
+	ChargeStatusSucceeded ChargeStatus = "succeeded" // 支付成功
+	ChargeStatusFailed    ChargeStatus = "failed"    // 支付失败
+	ChargeStatusClosed    ChargeStatus = "closed"    // 已关闭
+)
+
+// RefundStatus 退款状态
+type RefundStatus string
+
+const (
+	RefundStatusProcessing RefundStatus = "processing" // 退款处理中
+	RefundStatusSucceeded  RefundStatus = "succeeded"  // 退款成功
+	RefundStatusFailed     RefundStatus = "failed"     // 退款失败
+)
+
+// 常见错误
+var (
+	ErrInvalidSignature = errors.New("payment: invalid notify signature")
+	ErrChargeNotFound   = errors.New("payment: charge not found")
+	ErrChargePaid       = errors.New("payment: charge already paid")
+	ErrProviderNotFound = errors.New("payment: provider not found")
+)
+
+// ChargeRequest 创建支付单请求
+type ChargeRequest struct {
+	OutTradeNo string  // 商户侧交易号（唯一）
+	Amount     float64 // 支付金额（元）
+	Subject    string  // 商品描述
+	NotifyURL  string  // 异步通知地址
+}
+
+// Charge 渠道支付单
+type Charge struct {
+	OutTradeNo string       `json:"out_trade_no"` // 商户侧交易号
+	TradeNo    string       `json:"trade_no"`     // 渠道交易号
+	Amount     float64      `json:"amount"`       // 支付金额
+	Status     ChargeStatus `json:"status"`       // 支付状态
+	PayURL     string       `json:"pay_url"`      // 收银台地址
+	PaidAt     *time.Time   `json:"paid_at"`      // 支付完成时间
+}
+
+// RefundRequest 退款请求
+type RefundRequest struct {
+	OutTradeNo  string  // 原商户侧交易号
+	OutRefundNo string  // 商户侧退款单号（唯一），重复提交时渠道按它去重
+	Amount      float64 // 退款金额
+	Reason      string  // 退款原因
+}
+
+// RefundResult 退款结果
+type RefundResult struct {
+	OutRefundNo string       `json:"out_refund_no"` // 商户侧退款单号
+	RefundNo    string       `json:"refund_no"`     // 渠道退款单号
+	Amount      float64      `json:"amount"`        // 退款金额
+	Status      RefundStatus `json:"status"`        // 退款状态
+}
+
+// Notification 渠道异步通知（验签通过后的内容）
+type Notification struct {
+	OutTradeNo string       `json:"out_trade_no"` // 商户侧交易号
+	TradeNo    string       `json:"trade_no"`     // 渠道交易号
+	Amount     float64      `json:"amount"`       // 实付金额
+	Status     ChargeStatus `json:"status"`       // 支付结果
+	PaidAt     time.Time    `json:"paid_at"`      // 支付时间
+}
+
+// Provider 支付渠道接口
+// 每个第三方支付（支付宝、微信等）实现该接口即可接入
+type Provider interface {
+	Name() string                                                        // 渠道名称
+	CreateCharge(req *ChargeRequest) (*Charge, error)                    // 创建支付单
+	QueryCharge(outTradeNo string) (*Charge, error)                      // 查询支付单
+	CloseCharge(outTradeNo string) error                                 // 关闭未支付的支付单，已支付时返回ErrChargePaid
+	Refund(req *RefundRequest) (*RefundResult, error)                    // 发起退款，相同退款单号重复提交只退一次
+	VerifyNotify(header http.Header, body []byte) (*Notification, error) // 验证异步通知签名并解析
+}
+
+// Simulator 可模拟用户付款的渠道（仅沙箱实现）
+type Simulator interface {
+	Simulate(outTradeNo string, success bool) error
+}
+
+// Registry 支付渠道注册表
+// 按支付方式（alipay、wechat、balance）查找对应渠道
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewRegistry 创建支付渠道注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+	}
+}
+
+// Register 注册支付方式对应的渠道
+func (r *Registry) Register(method string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[method] = provider
+}
+
+// Get 获取支付方式对应的渠道
+func (r *Registry) Get(method string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, method)
+	}
+	return provider, nil
+}
+
+// Methods 获取所有已注册的支付方式
+func (r *Registry) Methods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]string, 0, len(r.providers))
+	for method := range r.providers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
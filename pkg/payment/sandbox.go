@@ -0,0 +1,262 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SandboxSignatureHeader 沙箱通知签名请求头
+const SandboxSignatureHeader = "X-Sandbox-Signature"
+
+// SandboxProvider 本地沙箱支付渠道
+// 不连接任何真实支付网络，支付单保存在内存中；
+// 调用Simulate模拟用户付款后，会像真实渠道一样向NotifyURL异步推送签名通知
+type SandboxProvider struct {
+	name       string
+	secret     []byte
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	charges map[string]*sandboxCharge
+	seq     uint64
+}
+
+// sandboxCharge 沙箱内部支付单
+type sandboxCharge struct {
+	Charge
+	notifyURL string
+	refunded  float64
+	refunds   map[string]*RefundResult // 按商户侧退款单号记录的退款
+}
+
+// NewSandboxProvider 创建沙箱支付渠道
+// name: 渠道名称，secret: 通知签名密钥
+func NewSandboxProvider(name, secret string) *SandboxProvider {
+	return &SandboxProvider{
+		name:   name,
+		secret: []byte(secret),
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+		charges: make(map[string]*sandboxCharge),
+	}
+}
+
+// Name 渠道名称
+func (p *SandboxProvider) Name() string {
+	return p.name
+}
+
+// CreateCharge 创建支付单
+func (p *SandboxProvider) CreateCharge(req *ChargeRequest) (*Charge, error) {
+	if req.OutTradeNo == "" {
+		return nil, fmt.Errorf("payment: out_trade_no is required")
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("payment: amount must be positive")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 同一交易号重复下单直接返回已有支付单
+	if existing, ok := p.charges[req.OutTradeNo]; ok {
+		charge := existing.Charge
+		return &charge, nil
+	}
+
+	tradeNo := fmt.Sprintf("SBX%s%06d", time.Now().Format("20060102150405"), atomic.AddUint64(&p.seq, 1)%1000000)
+	charge := &sandboxCharge{
+		Charge: Charge{
+			OutTradeNo: req.OutTradeNo,
+			TradeNo:    tradeNo,
+			Amount:     req.Amount,
+			Status:     ChargeStatusPending,
+			PayURL:     fmt.Sprintf("sandbox://%s/pay/%s", p.name, req.OutTradeNo),
+		},
+		notifyURL: req.NotifyURL,
+	}
+	p.charges[req.OutTradeNo] = charge
+
+	result := charge.Charge
+	return &result, nil
+}
+
+// QueryCharge 查询支付单
+func (p *SandboxProvider) QueryCharge(outTradeNo string) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[outTradeNo]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+
+	result := charge.Charge
+	return &result, nil
+}
+
+// CloseCharge 关闭未支付的支付单，关闭后不能再支付
+// 已失败或已关闭的支付单直接返回成功
+func (p *SandboxProvider) CloseCharge(outTradeNo string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[outTradeNo]
+	if !ok {
+		return ErrChargeNotFound
+	}
+	if charge.Status == ChargeStatusSucceeded {
+		return ErrChargePaid
+	}
+	if charge.Status == ChargeStatusPending {
+		charge.Status = ChargeStatusClosed
+	}
+	return nil
+}
+
+// Refund 发起退款（沙箱中同步成功）
+func (p *SandboxProvider) Refund(req *RefundRequest) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[req.OutTradeNo]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	if req.OutRefundNo == "" {
+		return nil, fmt.Errorf("payment: out refund no is required")
+	}
+
+	// 相同退款单号重复提交时返回第一次的结果，不会重复退款
+	if existing, ok := charge.refunds[req.OutRefundNo]; ok {
+		if math.Abs(existing.Amount-req.Amount) > 0.001 {
+			return nil, fmt.Errorf("payment: refund %s already exists with amount %.2f", req.OutRefundNo, existing.Amount)
+		}
+		result := *existing
+		return &result, nil
+	}
+
+	if charge.Status != ChargeStatusSucceeded {
+		return nil, fmt.Errorf("payment: charge %s is not paid", req.OutTradeNo)
+	}
+	if req.Amount <= 0 || charge.refunded+req.Amount > charge.Amount+0.001 {
+		return nil, fmt.Errorf("payment: invalid refund amount %.2f", req.Amount)
+	}
+
+	charge.refunded += req.Amount
+	result := &RefundResult{
+		OutRefundNo: req.OutRefundNo,
+		RefundNo:    fmt.Sprintf("SBXR%s%06d", time.Now().Format("20060102150405"), atomic.AddUint64(&p.seq, 1)%1000000),
+		Amount:      req.Amount,
+		Status:      RefundStatusSucceeded,
+	}
+	if charge.refunds == nil {
+		charge.refunds = make(map[string]*RefundResult)
+	}
+	charge.refunds[req.OutRefundNo] = result
+
+	copied := *result
+	return &copied, nil
+}
+
+// VerifyNotify 验证异步通知签名
+func (p *SandboxProvider) VerifyNotify(header http.Header, body []byte) (*Notification, error) {
+	signature := header.Get(SandboxSignatureHeader)
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(SignSandboxPayload(p.secret, body))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var notification Notification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, fmt.Errorf("payment: invalid notify body: %w", err)
+	}
+	return &notification, nil
+}
+
+// Simulate 模拟用户在收银台完成（或放弃）支付
+// 更新沙箱支付单状态后，异步向NotifyURL推送签名通知
+func (p *SandboxProvider) Simulate(outTradeNo string, success bool) error {
+	p.mu.Lock()
+	charge, ok := p.charges[outTradeNo]
+	if !ok {
+		p.mu.Unlock()
+		return ErrChargeNotFound
+	}
+	if charge.Status != ChargeStatusPending {
+		p.mu.Unlock()
+		return fmt.Errorf("payment: charge %s already %s", outTradeNo, charge.Status)
+	}
+
+	now := time.Now()
+	if success {
+		charge.Status = ChargeStatusSucceeded
+		charge.PaidAt = &now
+	} else {
+		charge.Status = ChargeStatusFailed
+	}
+	notification := Notification{
+		OutTradeNo: charge.OutTradeNo,
+		TradeNo:    charge.TradeNo,
+		Amount:     charge.Amount,
+		Status:     charge.Status,
+		PaidAt:     now,
+	}
+	notifyURL := charge.notifyURL
+	p.mu.Unlock()
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	// 真实渠道的通知是异步的，这里同样在后台推送
+	go p.deliver(notifyURL, body)
+	return nil
+}
+
+// deliver 推送异步通知，失败时按固定间隔重试
+func (p *SandboxProvider) deliver(notifyURL string, body []byte) {
+	if notifyURL == "" {
+		return
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, notifyURL, bytes.NewReader(body))
+		if err != nil {
+			log.Printf("sandbox payment: build notify request failed: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SandboxSignatureHeader, SignSandboxPayload(p.secret, body))
+
+		resp, err := p.httpClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+			err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+
+		log.Printf("sandbox payment: notify attempt %d to %s failed: %v", attempt, notifyURL, err)
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// SignSandboxPayload 计算沙箱通知签名（HMAC-SHA256，十六进制）
+func SignSandboxPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSandboxProvider_VerifyNotify(t *testing.T) {
+	provider := NewSandboxProvider("sandbox", "secret")
+
+	body, _ := json.Marshal(Notification{
+		OutTradeNo: "20240101000000000001",
+		TradeNo:    "SBX1",
+		Amount:     99.5,
+		Status:     ChargeStatusSucceeded,
+	})
+
+	header := http.Header{}
+	header.Set(SandboxSignatureHeader, SignSandboxPayload([]byte("secret"), body))
+
+	notification, err := provider.VerifyNotify(header, body)
+	if err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+	if notification.OutTradeNo != "20240101000000000001" || notification.Amount != 99.5 {
+		t.Fatalf("unexpected notification: %+v", notification)
+	}
+
+	// 篡改报文后签名应失效
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)-2] = '0'
+	if _, err := provider.VerifyNotify(header, tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for tampered body, got %v", err)
+	}
+
+	// 使用其他密钥签名的报文应被拒绝
+	header.Set(SandboxSignatureHeader, SignSandboxPayload([]byte("other"), body))
+	if _, err := provider.VerifyNotify(header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+}
+
+func TestSandboxProvider_ChargeLifecycle(t *testing.T) {
+	provider := NewSandboxProvider("sandbox", "secret")
+
+	charge, err := provider.CreateCharge(&ChargeRequest{OutTradeNo: "T1", Amount: 100})
+	if err != nil {
+		t.Fatalf("create charge: %v", err)
+	}
+	if charge.Status != ChargeStatusPending || charge.TradeNo == "" {
+		t.Fatalf("unexpected charge: %+v", charge)
+	}
+
+	// 未支付不能退款
+	if _, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R1", Amount: 10}); err == nil {
+		t.Fatal("expected refund of unpaid charge to fail")
+	}
+
+	if err := provider.Simulate("T1", true); err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	queried, err := provider.QueryCharge("T1")
+	if err != nil || queried.Status != ChargeStatusSucceeded {
+		t.Fatalf("expected succeeded charge, got %+v, %v", queried, err)
+	}
+
+	if _, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R1", Amount: 60}); err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if _, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R2", Amount: 60}); err == nil {
+		t.Fatal("expected refund exceeding paid amount to fail")
+	}
+}
+
+func TestSandboxProvider_CloseCharge(t *testing.T) {
+	provider := NewSandboxProvider("sandbox", "secret")
+
+	if err := provider.CloseCharge("T1"); !errors.Is(err, ErrChargeNotFound) {
+		t.Fatalf("expected ErrChargeNotFound, got %v", err)
+	}
+
+	// 关闭后不能再支付
+	if _, err := provider.CreateCharge(&ChargeRequest{OutTradeNo: "T1", Amount: 100}); err != nil {
+		t.Fatalf("create charge: %v", err)
+	}
+	if err := provider.CloseCharge("T1"); err != nil {
+		t.Fatalf("close charge: %v", err)
+	}
+	if err := provider.Simulate("T1", true); err == nil {
+		t.Fatal("expected payment of closed charge to fail")
+	}
+
+	// 已支付的支付单不能关闭
+	if _, err := provider.CreateCharge(&ChargeRequest{OutTradeNo: "T2", Amount: 100}); err != nil {
+		t.Fatalf("create charge: %v", err)
+	}
+	if err := provider.Simulate("T2", true); err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+	if err := provider.CloseCharge("T2"); !errors.Is(err, ErrChargePaid) {
+		t.Fatalf("expected ErrChargePaid, got %v", err)
+	}
+}
+
+func TestSandboxProvider_RefundDeduplicatesOutRefundNo(t *testing.T) {
+	provider := NewSandboxProvider("sandbox", "secret")
+	if _, err := provider.CreateCharge(&ChargeRequest{OutTradeNo: "T1", Amount: 100}); err != nil {
+		t.Fatalf("create charge: %v", err)
+	}
+	if err := provider.Simulate("T1", true); err != nil {
+		t.Fatalf("simulate: %v", err)
+	}
+
+	first, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R1", Amount: 60})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+
+	// 重试同一笔退款返回第一次的结果，不会重复退款
+	retried, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R1", Amount: 60})
+	if err != nil {
+		t.Fatalf("retry refund: %v", err)
+	}
+	if retried.RefundNo != first.RefundNo {
+		t.Fatalf("retry returned refund %s, want %s", retried.RefundNo, first.RefundNo)
+	}
+	if _, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R1", Amount: 30}); err == nil {
+		t.Fatal("expected reused refund no with a different amount to fail")
+	}
+
+	// 不同退款单号的部分退款各自生效
+	if _, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R2", Amount: 40}); err != nil {
+		t.Fatalf("second partial refund: %v", err)
+	}
+	if _, err := provider.Refund(&RefundRequest{OutTradeNo: "T1", OutRefundNo: "R3", Amount: 0.01}); err == nil {
+		t.Fatal("expected refund exceeding paid amount to fail")
+	}
+}
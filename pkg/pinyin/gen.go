@@ -0,0 +1,101 @@
+//go:build ignore
+
+// gen 根据Perl Unicode::Collate的拼音排序数据生成汉字首字母表
+// 数据按拼音排序，FDD0-0041 到 FDD0-005A 标记首字母A-Z的开始，多音字只出现一次（常用读音）。
+// 用法：go run gen.go [Pinyin.pm路径]
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultSource = "/usr/share/perl/5.36.0/Unicode/Collate/CJK/Pinyin.pm"
+
+func main() {
+	source := defaultSource
+	if len(os.Args) > 1 {
+		source = os.Args[1]
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	// 1. 解析数据段
+	table := bytes.Repeat([]byte{'-'}, tableEnd-tableStart+1)
+	var letter byte
+	inData := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "__DATA__":
+			inData = true
+			continue
+		case line == "__END__":
+			inData = false
+		}
+		if !inData {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if strings.HasPrefix(field, "FDD0-") {
+				code, err := strconv.ParseUint(field[5:], 16, 32)
+				if err != nil {
+					log.Fatal(err)
+				}
+				letter = byte(code) + 'a' - 'A'
+				continue
+			}
+			code, err := strconv.ParseUint(field, 16, 32)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if code >= tableStart && code <= tableEnd && letter != 0 {
+				table[code-tableStart] = letter
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	// 2. 生成代码
+	var out bytes.Buffer
+	out.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage pinyin\n\n")
+	fmt.Fprintf(&out, "// initials U+%04X 到 U+%04X 汉字的拼音首字母，- 表示没有数据\n", tableStart, tableEnd)
+	out.WriteString("const initials = \"\" +\n")
+	for i := 0; i < len(table); i += 128 {
+		end := i + 128
+		if end > len(table) {
+			end = len(table)
+		}
+		fmt.Fprintf(&out, "\t%q", table[i:end])
+		if end < len(table) {
+			out.WriteString(" +")
+		}
+		out.WriteString("\n")
+	}
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("initials_table.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// 与 pinyin.go 中的范围一致
+const (
+	tableStart = 0x4E00
+	tableEnd   = 0x9FFF
+)
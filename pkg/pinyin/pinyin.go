@@ -0,0 +1,41 @@
+// Package pinyin 汉字拼音首字母转换，用于搜索建议的首字母匹配
+package pinyin
+
+//go:generate go run gen.go
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 首字母表覆盖的汉字范围（CJK统一汉字基本区）
+const (
+	tableStart = 0x4E00
+	tableEnd   = 0x9FFF
+)
+
+// Initial 汉字的拼音首字母（小写），不是汉字或没有数据时返回false
+// 多音字取常用读音
+func Initial(r rune) (byte, bool) {
+	if r < tableStart || r > tableEnd {
+		return 0, false
+	}
+	letter := initials[r-tableStart]
+	return letter, letter != '-'
+}
+
+// Initials 字符串的拼音首字母
+// 汉字转换为拼音首字母，字母和数字转为小写保留，其他字符忽略，如 "华为Mate 60" 转换为 "hwmate60"
+func Initials(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if letter, ok := Initial(r); ok {
+			b.WriteByte(letter)
+			continue
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package pinyin
+
+import "testing"
+
+func TestInitials(t *testing.T) {
+	cases := map[string]string{
+		"华为手机":      "hwsj",
+		"华为Mate 60": "hwmate60",
+		"Apple":     "apple",
+		"T恤（男款）":    "txnk",
+		"":          "",
+	}
+	for in, want := range cases {
+		if got := Initials(in); got != want {
+			t.Errorf("Initials(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package push
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 连接参数
+const (
+	writeWait      = 10 * time.Second  // 单条消息的写超时
+	pongWait       = 60 * time.Second  // 等待客户端pong的时间，超时视为断开
+	pingPeriod     = pongWait * 9 / 10 // 发送ping的间隔，需小于pongWait
+	maxMessageSize = 512               // 客户端消息的最大长度，客户端只需要回复pong
+	sendBufferSize = 64                // 每个连接的待发送消息缓冲
+)
+
+// client 一个WebSocket连接
+type client struct {
+	userID uint
+	conn   *websocket.Conn
+	send   chan []byte
+}
+
+// Hub 管理本实例的WebSocket连接，按用户推送消息
+// 一个用户可以同时有多个连接（多个标签页或设备），消息推送给该用户的所有连接
+type Hub struct {
+	mu       sync.RWMutex
+	clients  map[uint]map[*client]struct{}
+	upgrader websocket.Upgrader
+}
+
+// NewHub 创建连接管理器
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[uint]map[*client]struct{}),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// 连接通过令牌认证而不是Cookie，不需要限制来源
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Serve 将HTTP请求升级为WebSocket连接并登记到用户名下，阻塞到连接断开
+// 升级失败时已向客户端返回错误响应
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, userID uint) error {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return err
+	}
+
+	c := &client{
+		userID: userID,
+		conn:   conn,
+		send:   make(chan []byte, sendBufferSize),
+	}
+	h.register(c)
+
+	go h.writePump(c)
+	h.readPump(c)
+	return nil
+}
+
+// Push 推送消息给本实例上该用户的所有连接
+func (h *Hub) Push(userID uint, payload []byte) error {
+	h.Deliver(userID, payload)
+	return nil
+}
+
+// Deliver 推送消息给本实例上该用户的所有连接
+// 连接的发送缓冲已满说明客户端处理不过来，直接断开，客户端重连后从收件箱补齐
+func (h *Hub) Deliver(userID uint, payload []byte) {
+	h.mu.RLock()
+	var slow []*client
+	for c := range h.clients[userID] {
+		select {
+		case c.send <- payload:
+		default:
+			slow = append(slow, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range slow {
+		log.Printf("push: send buffer of user %d is full, disconnect", userID)
+		h.unregister(c)
+	}
+}
+
+// Online 本实例上该用户的连接数
+func (h *Hub) Online(userID uint) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID])
+}
+
+// register 登记连接
+func (h *Hub) register(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[c.userID] == nil {
+		h.clients[c.userID] = make(map[*client]struct{})
+	}
+	h.clients[c.userID][c] = struct{}{}
+}
+
+// unregister 注销连接并关闭发送通道，写协程随后关闭连接
+// 可以重复调用，只有第一次生效
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := h.clients[c.userID]
+	if _, ok := clients[c]; !ok {
+		return
+	}
+	delete(clients, c)
+	if len(clients) == 0 {
+		delete(h.clients, c.userID)
+	}
+	close(c.send)
+}
+
+// readPump 读取客户端消息，只用于处理pong和发现连接断开
+func (h *Hub) readPump(c *client) {
+	defer func() {
+		h.unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump 发送消息并定时ping，发送通道关闭后关闭连接
+func (h *Hub) writePump(c *client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				h.unregister(c)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				h.unregister(c)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package push
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestHub_PushDeliversToUserConnections(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Serve(w, r, 1)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Online(1) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("connection not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	hub.Push(2, []byte(`{"id":2}`))
+	hub.Push(1, []byte(`{"id":1}`))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, payload, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(payload) != `{"id":1}` {
+		t.Fatalf("payload = %s, want message of user 1 only", payload)
+	}
+}
+
+func TestHub_UnregisterOnDisconnect(t *testing.T) {
+	hub := NewHub()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Serve(w, r, 1)
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Online(1) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("connection not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	conn.Close()
+	deadline = time.Now().Add(time.Second)
+	for hub.Online(1) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("connection not unregistered after disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
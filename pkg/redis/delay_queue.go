@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 领取到期消息Lua脚本
+// 先把确认超时的消息放回队列重新投递，再把到期消息移入处理中集合
+const claimDueScript = `
+local queue = KEYS[1]
+local processing = KEYS[2]
+local now = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local deadline = tonumber(ARGV[3])
+
+local expired = redis.call('ZRANGEBYSCORE', processing, '-inf', now, 'LIMIT', 0, limit)
+for _, member in ipairs(expired) do
+    redis.call('ZREM', processing, member)
+    redis.call('ZADD', queue, now, member)
+end
+
+local due = redis.call('ZRANGEBYSCORE', queue, '-inf', now, 'LIMIT', 0, limit)
+for _, member in ipairs(due) do
+    redis.call('ZREM', queue, member)
+    redis.call('ZADD', processing, deadline, member)
+end
+
+return due
+`
+
+// DelayQueue 基于ZSET的延时队列
+// 消息以到期时间为分数存入队列，消费者领取到期消息后需要确认；
+// 超过确认时限未确认的消息会被重新投递，保证至少一次消费
+type DelayQueue struct {
+	redis      *RedisManager
+	queueKey   string
+	processKey string
+	ackTimeout time.Duration
+}
+
+// NewDelayQueue 创建延时队列
+// name用于区分不同业务的队列，ackTimeout为领取后的确认时限
+func (rm *RedisManager) NewDelayQueue(name string, ackTimeout time.Duration) *DelayQueue {
+	// 使用hash tag保证集群模式下两个key落在同一个槽位，Lua脚本才能同时操作
+	return &DelayQueue{
+		redis:      rm,
+		queueKey:   fmt.Sprintf("delay:{%s}:queue", name),
+		processKey: fmt.Sprintf("delay:{%s}:processing", name),
+		ackTimeout: ackTimeout,
+	}
+}
+
+// Push 添加消息，在dueAt到期；消息已存在时更新到期时间
+func (dq *DelayQueue) Push(member string, dueAt time.Time) error {
+	return dq.redis.client.ZAdd(dq.redis.ctx, dq.queueKey, &redis.Z{
+		Score:  float64(dueAt.UnixMilli()),
+		Member: member,
+	}).Err()
+}
+
+// Remove 删除消息，无论消息是否已被领取
+func (dq *DelayQueue) Remove(member string) error {
+	pipe := dq.redis.client.TxPipeline()
+	pipe.ZRem(dq.redis.ctx, dq.queueKey, member)
+	pipe.ZRem(dq.redis.ctx, dq.processKey, member)
+	_, err := pipe.Exec(dq.redis.ctx)
+	return err
+}
+
+// Claim 领取最多limit条到期消息
+// 领取后的消息在确认时限内不会被其他消费者领取
+func (dq *DelayQueue) Claim(limit int) ([]string, error) {
+	now := time.Now()
+	return dq.redis.client.Eval(dq.redis.ctx, claimDueScript,
+		[]string{dq.queueKey, dq.processKey},
+		now.UnixMilli(), limit, now.Add(dq.ackTimeout).UnixMilli(),
+	).StringSlice()
+}
+
+// Ack 确认消息已处理完成
+func (dq *DelayQueue) Ack(members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	return dq.redis.client.ZRem(dq.redis.ctx, dq.processKey, values...).Err()
+}
+
+// Len 获取队列中未领取的消息数
+func (dq *DelayQueue) Len() (int64, error) {
+	return dq.redis.client.ZCard(dq.redis.ctx, dq.queueKey).Result()
+}
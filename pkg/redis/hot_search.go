@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 热门搜索词的key使用同一个hash tag，集群模式下才能合并多天的有序集合
+const (
+	hotSearchDayKeyPrefix = "hot:{search}:day:"
+	hotSearchMergedKey    = "hot:{search}:merged"
+)
+
+// hotSearchMergedTTL 合并结果的缓存时间
+const hotSearchMergedTTL = time.Minute
+
+// HotSearchManager 热门搜索词管理
+// 每天一个有序集合记录各搜索词的次数，热搜榜按天衰减合并最近几天的次数：
+// 今天的权重为1，每早一天乘以decay，旧的热词会逐渐被新的热词替代
+type HotSearchManager struct {
+	redis *RedisManager
+	days  int     // 合并的天数
+	decay float64 // 每早一天的权重衰减系数
+}
+
+// NewHotSearchManager 创建热门搜索词管理器
+// days为合并的天数，decay为每早一天的权重衰减系数（0-1）
+func NewHotSearchManager(redis *RedisManager, days int, decay float64) *HotSearchManager {
+	if days < 1 {
+		days = 1
+	}
+	return &HotSearchManager{redis: redis, days: days, decay: decay}
+}
+
+// RecordSearch 记录一次搜索
+func (hsm *HotSearchManager) RecordSearch(keyword string) error {
+	key := hotSearchDayKey(time.Now())
+
+	pipe := hsm.redis.client.Pipeline()
+	pipe.ZIncrBy(hsm.redis.ctx, key, 1, keyword)
+	// 超过合并天数的集合不再需要
+	pipe.Expire(hsm.redis.ctx, key, time.Duration(hsm.days+1)*24*time.Hour)
+	_, err := pipe.Exec(hsm.redis.ctx)
+	return err
+}
+
+// GetHotSearches 获取热门搜索词，按衰减后的次数倒序
+// 合并结果缓存一分钟，避免每次请求都合并多个有序集合
+func (hsm *HotSearchManager) GetHotSearches(limit int) ([]string, error) {
+	exists, err := hsm.redis.client.Exists(hsm.redis.ctx, hotSearchMergedKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if exists == 0 {
+		now := time.Now()
+		store := &redis.ZStore{Aggregate: "SUM"}
+		weight := 1.0
+		for i := 0; i < hsm.days; i++ {
+			store.Keys = append(store.Keys, hotSearchDayKey(now.AddDate(0, 0, -i)))
+			store.Weights = append(store.Weights, weight)
+			weight *= hsm.decay
+		}
+
+		pipe := hsm.redis.client.TxPipeline()
+		pipe.ZUnionStore(hsm.redis.ctx, hotSearchMergedKey, store)
+		pipe.Expire(hsm.redis.ctx, hotSearchMergedKey, hotSearchMergedTTL)
+		if _, err := pipe.Exec(hsm.redis.ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return hsm.redis.client.ZRevRange(hsm.redis.ctx, hotSearchMergedKey, 0, int64(limit-1)).Result()
+}
+
+// hotSearchDayKey 某一天的搜索次数有序集合
+func hotSearchDayKey(day time.Time) string {
+	return hotSearchDayKeyPrefix + day.Format("20060102")
+}
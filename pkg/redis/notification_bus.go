@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// busMessage 通过Redis频道传递的推送消息
+type busMessage struct {
+	UserID  uint            `json:"user_id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// NotificationBus 基于Redis发布订阅的推送总线
+// 用户的WebSocket连接可能在任意实例上，推送消息发布到频道，每个实例订阅后推送给本实例上的连接
+type NotificationBus struct {
+	redis   *RedisManager
+	channel string
+}
+
+// NewNotificationBus 创建推送总线
+func NewNotificationBus(rm *RedisManager, channel string) *NotificationBus {
+	return &NotificationBus{
+		redis:   rm,
+		channel: channel,
+	}
+}
+
+// Push 发布推送消息，payload需要是JSON
+func (b *NotificationBus) Push(userID uint, payload []byte) error {
+	data, err := json.Marshal(busMessage{UserID: userID, Payload: payload})
+	if err != nil {
+		return err
+	}
+	return b.redis.client.Publish(b.redis.ctx, b.channel, data).Err()
+}
+
+// Subscribe 订阅推送消息并交给deliver处理，阻塞到ctx取消
+// 断线期间发布的消息会丢失，发布订阅只用于实时推送，通知本身保存在数据库中
+func (b *NotificationBus) Subscribe(ctx context.Context, deliver func(userID uint, payload []byte)) error {
+	var pubsub *redis.PubSub
+	if b.redis.isCluster {
+		pubsub = b.redis.clusterClient.Subscribe(ctx, b.channel)
+	} else {
+		pubsub = b.redis.singleClient.Subscribe(ctx, b.channel)
+	}
+	defer pubsub.Close()
+
+	// 等待订阅确认，订阅失败时返回错误
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	// Channel断线后自动重连重新订阅
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			var m busMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				log.Printf("notification bus: invalid message dropped: %v", err)
+				continue
+			}
+			deliver(m.UserID, m.Payload)
+		}
+	}
+}
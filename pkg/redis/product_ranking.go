@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 商品排行的key使用同一个hash tag，集群模式下才能合并多个时间桶
+const productRankingKeyPrefix = "rank:{product}:"
+
+// productRankingMergedTTL 合并结果的缓存时间
+const productRankingMergedTTL = time.Minute
+
+// RankingWindow 排行榜的时间窗口
+// 按时间桶记录热度，查询时合并窗口内的全部时间桶，越早的桶权重越低（按半衰期指数衰减）
+type RankingWindow struct {
+	Name     string        // 窗口名称
+	Bucket   time.Duration // 时间桶长度
+	Buckets  int           // 窗口包含的时间桶数量
+	HalfLife time.Duration // 热度半衰期
+	Layout   string        // 时间桶key中的时间格式
+}
+
+// 预定义的排行榜时间窗口
+var (
+	RankingDaily  = RankingWindow{Name: "daily", Bucket: time.Hour, Buckets: 24, HalfLife: 6 * time.Hour, Layout: "2006010215"}
+	RankingWeekly = RankingWindow{Name: "weekly", Bucket: 24 * time.Hour, Buckets: 7, HalfLife: 48 * time.Hour, Layout: "20060102"}
+)
+
+// ProductRankingManager 商品热度排行管理
+// 每个热度信号同时计入全站排行和商品所属分类的排行，以及每个时间窗口当前的时间桶
+type ProductRankingManager struct {
+	redis   *RedisManager
+	windows []RankingWindow
+}
+
+// NewProductRankingManager 创建商品热度排行管理器
+func NewProductRankingManager(redis *RedisManager) *ProductRankingManager {
+	return &ProductRankingManager{
+		redis:   redis,
+		windows: []RankingWindow{RankingDaily, RankingWeekly},
+	}
+}
+
+// Record 记录商品的热度
+// categoryID为0时只计入全站排行
+func (prm *ProductRankingManager) Record(productID, categoryID uint, score float64) error {
+	now := time.Now()
+	member := strconv.FormatUint(uint64(productID), 10)
+
+	pipe := prm.redis.client.Pipeline()
+	for _, window := range prm.windows {
+		ttl := time.Duration(window.Buckets+1) * window.Bucket
+		scopes := []string{rankingScope(0)}
+		if categoryID != 0 {
+			scopes = append(scopes, rankingScope(categoryID))
+		}
+		for _, scope := range scopes {
+			key := rankingBucketKey(window, scope, now)
+			pipe.ZIncrBy(prm.redis.ctx, key, score, member)
+			pipe.Expire(prm.redis.ctx, key, ttl)
+		}
+	}
+	_, err := pipe.Exec(prm.redis.ctx)
+	return err
+}
+
+// Top 获取排行榜前limit个商品ID和衰减后的热度
+// window为 daily 或 weekly，categoryID为0时为全站排行；合并结果缓存一分钟
+func (prm *ProductRankingManager) Top(window string, categoryID uint, limit int) ([]uint, []float64, error) {
+	w, ok := prm.window(window)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown ranking window: %s", window)
+	}
+	scope := rankingScope(categoryID)
+	merged := productRankingKeyPrefix + "merged:" + w.Name + ":" + scope
+
+	// 1. 合并结果过期后重新合并时间桶
+	exists, err := prm.redis.client.Exists(prm.redis.ctx, merged).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists == 0 {
+		now := time.Now()
+		store := &redis.ZStore{Aggregate: "SUM"}
+		for i := 0; i < w.Buckets; i++ {
+			age := time.Duration(i) * w.Bucket
+			store.Keys = append(store.Keys, rankingBucketKey(w, scope, now.Add(-age)))
+			store.Weights = append(store.Weights, math.Pow(0.5, float64(age)/float64(w.HalfLife)))
+		}
+
+		pipe := prm.redis.client.TxPipeline()
+		pipe.ZUnionStore(prm.redis.ctx, merged, store)
+		pipe.Expire(prm.redis.ctx, merged, productRankingMergedTTL)
+		if _, err := pipe.Exec(prm.redis.ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// 2. 读取排行
+	items, err := prm.redis.client.ZRevRangeWithScores(prm.redis.ctx, merged, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]uint, 0, len(items))
+	scores := make([]float64, 0, len(items))
+	for _, item := range items {
+		member, _ := item.Member.(string)
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+		scores = append(scores, item.Score)
+	}
+	return ids, scores, nil
+}
+
+// window 按名称查找时间窗口
+func (prm *ProductRankingManager) window(name string) (RankingWindow, bool) {
+	for _, w := range prm.windows {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return RankingWindow{}, false
+}
+
+// rankingScope 排行范围：全站或某个分类
+func rankingScope(categoryID uint) string {
+	if categoryID == 0 {
+		return "all"
+	}
+	return fmt.Sprintf("cat:%d", categoryID)
+}
+
+// rankingBucketKey 某个时间窗口、排行范围在t所在时间桶的key
+func rankingBucketKey(window RankingWindow, scope string, t time.Time) string {
+	return productRankingKeyPrefix + window.Name + ":" + scope + ":" + t.Format(window.Layout)
+}
@@ -101,6 +101,11 @@ func (rm *RedisManager) GetClusterInfo() (map[string]interface{}, error) {
 	return info, nil
 }
 
+// Ping 检查Redis连接是否可用
+func (rm *RedisManager) Ping() error {
+	return rm.client.Ping(rm.ctx).Err()
+}
+
 // InventoryManager 库存管理器
 type InventoryManager struct {
 	redis *RedisManager
@@ -205,6 +210,31 @@ func (dl *DistributedLock) Unlock() error {
 	return nil
 }
 
+// 锁续期Lua脚本 - 只有持有者才能续期
+const refreshLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+else
+    return 0
+end
+`
+
+// Refresh 续期锁
+// 返回false表示锁已过期或被其他持有者获取
+func (dl *DistributedLock) Refresh() (bool, error) {
+	if !dl.acquired {
+		return false, nil
+	}
+	
+	result, err := dl.redis.client.Eval(dl.redis.ctx, refreshLockScript, []string{dl.key}, dl.value, dl.expiry.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	
+	dl.acquired = result == 1
+	return dl.acquired, nil
+}
+
 // 限流器Lua脚本 - 滑动窗口
 const rateLimiterScript = `
 local key = KEYS[1]
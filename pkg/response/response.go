@@ -22,6 +22,7 @@ const (
 	UNAUTHORIZED = 401    // 未授权
 	FORBIDDEN = 403       // 禁止访问
 	NOT_FOUND = 404       // 资源不存在
+	CONFLICT = 409        // 请求冲突
 )
 
 // Success 成功响应
@@ -82,6 +83,11 @@ func NotFound(c *gin.Context, message string) {
 	Error(c, NOT_FOUND, message)
 }
 
+// Conflict 请求冲突
+func Conflict(c *gin.Context, message string) {
+	Error(c, CONFLICT, message)
+}
+
 // InternalServerError 服务器内部错误
 func InternalServerError(c *gin.Context, message string) {
 	Error(c, ERROR, message)
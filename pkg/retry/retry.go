@@ -0,0 +1,60 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Policy 重试策略
+// 第n次重试前等待 Initial * 2^(n-1)，不超过Max
+type Policy struct {
+	Attempts int           // 最多执行次数（含第一次），小于1时按1次
+	Initial  time.Duration // 第一次重试前的等待时间
+	Max      time.Duration // 最长等待时间，0表示不限制
+}
+
+// Delay 第attempt次重试前的等待时间，attempt从1开始
+func (p Policy) Delay(attempt int) time.Duration {
+	if attempt < 1 || p.Initial <= 0 {
+		return 0
+	}
+
+	delay := p.Initial
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.Max > 0 && delay >= p.Max {
+			return p.Max
+		}
+	}
+	if p.Max > 0 && delay > p.Max {
+		return p.Max
+	}
+	return delay
+}
+
+// WithBackoff 执行fn，失败时按指数退避重试，返回最后一次的错误
+// 等待期间ctx取消时立即返回ctx的错误
+func WithBackoff(ctx context.Context, policy Policy, fn func() error) error {
+	attempts := policy.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(policy.Delay(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
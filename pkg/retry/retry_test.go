@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicy_DelayDoublesUpToMax(t *testing.T) {
+	p := Policy{Initial: 100 * time.Millisecond, Max: 500 * time.Millisecond}
+
+	cases := map[int]time.Duration{
+		0: 0,
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 500 * time.Millisecond,
+		9: 500 * time.Millisecond,
+	}
+	for attempt, want := range cases {
+		if got := p.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestWithBackoff_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := WithBackoff(context.Background(), Policy{Attempts: 3, Initial: time.Millisecond}, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("err = %v, calls = %d, want success after 3 calls", err, calls)
+	}
+}
+
+func TestWithBackoff_ReturnsLastError(t *testing.T) {
+	calls := 0
+	last := errors.New("last")
+	err := WithBackoff(context.Background(), Policy{Attempts: 2, Initial: time.Millisecond}, func() error {
+		calls++
+		if calls == 2 {
+			return last
+		}
+		return errors.New("first")
+	})
+	if err != last || calls != 2 {
+		t.Fatalf("err = %v, calls = %d, want last error after 2 calls", err, calls)
+	}
+}
+
+func TestWithBackoff_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := WithBackoff(ctx, Policy{Attempts: 5, Initial: time.Hour}, func() error {
+		calls++
+		cancel()
+		return errors.New("failed")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Fatalf("err = %v, calls = %d, want context.Canceled after 1 call", err, calls)
+	}
+}
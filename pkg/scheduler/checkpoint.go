@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"ryan-mall/pkg/redis"
+	"sync"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Checkpoint 任务断点存储
+// 批处理任务每完成一批就保存进度，中断后（如主实例切换）从断点继续
+type Checkpoint interface {
+	Load(job string) (string, error) // 读取断点，没有断点时返回空字符串
+	Save(job, value string) error    // 保存断点
+	Clear(job string) error          // 任务完整执行后清除断点
+}
+
+// memoryCheckpoint 进程内断点存储，用于单实例模式
+type memoryCheckpoint struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewMemoryCheckpoint 创建进程内断点存储
+func NewMemoryCheckpoint() Checkpoint {
+	return &memoryCheckpoint{values: make(map[string]string)}
+}
+
+func (c *memoryCheckpoint) Load(job string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[job], nil
+}
+
+func (c *memoryCheckpoint) Save(job, value string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[job] = value
+	return nil
+}
+
+func (c *memoryCheckpoint) Clear(job string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, job)
+	return nil
+}
+
+// redisCheckpoint Redis断点存储，主实例切换后新的主实例可以继续处理
+type redisCheckpoint struct {
+	rm     *redis.RedisManager
+	prefix string
+}
+
+// NewRedisCheckpoint 创建Redis断点存储
+func NewRedisCheckpoint(rm *redis.RedisManager, prefix string) Checkpoint {
+	return &redisCheckpoint{rm: rm, prefix: prefix}
+}
+
+func (c *redisCheckpoint) Load(job string) (string, error) {
+	value, err := c.rm.GetClient().Get(context.Background(), c.prefix+job).Result()
+	if err == goredis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+func (c *redisCheckpoint) Save(job, value string) error {
+	return c.rm.GetClient().Set(context.Background(), c.prefix+job, value, 0).Err()
+}
+
+func (c *redisCheckpoint) Clear(job string) error {
+	return c.rm.GetClient().Del(context.Background(), c.prefix+job).Err()
+}
@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job 定时任务
+type Job struct {
+	Name     string                          // 任务名称
+	Interval time.Duration                   // 执行间隔
+	Run      func(ctx context.Context) error // 任务逻辑，需要保证幂等
+}
+
+// Locker 选主使用的分布式锁
+// redis.DistributedLock 实现了该接口
+type Locker interface {
+	TryLock() (bool, error) // 尝试获取锁
+	Refresh() (bool, error) // 续期锁，返回false表示锁已丢失
+	Unlock() error          // 释放锁
+}
+
+// Scheduler 任务调度器
+// 多实例部署时通过分布式锁选主，只有主实例执行任务；
+// 未配置锁时以单实例模式运行，始终执行任务
+type Scheduler struct {
+	locker   Locker
+	leaseTTL time.Duration
+	jobs     []Job
+	leader   atomic.Bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// New 创建任务调度器
+// leaseTTL为锁的有效期，主实例每隔leaseTTL/3续期一次
+func New(locker Locker, leaseTTL time.Duration) *Scheduler {
+	return &Scheduler{
+		locker:   locker,
+		leaseTTL: leaseTTL,
+	}
+}
+
+// Add 注册任务，需要在Start之前调用
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// IsLeader 当前实例是否为主实例
+func (s *Scheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+// Start 启动选主和所有任务
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	if s.locker == nil {
+		s.leader.Store(true)
+	} else {
+		s.elect()
+		s.wg.Add(1)
+		go s.electLoop(ctx)
+	}
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.runLoop(ctx, job)
+	}
+}
+
+// Stop 停止所有任务并释放主实例锁
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+
+	if s.locker != nil && s.leader.Swap(false) {
+		if err := s.locker.Unlock(); err != nil {
+			log.Printf("scheduler: release leader lock failed: %v", err)
+		}
+	}
+}
+
+// electLoop 定期续期或竞争主实例锁
+func (s *Scheduler) electLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.elect()
+		}
+	}
+}
+
+// elect 主实例续期，从实例尝试获取锁
+func (s *Scheduler) elect() {
+	if s.leader.Load() {
+		ok, err := s.locker.Refresh()
+		if err != nil || !ok {
+			s.leader.Store(false)
+			log.Printf("scheduler: lost leadership: %v", err)
+		}
+		return
+	}
+
+	ok, err := s.locker.TryLock()
+	if err != nil {
+		log.Printf("scheduler: acquire leader lock failed: %v", err)
+		return
+	}
+	if ok {
+		s.leader.Store(true)
+		log.Println("scheduler: became leader")
+	}
+}
+
+// runLoop 按间隔执行任务，非主实例跳过
+func (s *Scheduler) runLoop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.IsLeader() {
+				continue
+			}
+			if err := job.Run(ctx); err != nil {
+				log.Printf("scheduler: job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLock 模拟多个实例共享的一把锁
+type fakeLock struct {
+	mu    *sync.Mutex
+	owner *string
+	id    string
+}
+
+func (l *fakeLock) TryLock() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if *l.owner != "" {
+		return false, nil
+	}
+	*l.owner = l.id
+	return true, nil
+}
+
+func (l *fakeLock) Refresh() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return *l.owner == l.id, nil
+}
+
+func (l *fakeLock) Unlock() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if *l.owner == l.id {
+		*l.owner = ""
+	}
+	return nil
+}
+
+func TestScheduler_OnlyLeaderRunsJobs(t *testing.T) {
+	var mu sync.Mutex
+	var owner string
+	var runs [2]atomic.Int32
+
+	schedulers := make([]*Scheduler, 2)
+	for i := range schedulers {
+		i := i
+		s := New(&fakeLock{mu: &mu, owner: &owner, id: string(rune('a' + i))}, 30*time.Millisecond)
+		s.Add(Job{Name: "count", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+			runs[i].Add(1)
+			return nil
+		}})
+		s.Start(context.Background())
+		schedulers[i] = s
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if schedulers[0].IsLeader() == schedulers[1].IsLeader() {
+		t.Fatalf("exactly one scheduler should be leader")
+	}
+	if runs[0].Load() > 0 && runs[1].Load() > 0 {
+		t.Fatalf("both schedulers ran the job: %d, %d", runs[0].Load(), runs[1].Load())
+	}
+
+	// 主实例停止后，另一个实例接管
+	leader, follower := schedulers[0], schedulers[1]
+	if !leader.IsLeader() {
+		leader, follower = follower, leader
+	}
+	leader.Stop()
+	time.Sleep(50 * time.Millisecond)
+	if !follower.IsLeader() {
+		t.Fatalf("follower should take over after leader stops")
+	}
+	follower.Stop()
+}
+
+func TestScheduler_NoLockerAlwaysLeader(t *testing.T) {
+	var runs atomic.Int32
+	s := New(nil, time.Second)
+	s.Add(Job{Name: "count", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	}})
+	s.Start(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	s.Stop()
+
+	if runs.Load() == 0 {
+		t.Fatalf("job should run without locker")
+	}
+}
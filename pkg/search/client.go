@@ -0,0 +1,317 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrUnavailable Elasticsearch暂时不可用，调用方应降级
+var ErrUnavailable = errors.New("search: elasticsearch unavailable")
+
+// downCooldown 请求失败后暂停访问的时间，避免每个请求都等待超时
+const downCooldown = 30 * time.Second
+
+// Error Elasticsearch返回的错误
+type Error struct {
+	Status int    // HTTP状态码
+	Type   string // 错误类型，如 index_not_found_exception
+	Reason string // 错误原因
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("search: elasticsearch %d %s: %s", e.Status, e.Type, e.Reason)
+}
+
+// IsNotFound 是否为索引或文档不存在
+func IsNotFound(err error) bool {
+	var esErr *Error
+	return errors.As(err, &esErr) && esErr.Status == http.StatusNotFound
+}
+
+// Client Elasticsearch REST客户端
+// 只封装商品搜索用到的接口；多个节点轮询访问，请求失败后暂停访问一段时间，期间直接返回ErrUnavailable
+type Client struct {
+	addresses  []string
+	username   string
+	password   string
+	httpClient *http.Client
+	next       uint32
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// NewClient 创建Elasticsearch客户端
+// addresses: 节点地址，如 http://localhost:9200；username/password为空时不认证
+func NewClient(addresses []string, username, password string, timeout time.Duration) *Client {
+	trimmed := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if address = strings.TrimRight(strings.TrimSpace(address), "/"); address != "" {
+			trimmed = append(trimmed, address)
+		}
+	}
+	return &Client{
+		addresses: trimmed,
+		username:  username,
+		password:  password,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Available 当前是否可以访问Elasticsearch
+func (c *Client) Available() bool {
+	if c == nil || len(c.addresses) == 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().After(c.downUntil)
+}
+
+// Ping 检查Elasticsearch是否可用
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/", nil, nil)
+}
+
+// IndexExists 索引或别名是否存在
+func (c *Client) IndexExists(ctx context.Context, name string) (bool, error) {
+	err := c.do(ctx, http.MethodHead, "/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateIndex 按settings和mappings创建索引
+func (c *Client) CreateIndex(ctx context.Context, name string, body interface{}) error {
+	return c.do(ctx, http.MethodPut, "/"+url.PathEscape(name), body, nil)
+}
+
+// DeleteIndex 删除索引，索引不存在时忽略
+func (c *Client) DeleteIndex(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	escaped := make([]string, 0, len(names))
+	for _, name := range names {
+		escaped = append(escaped, url.PathEscape(name))
+	}
+	err := c.do(ctx, http.MethodDelete, "/"+strings.Join(escaped, ","), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Refresh 刷新索引，使刚写入的文档可以被搜索到
+func (c *Client) Refresh(ctx context.Context, index string) error {
+	return c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_refresh", nil, nil)
+}
+
+// AliasIndices 别名当前指向的索引，别名不存在时返回空
+func (c *Client) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	var result map[string]json.RawMessage
+	err := c.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, &result)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// SwitchAlias 原子地把别名从旧索引切换到新索引
+func (c *Client) SwitchAlias(ctx context.Context, alias, index string, oldIndices []string) error {
+	actions := make([]map[string]interface{}, 0, len(oldIndices)+1)
+	for _, old := range oldIndices {
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]string{"index": old, "alias": alias},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]string{"index": index, "alias": alias},
+	})
+	return c.do(ctx, http.MethodPost, "/_aliases", map[string]interface{}{"actions": actions}, nil)
+}
+
+// BulkAction 批量操作中的一项
+type BulkAction struct {
+	Delete   bool        // true为删除文档，否则写入Document
+	ID       string      // 文档ID
+	Document interface{} // 文档内容
+}
+
+// Bulk 批量写入或删除文档
+// 删除不存在的文档不算失败，其他任何一项失败都返回错误
+func (c *Client) Bulk(ctx context.Context, index string, actions []BulkAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, action := range actions {
+		op := "index"
+		if action.Delete {
+			op = "delete"
+		}
+		if err := encoder.Encode(map[string]interface{}{op: map[string]string{"_id": action.ID}}); err != nil {
+			return err
+		}
+		if !action.Delete {
+			if err := encoder.Encode(action.Document); err != nil {
+				return err
+			}
+		}
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_bulk", &body, &result); err != nil {
+		return err
+	}
+	if !result.Errors {
+		return nil
+	}
+	for _, item := range result.Items {
+		for op, status := range item {
+			if status.Error == nil || (op == "delete" && status.Status == http.StatusNotFound) {
+				continue
+			}
+			return &Error{Status: status.Status, Type: status.Error.Type, Reason: fmt.Sprintf("%s %s: %s", op, status.ID, status.Error.Reason)}
+		}
+	}
+	return nil
+}
+
+// Hit 一条搜索结果
+type Hit struct {
+	ID        string              `json:"_id"`
+	Score     float64             `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight"`
+}
+
+// SearchResponse 搜索结果
+type SearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []Hit `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations"`
+}
+
+// Search 执行搜索
+func (c *Client) Search(ctx context.Context, index string, query interface{}) (*SearchResponse, error) {
+	var result SearchResponse
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// do 发送请求，body为io.Reader时按NDJSON原样发送，否则编码为JSON
+// 连接失败或服务端5xx错误时暂停访问一段时间
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	if !c.Available() {
+		return ErrUnavailable
+	}
+
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+		contentType = "application/x-ndjson"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	address := c.addresses[int(atomic.AddUint32(&c.next, 1))%len(c.addresses)]
+	req, err := http.NewRequestWithContext(ctx, method, address+path, reader)
+	if err != nil {
+		return err
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.markDown()
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.markDown()
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		c.markDown()
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		esErr := &Error{Status: resp.StatusCode}
+		var payload struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			esErr.Type, esErr.Reason = payload.Error.Type, payload.Error.Reason
+		}
+		return esErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// markDown 暂停访问
+func (c *Client) markDown() {
+	c.mu.Lock()
+	c.downUntil = time.Now().Add(downCooldown)
+	c.mu.Unlock()
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_BulkReportsItemErrors(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.Write([]byte(`{"errors":true,"items":[
+			{"delete":{"_id":"1","status":404,"error":{"type":"not_found","reason":"missing"}}},
+			{"index":{"_id":"2","status":400,"error":{"type":"mapper_parsing_exception","reason":"bad price"}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient([]string{server.URL + "/"}, "", "", time.Second)
+	err := client.Bulk(context.Background(), "products", []BulkAction{
+		{ID: "1", Delete: true},
+		{ID: "2", Document: map[string]string{"name": "手机"}},
+	})
+
+	want := "{\"delete\":{\"_id\":\"1\"}}\n{\"index\":{\"_id\":\"2\"}}\n{\"name\":\"手机\"}\n"
+	if body != want {
+		t.Fatalf("unexpected bulk body:\n%s", body)
+	}
+	var esErr *Error
+	if !errors.As(err, &esErr) || esErr.Type != "mapper_parsing_exception" || !strings.Contains(esErr.Reason, "index 2") {
+		t.Fatalf("expected index error for document 2, got %v", err)
+	}
+}
+
+func TestClient_MarksDownAfterServerError(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":{"type":"cluster_block_exception","reason":"blocked"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient([]string{server.URL}, "", "", time.Second)
+	if _, err := client.Search(context.Background(), "products", map[string]interface{}{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if client.Available() {
+		t.Fatal("client should be unavailable after a server error")
+	}
+	if _, err := client.Search(context.Background(), "products", map[string]interface{}{}); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 request while marked down, got %d", calls)
+	}
+}
+
+func TestClient_IndexExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/products" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient([]string{server.URL}, "", "", time.Second)
+	for name, want := range map[string]bool{"products": true, "missing": false} {
+		exists, err := client.IndexExists(context.Background(), name)
+		if err != nil || exists != want {
+			t.Fatalf("IndexExists(%s) = %v, %v; want %v", name, exists, err, want)
+		}
+	}
+	if !client.Available() {
+		t.Fatal("404 should not mark the client down")
+	}
+}